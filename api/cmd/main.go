@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"io"
 	"log"
+	"net/http"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
 	_ "trackable-donations/api/docs" // Importar documentação Swagger
+	"trackable-donations/api/internal/config"
+	"trackable-donations/api/internal/controllers"
+	"trackable-donations/api/internal/metrics"
 	"trackable-donations/api/internal/middleware"
+	"trackable-donations/api/internal/repository"
+	"trackable-donations/api/internal/utils"
 	"trackable-donations/api/routes"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -25,66 +37,164 @@ import (
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 
 // @host localhost:8080
-// @BasePath /
+// @BasePath /api/v1
 // @schemes http https
 
 // @securityDefinitions.apikey AdminAuth
 // @in header
-// @name X-Admin-ID
-// @description Chave de autenticação para rotas administrativas
+// @name Authorization
+// @description Token JWT obtido em /admin/login, no formato "Bearer <token>"
 
 func main() {
+	// Carregar e validar toda a configuração antes de iniciar o servidor
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	utils.SetHashSalt(cfg.HashSalt)
+	utils.SetEncryptionKey(cfg.DataEncryptionKey)
+
 	// Em produção, usar modo "release"
-	if os.Getenv("ENV") == "production" {
+	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.Default()
 
+	// Métricas Prometheus: o middleware vem primeiro para medir também as requisições
+	// recusadas pelos demais (ex.: rate limiting)
+	registry := prometheus.NewRegistry()
+	if err := metrics.Register(registry); err != nil {
+		log.Fatalf("Falha ao registrar as métricas: %v", err)
+	}
+	router.Use(middleware.Metrics())
+
 	// Configurar middlewares de segurança
-	router.Use(middleware.CORS())
-	router.Use(middleware.SecureHeaders())
+	router.Use(middleware.CORS(cfg.CORSAllowedOrigins))
+	router.Use(middleware.SecureHeadersWithConfig(middleware.NewSecureHeadersConfig(cfg.Env)))
 
 	// Redirecionar HTTP para HTTPS (apenas em produção)
-	if os.Getenv("ENV") == "production" {
+	if cfg.IsProduction() {
 		router.Use(middleware.RedirectHTTP())
 	}
 
 	// Aplicar rate limiting em rotas públicas
-	publicRateLimiter := middleware.NewRateLimiter(100, 1*time.Minute)
+	publicRateLimiter := newRateLimiter(cfg, cfg.PublicRateLimit)
 
 	// Aplicar rate limiting mais restrito em rotas de admin
-	adminRateLimiter := middleware.NewRateLimiter(30, 1*time.Minute) // 30 requisições por minuto
+	adminRateLimiter := newRateLimiter(cfg, cfg.AdminRateLimit)
 
-	// Configurar rotas com rate limiting
-	routes.SetupRoutes(router, publicRateLimiter, adminRateLimiter)
+	// A reverificação pública de doações tem um limite próprio, além do público
+	verifyRateLimiter := newRateLimiter(cfg, cfg.VerifyRateLimit)
 
-	// Determinar porta
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Persistir os dados no PostgreSQL ou, sem DATABASE_URL, apenas em memória
+	store := repository.NewMemoryStore()
+	if cfg.DatabaseURL != "" {
+		db, err := repository.Open(cfg.DatabaseURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		store = repository.NewGormStore(db)
+	} else {
+		log.Println("DATABASE_URL não definido: os dados serão mantidos apenas em memória")
 	}
 
+	// Configurar rotas com rate limiting
+	jobs, err := routes.SetupRoutes(router, cfg, store, publicRateLimiter, adminRateLimiter, verifyRateLimiter)
+	if err != nil {
+		log.Fatalf("Falha ao carregar os dados: %v", err)
+	}
+
+	// As quantidades em memória são lidas dos serviços a cada coleta; /metrics fica fora do
+	// rate limiting, como o /health, para não falhar as coletas do Prometheus
+	registry.MustRegister(metrics.NewInventoryCollector(controllers.ExpenseService.MetricsInventory))
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+
 	// Configuração simplificada do Swagger - isso deve resolver o problema
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Verificar certificados SSL em produção
-	certFile := os.Getenv("SSL_CERT_FILE")
-	keyFile := os.Getenv("SSL_KEY_FILE")
+	server := &http.Server{Addr: ":" + cfg.Port, Handler: router}
 
 	// Iniciar o servidor com SSL em produção ou HTTP em desenvolvimento
-	log.Printf("Documentação Swagger disponível em http://localhost:%s/swagger/index.html", port)
-
-	if os.Getenv("ENV") == "production" && certFile != "" && keyFile != "" {
-		log.Printf("Servidor iniciando em modo seguro (HTTPS) na porta %s...", port)
-		if err := router.RunTLS(":"+port, certFile, keyFile); err != nil {
-			log.Fatalf("Falha ao iniciar servidor HTTPS: %v", err)
+	log.Printf("Documentação Swagger disponível em http://localhost:%s/swagger/index.html", cfg.Port)
+
+	go func() {
+		var err error
+		if cfg.IsProduction() {
+			log.Printf("Servidor iniciando em modo seguro (HTTPS) na porta %s...", cfg.Port)
+			err = server.ListenAndServeTLS(cfg.SSLCertFile, cfg.SSLKeyFile)
+		} else {
+			log.Printf("Servidor iniciando em modo HTTP na porta %s...", cfg.Port)
+			err = server.ListenAndServe()
 		}
-	} else {
-		log.Printf("Servidor iniciando em modo HTTP na porta %s...", port)
-		log.Println("AVISO: Em produção, configure SSL_CERT_FILE e SSL_KEY_FILE para usar HTTPS")
-		if err := router.Run(":" + port); err != nil {
-			log.Fatalf("Falha ao iniciar servidor HTTP: %v", err)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Falha ao iniciar servidor: %v", err)
 		}
+	}()
+
+	// Aguardar o sinal de desligamento
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Desligando o servidor...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Erro ao desligar o servidor: %v", err)
+	}
+
+	// Só depois de parar de aceitar requisições encerrar os componentes em segundo plano
+	closeAll(ctx, append(jobs, publicRateLimiter, adminRateLimiter, verifyRateLimiter))
+
+	// O banco é fechado por último, pois os jobs ainda podem gravar enquanto terminam
+	if err := store.Close(); err != nil {
+		log.Printf("Erro ao fechar o banco de dados: %v", err)
+	}
+	log.Println("Servidor encerrado")
+}
+
+// closeAll fecha os componentes em paralelo, registrando os que falharem ou não
+// terminarem antes do fim do prazo do contexto
+func closeAll(ctx context.Context, closers []io.Closer) {
+	type result struct {
+		closer io.Closer
+		err    error
+	}
+
+	results := make(chan result, len(closers))
+	for _, closer := range closers {
+		go func(closer io.Closer) {
+			results <- result{closer, closer.Close()}
+		}(closer)
+	}
+
+	pending := make(map[io.Closer]bool, len(closers))
+	for _, closer := range closers {
+		pending[closer] = true
+	}
+
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.closer)
+			if r.err != nil {
+				log.Printf("Erro ao encerrar %T: %v", r.closer, r.err)
+			}
+		case <-ctx.Done():
+			for closer := range pending {
+				log.Printf("%T não terminou dentro do prazo de desligamento", closer)
+			}
+			return
+		}
+	}
+}
+
+// newRateLimiter cria o limitador do algoritmo configurado. No token bucket, o limite é a
+// capacidade do balde, reposta por completo ao longo de uma janela.
+func newRateLimiter(cfg config.Config, limit int) middleware.Limiter {
+	if cfg.RateLimitAlgorithm == config.RateLimitAlgorithmTokenBucket {
+		return middleware.NewTokenBucketLimiter(limit, float64(limit)/cfg.RateLimitWindow.Seconds())
 	}
+	return middleware.NewRateLimiter(limit, cfg.RateLimitWindow)
 }
@@ -1,3 +1,116 @@
 package auth
 
-// Implementação da lógica de autenticação JWT 
\ No newline at end of file
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ContextAdminIDKey é a chave do contexto do Gin em que o middleware guarda o ID do administrador autenticado
+const ContextAdminIDKey = "admin_id"
+
+var (
+	// ErrInvalidCredentials indica usuário ou senha de administrador incorretos
+	ErrInvalidCredentials = errors.New("usuário ou senha inválidos")
+	// ErrInvalidToken indica um token ausente, adulterado ou expirado
+	ErrInvalidToken = errors.New("token inválido ou expirado")
+)
+
+// Admin é uma conta de administrador autorizada a obter tokens de acesso
+type Admin struct {
+	ID           uint
+	Username     string
+	PasswordHash string // Hash bcrypt da senha
+}
+
+// Manager autentica administradores e emite/valida tokens JWT assinados com HS256
+type Manager struct {
+	secret []byte
+	ttl    time.Duration
+	admins map[string]Admin
+	// now permite controlar o relógio nos testes
+	now func() time.Time
+}
+
+// NewManager cria o gerenciador de tokens. Sem segredo, é gerado um aleatório, e os
+// tokens emitidos deixam de valer quando a API reinicia (útil apenas em desenvolvimento).
+func NewManager(secret string, ttl time.Duration, admins []Admin) *Manager {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(fmt.Sprintf("falha ao gerar segredo JWT: %v", err))
+		}
+	}
+
+	byUsername := make(map[string]Admin, len(admins))
+	for _, admin := range admins {
+		byUsername[admin.Username] = admin
+	}
+
+	return &Manager{secret: key, ttl: ttl, admins: byUsername, now: time.Now}
+}
+
+// Login confere as credenciais e emite um token de acesso para o administrador
+func (m *Manager) Login(username, password string) (string, time.Time, error) {
+	admin, ok := m.admins[username]
+	if !ok || bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte(password)) != nil {
+		return "", time.Time{}, ErrInvalidCredentials
+	}
+	return m.IssueToken(admin.ID)
+}
+
+// IssueToken emite um token de acesso para o administrador, válido pelo TTL configurado
+func (m *Manager) IssueToken(adminID uint) (string, time.Time, error) {
+	now := m.now()
+	expiresAt := now.Add(m.ttl)
+
+	// O ID do administrador vai no "sub"
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(uint64(adminID), 10),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+
+	signed, err := token.SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("falha ao assinar token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseToken valida a assinatura e a expiração do token e retorna o ID do administrador
+func (m *Manager) ParseToken(tokenString string) (uint, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims,
+		func(*jwt.Token) (any, error) { return m.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(m.now),
+	)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+
+	adminID, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil || adminID == 0 {
+		return 0, ErrInvalidToken
+	}
+	return uint(adminID), nil
+}
+
+// AdminIDFromContext retorna o ID do administrador autenticado na requisição
+func AdminIDFromContext(c *gin.Context) (uint, bool) {
+	value, ok := c.Get(ContextAdminIDKey)
+	if !ok {
+		return 0, false
+	}
+	adminID, ok := value.(uint)
+	return adminID, ok && adminID != 0
+}
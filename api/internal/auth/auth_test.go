@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testSecret = "segredo-de-teste-com-32-caracteres!"
+
+func newTestManager(t *testing.T) *Manager {
+	hash, err := bcrypt.GenerateFromPassword([]byte("senha-forte"), bcrypt.MinCost)
+	require.NoError(t, err)
+	return NewManager(testSecret, time.Hour, []Admin{{ID: 7, Username: "admin", PasswordHash: string(hash)}})
+}
+
+func TestLoginIssuesTokenForAdmin(t *testing.T) {
+	manager := newTestManager(t)
+
+	token, expiresAt, err := manager.Login("admin", "senha-forte")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+	adminID, err := manager.ParseToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), adminID)
+
+	for _, credentials := range [][2]string{{"admin", "senha-errada"}, {"outro", "senha-forte"}} {
+		_, _, err := manager.Login(credentials[0], credentials[1])
+		assert.ErrorIs(t, err, ErrInvalidCredentials, credentials[0])
+	}
+}
+
+func TestParseTokenRejectsForgedTokens(t *testing.T) {
+	manager := newTestManager(t)
+	claims := jwt.RegisteredClaims{Subject: "1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+
+	// Assinado com outro segredo
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("outro-segredo"))
+	require.NoError(t, err)
+	_, err = manager.ParseToken(forged)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Sem assinatura (alg "none")
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	_, err = manager.ParseToken(unsigned)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Sem expiração
+	noExpiry, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "1"}).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	_, err = manager.ParseToken(noExpiry)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = manager.ParseToken("nao.e.jwt")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestParseTokenRejectsExpiredToken(t *testing.T) {
+	manager := newTestManager(t)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+
+	token, _, err := manager.IssueToken(7)
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour + time.Second)
+	_, err = manager.ParseToken(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,410 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"trackable-donations/api/internal/auth"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Config reúne toda a configuração da API lida das variáveis de ambiente na inicialização
+type Config struct {
+	Env         string
+	Port        string
+	SSLCertFile string
+	SSLKeyFile  string
+	HashSalt    string
+
+	// Segredo que cifra as chaves privadas da blockchain guardadas no banco
+	DataEncryptionKey string
+
+	// Conexão com o PostgreSQL (vazio = armazenamento em memória, para desenvolvimento)
+	DatabaseURL string
+
+	// Cria as ONGs e usuários de demonstração quando o armazenamento está vazio
+	// (padrão: ligado, exceto em produção)
+	SeedDemoData bool
+
+	// Autenticação dos administradores (tokens JWT HS256)
+	JWTSecret     string
+	AdminTokenTTL time.Duration
+	AdminUsers    []auth.Admin
+
+	// Segredo compartilhado com o gateway para assinar os webhooks de pagamento (vazio = webhooks rejeitados)
+	PaymentWebhookSecret string
+
+	// Origens liberadas para CORS ("*" libera qualquer origem, apenas em desenvolvimento)
+	CORSAllowedOrigins []string
+
+	// Rate limiting (requisições por janela). Com o algoritmo token_bucket, o limite é a
+	// capacidade do balde, reposta ao longo da janela. VerifyRateLimit vale para a
+	// reverificação pública de doações, mais custosa, além do limite público
+	PublicRateLimit    int
+	AdminRateLimit     int
+	VerifyRateLimit    int
+	RateLimitWindow    time.Duration
+	RateLimitAlgorithm string
+
+	// Limite de gastos por doação (0 = ilimitado)
+	MaxExpensesPerDonation int
+
+	// Chaves de metadados de doações que podem aparecer nas visões públicas
+	PublicMetadataKeys []string
+
+	// Valores mínimo e máximo, em reais, aceitos em uma doação (ONGs podem ter limites próprios)
+	MinDonationAmount float64
+	MaxDonationAmount float64
+
+	// Cotações em reais das moedas estrangeiras aceitas nas doações (sem cotação = moeda recusada)
+	ExchangeRates map[string]float64
+
+	// URL da API HTTP do nó IPFS (vazio = armazenamento em memória, para desenvolvimento)
+	IPFSAPIURL string
+
+	// URL do nó da blockchain, verificado no /health (vazio = não verificado)
+	BlockchainNodeURL string
+
+	// Tempo máximo de espera por cada dependência na verificação de saúde
+	HealthCheckTimeout time.Duration
+
+	// Tempo durante o qual o dashboard global é reaproveitado (recalculado antes disso a cada nova doação concluída)
+	DashboardCacheTTL time.Duration
+
+	// Servidor SMTP para envio de e-mails aos doadores (SMTPHost vazio = notificações apenas no log)
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// Tipos MIME aceitos por categoria de upload (ver UploadType*)
+	AllowedUploadTypes map[string][]string
+	// Tamanho máximo, em bytes, das requisições de upload (MAX_UPLOAD_SIZE_MB, padrão 10 MB)
+	MaxUploadSize int64
+
+	// Jobs em segundo plano
+	PaymentReminderAfter      time.Duration
+	PendingDonationTTL        time.Duration // Doações pendentes há mais tempo expiram e deixam de receber lembretes
+	PaymentReminderInterval   time.Duration
+	PendingExpiryInterval     time.Duration
+	RecurringDonationInterval time.Duration
+
+	// Tempo máximo de espera pelas requisições e jobs em andamento no desligamento
+	ShutdownTimeout time.Duration
+}
+
+// Algoritmos de rate limiting aceitos em RATE_LIMIT_ALGORITHM
+const (
+	RateLimitAlgorithmWindow      = "window"
+	RateLimitAlgorithmTokenBucket = "token_bucket"
+)
+
+// Categorias de upload com tipos MIME configuráveis
+const (
+	UploadTypeReceipt     = "receipt"
+	UploadTypeNGODocument = "ngo_document"
+	UploadTypeLogo        = "logo"
+)
+
+// DefaultMaxUploadSize é o tamanho máximo padrão das requisições de upload
+const DefaultMaxUploadSize = 10 << 20
+
+// DefaultAllowedUploadTypes retorna os tipos MIME aceitos por padrão em cada categoria de upload
+func DefaultAllowedUploadTypes() map[string][]string {
+	return map[string][]string{
+		UploadTypeReceipt:     {"application/pdf", "image/jpeg", "image/png"},
+		UploadTypeNGODocument: {"application/pdf", "image/jpeg", "image/png", utils.MIMETypeDOCX},
+		UploadTypeLogo:        {"image/jpeg", "image/png", "image/webp"},
+	}
+}
+
+// IsProduction indica se a API está rodando em produção
+func (c Config) IsProduction() bool {
+	return c.Env == "production"
+}
+
+// Load lê e valida toda a configuração de uma só vez. Em caso de erro, retorna
+// uma mensagem agregada com todos os problemas encontrados.
+func Load() (Config, error) {
+	var problems []error
+
+	cfg := Config{
+		Env:         os.Getenv("ENV"),
+		Port:        getEnv("PORT", "8080"),
+		SSLCertFile: os.Getenv("SSL_CERT_FILE"),
+		SSLKeyFile:  os.Getenv("SSL_KEY_FILE"),
+		HashSalt:    os.Getenv("HASH_SALT"),
+		JWTSecret:   os.Getenv("JWT_SECRET"),
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+
+		PaymentWebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		DataEncryptionKey:    os.Getenv("DATA_ENCRYPTION_KEY"),
+	}
+
+	if port, err := strconv.Atoi(cfg.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Errorf("PORT deve ser um número entre 1 e 65535 (recebido %q)", cfg.Port))
+	}
+
+	cfg.PublicRateLimit = parseInt("PUBLIC_RATE_LIMIT", 100, 1, &problems)
+	cfg.AdminRateLimit = parseInt("ADMIN_RATE_LIMIT", 30, 1, &problems)
+	cfg.VerifyRateLimit = parseInt("VERIFY_RATE_LIMIT", 10, 1, &problems)
+	cfg.RateLimitWindow = parseDuration("RATE_LIMIT_WINDOW", time.Minute, &problems)
+	cfg.RateLimitAlgorithm = getEnv("RATE_LIMIT_ALGORITHM", RateLimitAlgorithmWindow)
+	if cfg.RateLimitAlgorithm != RateLimitAlgorithmWindow && cfg.RateLimitAlgorithm != RateLimitAlgorithmTokenBucket {
+		problems = append(problems, fmt.Errorf("RATE_LIMIT_ALGORITHM deve ser %q ou %q (recebido %q)",
+			RateLimitAlgorithmWindow, RateLimitAlgorithmTokenBucket, cfg.RateLimitAlgorithm))
+	}
+	cfg.SeedDemoData = parseBool("SEED_DEMO_DATA", !cfg.IsProduction(), &problems)
+	cfg.MaxExpensesPerDonation = parseInt("MAX_EXPENSES_PER_DONATION", 0, 0, &problems)
+	cfg.PublicMetadataKeys = parseList("PUBLIC_METADATA_KEYS")
+	cfg.ExchangeRates = parseExchangeRates("EXCHANGE_RATES", &problems)
+	cfg.MinDonationAmount = parseAmount("MIN_DONATION_AMOUNT", 1, &problems)
+	cfg.MaxDonationAmount = parseAmount("MAX_DONATION_AMOUNT", 1_000_000, &problems)
+	if cfg.MinDonationAmount > cfg.MaxDonationAmount {
+		problems = append(problems, fmt.Errorf("MIN_DONATION_AMOUNT (%.2f) não pode ser maior que MAX_DONATION_AMOUNT (%.2f)",
+			cfg.MinDonationAmount, cfg.MaxDonationAmount))
+	}
+
+	// Ex.: CORS_ALLOWED_ORIGINS=https://levitate.org,https://admin.levitate.org
+	cfg.CORSAllowedOrigins = parseList("CORS_ALLOWED_ORIGINS")
+	for _, origin := range cfg.CORSAllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			problems = append(problems, fmt.Errorf("CORS_ALLOWED_ORIGINS deve conter origens como https://exemplo.org (recebido %q)", origin))
+		}
+	}
+
+	cfg.IPFSAPIURL = os.Getenv("IPFS_API_URL")
+	if cfg.IPFSAPIURL != "" {
+		if u, err := url.Parse(cfg.IPFSAPIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Errorf("IPFS_API_URL deve ser uma URL http(s), ex.: http://localhost:5001 (recebido %q)", cfg.IPFSAPIURL))
+		}
+	}
+
+	cfg.BlockchainNodeURL = os.Getenv("BLOCKCHAIN_NODE_URL")
+	if cfg.BlockchainNodeURL != "" {
+		if u, err := url.Parse(cfg.BlockchainNodeURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Errorf("BLOCKCHAIN_NODE_URL deve ser uma URL http(s), ex.: http://localhost:8545 (recebido %q)", cfg.BlockchainNodeURL))
+		}
+	}
+
+	cfg.SMTPHost = os.Getenv("SMTP_HOST")
+	cfg.SMTPPort = parseInt("SMTP_PORT", 587, 1, &problems)
+	cfg.SMTPUsername = os.Getenv("SMTP_USERNAME")
+	cfg.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+	cfg.SMTPFrom = os.Getenv("SMTP_FROM")
+	if cfg.SMTPHost != "" {
+		if _, err := mail.ParseAddress(cfg.SMTPFrom); err != nil {
+			problems = append(problems, fmt.Errorf("SMTP_FROM deve ser um e-mail válido quando SMTP_HOST é definido (recebido %q)", cfg.SMTPFrom))
+		}
+	}
+
+	// Ex.: ALLOWED_UPLOAD_TYPES_RECEIPT=application/pdf,image/png
+	cfg.AllowedUploadTypes = DefaultAllowedUploadTypes()
+	for uploadType := range cfg.AllowedUploadTypes {
+		if types := parseList("ALLOWED_UPLOAD_TYPES_" + strings.ToUpper(uploadType)); len(types) > 0 {
+			cfg.AllowedUploadTypes[uploadType] = types
+		}
+	}
+
+	cfg.MaxUploadSize = int64(parseInt("MAX_UPLOAD_SIZE_MB", DefaultMaxUploadSize>>20, 1, &problems)) << 20
+
+	cfg.PaymentReminderAfter = parseDuration("PAYMENT_REMINDER_AFTER", time.Hour, &problems)
+	cfg.PendingDonationTTL = parseDuration("PENDING_DONATION_TTL", 24*time.Hour, &problems)
+	cfg.PaymentReminderInterval = parseDuration("PAYMENT_REMINDER_INTERVAL", 15*time.Minute, &problems)
+	cfg.PendingExpiryInterval = parseDuration("PENDING_DONATION_EXPIRY_INTERVAL", 15*time.Minute, &problems)
+	cfg.RecurringDonationInterval = parseDuration("RECURRING_DONATION_INTERVAL", time.Hour, &problems)
+	cfg.ShutdownTimeout = parseDuration("SHUTDOWN_TIMEOUT", 10*time.Second, &problems)
+	cfg.HealthCheckTimeout = parseDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second, &problems)
+	cfg.DashboardCacheTTL = parseDuration("DASHBOARD_CACHE_TTL", time.Minute, &problems)
+	cfg.AdminTokenTTL = parseDuration("ADMIN_TOKEN_TTL", time.Hour, &problems)
+	cfg.AdminUsers = parseAdminUsers("ADMIN_USERS", &problems)
+
+	if cfg.IsProduction() {
+		if cfg.HashSalt == "" {
+			problems = append(problems, errors.New("HASH_SALT é obrigatório em produção"))
+		}
+		if cfg.DatabaseURL == "" {
+			problems = append(problems, errors.New("DATABASE_URL é obrigatório em produção"))
+		}
+		if cfg.PaymentWebhookSecret == "" {
+			problems = append(problems, errors.New("PAYMENT_WEBHOOK_SECRET é obrigatório em produção"))
+		}
+		if slices.Contains(cfg.CORSAllowedOrigins, "*") {
+			problems = append(problems, errors.New("CORS_ALLOWED_ORIGINS não pode liberar qualquer origem (\"*\") em produção"))
+		}
+		if len(cfg.JWTSecret) < minJWTSecretLength {
+			problems = append(problems, fmt.Errorf("JWT_SECRET é obrigatório em produção e deve ter ao menos %d caracteres", minJWTSecretLength))
+		}
+		if len(cfg.DataEncryptionKey) < minDataEncryptionKeyLength {
+			problems = append(problems, fmt.Errorf("DATA_ENCRYPTION_KEY é obrigatório em produção e deve ter ao menos %d caracteres", minDataEncryptionKeyLength))
+		}
+		for _, file := range []struct{ key, path string }{
+			{"SSL_CERT_FILE", cfg.SSLCertFile},
+			{"SSL_KEY_FILE", cfg.SSLKeyFile},
+		} {
+			if file.path == "" {
+				problems = append(problems, fmt.Errorf("%s é obrigatório em produção", file.key))
+			} else if _, err := os.Stat(file.path); err != nil {
+				problems = append(problems, fmt.Errorf("%s não encontrado: %s", file.key, file.path))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("configuração inválida:\n%w", errors.Join(problems...))
+	}
+	return cfg, nil
+}
+
+// minJWTSecretLength é o tamanho mínimo do segredo JWT exigido em produção
+const minJWTSecretLength = 32
+
+// minDataEncryptionKeyLength é o tamanho mínimo do segredo de criptografia exigido em produção
+const minDataEncryptionKeyLength = 32
+
+// parseAdminUsers lê as contas de administrador no formato "id:usuário:hash-bcrypt",
+// separadas por vírgula, registrando os problemas encontrados
+func parseAdminUsers(key string, problems *[]error) []auth.Admin {
+	var admins []auth.Admin
+	seen := map[string]bool{}
+	for _, entry := range parseList(key) {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			*problems = append(*problems, fmt.Errorf("%s: use o formato id:usuário:hash-bcrypt (recebido %q)", key, entry))
+			continue
+		}
+
+		id, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil || id == 0 {
+			*problems = append(*problems, fmt.Errorf("%s: ID de administrador inválido %q", key, parts[0]))
+			continue
+		}
+		if parts[1] == "" || seen[parts[1]] {
+			*problems = append(*problems, fmt.Errorf("%s: usuário vazio ou repetido %q", key, parts[1]))
+			continue
+		}
+		if _, err := bcrypt.Cost([]byte(parts[2])); err != nil {
+			*problems = append(*problems, fmt.Errorf("%s: a senha de %q deve ser um hash bcrypt", key, parts[1]))
+			continue
+		}
+
+		seen[parts[1]] = true
+		admins = append(admins, auth.Admin{ID: uint(id), Username: parts[1], PasswordHash: parts[2]})
+	}
+	return admins
+}
+
+// parseExchangeRates lê as cotações no formato "moeda:reais", separadas por vírgula
+// (ex.: USD:5.10,EUR:5.50), registrando os problemas encontrados
+func parseExchangeRates(key string, problems *[]error) map[string]float64 {
+	rates := map[string]float64{}
+	for _, entry := range parseList(key) {
+		currency, value, ok := strings.Cut(entry, ":")
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if !ok || !slices.Contains(models.SupportedCurrencies, currency) || currency == models.DefaultCurrency {
+			*problems = append(*problems, fmt.Errorf("%s: use o formato moeda:reais com uma moeda estrangeira suportada (%s), ex.: USD:5.10 (recebido %q)",
+				key, strings.Join(models.SupportedCurrencies, ", "), entry))
+			continue
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			*problems = append(*problems, fmt.Errorf("%s: cotação inválida para %s (recebido %q)", key, currency, value))
+			continue
+		}
+		rates[currency] = rate
+	}
+	return rates
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// parseList lê uma lista separada por vírgulas, ignorando itens vazios
+func parseList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseBool lê um booleano (true/false, 1/0), registrando o problema quando inválido
+func parseBool(key string, defaultValue bool, problems *[]error) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		*problems = append(*problems, fmt.Errorf("%s deve ser true ou false (recebido %q)", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+// parseInt lê um inteiro maior ou igual a min, registrando o problema quando inválido
+func parseInt(key string, defaultValue, min int, problems *[]error) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < min {
+		*problems = append(*problems, fmt.Errorf("%s deve ser um inteiro maior ou igual a %d (recebido %q)", key, min, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+// parseAmount lê um valor em reais maior que zero (ex.: "1", "1000000.50"), registrando o problema quando inválido
+func parseAmount(key string, defaultValue float64, problems *[]error) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed <= 0 || math.IsInf(parsed, 0) {
+		*problems = append(*problems, fmt.Errorf("%s deve ser um valor em reais maior que zero, ex.: 10.50 (recebido %q)", key, value))
+		return defaultValue
+	}
+	return parsed
+}
+
+// parseDuration lê uma duração positiva (ex.: "15m", "24h"), registrando o problema quando inválida
+func parseDuration(key string, defaultValue time.Duration, problems *[]error) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		*problems = append(*problems, fmt.Errorf("%s deve ser uma duração positiva, ex.: 15m (recebido %q)", key, value))
+		return defaultValue
+	}
+	return parsed
+}
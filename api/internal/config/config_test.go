@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadValidProductionConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, []byte("cert"), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, []byte("key"), 0o600))
+
+	t.Setenv("ENV", "production")
+	t.Setenv("PORT", "9090")
+	t.Setenv("SSL_CERT_FILE", certFile)
+	t.Setenv("SSL_KEY_FILE", keyFile)
+	t.Setenv("HASH_SALT", "segredo")
+	t.Setenv("JWT_SECRET", "um-segredo-jwt-com-mais-de-32-caracteres")
+	t.Setenv("DATA_ENCRYPTION_KEY", "uma-chave-de-criptografia-com-32-caracteres")
+	t.Setenv("DATABASE_URL", "postgres://levitate:senha@db:5432/trackable_donations")
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "segredo-do-gateway")
+	t.Setenv("ADMIN_USERS", "1:admin:$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy")
+	t.Setenv("PUBLIC_RATE_LIMIT", "200")
+	t.Setenv("PAYMENT_REMINDER_AFTER", "2h")
+	t.Setenv("RATE_LIMIT_ALGORITHM", "token_bucket")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://levitate.org, https://admin.levitate.org")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.IsProduction())
+	assert.Equal(t, "9090", cfg.Port)
+	assert.Equal(t, 200, cfg.PublicRateLimit)
+	assert.Equal(t, 30, cfg.AdminRateLimit, "Valores ausentes devem usar o padrão")
+	assert.Equal(t, 10, cfg.VerifyRateLimit)
+	assert.Equal(t, 2*time.Hour, cfg.PaymentReminderAfter)
+	assert.Equal(t, time.Minute, cfg.RateLimitWindow)
+	assert.Equal(t, RateLimitAlgorithmTokenBucket, cfg.RateLimitAlgorithm)
+	assert.Equal(t, []string{"https://levitate.org", "https://admin.levitate.org"}, cfg.CORSAllowedOrigins)
+	assert.Equal(t, time.Hour, cfg.AdminTokenTTL)
+	require.Len(t, cfg.AdminUsers, 1)
+	assert.Equal(t, uint(1), cfg.AdminUsers[0].ID)
+	assert.Equal(t, "admin", cfg.AdminUsers[0].Username)
+	assert.Equal(t, "postgres://levitate:senha@db:5432/trackable_donations", cfg.DatabaseURL)
+	assert.False(t, cfg.SeedDemoData, "Em produção os dados de demonstração ficam desligados por padrão")
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	t.Setenv("ENV", "production")
+	t.Setenv("PORT", "porta")
+	t.Setenv("SSL_CERT_FILE", filepath.Join(t.TempDir(), "inexistente.pem"))
+	t.Setenv("SSL_KEY_FILE", "")
+	t.Setenv("HASH_SALT", "")
+	t.Setenv("JWT_SECRET", "curto")
+	t.Setenv("DATA_ENCRYPTION_KEY", "")
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "")
+	t.Setenv("ADMIN_USERS", "1:admin:senha-em-texto")
+	t.Setenv("ADMIN_RATE_LIMIT", "0")
+	t.Setenv("RATE_LIMIT_ALGORITHM", "leaky_bucket")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+	t.Setenv("PENDING_DONATION_TTL", "ontem")
+	t.Setenv("SEED_DEMO_DATA", "talvez")
+	t.Setenv("IPFS_API_URL", "localhost:5001")
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_FROM", "")
+
+	_, err := Load()
+	require.Error(t, err)
+
+	for _, key := range []string{"PORT", "SSL_CERT_FILE", "SSL_KEY_FILE", "HASH_SALT", "ADMIN_RATE_LIMIT", "RATE_LIMIT_ALGORITHM", "CORS_ALLOWED_ORIGINS", "PENDING_DONATION_TTL", "SEED_DEMO_DATA", "IPFS_API_URL", "SMTP_FROM", "JWT_SECRET", "DATA_ENCRYPTION_KEY", "ADMIN_USERS", "DATABASE_URL", "PAYMENT_WEBHOOK_SECRET"} {
+		assert.Contains(t, err.Error(), key)
+	}
+}
+
+func TestLoadRejectsInvalidCORSOrigins(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "levitate.org,https://levitate.org/doacoes")
+
+	_, err := Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"levitate.org"`)
+	assert.Contains(t, err.Error(), `"https://levitate.org/doacoes"`)
+}
+
+func TestLoadSeedDemoData(t *testing.T) {
+	t.Setenv("ENV", "development")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.SeedDemoData, "Fora de produção os dados de demonstração são criados por padrão")
+
+	t.Setenv("SEED_DEMO_DATA", "false")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.SeedDemoData)
+}
+
+func TestLoadExchangeRates(t *testing.T) {
+	t.Setenv("EXCHANGE_RATES", "usd:5.10, EUR:5.5")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"USD": 5.1, "EUR": 5.5}, cfg.ExchangeRates)
+
+	t.Setenv("EXCHANGE_RATES", "JPY:0.03,USD:zero,BRL:1")
+	_, err = Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"JPY:0.03"`)
+	assert.Contains(t, err.Error(), `cotação inválida para USD`)
+	assert.Contains(t, err.Error(), `"BRL:1"`)
+}
+
+func TestLoadMaxUploadSize(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(DefaultMaxUploadSize), cfg.MaxUploadSize)
+
+	t.Setenv("MAX_UPLOAD_SIZE_MB", "25")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(25<<20), cfg.MaxUploadSize)
+
+	t.Setenv("MAX_UPLOAD_SIZE_MB", "0")
+	_, err = Load()
+	assert.ErrorContains(t, err, "MAX_UPLOAD_SIZE_MB")
+}
+
+func TestLoadDonationLimits(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, cfg.MinDonationAmount)
+	assert.Equal(t, 1_000_000.0, cfg.MaxDonationAmount)
+
+	t.Setenv("MIN_DONATION_AMOUNT", "5.50")
+	t.Setenv("MAX_DONATION_AMOUNT", "50000")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 5.5, cfg.MinDonationAmount)
+	assert.Equal(t, 50000.0, cfg.MaxDonationAmount)
+
+	t.Setenv("MIN_DONATION_AMOUNT", "100000")
+	_, err = Load()
+	assert.ErrorContains(t, err, "MIN_DONATION_AMOUNT (100000.00) não pode ser maior que MAX_DONATION_AMOUNT")
+
+	t.Setenv("MAX_DONATION_AMOUNT", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "MAX_DONATION_AMOUNT deve ser um valor em reais maior que zero")
+}
@@ -1,10 +1,17 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
+	"trackable-donations/api/internal/auth"
+	"trackable-donations/api/internal/config"
 	"trackable-donations/api/internal/models"
 	"trackable-donations/api/internal/services"
+	"trackable-donations/api/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,11 +24,49 @@ func SetupAdminService(donationService *services.DonationService, expenseService
 	AdminService = services.NewAdminService(donationService, expenseService)
 }
 
+// AuthManager autentica os administradores e emite os tokens de acesso
+var AuthManager *auth.Manager
+
+// SetupAuth configura a autenticação dos administradores
+func SetupAuth(manager *auth.Manager) {
+	AuthManager = manager
+}
+
+// AdminLogin confere as credenciais do administrador e emite um token JWT
+func AdminLogin(ctx *gin.Context) {
+	var req models.AdminLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao decodificar dados de login"})
+		return
+	}
+
+	token, expiresAt, err := AuthManager.Login(req.Username, req.Password)
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.AdminLoginResponse{Token: token, TokenType: "Bearer", ExpiresAt: expiresAt})
+}
+
+// requireAdminID retorna o ID do administrador autenticado pelo middleware, respondendo
+// 401 quando a requisição não passou pela autenticação
+func requireAdminID(ctx *gin.Context) (uint, bool) {
+	adminID, ok := auth.AdminIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Acesso não autorizado"})
+	}
+	return adminID, ok
+}
+
 // RegisterNGO processa o registro de uma nova ONG
 func RegisterNGO(ctx *gin.Context) {
 	var req models.NGORegistrationRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao decodificar dados do registro de ONG"})
+	if !bindJSON(ctx, &req) {
 		return
 	}
 
@@ -31,7 +76,11 @@ func RegisterNGO(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, registration)
+	// O segredo que assina os eventos de status só é informado aqui
+	ctx.JSON(http.StatusCreated, struct {
+		models.NGORegistration
+		WebhookSecret string `json:"webhook_secret,omitempty"`
+	}{registration, registration.WebhookSecret})
 }
 
 // ValidateCNPJ valida o CNPJ de um registro de ONG
@@ -59,27 +108,23 @@ func UploadNGODocuments(ctx *gin.Context) {
 		return
 	}
 
-	// Limite o upload para 10MB
-	file, _, err := ctx.Request.FormFile("documents")
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao processar arquivo: " + err.Error()})
+	file, ok := formFile(ctx, "documents")
+	if !ok {
 		return
 	}
 	defer file.Close()
 
-	fileBytes := make([]byte, 0)
-	buf := make([]byte, 1024)
-	for {
-		n, err := file.Read(buf)
-		if n > 0 {
-			fileBytes = append(fileBytes, buf[:n]...)
-		}
-		if err != nil {
-			break
-		}
+	fileBytes, ok := readUpload(ctx, file)
+	if !ok {
+		return
 	}
 
-	registration, err := AdminService.UploadNGODocuments(uint(regID), fileBytes)
+	if err := validateUploadType(config.UploadTypeNGODocument, fileBytes); err != nil {
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+
+	registration, err := AdminService.UploadNGODocuments(ctx.Request.Context(), uint(regID), fileBytes)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -96,8 +141,12 @@ func ApproveNGO(ctx *gin.Context) {
 		return
 	}
 
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
 	type ApprovalRequest struct {
-		AdminID  uint   `json:"admin_id" binding:"required"`
 		Comments string `json:"comments"`
 	}
 
@@ -107,13 +156,17 @@ func ApproveNGO(ctx *gin.Context) {
 		return
 	}
 
-	ngo, err := AdminService.ApproveNGO(uint(regID), req.AdminID, req.Comments)
+	ngo, apiKey, err := AdminService.ApproveNGO(uint(regID), adminID, req.Comments)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, ngo)
+	// A chave de API com que a ONG envia seus gastos só é informada aqui
+	ctx.JSON(http.StatusOK, struct {
+		models.NGO
+		APIKey string `json:"api_key"`
+	}{ngo, apiKey})
 }
 
 // RejectNGO rejeita o registro de uma ONG
@@ -124,9 +177,13 @@ func RejectNGO(ctx *gin.Context) {
 		return
 	}
 
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
 	type RejectionRequest struct {
-		AdminID uint   `json:"admin_id" binding:"required"`
-		Reason  string `json:"reason" binding:"required"`
+		Reason string `json:"reason" binding:"required"`
 	}
 
 	var req RejectionRequest
@@ -135,7 +192,7 @@ func RejectNGO(ctx *gin.Context) {
 		return
 	}
 
-	registration, err := AdminService.RejectNGO(uint(regID), req.AdminID, req.Reason)
+	registration, err := AdminService.RejectNGO(uint(regID), adminID, req.Reason)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -144,10 +201,324 @@ func RejectNGO(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, registration)
 }
 
-// GetNGORegistrations retorna todos os registros de ONGs
+// ReopenNGORegistration reabre um registro de ONG rejeitado, devolvendo-o ao status pendente
+func ReopenNGORegistration(ctx *gin.Context) {
+	regID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de registro inválido"})
+		return
+	}
+
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
+	type ReopenRequest struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+
+	var req ReopenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao decodificar dados da reabertura"})
+		return
+	}
+
+	registration, err := AdminService.ReopenNGORegistration(uint(regID), adminID, req.Reason)
+	switch {
+	case errors.Is(err, services.ErrNGORegistrationNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNGORegistrationNotRejected):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusOK, registration)
+	}
+}
+
+// GetPendingExpenses retorna a fila de gastos aguardando aprovação, do mais antigo ao mais recente
+func GetPendingExpenses(ctx *gin.Context) {
+	var page, pageSize int
+	for param, target := range map[string]*int{"page": &page, "page_size": &pageSize} {
+		if value := ctx.Query(param); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 1 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " deve ser um inteiro positivo"})
+				return
+			}
+			*target = number
+		}
+	}
+
+	result, err := ExpenseService.GetPendingExpenses(page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// ApproveExpense aprova um gasto pendente após a revisão do comprovante
+func ApproveExpense(ctx *gin.Context) {
+	expenseID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de despesa inválido"})
+		return
+	}
+
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := AdminService.ApproveExpense(uint(expenseID), adminID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Despesa aprovada com sucesso"})
+}
+
+// RejectExpense rejeita um gasto pendente, registrando o motivo
+func RejectExpense(ctx *gin.Context) {
+	expenseID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de despesa inválido"})
+		return
+	}
+
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
+	type RejectionRequest struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+
+	var req RejectionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao decodificar dados da rejeição"})
+		return
+	}
+
+	if err := AdminService.RejectExpense(uint(expenseID), adminID, req.Reason); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Despesa rejeitada"})
+}
+
+// SuspendNGO suspende uma ONG, que deixa de receber novas doações
+func SuspendNGO(ctx *gin.Context) {
+	ngoID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de ONG inválido"})
+		return
+	}
+
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
+	type SuspensionRequest struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+
+	var req SuspensionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao decodificar dados da suspensão"})
+		return
+	}
+
+	ngo, err := AdminService.SuspendNGO(uint(ngoID), adminID, req.Reason)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, services.ErrNGONotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrNGOAlreadySuspended), errors.Is(err, services.ErrNGOMerged):
+			status = http.StatusConflict
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": ngo})
+}
+
+// IssueNGOAPIKey emite uma nova chave de API para a ONG, invalidando a anterior
+func IssueNGOAPIKey(ctx *gin.Context) {
+	ngoID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de ONG inválido"})
+		return
+	}
+
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
+	apiKey, err := AdminService.IssueNGOAPIKey(uint(ngoID), adminID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrNGONotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrNGOMerged):
+			status = http.StatusConflict
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": gin.H{"ngo_id": ngoID, "api_key": apiKey}})
+}
+
+// UpdateNGO atualiza o perfil de uma ONG aprovada; o CNPJ não pode ser alterado
+func UpdateNGO(ctx *gin.Context) {
+	ngoID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de ONG inválido"})
+		return
+	}
+
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.NGOUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao decodificar dados da ONG"})
+		return
+	}
+
+	ngo, err := AdminService.UpdateNGO(uint(ngoID), req, adminID)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, services.ErrNGONotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrNGOMerged):
+			status = http.StatusConflict
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": ngo})
+}
+
+// RefundDonation estorna uma doação concluída, registrando o motivo
+func RefundDonation(ctx *gin.Context) {
+	donationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de doação inválido"})
+		return
+	}
+
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.RefundRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao decodificar dados do estorno"})
+		return
+	}
+
+	if err := AdminService.RefundDonation(uint(donationID), adminID, req.Reason); err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, services.ErrDonationNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrDonationNotRefundable), errors.Is(err, services.ErrDonationHasExpenses),
+			errors.Is(err, services.ErrRefundInProgress):
+			status = http.StatusConflict
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Doação estornada"})
+}
+
+// MergeNGOs mescla uma ONG duplicada na ONG canônica
+func MergeNGOs(ctx *gin.Context) {
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.NGOMergeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao decodificar dados da mesclagem"})
+		return
+	}
+
+	if err := AdminService.MergeNGOs(req.CanonicalID, req.DuplicateID, adminID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ONGs mescladas com sucesso"})
+}
+
+// GetWebhookDeliveries lista as entregas dos eventos de status à URL de callback de um registro de ONG
+func GetWebhookDeliveries(ctx *gin.Context) {
+	regID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de registro inválido"})
+		return
+	}
+
+	deliveries, err := AdminService.GetWebhookDeliveries(uint(regID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+// GetNGORegistrations lista os registros de ONGs com filtros combináveis por status e CNPJ,
+// paginados e ordenados pela data da solicitação
 func GetNGORegistrations(ctx *gin.Context) {
-	registrations := AdminService.GetNGORegistrations()
-	ctx.JSON(http.StatusOK, registrations)
+	query := models.NGORegistrationQuery{
+		Status: models.NGORegistrationStatus(ctx.Query("status")),
+		CNPJ:   ctx.Query("cnpj"),
+		Sort:   ctx.Query("sort"),
+	}
+
+	// Paginação
+	for param, target := range map[string]*int{"page": &query.Page, "page_size": &query.PageSize} {
+		if value := ctx.Query(param); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 1 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " deve ser um inteiro positivo"})
+				return
+			}
+			*target = number
+		}
+	}
+
+	result, err := AdminService.GetNGORegistrations(query)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetPendingNGORegistrations retorna a fila de registros aguardando ação, do mais antigo ao mais recente
+func GetPendingNGORegistrations(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, AdminService.GetPendingNGORegistrations())
 }
 
 // GetNGORegistrationByID retorna um registro de ONG pelo ID
@@ -167,6 +538,30 @@ func GetNGORegistrationByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, registration)
 }
 
+// GetNGODocuments devolve os documentos enviados para o registro de ONG, lidos do IPFS
+func GetNGODocuments(ctx *gin.Context) {
+	regID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de registro inválido"})
+		return
+	}
+
+	content, err := AdminService.GetNGODocuments(uint(regID))
+	switch {
+	case errors.Is(err, services.ErrNGORegistrationNotFound),
+		errors.Is(err, services.ErrNGODocumentsNotFound),
+		errors.Is(err, services.ErrIPFSContentNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"documentos-registro-%d\"", regID))
+	ctx.Data(http.StatusOK, utils.DetectMIMEType(content), content)
+}
+
 // GetNGORegistrationsByCNPJ retorna registros de ONGs pelo CNPJ
 func GetNGORegistrationsByCNPJ(ctx *gin.Context) {
 	cnpj := ctx.Query("cnpj")
@@ -181,22 +576,17 @@ func GetNGORegistrationsByCNPJ(ctx *gin.Context) {
 
 // AuditEntity realiza auditoria em uma entidade
 func AuditEntity(ctx *gin.Context) {
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
 	var req models.AuditRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao decodificar dados da auditoria"})
 		return
 	}
 
-	// Obter ID do administrador dos headers (em um sistema real, validaria o token)
-	adminIDStr := ctx.GetHeader("X-Admin-ID")
-	adminID := uint(0)
-	if adminIDStr != "" {
-		id, err := strconv.ParseUint(adminIDStr, 10, 32)
-		if err == nil {
-			adminID = uint(id)
-		}
-	}
-
 	result, err := AdminService.AuditEntity(req, adminID)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -206,28 +596,187 @@ func AuditEntity(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, result)
 }
 
-// GetAuditLogs retorna os logs de auditoria
+// GetAuditLogs consulta o log de auditoria com filtros combináveis por entidade, ação,
+// administrador e período, paginado do registro mais recente ao mais antigo
 func GetAuditLogs(ctx *gin.Context) {
-	entityType := ctx.Query("entity_type")
-	entityIDStr := ctx.Query("entity_id")
+	query := models.AuditLogQuery{
+		EntityType: ctx.Query("entity_type"),
+		Action:     models.AuditAction(ctx.Query("action")),
+	}
+
+	if query.Action != "" && !query.Action.IsValid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Ação de auditoria inválida", "valid_actions": models.AuditActions})
+		return
+	}
+
+	// IDs opcionais
+	for param, target := range map[string]*uint{"entity_id": &query.EntityID, "admin_id": &query.AdminID} {
+		if value := ctx.Query(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil || id == 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " inválido"})
+				return
+			}
+			*target = uint(id)
+		}
+	}
+
+	// Período no formato AAAA-MM-DD, com as duas datas inclusivas
+	for param, target := range map[string]*time.Time{"start_date": &query.StartDate, "end_date": &query.EndDate} {
+		if value := ctx.Query(param); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " deve estar no formato AAAA-MM-DD"})
+				return
+			}
+			*target = date
+		}
+	}
+	if !query.EndDate.IsZero() {
+		query.EndDate = query.EndDate.Add(24*time.Hour - time.Nanosecond)
+	}
+	if !query.StartDate.IsZero() && !query.EndDate.IsZero() && query.StartDate.After(query.EndDate) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_date não pode ser posterior a end_date"})
+		return
+	}
+
+	// Paginação
+	for param, target := range map[string]*int{"page": &query.Page, "page_size": &query.PageSize} {
+		if value := ctx.Query(param); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 1 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " deve ser um inteiro positivo"})
+				return
+			}
+			*target = number
+		}
+	}
+
+	ctx.JSON(http.StatusOK, AdminService.SearchAuditLogs(query))
+}
 
-	if entityType != "" && entityIDStr != "" {
-		entityID, err := strconv.ParseUint(entityIDStr, 10, 32)
+// ListDonations lista todas as doações, de qualquer status, com filtros combináveis por
+// status, ONG, doador, período e faixa de valor, paginadas da mais recente à mais antiga
+func ListDonations(ctx *gin.Context) {
+	filter := models.DonationFilter{Status: ctx.Query("status")}
+
+	// IDs opcionais
+	for param, target := range map[string]*uint{"ngo_id": &filter.NGOID, "donor_id": &filter.DonorID} {
+		if value := ctx.Query(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil || id == 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " inválido"})
+				return
+			}
+			*target = uint(id)
+		}
+	}
+
+	// Período no formato AAAA-MM-DD, com as duas datas inclusivas
+	for param, target := range map[string]*time.Time{"start_date": &filter.StartDate, "end_date": &filter.EndDate} {
+		if value := ctx.Query(param); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " deve estar no formato AAAA-MM-DD"})
+				return
+			}
+			*target = date
+		}
+	}
+	if !filter.EndDate.IsZero() {
+		filter.EndDate = filter.EndDate.Add(24*time.Hour - time.Nanosecond)
+	}
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() && filter.StartDate.After(filter.EndDate) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "start_date não pode ser posterior a end_date"})
+		return
+	}
+
+	// Faixa de valores (zero ou ausente = sem limite)
+	for param, target := range map[string]*float64{"min_amount": &filter.MinAmount, "max_amount": &filter.MaxAmount} {
+		if value := ctx.Query(param); value != "" {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil || amount < 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " deve ser um número não negativo"})
+				return
+			}
+			*target = amount
+		}
+	}
+
+	// Paginação
+	for param, target := range map[string]*int{"page": &filter.Page, "page_size": &filter.PageSize} {
+		if value := ctx.Query(param); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 1 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " deve ser um inteiro positivo"})
+				return
+			}
+			*target = number
+		}
+	}
+
+	result, err := AdminService.ListDonations(filter)
+	if errors.Is(err, services.ErrInvalidAmountRange) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// ImportDonations importa em lote doações históricas de uma campanha feita em outra plataforma,
+// a partir de um CSV (Content-Type text/csv) ou de uma lista JSON
+func ImportDonations(ctx *gin.Context) {
+	adminID, ok := requireAdminID(ctx)
+	if !ok {
+		return
+	}
+
+	// Com partial=true as linhas válidas são importadas mesmo havendo linhas inválidas
+	partial := false
+	if value := ctx.Query("partial"); value != "" {
+		parsed, err := strconv.ParseBool(value)
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de entidade inválido"})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "partial deve ser true ou false"})
 			return
 		}
-		logs := AdminService.GetAuditLogsByEntityID(entityType, uint(entityID))
-		ctx.JSON(http.StatusOK, logs)
-		return
+		partial = parsed
 	}
 
-	if entityType != "" {
-		logs := AdminService.GetAuditLogsByEntityType(entityType)
-		ctx.JSON(http.StatusOK, logs)
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxUploadSize)
+	var rows []models.DonationImportRow
+	var err error
+	if ctx.ContentType() == "text/csv" {
+		rows, err = services.ParseDonationImportCSV(ctx.Request.Body)
+	} else if err = ctx.ShouldBindJSON(&rows); err != nil && !errors.Is(err, io.EOF) {
+		err = fmt.Errorf("JSON inválido no corpo da requisição: envie uma lista de doações (%w)", err)
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Arquivo muito grande; o limite é de %d MB", maxUploadSize>>20)})
+		case errors.Is(err, io.EOF):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": services.ErrDonationImportEmpty.Error()})
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	logs := AdminService.GetAuditLogs()
-	ctx.JSON(http.StatusOK, logs)
+	result, err := AdminService.ImportDonations(rows, partial, adminID)
+	switch {
+	case errors.Is(err, services.ErrDonationImportInvalid):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "result": result})
+	case errors.Is(err, services.ErrDonationImportFailed):
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "result": result})
+	case err != nil:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusOK, result)
+	}
 }
@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"trackable-donations/api/internal/auth"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestServices inicializa os serviços globais dos controladores com dados de demonstração
+func setupTestServices() {
+	gin.SetMode(gin.TestMode)
+	donationService := services.NewDonationService()
+	SetupDonationService(donationService)
+	SetupExpenseService(donationService, 0)
+	SetupTransparencyService(donationService, ExpenseService)
+	SetupAdminService(donationService, ExpenseService)
+	SetupPublicServices(donationService, ExpenseService, services.DefaultDashboardCacheTTL)
+}
+
+func TestGetAuditLogsFilterByAction(t *testing.T) {
+	setupTestServices()
+	registration, err := AdminService.RegisterNGO(models.NGORegistrationRequest{
+		Name: "Nova ONG", Description: "Teste", Category: "Saúde", CNPJ: "11.222.333/0001-81",
+		Email: "contato@nova.org", Phone: "1199999999", Address: "Rua A", State: "SP", ResponsibleID: 1,
+	})
+	require.NoError(t, err)
+	_, err = AdminService.ValidateCNPJOnline(registration.ID)
+	require.NoError(t, err)
+	_, err = AdminService.UploadNGODocuments(context.Background(), registration.ID, []byte("estatuto"))
+	require.NoError(t, err)
+	ngo, _, err := AdminService.ApproveNGO(registration.ID, 9, "Documentação em ordem")
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/admin/audit/logs", GetAuditLogs)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit/logs?action=ngo_approved", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var result models.AuditLogResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	logs := result.Logs
+	require.Len(t, logs, 1, "Apenas o evento de aprovação deve ser retornado")
+	assert.Equal(t, models.AuditActionNGOApproved, logs[0].Action)
+	assert.Equal(t, ngo.ID, logs[0].EntityID)
+	assert.Equal(t, uint(9), logs[0].AdminID)
+}
+
+func TestGetAuditLogsRejectsUnknownAction(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/admin/audit/logs", GetAuditLogs)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit/logs?action=ngo_deleted", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAuditEntityUsesAuthenticatedAdmin(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.POST("/admin/audit", func(c *gin.Context) { c.Set(auth.ContextAdminIDKey, uint(4)) }, AuditEntity)
+	router.POST("/admin/audit-unauthenticated", AuditEntity)
+
+	body := `{"entity_type":"donation","entity_id":999}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/audit-unauthenticated", strings.NewReader(body)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	donation, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 10, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	body = fmt.Sprintf(`{"entity_type":"donation","entity_id":%d}`, donation.ID)
+	req := httptest.NewRequest(http.MethodPost, "/admin/audit", strings.NewReader(body))
+	req.Header.Set("X-Admin-ID", "99")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	logs := AdminService.GetAuditLogs()
+	require.NotEmpty(t, logs)
+	assert.Equal(t, uint(4), logs[len(logs)-1].AdminID, "O ID vem do token, nunca do header")
+}
+
+func TestRefundDonationStatusCodes(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.POST("/donations/:id/refund", func(c *gin.Context) { c.Set(auth.ContextAdminIDKey, uint(2)) }, RefundDonation)
+
+	refund := func(id uint, body string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/donations/%d/refund", id), strings.NewReader(body)))
+		return w.Code
+	}
+
+	donation, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 25, DonorID: 1, NGOID: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, refund(donation.ID, `{}`))
+	assert.Equal(t, http.StatusNotFound, refund(999, `{"reason":"teste"}`))
+	assert.Equal(t, http.StatusConflict, refund(donation.ID, `{"reason":"teste"}`), "Doações pendentes não são estornadas")
+
+	_, err = DonationService.MockPaymentConfirmation(donation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, refund(donation.ID, `{"reason":"teste"}`))
+}
+
+func TestGetAuditLogsValidatesFilters(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/admin/audit/logs", GetAuditLogs)
+
+	for _, query := range []string{
+		"start_date=2024-13-01",
+		"end_date=01/03/2024",
+		"start_date=2024-03-31&end_date=2024-03-01",
+		"admin_id=abc",
+		"page=0",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit/logs?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit/logs?start_date=2024-03-01&end_date=2024-03-31&admin_id=7&page_size=5", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var result models.AuditLogResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 5, result.PageSize)
+}
+
+func TestListDonationsValidatesFilters(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/admin/donations", ListDonations)
+
+	_, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+
+	for query, status := range map[string]int{
+		"":                                   http.StatusOK,
+		"status=pending&ngo_id=1&donor_id=1": http.StatusOK,
+		"start_date=2024-01-01&end_date=2099-12-31": http.StatusOK,
+		"ngo_id=abc":            http.StatusBadRequest,
+		"start_date=01/01/2024": http.StatusBadRequest,
+		"start_date=2024-02-01&end_date=2024-01-01": http.StatusBadRequest,
+		"min_amount=-1":                http.StatusBadRequest,
+		"min_amount=500&max_amount=50": http.StatusBadRequest,
+		"page=0":                       http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/donations?"+query, nil))
+		assert.Equal(t, status, w.Code, query)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/donations?status=pending", nil))
+	var result models.DonationListResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 1, result.Total, "Doações pendentes também aparecem para os administradores")
+}
+
+func TestGetNGORegistrationsValidatesQuery(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/admin/ngos/registrations", GetNGORegistrations)
+
+	_, err := AdminService.RegisterNGO(models.NGORegistrationRequest{
+		Name: "ONG", Description: "Teste", Category: "Saúde", CNPJ: "11.222.333/0001-81",
+		Email: "contato@ong.org", Phone: "1199999999", Address: "Rua A", State: "SP", ResponsibleID: 1,
+	})
+	require.NoError(t, err)
+
+	for query, status := range map[string]int{
+		"": http.StatusOK,
+		"status=pendente&cnpj=11.222.333/0001-81&sort=date_asc&page=1&page_size=10": http.StatusOK,
+		"status=arquivado": http.StatusBadRequest,
+		"sort=name":        http.StatusBadRequest,
+		"page=0":           http.StatusBadRequest,
+		"page_size=abc":    http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/ngos/registrations?"+query, nil))
+		assert.Equal(t, status, w.Code, query)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/ngos/registrations?cnpj=11.222.333/0001-81", nil))
+	var result models.NGORegistrationListResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 1, result.Total)
+	assert.Equal(t, 1, result.Page)
+}
+
+func TestGetNGODocuments(t *testing.T) {
+	setupTestServices()
+	registration, err := AdminService.RegisterNGO(models.NGORegistrationRequest{
+		Name: "Nova ONG", Description: "Teste", Category: "Saúde", CNPJ: "11.222.333/0001-81",
+		Email: "contato@nova.org", Phone: "1199999999", Address: "Rua A", State: "SP", ResponsibleID: 1,
+	})
+	require.NoError(t, err)
+	_, err = AdminService.ValidateCNPJOnline(registration.ID)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/admin/ngos/registration/:id/documents", GetNGODocuments)
+	get := func(id uint) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/admin/ngos/registration/%d/documents", id), nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, get(registration.ID).Code, "Sem documentos enviados")
+	assert.Equal(t, http.StatusNotFound, get(999).Code, "Registro inexistente")
+
+	document := []byte("%PDF-1.4\nestatuto social")
+	_, err = AdminService.UploadNGODocuments(context.Background(), registration.ID, document)
+	require.NoError(t, err)
+
+	w := get(registration.ID)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, document, w.Body.Bytes())
+}
+
+func TestImportDonationsAcceptsCSVAndJSON(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.POST("/admin/donations/import", func(c *gin.Context) { c.Set(auth.ContextAdminIDKey, uint(3)) }, ImportDonations)
+
+	post := func(query, contentType, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/donations/import"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	csvBody := "donor_email,ngo_id,amount,date,external_ref\n" +
+		"ana@example.com,1,100,2023-10-01,a-1\n" +
+		"ana@example.com,1,-5,2023-10-02,a-2\n"
+
+	w := post("", "text/csv", csvBody)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var rejected struct {
+		Result models.DonationImportResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rejected))
+	assert.Equal(t, models.ImportRowSkipped, rejected.Result.Rows[0].Status)
+	assert.Equal(t, models.ImportRowInvalid, rejected.Result.Rows[1].Status)
+
+	w = post("?partial=true", "text/csv; charset=utf-8", csvBody)
+	require.Equal(t, http.StatusOK, w.Code)
+	var result models.DonationImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 1, result.Imported)
+
+	w = post("", "application/json", `[{"donor_email": "bia@example.com", "ngo_id": 2, "amount": 40, "date": "2023-10-05"}]`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	logs := AdminService.GetAuditLogs()
+	assert.Equal(t, models.AuditActionDonationsImported, logs[len(logs)-1].Action)
+	assert.Equal(t, uint(3), logs[len(logs)-1].AdminID)
+
+	for body, contentType := range map[string]string{
+		`{"donor_email": "bia@example.com"}`: "application/json",
+		``:                                   "application/json",
+		"donor_email,amount\n":               "text/csv",
+	} {
+		assert.Equal(t, http.StatusBadRequest, post("", contentType, body).Code, body)
+	}
+	assert.Equal(t, http.StatusBadRequest, post("?partial=talvez", "text/csv", csvBody).Code)
+}
+
+func TestRegisterNGOReturnsWebhookSecretOnlyOnCreation(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.POST("/admin/ngos/register", RegisterNGO)
+	router.GET("/admin/ngos/registrations/:id", GetNGORegistrationByID)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/admin/ngos/register", strings.NewReader(`{
+		"name": "Sertão Vivo", "description": "Cisternas", "category": "Infraestrutura", "cnpj": "11.222.333/0001-81",
+		"email": "contato@sertaovivo.org", "phone": "7133334444", "address": "Rua C", "state": "BA", "responsible_id": 1,
+		"callback_url": "https://sertaovivo.org/eventos"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		ID            uint   `json:"id"`
+		CallbackURL   string `json:"callback_url"`
+		WebhookSecret string `json:"webhook_secret"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "https://sertaovivo.org/eventos", created.CallbackURL)
+	assert.Len(t, created.WebhookSecret, 64)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/admin/ngos/registrations/%d", created.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.WebhookSecret)
+}
+
+func TestGetPendingExpensesPagination(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/admin/expenses/pending", GetPendingExpenses)
+
+	for path, expected := range map[string]int{
+		"/admin/expenses/pending":                     http.StatusOK,
+		"/admin/expenses/pending?page=2&page_size=10": http.StatusOK,
+		"/admin/expenses/pending?page=0":              http.StatusBadRequest,
+		"/admin/expenses/pending?page_size=muitos":    http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, expected, w.Code, path)
+	}
+}
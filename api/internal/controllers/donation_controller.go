@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"trackable-donations/api/internal/models"
 	"trackable-donations/api/internal/services"
 	"trackable-donations/api/internal/utils"
@@ -10,18 +13,24 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-var donationService = services.NewDonationService()
+// DonationService é a instância do serviço de doações
+var DonationService *services.DonationService
 
-// ListNGOs lista todas as ONGs disponíveis
+// SetupDonationService configura o serviço de doações compartilhado com os demais serviços
+func SetupDonationService(donationService *services.DonationService) {
+	DonationService = donationService
+}
+
+// ListNGOs lista as ONGs que podem receber doações
 // @Summary Listar ONGs
-// @Description Retorna a lista de todas as ONGs cadastradas
+// @Description Retorna a lista das ONGs que podem receber novas doações (sem as suspensas)
 // @Tags NGOs
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string][]models.NGO
 // @Router /ngos [get]
 func ListNGOs(c *gin.Context) {
-	ngos := donationService.GetAllNGOs()
+	ngos := DonationService.GetNGOsAcceptingDonations()
 	c.JSON(http.StatusOK, gin.H{
 		"data": ngos,
 	})
@@ -45,7 +54,7 @@ func GetNGOByID(c *gin.Context) {
 		return
 	}
 
-	ngo, err := donationService.GetNGOByID(uint(id))
+	ngo, err := DonationService.GetNGOByID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -54,6 +63,75 @@ func GetNGOByID(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": ngo})
 }
 
+// CreateCampaign cadastra uma campanha da ONG
+// @Summary Criar campanha
+// @Description Cadastra uma campanha de arrecadação da ONG, à qual as doações podem ser atribuídas pelo campaign_id
+// @Tags NGOs
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da ONG"
+// @Param X-NGO-Key header string true "Chave de API da ONG"
+// @Param campanha body models.CampaignRequest true "Dados da campanha"
+// @Success 201 {object} map[string]models.Campaign
+// @Failure 400 {object} map[string]string "ID inválido, erro nos dados ou ONG mesclada"
+// @Failure 401 {object} map[string]string "Chave de API da ONG não informada"
+// @Failure 403 {object} map[string]string "Chave de API de outra ONG"
+// @Failure 409 {object} map[string]string "A ONG já tem uma campanha com este nome"
+// @Router /ngos/{id}/campaigns [post]
+func CreateCampaign(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req models.CampaignRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !authorizeNGOKey(c, DonationService.AuthenticateNGOKey(uint(id), c.GetHeader(services.NGOAPIKeyHeader))) {
+		return
+	}
+
+	campaign, err := CampaignService.CreateCampaign(uint(id), req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrCampaignNameTaken) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": campaign})
+}
+
+// ListCampaigns lista as campanhas de uma ONG
+// @Summary Listar campanhas da ONG
+// @Description Retorna as campanhas da ONG, da mais antiga para a mais recente
+// @Tags NGOs
+// @Produce json
+// @Param id path int true "ID da ONG"
+// @Success 200 {object} map[string][]models.Campaign
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "ONG não encontrada"
+// @Router /ngos/{id}/campaigns [get]
+func ListCampaigns(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	campaigns, err := CampaignService.ListCampaigns(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": campaigns})
+}
+
 // CreateDonation processa uma nova doação
 // @Summary Criar doação
 // @Description Registra uma nova doação no sistema
@@ -62,12 +140,12 @@ func GetNGOByID(c *gin.Context) {
 // @Produce json
 // @Param doacao body models.DonationRequest true "Dados da doação"
 // @Success 201 {object} map[string]models.DonationResponse
-// @Failure 400 {object} map[string]string "Erro nos dados ou documento inválido"
+// @Failure 400 {object} map[string]string "Erro nos dados (mensagens por campo em errors), valor fora dos limites, moeda não suportada, documento inválido ou campanha de outra ONG"
+// @Failure 503 {object} map[string]string "Cotação da moeda indisponível"
 // @Router /donations [post]
 func CreateDonation(c *gin.Context) {
 	var req models.DonationRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -86,7 +164,11 @@ func CreateDonation(c *gin.Context) {
 
 	// Se tiver outros dados sensíveis, anonimizar aqui também
 
-	response, err := donationService.ProcessDonation(req)
+	response, err := DonationService.ProcessDonation(req)
+	if errors.Is(err, services.ErrExchangeRateUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -95,16 +177,18 @@ func CreateDonation(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"data": response})
 }
 
-// ConfirmPayment simula a confirmação de um pagamento
+// ConfirmPayment simula a confirmação de um pagamento. Exige um administrador e só existe fora de produção.
 // @Summary Confirmar pagamento
-// @Description Confirma o pagamento de uma doação e gera comprovante
+// @Description Confirma o pagamento de uma doação e gera comprovante (simulação, apenas fora de produção)
 // @Tags Doações
 // @Accept json
 // @Produce json
 // @Param id path int true "ID da doação"
 // @Success 200 {object} map[string]models.DonationResponse
 // @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 401 {object} map[string]string "Token de administrador ausente ou inválido"
 // @Failure 404 {object} map[string]string "Doação não encontrada"
+// @Failure 409 {object} map[string]string "Doação não está pendente ou já está sendo confirmada"
 // @Router /donations/{id}/confirm-payment [post]
 func ConfirmPayment(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -113,9 +197,9 @@ func ConfirmPayment(c *gin.Context) {
 		return
 	}
 
-	response, err := donationService.MockPaymentConfirmation(uint(id))
+	response, err := DonationService.MockPaymentConfirmation(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(confirmationErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -140,7 +224,7 @@ func GetDonationsByDonor(c *gin.Context) {
 		return
 	}
 
-	donations, err := donationService.GetDonationsByDonorID(uint(id))
+	donations, err := DonationService.GetDonationsByDonorID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -167,7 +251,7 @@ func GetDonationReceipt(c *gin.Context) {
 		return
 	}
 
-	receipt, err := donationService.GetDonationReceipt(uint(id))
+	receipt, err := DonationService.GetDonationReceipt(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -176,6 +260,66 @@ func GetDonationReceipt(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": receipt})
 }
 
+// GetDonationReceiptPDF retorna o comprovante de uma doação em PDF
+// @Summary Baixar comprovante de doação em PDF
+// @Description Gera o comprovante de uma doação confirmada em PDF (A4)
+// @Tags Doações
+// @Produce application/pdf
+// @Param id path int true "ID da doação"
+// @Success 200 {file} file "Comprovante em PDF"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "Comprovante não encontrado"
+// @Failure 500 {object} map[string]string "Erro ao gerar o PDF"
+// @Router /donations/{id}/receipt/pdf [get]
+func GetDonationReceiptPDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	receipt, err := DonationService.GetDonationReceipt(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	pdf, err := services.ReceiptPDF(receipt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"comprovante-%d.pdf\"", receipt.DonationID))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// PreviewDonationReceipt retorna a prévia do comprovante de uma doação pendente
+// @Summary Prévia do comprovante de doação
+// @Description Retorna como ficará o comprovante antes da confirmação do pagamento (sem hash de transação nem IPFS)
+// @Tags Doações
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da doação"
+// @Success 200 {object} map[string]models.DonationReceipt
+// @Failure 400 {object} map[string]string "ID inválido ou doação já confirmada"
+// @Router /donations/{id}/receipt/preview [get]
+func PreviewDonationReceipt(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	receipt, err := DonationService.PreviewReceipt(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": receipt})
+}
+
 // GetResourceUsagesByDonation retorna os usos dos recursos de uma doação
 // @Summary Obter usos dos recursos de doação
 // @Description Retorna os registros de uso dos recursos de uma doação específica
@@ -194,7 +338,7 @@ func GetResourceUsagesByDonation(c *gin.Context) {
 		return
 	}
 
-	usages, err := donationService.GetResourceUsagesByDonationID(uint(id))
+	usages, err := DonationService.GetResourceUsagesByDonationID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -221,7 +365,7 @@ func GetDonorDashboard(c *gin.Context) {
 		return
 	}
 
-	dashboard, err := donationService.GetDonorDashboard(uint(id))
+	dashboard, err := DonationService.GetDonorDashboard(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -229,3 +373,176 @@ func GetDonorDashboard(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": dashboard})
 }
+
+// GetDonorHistoryPDF retorna o histórico completo de doações de um doador em PDF
+// @Summary Exportar histórico do doador em PDF
+// @Description Gera um PDF com todas as doações do doador, agrupadas por ano e por ONG
+// @Tags Doações
+// @Produce application/pdf
+// @Param id path int true "ID do doador"
+// @Success 200 {file} binary "Histórico em PDF"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "Doador não encontrado"
+// @Router /donors/{id}/history/pdf [get]
+func GetDonorHistoryPDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	pdf, err := DonationService.GenerateDonorHistoryPDF(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=historico-doacoes-%d.pdf", id))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// ExportDonorHistory exporta o histórico de doações do doador em CSV ou JSON
+// @Summary Exportar histórico do doador em CSV ou JSON
+// @Description Exporta todas as doações do doador (valores, ONGs, datas, status e hashes das transações), por exemplo para a declaração de imposto de renda. O CSV termina com uma linha de total das doações concluídas.
+// @Tags Doações
+// @Produce text/csv
+// @Produce json
+// @Param id path int true "ID do doador"
+// @Param format query string false "Formato da exportação (csv ou json)" default(csv)
+// @Success 200 {file} binary "Histórico de doações"
+// @Failure 400 {object} map[string]string "ID ou formato inválido"
+// @Failure 404 {object} map[string]string "Doador não encontrado"
+// @Router /donors/{id}/donations/export [get]
+func ExportDonorHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", services.ExportFormatCSV))
+	export, err := DonationService.ExportDonorHistory(uint(id), format)
+	switch {
+	case errors.Is(err, services.ErrUnsupportedExportFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Doador não encontrado"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == services.ExportFormatJSON {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=historico-doacoes-%d.%s", id, format))
+	c.Data(http.StatusOK, contentType, export)
+}
+
+// GetDonorImpactProjection retorna a projeção de impacto de 12 meses de um doador
+// @Summary Projeção de impacto do doador
+// @Description Projeta o total doado e o impacto dos próximos 12 meses a partir das doações recorrentes ativas ou do histórico recente
+// @Tags Doadores
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do doador"
+// @Success 200 {object} map[string]models.ProjectedImpact
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "Doador não encontrado"
+// @Router /donors/{id}/impact-projection [get]
+func GetDonorImpactProjection(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	projection, err := DonationService.ProjectDonorImpact(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": projection})
+}
+
+// CreateRecurringDonation cria uma doação recorrente
+// @Summary Criar doação recorrente
+// @Description Cria uma assinatura que gera uma doação a cada intervalo (padrão: 30 dias)
+// @Tags Doações
+// @Accept json
+// @Produce json
+// @Param doacao body models.RecurringDonationRequest true "Dados da doação recorrente"
+// @Success 201 {object} map[string]models.RecurringDonation
+// @Failure 400 {object} map[string]string "Erro nos dados"
+// @Router /donations/recurring [post]
+func CreateRecurringDonation(c *gin.Context) {
+	var req models.RecurringDonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recurring, err := DonationService.CreateRecurringDonation(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": recurring})
+}
+
+// PauseRecurringDonation pausa uma doação recorrente
+// @Summary Pausar doação recorrente
+// @Description Suspende as cobranças de uma doação recorrente sem cancelar o agendamento
+// @Tags Doações
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da doação recorrente"
+// @Success 200 {object} map[string]models.RecurringDonation
+// @Failure 400 {object} map[string]string "ID inválido ou doação já pausada"
+// @Router /donations/recurring/{id}/pause [post]
+func PauseRecurringDonation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	recurring, err := DonationService.PauseRecurringDonation(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": recurring})
+}
+
+// ResumeRecurringDonation retoma uma doação recorrente pausada
+// @Summary Retomar doação recorrente
+// @Description Reativa uma doação recorrente a partir da próxima data agendada, sem cobrar os ciclos perdidos
+// @Tags Doações
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da doação recorrente"
+// @Success 200 {object} map[string]models.RecurringDonation
+// @Failure 400 {object} map[string]string "ID inválido ou doação não pausada"
+// @Router /donations/recurring/{id}/resume [post]
+func ResumeRecurringDonation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	recurring, err := DonationService.ResumeRecurringDonation(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": recurring})
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDonationReceiptPDF(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/donations/:id/receipt/pdf", GetDonationReceiptPDF)
+
+	donation, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+
+	// Doações pendentes ainda não têm comprovante
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/donations/%d/receipt/pdf", donation.ID), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	_, err = DonationService.MockPaymentConfirmation(donation.ID)
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/donations/%d/receipt/pdf", donation.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")))
+}
+
+func TestExportDonorHistoryStatusCodes(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/donors/:id/donations/export", ExportDonorHistory)
+
+	for path, status := range map[string]int{
+		"/donors/1/donations/export":             http.StatusOK,
+		"/donors/1/donations/export?format=JSON": http.StatusOK,
+		"/donors/1/donations/export?format=xml":  http.StatusBadRequest,
+		"/donors/abc/donations/export":           http.StatusBadRequest,
+		"/donors/999/donations/export":           http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, status, w.Code, path)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/donors/1/donations/export", nil))
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "historico-doacoes-1.csv")
+}
+
+func TestCampaignEndpoints(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.POST("/ngos/:id/campaigns", CreateCampaign)
+	router.GET("/ngos/:id/campaigns", ListCampaigns)
+	router.POST("/donations", CreateDonation)
+	router.GET("/dashboard/ngos/:id/campaigns", GetNGOCampaignDashboard)
+	router.GET("/transparency/donations", GetPublicDonations)
+
+	ownKey, err := AdminService.IssueNGOAPIKey(1, 9)
+	require.NoError(t, err)
+	otherKey, err := AdminService.IssueNGOAPIKey(2, 9)
+	require.NoError(t, err)
+
+	create := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/ngos/1/campaigns", strings.NewReader(`{"name":"Inverno"}`))
+		if key != "" {
+			req.Header.Set(services.NGOAPIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, create("").Code)
+	assert.Equal(t, http.StatusForbidden, create(otherKey).Code)
+	w := create(ownKey)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, http.StatusConflict, create(ownKey).Code)
+
+	campaigns, err := CampaignService.ListCampaigns(1)
+	require.NoError(t, err)
+	require.Len(t, campaigns, 1)
+	campaignID := campaigns[0].ID
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ngos/1/campaigns", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Inverno"`)
+
+	// A campanha de uma ONG não aceita doações para outra
+	donate := func(ngoID uint) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"amount":100,"donor_id":1,"ngo_id":%d,"campaign_id":%d}`, ngoID, campaignID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/donations", strings.NewReader(body)))
+		return w
+	}
+	w = donate(2)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), services.ErrCampaignNGOMismatch.Error())
+	require.Equal(t, http.StatusCreated, donate(1).Code)
+
+	donations, err := DonationService.GetDonationsByDonorID(1)
+	require.NoError(t, err)
+	_, err = DonationService.MockPaymentConfirmation(donations[len(donations)-1].ID)
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dashboard/ngos/1/campaigns", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`{"campaign_id":%d,"campaign_name":"Inverno","total_amount":100,"count":1}`, campaignID))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/transparency/donations?campaign_id=%d", campaignID), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, strings.Count(w.Body.String(), `"campaign_id"`))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/transparency/donations?campaign_id=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,9 +1,10 @@
 package controllers
 
 import (
-	"io"
+	"errors"
 	"net/http"
 	"strconv"
+	"trackable-donations/api/internal/config"
 	"trackable-donations/api/internal/models"
 	"trackable-donations/api/internal/services"
 
@@ -13,9 +14,10 @@ import (
 // ExpenseService é a instância do serviço de despesas
 var ExpenseService *services.ExpenseService
 
-// SetupExpenseService configura o serviço de despesas
-func SetupExpenseService(donationService *services.DonationService) {
+// SetupExpenseService configura o serviço de despesas com o limite de gastos por doação (0 = ilimitado)
+func SetupExpenseService(donationService *services.DonationService, maxExpensesPerDonation int) {
 	ExpenseService = services.NewExpenseService(donationService)
+	ExpenseService.SetMaxExpensesPerDonation(maxExpensesPerDonation)
 }
 
 // RegisterExpense registra uma nova despesa
@@ -24,14 +26,20 @@ func SetupExpenseService(donationService *services.DonationService) {
 // @Tags Despesas
 // @Accept json
 // @Produce json
+// @Param X-NGO-Key header string true "Chave de API da ONG do gasto"
 // @Param despesa body models.ExpenseRequest true "Dados da despesa"
 // @Success 201 {object} models.ExpenseResponse
-// @Failure 400 {object} map[string]string "Erro nos dados da despesa"
+// @Failure 400 {object} map[string]string "Erro nos dados da despesa (mensagens por campo em errors)"
+// @Failure 401 {object} map[string]string "Chave de API da ONG não informada"
+// @Failure 403 {object} map[string]string "Chave de API de outra ONG"
 // @Router /expenses [post]
 func RegisterExpense(ctx *gin.Context) {
 	var expenseReq models.ExpenseRequest
-	if err := ctx.ShouldBindJSON(&expenseReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao decodificar dados da despesa"})
+	if !bindJSON(ctx, &expenseReq) {
+		return
+	}
+
+	if !authorizeNGOKey(ctx, ExpenseService.AuthenticateNGOKey(expenseReq.NGOID, ctx.GetHeader(services.NGOAPIKeyHeader))) {
 		return
 	}
 
@@ -44,16 +52,53 @@ func RegisterExpense(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, response)
 }
 
+// RegisterPoolExpense registra uma despesa custeada pelo saldo geral da ONG
+// @Summary Registrar despesa do saldo geral
+// @Description Registra uma despesa retirada das doações concluídas da ONG com saldo, da mais antiga para a mais recente; as parcelas aparecem em sources
+// @Tags Despesas
+// @Accept json
+// @Produce json
+// @Param X-NGO-Key header string true "Chave de API da ONG do gasto"
+// @Param despesa body models.PoolExpenseRequest true "Dados da despesa"
+// @Success 201 {object} models.ExpenseResponse
+// @Failure 400 {object} map[string]string "Erro nos dados da despesa ou saldo insuficiente"
+// @Failure 401 {object} map[string]string "Chave de API da ONG não informada"
+// @Failure 403 {object} map[string]string "Chave de API de outra ONG"
+// @Router /expenses/pool [post]
+func RegisterPoolExpense(ctx *gin.Context) {
+	var expenseReq models.PoolExpenseRequest
+	if !bindJSON(ctx, &expenseReq) {
+		return
+	}
+
+	if !authorizeNGOKey(ctx, ExpenseService.AuthenticateNGOKey(expenseReq.NGOID, ctx.GetHeader(services.NGOAPIKeyHeader))) {
+		return
+	}
+
+	response, err := ExpenseService.RegisterPoolExpense(expenseReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
 // UploadReceipt faz upload de um comprovante para uma despesa
 // @Summary Fazer upload de comprovante
 // @Description Envia o comprovante/recibo de uma despesa
 // @Tags Despesas
 // @Accept multipart/form-data
 // @Produce json
+// @Param X-NGO-Key header string true "Chave de API da ONG do gasto"
 // @Param id path int true "ID da despesa"
 // @Param receipt formData file true "Arquivo do comprovante (PDF, JPG, PNG)"
 // @Success 200 {object} models.ExpenseResponse
 // @Failure 400 {object} map[string]string "ID de despesa inválido ou erro no arquivo"
+// @Failure 401 {object} map[string]string "Chave de API da ONG não informada"
+// @Failure 403 {object} map[string]string "Chave de API de outra ONG"
+// @Failure 413 {object} map[string]string "Arquivo maior que o limite configurado (padrão 10 MB)"
+// @Failure 415 {object} map[string]string "Tipo de arquivo não permitido"
 // @Router /expenses/{id}/receipt [post]
 func UploadReceipt(ctx *gin.Context) {
 	expenseID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
@@ -62,21 +107,28 @@ func UploadReceipt(ctx *gin.Context) {
 		return
 	}
 
-	// Limite o upload para 10MB
-	file, _, err := ctx.Request.FormFile("receipt")
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao processar arquivo: " + err.Error()})
+	// A chave é conferida antes de ler o arquivo, para não receber uploads de quem não pode enviá-los
+	if !authorizeNGOKey(ctx, ExpenseService.AuthenticateExpenseNGOKey(uint(expenseID), ctx.GetHeader(services.NGOAPIKeyHeader))) {
+		return
+	}
+
+	file, ok := formFile(ctx, "receipt")
+	if !ok {
 		return
 	}
 	defer file.Close()
 
-	fileBytes, err := io.ReadAll(file)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler o arquivo: " + err.Error()})
+	fileBytes, ok := readUpload(ctx, file)
+	if !ok {
+		return
+	}
+
+	if err := validateUploadType(config.UploadTypeReceipt, fileBytes); err != nil {
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
 		return
 	}
 
-	response, err := ExpenseService.UploadReceipt(uint(expenseID), fileBytes)
+	response, err := ExpenseService.UploadReceipt(ctx.Request.Context(), uint(expenseID), fileBytes)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -85,15 +137,66 @@ func UploadReceipt(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// GetDonationBalance retorna o saldo ainda não gasto de uma doação
+// @Summary Obter saldo da doação
+// @Description Retorna o total de uma doação concluída, os gastos aprovados (spent), os aguardando aprovação (pending) e o saldo disponível (available)
+// @Tags Despesas
+// @Produce json
+// @Param id path int true "ID da doação"
+// @Success 200 {object} map[string]models.DonationBalance
+// @Failure 400 {object} map[string]string "ID inválido ou doação não concluída"
+// @Failure 404 {object} map[string]string "Doação não encontrada"
+// @Router /donations/{id}/balance [get]
+func GetDonationBalance(ctx *gin.Context) {
+	donationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	balance, err := ExpenseService.GetDonationBalance(uint(donationID))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrDonationNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrDonationNotCompleted):
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": balance})
+}
+
+// authorizeNGOKey responde à falha na verificação da chave de API da ONG, retornando se a
+// requisição pode prosseguir
+func authorizeNGOKey(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, services.ErrNGOAPIKeyMissing):
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNGOAPIKeyInvalid):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+	return false
+}
+
 // GetExpensesByDonation retorna as despesas relacionadas a uma doação específica
 // @Summary Listar despesas por doação
-// @Description Retorna todas as despesas relacionadas a uma doação específica
+// @Description Retorna as despesas relacionadas a uma doação específica, opcionalmente filtradas por categoria e status
 // @Tags Despesas
 // @Accept json
 // @Produce json
 // @Param donationId path int true "ID da doação"
+// @Param category query string false "Categoria do gasto (ver /categories)"
+// @Param status query string false "Status do gasto" Enums(pendente, aprovado, rejeitado)
 // @Success 200 {array} models.Expense
-// @Failure 400 {object} map[string]string "ID de doação inválido"
+// @Failure 400 {object} map[string]string "ID de doação, categoria ou status inválido"
 // @Router /expenses/donation/{donationId} [get]
 func GetExpensesByDonation(ctx *gin.Context) {
 	donationID, err := strconv.ParseUint(ctx.Param("donationId"), 10, 32)
@@ -102,7 +205,7 @@ func GetExpensesByDonation(ctx *gin.Context) {
 		return
 	}
 
-	expenses, err := ExpenseService.GetExpensesByDonation(uint(donationID))
+	expenses, err := ExpenseService.GetExpensesByDonation(uint(donationID), expenseFilter(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -111,15 +214,17 @@ func GetExpensesByDonation(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, expenses)
 }
 
-// GetExpensesByNGO retorna todas as despesas de uma ONG
+// GetExpensesByNGO retorna as despesas de uma ONG
 // @Summary Listar despesas por ONG
-// @Description Retorna todas as despesas registradas por uma ONG específica
+// @Description Retorna as despesas registradas por uma ONG específica, opcionalmente filtradas por categoria e status
 // @Tags Despesas
 // @Accept json
 // @Produce json
 // @Param ngoId path int true "ID da ONG"
+// @Param category query string false "Categoria do gasto (ver /categories)"
+// @Param status query string false "Status do gasto" Enums(pendente, aprovado, rejeitado)
 // @Success 200 {array} models.Expense
-// @Failure 400 {object} map[string]string "ID de ONG inválido"
+// @Failure 400 {object} map[string]string "ID de ONG, categoria ou status inválido"
 // @Router /expenses/ngo/{ngoId} [get]
 func GetExpensesByNGO(ctx *gin.Context) {
 	ngoID, err := strconv.ParseUint(ctx.Param("ngoId"), 10, 32)
@@ -128,7 +233,7 @@ func GetExpensesByNGO(ctx *gin.Context) {
 		return
 	}
 
-	expenses, err := ExpenseService.GetExpensesByNGO(uint(ngoID))
+	expenses, err := ExpenseService.GetExpensesByNGO(uint(ngoID), expenseFilter(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -136,3 +241,35 @@ func GetExpensesByNGO(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, expenses)
 }
+
+// expenseFilter lê os filtros opcionais das listagens de despesas; o serviço os valida
+func expenseFilter(ctx *gin.Context) models.ExpenseFilter {
+	return models.ExpenseFilter{Category: ctx.Query("category"), Status: ctx.Query("status")}
+}
+
+// GetExpenseFunding retorna as doações que custearam uma despesa
+// @Summary Listar doações que custearam uma despesa
+// @Description Retorna cada doação de origem de uma despesa e o valor retirado dela
+// @Tags Despesas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da despesa"
+// @Success 200 {array} models.DonationContribution
+// @Failure 400 {object} map[string]string "ID de despesa inválido"
+// @Failure 404 {object} map[string]string "Despesa não encontrada"
+// @Router /expenses/{id}/funding [get]
+func GetExpenseFunding(ctx *gin.Context) {
+	expenseID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de despesa inválido"})
+		return
+	}
+
+	contributions, err := ExpenseService.GetFundingDonations(uint(expenseID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, contributions)
+}
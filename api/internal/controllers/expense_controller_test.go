@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterExpenseCategoryValidation(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.POST("/expenses", RegisterExpense)
+
+	donation, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	_, err = DonationService.MockPaymentConfirmation(donation.ID)
+	require.NoError(t, err)
+	key, err := AdminService.IssueNGOAPIKey(1, 9)
+	require.NoError(t, err)
+
+	register := func(category string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"donation_id":%d,"ngo_id":1,"amount":20,"description":"Cestas básicas","category":%q}`, donation.ID, category)
+		req := httptest.NewRequest(http.MethodPost, "/expenses", strings.NewReader(body))
+		req.Header.Set(services.NGOAPIKeyHeader, key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := register("Alimentaçao")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Alimentação")
+
+	w = register("alimentação")
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"category":"Alimentação"`)
+}
+
+func TestExpenseSubmissionRequiresOwnNGOKey(t *testing.T) {
+	setupTestServices()
+	SetAllowedUploadTypes(nil)
+	router := gin.New()
+	router.POST("/expenses", RegisterExpense)
+	router.POST("/expenses/:id/receipt", UploadReceipt)
+
+	donation, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	_, err = DonationService.MockPaymentConfirmation(donation.ID)
+	require.NoError(t, err)
+	ownKey, err := AdminService.IssueNGOAPIKey(1, 9)
+	require.NoError(t, err)
+	otherKey, err := AdminService.IssueNGOAPIKey(2, 9)
+	require.NoError(t, err)
+
+	before, err := ExpenseService.GetExpensesByNGO(1, models.ExpenseFilter{})
+	require.NoError(t, err)
+
+	body := fmt.Sprintf(`{"donation_id":%d,"ngo_id":1,"amount":20,"description":"Cestas básicas","category":"Alimentação"}`, donation.ID)
+	register := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/expenses", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(services.NGOAPIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, register("").Code)
+	assert.Equal(t, http.StatusForbidden, register(otherKey).Code)
+	after, err := ExpenseService.GetExpensesByNGO(1, models.ExpenseFilter{})
+	require.NoError(t, err)
+	assert.Len(t, after, len(before))
+
+	w := register(ownKey)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var expense models.ExpenseResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &expense))
+
+	// O comprovante também só pode ser enviado pela ONG dona do gasto
+	upload := func(key string) *httptest.ResponseRecorder {
+		var form bytes.Buffer
+		writer := multipart.NewWriter(&form)
+		part, err := writer.CreateFormFile("receipt", "nota.pdf")
+		require.NoError(t, err)
+		_, err = part.Write(pdfFile)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/expenses/%d/receipt", expense.ID), &form)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set(services.NGOAPIKeyHeader, key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusForbidden, upload(otherKey).Code)
+	assert.Equal(t, http.StatusOK, upload(ownKey).Code)
+}
+
+func TestGetExpensesByNGOFilters(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/expenses/ngo/:ngoId", GetExpensesByNGO)
+	router.GET("/expenses/donation/:donationId", GetExpensesByDonation)
+
+	donation, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	_, err = DonationService.MockPaymentConfirmation(donation.ID)
+	require.NoError(t, err)
+	for _, category := range []string{"Alimentação", "Transporte"} {
+		_, err = ExpenseService.RegisterExpense(models.ExpenseRequest{DonationID: donation.ID, NGOID: 1, Amount: 10, Description: "Item", Category: category})
+		require.NoError(t, err)
+	}
+
+	for query, expected := range map[string]int{
+		"/expenses/ngo/1?category=Alimentação&status=pendente":                http.StatusOK,
+		"/expenses/ngo/1?category=Lazer":                                      http.StatusBadRequest,
+		"/expenses/ngo/1?status=pago":                                         http.StatusBadRequest,
+		fmt.Sprintf("/expenses/donation/%d?category=transporte", donation.ID): http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, query, nil))
+		assert.Equal(t, expected, w.Code, query)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/expenses/ngo/1?category=Alimenta%C3%A7%C3%A3o", nil))
+	assert.Contains(t, w.Body.String(), `"category":"Alimentação"`)
+	assert.NotContains(t, w.Body.String(), "Transporte")
+}
+
+func TestGetDonationBalanceStatuses(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/donations/:id/balance", GetDonationBalance)
+
+	pending, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+
+	for path, expected := range map[string]int{
+		fmt.Sprintf("/donations/%d/balance", pending.ID): http.StatusBadRequest,
+		"/donations/9999/balance":                        http.StatusNotFound,
+		"/donations/abc/balance":                         http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, expected, w.Code, path)
+	}
+
+	_, err = DonationService.MockPaymentConfirmation(pending.ID)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/donations/%d/balance", pending.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"available":100`)
+}
@@ -4,45 +4,86 @@ import (
 	"net/http"
 	"os"
 	"time"
+	"trackable-donations/api/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 // HealthStatus representa o status de saúde da API
 type HealthStatus struct {
-	Status    string    `json:"status"`
-	Version   string    `json:"version"`
-	Timestamp time.Time `json:"timestamp"`
-	Uptime    string    `json:"uptime"`
+	Status       string                               `json:"status"`
+	Version      string                               `json:"version"`
+	Timestamp    time.Time                            `json:"timestamp"`
+	Uptime       string                               `json:"uptime"`
+	Dependencies map[string]services.DependencyStatus `json:"dependencies,omitempty"`
 }
 
 var startTime = time.Now()
 
-// HealthCheck verifica o status de saúde da API
+// HealthChecker verifica as dependências externas da API (nil = sem dependências)
+var HealthChecker *services.HealthChecker
+
+// SetupHealthChecker configura o verificador de dependências usado no /health
+func SetupHealthChecker(checker *services.HealthChecker) {
+	HealthChecker = checker
+}
+
+// HealthCheck verifica o status de saúde da API e de suas dependências
 // @Summary Verificar saúde da API
-// @Description Verifica se a API está funcionando corretamente
+// @Description Verifica se a API e suas dependências (banco de dados, IPFS, nó da blockchain) estão acessíveis.
+// @Description Com alguma dependência fora do ar, o status é "degraded"; se ela for crítica, a resposta é 503.
+// @Description /health/ready é a verificação de prontidão (readiness) usada pelo Kubernetes.
 // @Tags Sistema
 // @Accept json
 // @Produce json
 // @Success 200 {object} controllers.HealthStatus
+// @Failure 503 {object} controllers.HealthStatus
 // @Router /health [get]
+// @Router /health/ready [get]
 func HealthCheck(c *gin.Context) {
+	status := newHealthStatus()
+	if HealthChecker == nil {
+		c.JSON(http.StatusOK, status)
+		return
+	}
+
+	report := HealthChecker.Check(c.Request.Context())
+	status.Dependencies = report.Dependencies
+	if report.Degraded {
+		status.Status = "degraded"
+	}
+
+	if report.CriticalDown {
+		c.JSON(http.StatusServiceUnavailable, status)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// LivenessCheck informa apenas que o processo está respondendo, sem verificar as dependências,
+// para que o Kubernetes não reinicie a API quando um serviço externo estiver fora do ar
+// @Summary Verificar se a API está viva
+// @Description Verificação de vida (liveness): responde sem consultar as dependências
+// @Tags Sistema
+// @Produce json
+// @Success 200 {object} controllers.HealthStatus
+// @Router /health/live [get]
+func LivenessCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, newHealthStatus())
+}
+
+// newHealthStatus monta a resposta com a versão e o tempo de atividade da API
+func newHealthStatus() HealthStatus {
 	// Obter versão da variável de ambiente ou usar padrão
 	version := os.Getenv("API_VERSION")
 	if version == "" {
 		version = "1.0.0"
 	}
 
-	// Calcular tempo de atividade
-	uptime := time.Since(startTime).String()
-
-	// Criar resposta
-	status := HealthStatus{
+	return HealthStatus{
 		Status:    "online",
 		Version:   version,
 		Timestamp: time.Now(),
-		Uptime:    uptime,
+		Uptime:    time.Since(startTime).String(),
 	}
-
-	c.JSON(http.StatusOK, status)
 }
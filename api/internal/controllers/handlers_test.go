@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheckReturns503WhenCriticalDependencyIsDown(t *testing.T) {
+	databaseErr := errors.New("conexão recusada")
+	SetupHealthChecker(services.NewHealthChecker(time.Second,
+		services.HealthProbe{Name: "database", Critical: true, Check: func(context.Context) error { return databaseErr }},
+	))
+	defer SetupHealthChecker(nil)
+
+	router := gin.New()
+	router.GET("/health", HealthCheck)
+	router.GET("/health/live", LivenessCheck)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var status HealthStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, "degraded", status.Status)
+	assert.NotEmpty(t, status.Version)
+	assert.NotEmpty(t, status.Uptime)
+	assert.Equal(t, services.DependencyDown, status.Dependencies["database"].Status)
+
+	// A verificação de vida não depende do banco
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	databaseErr = nil
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, "online", status.Status)
+}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	"trackable-donations/api/internal/models"
 	"trackable-donations/api/internal/services"
@@ -16,25 +18,38 @@ var ExplorerService *services.ExplorerService
 // DashboardService é a instância do serviço de dashboard
 var DashboardService *services.DashboardService
 
-// SetupPublicServices configura os serviços públicos
-func SetupPublicServices(donationService *services.DonationService, expenseService *services.ExpenseService) {
+// CampaignService é a instância do serviço de campanhas
+var CampaignService *services.CampaignService
+
+// SetupPublicServices configura os serviços públicos; o dashboard global fica em cache por dashboardCacheTTL
+func SetupPublicServices(donationService *services.DonationService, expenseService *services.ExpenseService, dashboardCacheTTL time.Duration) {
 	ExplorerService = services.NewExplorerService(donationService, expenseService)
 	DashboardService = services.NewDashboardService(donationService, expenseService)
+	DashboardService.SetCacheTTL(dashboardCacheTTL)
+	CampaignService = services.NewCampaignService(donationService)
 }
 
 // SearchDonations processa a busca de doações
 // @Summary Buscar doações
-// @Description Busca doações com filtros por hash, ONG e período
+// @Description Busca doações com filtros por hash, ONG, período e faixa de valor
 // @Tags Explorador
 // @Accept json
 // @Produce json
 // @Param hash query string false "Hash da transação na blockchain"
 // @Param ngo_id query int false "ID da ONG"
+// @Param campaign_id query int false "ID da campanha"
 // @Param start_date query string false "Data inicial (formato: YYYY-MM-DD)"
 // @Param end_date query string false "Data final (formato: YYYY-MM-DD)"
+// @Param metadata.chave query string false "Filtra por metadado (ex.: metadata.crm_id=123)"
+// @Param min_amount query number false "Valor mínimo da doação (0 = sem limite)"
+// @Param max_amount query number false "Valor máximo da doação (0 = sem limite)"
+// @Param sort query string false "Ordenação: date_asc, date_desc (padrão), amount_asc ou amount_desc"
+// @Param status query string false "Status das doações: completed (padrão), refunded ou all (confirmadas e estornadas); pendentes nunca são exibidas" Enums(completed, refunded, all)
 // @Param page query int false "Número da página (padrão: 1)"
 // @Param page_size query int false "Tamanho da página (padrão: 10)"
+// @Param after query string false "Cursor da próxima página (next_cursor da resposta anterior); substitui page nas ordenações por data"
 // @Success 200 {object} models.TransactionExplorerResult
+// @Failure 400 {object} map[string]string "Campanha, faixa de valores, ordenação, status ou cursor inválido"
 // @Failure 500 {object} map[string]string "Erro interno"
 // @Router /explorer/search [get]
 func SearchDonations(ctx *gin.Context) {
@@ -53,6 +68,12 @@ func SearchDonations(ctx *gin.Context) {
 		}
 	}
 
+	campaignID, ok := campaignFilter(ctx)
+	if !ok {
+		return
+	}
+	query.CampaignID = campaignID
+
 	if startDateStr := ctx.Query("start_date"); startDateStr != "" {
 		startDate, err := time.Parse("2006-01-02", startDateStr)
 		if err == nil {
@@ -69,6 +90,32 @@ func SearchDonations(ctx *gin.Context) {
 		}
 	}
 
+	// Filtros por metadados no formato metadata.<chave>=<valor>
+	for key, values := range ctx.Request.URL.Query() {
+		if metadataKey, ok := strings.CutPrefix(key, "metadata."); ok && metadataKey != "" && len(values) > 0 {
+			if query.Metadata == nil {
+				query.Metadata = make(map[string]string)
+			}
+			query.Metadata[metadataKey] = values[0]
+		}
+	}
+
+	// Faixa de valores (zero ou ausente = sem limite)
+	for param, target := range map[string]*float64{"min_amount": &query.MinAmount, "max_amount": &query.MaxAmount} {
+		if value := ctx.Query(param); value != "" {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil || amount < 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " deve ser um número não negativo"})
+				return
+			}
+			*target = amount
+		}
+	}
+
+	query.SortBy = ctx.Query("sort")
+	query.Status = ctx.Query("status")
+	query.After = ctx.Query("after")
+
 	// Obter parâmetros de paginação
 	if pageStr := ctx.Query("page"); pageStr != "" {
 		page, err := strconv.Atoi(pageStr)
@@ -86,6 +133,11 @@ func SearchDonations(ctx *gin.Context) {
 
 	// Executar a busca
 	result, err := ExplorerService.SearchDonations(query)
+	if errors.Is(err, services.ErrInvalidAmountRange) || errors.Is(err, services.ErrInvalidSortBy) ||
+		errors.Is(err, services.ErrInvalidExplorerStatus) || errors.Is(err, services.ErrInvalidCursor) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -149,6 +201,59 @@ func GetDonationByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, donation)
 }
 
+// GetDonationTrace retorna o rastro completo de uma doação
+// @Summary Rastrear o uso de uma doação
+// @Description Retorna a doação com o comprovante, os usos dos recursos e os gastos custeados por ela (com as referências no IPFS e na blockchain) e o saldo ainda não gasto
+// @Tags Explorador
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da doação"
+// @Success 200 {object} models.DonationTrace
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "Doação não encontrada"
+// @Router /explorer/donations/{id}/trace [get]
+func GetDonationTrace(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	trace, err := ExplorerService.GetDonationTrace(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, trace)
+}
+
+// VerifyDonation confere novamente uma doação na blockchain e no IPFS
+// @Summary Verificar doação na blockchain
+// @Description Confere, no momento da consulta, se a transação da doação está em um bloco de uma cadeia íntegra e se o comprovante existe no IPFS. Doações ainda não concluídas retornam as falhas em errors.
+// @Tags Explorador
+// @Produce json
+// @Param id path int true "ID da doação"
+// @Success 200 {object} models.DonationVerification
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "Doação não encontrada"
+// @Router /explorer/donations/{id}/verify [get]
+func VerifyDonation(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	result, err := ExplorerService.VerifyDonation(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
 // GetDonationsByNGO obtém as doações de uma ONG específica
 // @Summary Listar doações por ONG
 // @Description Retorna todas as doações recebidas por uma ONG específica
@@ -238,6 +343,128 @@ func GetGlobalDashboard(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, dashboard)
 }
 
+// GetActiveCategories obtém as categorias efetivamente em uso
+// @Summary Listar categorias em uso
+// @Description Retorna as categorias distintas das ONGs ativas e de seus gastos, com a contagem de cada uma
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.CategoryUsage
+// @Router /dashboard/categories [get]
+func GetActiveCategories(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, DashboardService.GetActiveCategories())
+}
+
+// GetCategories lista as categorias aceitas pela API
+// @Summary Listar categorias válidas
+// @Description Retorna as grafias oficiais das categorias de ONG e de gasto, para uso em formulários
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string][]string
+// @Router /categories [get]
+func GetCategories(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"ngo_categories":     models.NGOCategories,
+		"expense_categories": models.ExpenseCategories,
+	})
+}
+
+// GetDonorRetention obtém as métricas de retenção de doadores
+// @Summary Obter retenção de doadores
+// @Description Retorna doadores únicos e recorrentes, taxa de recorrência e média de doações por doador recorrente
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.RetentionMetrics
+// @Router /dashboard/retention [get]
+func GetDonorRetention(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, DashboardService.GetDonorRetention())
+}
+
+// GetTopDonors obtém o ranking dos doadores que aceitaram o reconhecimento público
+// @Summary Listar maiores doadores
+// @Description Retorna os doadores com maior total em doações concluídas, apenas entre os que optaram pelo reconhecimento público; empates favorecem quem doou primeiro
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limite de resultados (padrão: 10, máximo: 100)"
+// @Success 200 {array} models.TopDonor
+// @Router /dashboard/top-donors [get]
+func GetTopDonors(ctx *gin.Context) {
+	limit := services.DefaultTopDonorsLimit
+	if limitVal, err := strconv.Atoi(ctx.Query("limit")); err == nil && limitVal > 0 {
+		limit = limitVal
+	}
+
+	ctx.JSON(http.StatusOK, DashboardService.GetTopDonors(limit))
+}
+
+// GetNGOCampaignDashboard obtém os totais arrecadados por campanha de uma ONG
+// @Summary Obter dashboard das campanhas da ONG
+// @Description Soma as doações concluídas da ONG por campanha, da maior para a menor arrecadação, e totaliza à parte as doações sem campanha
+// @Tags Dashboard
+// @Produce json
+// @Param id path int true "ID da ONG"
+// @Success 200 {object} models.NGOCampaignDashboard
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "ONG não encontrada"
+// @Router /dashboard/ngos/{id}/campaigns [get]
+func GetNGOCampaignDashboard(ctx *gin.Context) {
+	ngoID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	dashboard, err := CampaignService.GetNGOCampaignDashboard(uint(ngoID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dashboard)
+}
+
+// GetCampaignDonations lista as doações de uma campanha com o andamento da arrecadação
+// @Summary Listar doações da campanha
+// @Description Retorna as doações concluídas da campanha, da mais recente para a mais antiga, com o nome do doador oculto salvo quando ele optou por aparecer, e o total arrecadado; doações estornadas não entram na lista nem no total
+// @Tags Campanhas
+// @Produce json
+// @Param id path int true "ID da campanha"
+// @Param page query int false "Número da página (padrão: 1)"
+// @Param page_size query int false "Tamanho da página (padrão: 20, máximo: 100)"
+// @Success 200 {object} models.CampaignDonationsResult
+// @Failure 400 {object} map[string]string "ID ou paginação inválidos"
+// @Failure 404 {object} map[string]string "Campanha não encontrada"
+// @Router /campaigns/{id}/donations [get]
+func GetCampaignDonations(ctx *gin.Context) {
+	campaignID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var page, pageSize int
+	for param, target := range map[string]*int{"page": &page, "page_size": &pageSize} {
+		if value := ctx.Query(param); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 1 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " deve ser um inteiro positivo"})
+				return
+			}
+			*target = number
+		}
+	}
+
+	result, err := CampaignService.GetCampaignDonations(uint(campaignID), page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
 // GetDashboardByDateRange obtém os dados do dashboard para um intervalo de datas
 // @Summary Obter dashboard por período
 // @Description Retorna dados do dashboard filtrados por período de tempo
@@ -277,6 +504,39 @@ func GetDashboardByDateRange(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, dashboard)
 }
 
+// GetCategoryTimeSeries obtém a série temporal das doações por categoria de ONG
+// @Summary Obter série temporal por categoria
+// @Description Soma as doações concluídas por categoria e por dia, semana ou mês do período; períodos sem doações aparecem zerados
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param start_date query string true "Data inicial (formato: YYYY-MM-DD)"
+// @Param end_date query string true "Data final (formato: YYYY-MM-DD)"
+// @Param granularity query string false "Granularidade: daily, weekly ou monthly (padrão)" Enums(daily, weekly, monthly)
+// @Success 200 {array} models.CategoryTimeSeries
+// @Failure 400 {object} map[string]string "Datas ou granularidade inválidas, ou período longo demais"
+// @Router /dashboard/category-timeseries [get]
+func GetCategoryTimeSeries(ctx *gin.Context) {
+	var dates [2]time.Time
+	for i, param := range []string{"start_date", "end_date"} {
+		date, err := time.Parse("2006-01-02", ctx.Query(param))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " é obrigatória no formato AAAA-MM-DD"})
+			return
+		}
+		dates[i] = date
+	}
+
+	// A data final vale até o fim do dia
+	endDate := dates[1].Add(24*time.Hour - time.Nanosecond)
+	series, err := DashboardService.GetCategoryTimeSeries(dates[0], endDate, ctx.Query("granularity"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, series)
+}
+
 // GetDashboardByCategory obtém os dados do dashboard para uma categoria específica
 // @Summary Obter dashboard por categoria
 // @Description Retorna dados do dashboard filtrados por categoria de ONG
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchDonationsRejectsInvalidAmountRange(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/explorer/search", SearchDonations)
+
+	for _, query := range []string{"min_amount=500&max_amount=50", "min_amount=abc", "max_amount=-1"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/explorer/search?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/explorer/search?min_amount=10000", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSearchDonationsRejectsUnknownSort(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/explorer/search", SearchDonations)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/explorer/search?sort=random", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/explorer/search?sort=amount_asc", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSearchDonationsValidatesStatus(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/explorer/search", SearchDonations)
+
+	for query, expected := range map[string]int{
+		"status=pending":   http.StatusBadRequest,
+		"status=cancelled": http.StatusBadRequest,
+		"status=refunded":  http.StatusOK,
+		"status=all":       http.StatusOK,
+		"after=xyz":        http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/explorer/search?"+query, nil))
+		assert.Equal(t, expected, w.Code, query)
+	}
+}
+
+func TestGetCategoryTimeSeriesValidatesQuery(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/dashboard/category-timeseries", GetCategoryTimeSeries)
+
+	for query, expected := range map[string]int{
+		"start_date=2024-01-01&end_date=2024-03-31":                    http.StatusOK,
+		"start_date=2024-01-01&end_date=2024-01-31&granularity=daily":  http.StatusOK,
+		"end_date=2024-03-31":                                          http.StatusBadRequest,
+		"start_date=01/01/2024&end_date=2024-03-31":                    http.StatusBadRequest,
+		"start_date=2024-04-01&end_date=2024-03-31":                    http.StatusBadRequest,
+		"start_date=2024-01-01&end_date=2024-03-31&granularity=yearly": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dashboard/category-timeseries?"+query, nil))
+		assert.Equal(t, expected, w.Code, query)
+	}
+}
+
+func TestGetCategories(t *testing.T) {
+	router := gin.New()
+	router.GET("/categories", GetCategories)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/categories", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string][]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, models.NGOCategories, body["ngo_categories"])
+	assert.Equal(t, models.ExpenseCategories, body["expense_categories"])
+}
+
+func TestGetTopDonorsExcludesDonorsWithoutOptIn(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/dashboard/top-donors", GetTopDonors)
+
+	user, err := DonationService.RegisterUser("Ana Souza", "ana@example.com", true)
+	require.NoError(t, err)
+	for _, donorID := range []uint{1, user.ID} {
+		donation, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: donorID, NGOID: 1})
+		require.NoError(t, err)
+		_, err = DonationService.MockPaymentConfirmation(donation.ID)
+		require.NoError(t, err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dashboard/top-donors?limit=5", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var donors []models.TopDonor
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &donors))
+	require.Len(t, donors, 1)
+	assert.Equal(t, user.ID, donors[0].DonorID)
+	assert.Equal(t, "Ana Souza", donors[0].DonorName)
+}
@@ -1,6 +1,10 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"trackable-donations/api/internal/services"
@@ -22,9 +26,14 @@ func GetPublicDashboard(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, dashboard)
 }
 
-// GetPublicDonations retorna todas as doações públicas
+// GetPublicDonations retorna todas as doações públicas, opcionalmente de uma campanha
 func GetPublicDonations(ctx *gin.Context) {
-	donations := TransparencyService.GetPublicDonations()
+	campaignID, ok := campaignFilter(ctx)
+	if !ok {
+		return
+	}
+
+	donations := TransparencyService.GetPublicDonations(campaignID)
 	ctx.JSON(http.StatusOK, donations)
 }
 
@@ -34,6 +43,54 @@ func GetPublicExpenses(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, expenses)
 }
 
+// ExportPublicDonationsCSV exporta as doações públicas em CSV, opcionalmente de uma campanha
+func ExportPublicDonationsCSV(ctx *gin.Context) {
+	campaignID, ok := campaignFilter(ctx)
+	if !ok {
+		return
+	}
+
+	donations := TransparencyService.GetPublicDonations(campaignID)
+	sendCSV(ctx, "doacoes.csv", func(w io.Writer) error {
+		return services.WriteDonationsCSV(w, donations)
+	})
+}
+
+// ExportPublicExpensesCSV exporta as despesas públicas em CSV
+func ExportPublicExpensesCSV(ctx *gin.Context) {
+	expenses := TransparencyService.GetPublicExpenses()
+	sendCSV(ctx, "despesas.csv", func(w io.Writer) error {
+		return services.WriteExpensesCSV(w, expenses)
+	})
+}
+
+// campaignFilter lê o filtro opcional campaign_id da query (0 = todas as campanhas),
+// respondendo 400 quando o valor é inválido
+func campaignFilter(ctx *gin.Context) (uint, bool) {
+	value := ctx.Query("campaign_id")
+	if value == "" {
+		return 0, true
+	}
+	campaignID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || campaignID == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "campaign_id deve ser um inteiro positivo"})
+		return 0, false
+	}
+	return uint(campaignID), true
+}
+
+// sendCSV envia o CSV como anexo, escrevendo diretamente na resposta. Os headers são
+// definidos antes da primeira escrita, quando passam a ser enviados junto com as linhas.
+func sendCSV(ctx *gin.Context, filename string, write func(io.Writer) error) {
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Status(http.StatusOK)
+	if err := write(ctx.Writer); err != nil {
+		// O cabeçalho já foi enviado; resta registrar a falha
+		log.Printf("Erro ao exportar %s: %v", filename, err)
+	}
+}
+
 // GetPublicNGOsSummary retorna um resumo de todas as ONGs
 func GetPublicNGOsSummary(ctx *gin.Context) {
 	summaries := TransparencyService.GetAllNGOsSummary()
@@ -57,15 +114,40 @@ func GetPublicNGOSummary(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, summary)
 }
 
-// GetPublicNGODonations retorna todas as doações de uma ONG específica
+// GetPublicNGOContact retorna o contato público de uma ONG para dúvidas de doadores
+func GetPublicNGOContact(ctx *gin.Context) {
+	ngoID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de ONG inválido"})
+		return
+	}
+
+	contact, err := TransparencyService.GetNGOContact(uint(ngoID))
+	if errors.Is(err, services.ErrNGOContactHidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, contact)
+}
+
+// GetPublicNGODonations retorna todas as doações de uma ONG específica, opcionalmente de uma campanha
 func GetPublicNGODonations(ctx *gin.Context) {
 	ngoID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID de ONG inválido"})
 		return
 	}
+	campaignID, ok := campaignFilter(ctx)
+	if !ok {
+		return
+	}
 
-	donations, err := TransparencyService.GetDonationsByNGO(uint(ngoID))
+	donations, err := TransparencyService.GetDonationsByNGO(uint(ngoID), campaignID)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -90,3 +172,14 @@ func GetPublicNGOExpenses(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, expenses)
 }
+
+// GetPlatformTransparencyScore retorna o índice de transparência da plataforma
+func GetPlatformTransparencyScore(ctx *gin.Context) {
+	score := TransparencyService.GetPlatformTransparencyScore()
+	ctx.JSON(http.StatusOK, score)
+}
+
+// GetTransparencySchema retorna o dicionário de dados (JSON Schema) dos tipos públicos de transparência
+func GetTransparencySchema(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, services.GetTransparencySchema())
+}
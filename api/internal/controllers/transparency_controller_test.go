@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPublicDonationsCSV(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.GET("/transparency/donations.csv", ExportPublicDonationsCSV)
+
+	donation, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 75.5, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	_, err = DonationService.MockPaymentConfirmation(donation.ID)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/transparency/donations.csv", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="doacoes.csv"`, w.Header().Get("Content-Disposition"))
+
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\r\n"), "\r\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "id,amount,ngo_name"))
+	assert.Contains(t, lines[1], ",75.50,Alimentando Esperança,")
+}
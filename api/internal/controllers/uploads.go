@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"trackable-donations/api/internal/config"
+	"trackable-donations/api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// allowedUploadTypes são os tipos MIME aceitos por categoria de upload
+var allowedUploadTypes = config.DefaultAllowedUploadTypes()
+
+// SetAllowedUploadTypes configura os tipos MIME aceitos por categoria de upload.
+// Categorias ausentes mantêm os tipos padrão.
+func SetAllowedUploadTypes(types map[string][]string) {
+	allowedUploadTypes = config.DefaultAllowedUploadTypes()
+	for uploadType, mimeTypes := range types {
+		allowedUploadTypes[uploadType] = mimeTypes
+	}
+}
+
+// maxUploadSize limita o corpo das requisições de upload, em bytes
+var maxUploadSize int64 = config.DefaultMaxUploadSize
+
+// SetMaxUploadSize configura o tamanho máximo, em bytes, das requisições de upload
+func SetMaxUploadSize(size int64) {
+	maxUploadSize = size
+}
+
+// formFile obtém o arquivo enviado no campo informado, limitando o corpo da requisição a
+// maxUploadSize. Em caso de erro já responde: 413 acima do limite e 400 nos demais casos.
+func formFile(ctx *gin.Context, field string) (multipart.File, bool) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxUploadSize)
+
+	file, _, err := ctx.Request.FormFile(field)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Arquivo muito grande; o limite é de %d MB", maxUploadSize>>20)})
+			return nil, false
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao processar arquivo: " + err.Error()})
+		return nil, false
+	}
+	return file, true
+}
+
+// readUpload lê o arquivo enviado por completo. Um erro de leitura responde 500, para que
+// um arquivo truncado nunca seja armazenado como se estivesse completo.
+func readUpload(ctx *gin.Context, file io.Reader) ([]byte, bool) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler o arquivo: " + err.Error()})
+		return nil, false
+	}
+	return data, true
+}
+
+// validateUploadType verifica, pelo conteúdo do arquivo, se o tipo é aceito para a categoria de upload
+func validateUploadType(uploadType string, data []byte) error {
+	allowed := allowedUploadTypes[uploadType]
+	detected := utils.DetectMIMEType(data)
+
+	for _, mimeType := range allowed {
+		if mimeType == detected {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("tipo de arquivo não permitido (%s); tipos aceitos: %s", detected, strings.Join(allowed, ", "))
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/iotest"
+	"trackable-donations/api/internal/config"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	pdfFile  = []byte("%PDF-1.4\n1 0 obj\n<< >>\nendobj\n")
+	pngFile  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01")
+	jpegFile = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	// Executável renomeado como se fosse um documento
+	disguisedFile = []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff\x00\x00")
+)
+
+func docxFile(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	entry, err := writer.Create("word/document.xml")
+	require.NoError(t, err)
+	_, err = entry.Write([]byte("<w:document/>"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestValidateUploadTypePerCategory(t *testing.T) {
+	SetAllowedUploadTypes(nil)
+
+	cases := []struct {
+		uploadType string
+		accepted   [][]byte
+		rejected   [][]byte
+	}{
+		{config.UploadTypeReceipt, [][]byte{pdfFile, pngFile, jpegFile}, [][]byte{disguisedFile, docxFile(t)}},
+		{config.UploadTypeNGODocument, [][]byte{pdfFile, docxFile(t)}, [][]byte{disguisedFile}},
+		{config.UploadTypeLogo, [][]byte{pngFile, jpegFile}, [][]byte{disguisedFile, pdfFile}},
+	}
+
+	for _, tc := range cases {
+		for _, file := range tc.accepted {
+			assert.NoError(t, validateUploadType(tc.uploadType, file), tc.uploadType)
+		}
+		for _, file := range tc.rejected {
+			err := validateUploadType(tc.uploadType, file)
+			require.Error(t, err, tc.uploadType)
+			assert.Contains(t, err.Error(), "tipos aceitos")
+		}
+	}
+}
+
+func TestUploadReceiptRejectsDisguisedFile(t *testing.T) {
+	setupTestServices()
+	SetAllowedUploadTypes(nil)
+
+	resp, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	_, err = DonationService.MockPaymentConfirmation(resp.ID)
+	require.NoError(t, err)
+	expense, err := ExpenseService.RegisterExpense(models.ExpenseRequest{DonationID: resp.ID, NGOID: 1, Amount: 10, Description: "Cestas", Category: "Alimentação"})
+	require.NoError(t, err)
+	key, err := AdminService.IssueNGOAPIKey(1, 9)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.POST("/expenses/:id/receipt", UploadReceipt)
+
+	path := fmt.Sprintf("/expenses/%d/receipt", expense.ID)
+	upload := func(name string, content []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("receipt", name)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, path, &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set(services.NGOAPIKeyHeader, key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, upload("nota.pdf", disguisedFile).Code)
+	assert.Equal(t, http.StatusOK, upload("nota.pdf", pdfFile).Code)
+}
+
+func TestUploadsRejectOversizedFiles(t *testing.T) {
+	setupTestServices()
+	SetMaxUploadSize(1 << 20)
+	defer SetMaxUploadSize(config.DefaultMaxUploadSize)
+
+	resp, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	_, err = DonationService.MockPaymentConfirmation(resp.ID)
+	require.NoError(t, err)
+	expense, err := ExpenseService.RegisterExpense(models.ExpenseRequest{DonationID: resp.ID, NGOID: 1, Amount: 10, Description: "Cestas", Category: "Alimentação"})
+	require.NoError(t, err)
+	key, err := AdminService.IssueNGOAPIKey(1, 9)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.POST("/expenses/:id/receipt", UploadReceipt)
+	router.POST("/admin/ngos/registration/:id/upload-documents", UploadNGODocuments)
+
+	oversized := append(append([]byte{}, pdfFile...), bytes.Repeat([]byte("0"), 2<<20)...)
+	for path, field := range map[string]string{
+		fmt.Sprintf("/expenses/%d/receipt", expense.ID): "receipt",
+		"/admin/ngos/registration/1/upload-documents":   "documents",
+	} {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile(field, "arquivo.pdf")
+		require.NoError(t, err)
+		_, err = part.Write(oversized)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, path, &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set(services.NGOAPIKeyHeader, key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, path)
+		assert.Contains(t, w.Body.String(), "1 MB", path)
+	}
+}
+
+func TestReadUploadFailsOnReadError(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	// O leitor entrega parte do arquivo e então falha, como uma conexão interrompida
+	file := io.MultiReader(bytes.NewReader(pdfFile), iotest.ErrReader(errors.New("conexão interrompida")))
+	data, ok := readUpload(ctx, file)
+
+	assert.False(t, ok)
+	assert.Nil(t, data, "Um arquivo truncado não deve seguir para o armazenamento")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "conexão interrompida")
+
+	w = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(w)
+	data, ok = readUpload(ctx, bytes.NewReader(pdfFile))
+	assert.True(t, ok)
+	assert.Equal(t, pdfFile, data)
+}
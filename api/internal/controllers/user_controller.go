@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterUser cadastra um novo doador
+// @Summary Cadastrar doador
+// @Description Cadastra um novo doador; o e-mail deve ser único
+// @Tags Usuários
+// @Accept json
+// @Produce json
+// @Param usuario body models.UserRequest true "Dados do doador"
+// @Success 201 {object} map[string]models.User
+// @Failure 400 {object} map[string]string "Dados inválidos"
+// @Failure 409 {object} map[string]string "Cadastro não concluído para este e-mail"
+// @Router /users [post]
+func RegisterUser(c *gin.Context) {
+	var req models.UserRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := DonationService.RegisterUser(req.Name, req.Email, req.PublicRecognition)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrEmailAlreadyRegistered) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": user})
+}
+
+// GetUserByID retorna um doador pelo ID. Os dados incluem o e-mail, por isso a rota exige um administrador.
+// @Summary Obter doador por ID
+// @Description Retorna os dados cadastrais de um doador (apenas administradores)
+// @Tags Usuários
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do doador"
+// @Success 200 {object} map[string]models.User
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 401 {object} map[string]string "Token de administrador ausente ou inválido"
+// @Failure 404 {object} map[string]string "Usuário não encontrado"
+// @Router /users/{id} [get]
+func GetUserByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	user, err := DonationService.GetUserByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
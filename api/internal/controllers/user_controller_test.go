@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterUserAndGetByID(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.POST("/users", RegisterUser)
+	router.GET("/users/:id", GetUserByID)
+
+	register := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := register(`{"name": "Ana Souza", "email": "ana@example.com"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data models.User `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotZero(t, created.Data.ID)
+
+	w = register(`{"name": "Outra Ana", "email": "ANA@example.com"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NotContains(t, w.Body.String(), "cadastrado", "A resposta não deve confirmar que o e-mail já existe")
+	assert.Equal(t, http.StatusBadRequest, register(`{"name": "Sem E-mail", "email": "ana.example.com"}`).Code)
+
+	// Falhas de validação usam as mensagens por campo, sem o texto cru do validador
+	w = register(`{"name": "Sem E-mail"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"errors": {"email": "é obrigatório"}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/users/%d", created.Data.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "ana@example.com")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/999", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// bindJSON decodifica o corpo JSON em obj e valida as tags binding. Em caso de erro já
+// responde 400: falhas de validação e de tipo viram {"errors": {"campo": "mensagem"}},
+// com o nome JSON do campo; um JSON malformado responde {"error": "..."}. Um corpo vazio
+// é validado como um objeto vazio, apontando os campos obrigatórios.
+func bindJSON(ctx *gin.Context, obj any) bool {
+	err := ctx.ShouldBindJSON(obj)
+	if errors.Is(err, io.EOF) {
+		err = binding.Validator.ValidateStruct(obj)
+	}
+	if err == nil {
+		return true
+	}
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make(map[string]string, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields[jsonFieldName(obj, fieldErr.StructField())] = validationMessage(fieldErr)
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": fields})
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": map[string]string{
+			typeErr.Field: fmt.Sprintf("deve ser do tipo %s", jsonTypeName(typeErr.Type)),
+		}})
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "JSON inválido no corpo da requisição"})
+	}
+	return false
+}
+
+// validationMessage traduz a falha de uma tag binding para uma mensagem em português
+func validationMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "é obrigatório"
+	case "email":
+		return "deve ser um e-mail válido"
+	case "url":
+		return "deve ser uma URL válida"
+	case "gt":
+		if param == "0" {
+			return "deve ser maior que zero"
+		}
+		return fmt.Sprintf("deve ser maior que %s", param)
+	case "gte":
+		if param == "0" {
+			return "não pode ser negativo"
+		}
+		return fmt.Sprintf("deve ser maior ou igual a %s", param)
+	case "lt":
+		return fmt.Sprintf("deve ser menor que %s", param)
+	case "lte":
+		return fmt.Sprintf("deve ser menor ou igual a %s", param)
+	case "oneof":
+		return fmt.Sprintf("deve ser um de: %s", strings.ReplaceAll(param, " ", ", "))
+	default:
+		return "é inválido"
+	}
+}
+
+// jsonFieldName retorna o nome JSON do campo da struct, ou o próprio nome sem a tag json
+func jsonFieldName(obj any, structField string) string {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		if field, ok := t.FieldByName(structField); ok {
+			if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return structField
+}
+
+// jsonTypeName descreve o tipo esperado de um campo na nomenclatura do JSON
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "booleano"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "número"
+	case reflect.String:
+		return "texto"
+	case reflect.Slice, reflect.Array:
+		return "lista"
+	default:
+		return "objeto"
+	}
+}
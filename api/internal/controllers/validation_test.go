@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postValidation envia o corpo e retorna o status e as mensagens por campo da resposta
+func postValidation(t *testing.T, router *gin.Engine, path, body string) (int, map[string]string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Errors map[string]string `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp.Errors
+}
+
+func TestBindingErrorsArePerField(t *testing.T) {
+	setupTestServices()
+	router := gin.New()
+	router.POST("/donations", CreateDonation)
+	router.POST("/expenses", RegisterExpense)
+	router.POST("/admin/ngos/register", RegisterNGO)
+
+	status, fields := postValidation(t, router, "/donations", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, map[string]string{
+		"amount":   "é obrigatório",
+		"donor_id": "é obrigatório",
+		"ngo_id":   "é obrigatório",
+	}, fields)
+
+	_, fields = postValidation(t, router, "/donations", `{"amount": -5, "tip": -1, "donor_id": 1, "ngo_id": 1}`)
+	assert.Equal(t, map[string]string{
+		"amount": "deve ser maior que zero",
+		"tip":    "não pode ser negativo",
+	}, fields)
+
+	_, fields = postValidation(t, router, "/donations", `{"amount": "cem", "donor_id": 1, "ngo_id": 1}`)
+	assert.Equal(t, map[string]string{"amount": "deve ser do tipo número"}, fields)
+
+	_, fields = postValidation(t, router, "/expenses", `{"donation_id": 1, "ngo_id": 1, "amount": 0}`)
+	assert.Equal(t, map[string]string{
+		"amount":      "é obrigatório",
+		"description": "é obrigatório",
+		"category":    "é obrigatório",
+	}, fields)
+
+	_, fields = postValidation(t, router, "/admin/ngos/register", `{"name": "ONG", "email": "invalido"}`)
+	assert.Equal(t, "deve ser um e-mail válido", fields["email"])
+	assert.Equal(t, "é obrigatório", fields["cnpj"])
+	assert.NotContains(t, fields, "name")
+
+	// JSON malformado não tem campos a apontar
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/donations", strings.NewReader(`{"amount":`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "JSON inválido no corpo da requisição"}`, w.Body.String())
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"trackable-donations/api/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentWebhookSignatureHeader é o header com o HMAC-SHA256 (hex) do corpo do webhook
+const PaymentWebhookSignatureHeader = "X-Webhook-Signature"
+
+// maxWebhookBodySize limita o corpo aceito nos webhooks
+const maxWebhookBodySize = 64 << 10
+
+// PaymentWebhook é o receptor dos webhooks do gateway de pagamento
+var PaymentWebhook *services.PaymentWebhookHandler
+
+// SetupPaymentWebhook configura o receptor de webhooks com o segredo compartilhado com o gateway
+func SetupPaymentWebhook(donationService *services.DonationService, secret string) {
+	PaymentWebhook = services.NewPaymentWebhookHandler(donationService, secret)
+}
+
+// ReceivePaymentWebhook recebe a notificação de pagamento do gateway
+// @Summary Webhook de pagamento
+// @Description Recebe notificações assinadas do gateway; o status "paid" confirma a doação (idempotente)
+// @Tags Doações
+// @Accept json
+// @Produce json
+// @Param X-Webhook-Signature header string true "HMAC-SHA256 (hex) do corpo com PAYMENT_WEBHOOK_SECRET"
+// @Param payload body models.PaymentWebhookPayload true "Notificação do gateway"
+// @Success 200 {object} map[string]models.DonationResponse
+// @Success 202 {object} map[string]string "Confirmação já em andamento"
+// @Failure 400 {object} map[string]string "Payload inválido"
+// @Failure 401 {object} map[string]string "Assinatura inválida"
+// @Failure 404 {object} map[string]string "Doação não encontrada"
+// @Failure 409 {object} map[string]string "Doação não está pendente"
+// @Router /webhooks/payment [post]
+func ReceivePaymentWebhook(c *gin.Context) {
+	// A assinatura é calculada sobre o corpo bruto, por isso ele é lido antes de qualquer decodificação
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Corpo do webhook muito grande"})
+		return
+	}
+
+	response, err := PaymentWebhook.Handle(body, c.GetHeader(PaymentWebhookSignatureHeader))
+	switch {
+	case errors.Is(err, services.ErrInvalidWebhookSignature):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidWebhookPayload):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPaymentConfirmationInProgress):
+		// O gateway não precisa reenviar: a confirmação em andamento concluirá a doação
+		c.JSON(http.StatusAccepted, gin.H{"message": err.Error()})
+	case err != nil:
+		c.JSON(confirmationErrorStatus(err), gin.H{"error": err.Error()})
+	case response == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Status registrado"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"data": response})
+	}
+}
+
+// confirmationErrorStatus mapeia os erros da confirmação de pagamento para o status HTTP
+func confirmationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrDonationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrDonationNotPending), errors.Is(err, services.ErrPaymentConfirmationInProgress):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
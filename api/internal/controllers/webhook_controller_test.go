@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceivePaymentWebhook(t *testing.T) {
+	setupTestServices()
+	SetupPaymentWebhook(DonationService, "segredo")
+	router := gin.New()
+	router.POST("/webhooks/payment", ReceivePaymentWebhook)
+
+	send := func(body, secret string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/payment", strings.NewReader(body))
+		req.Header.Set(PaymentWebhookSignatureHeader, hex.EncodeToString(services.SignWebhookPayload([]byte(secret), []byte(body))))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	donation, err := DonationService.ProcessDonation(models.DonationRequest{Amount: 40, DonorID: 1, NGOID: 2})
+	require.NoError(t, err)
+	body := fmt.Sprintf(`{"donation_id": %d, "status": "paid", "gateway_ref": "pay_9"}`, donation.ID)
+
+	assert.Equal(t, http.StatusUnauthorized, send(body, "errado").Code)
+	assert.Equal(t, http.StatusNotFound, send(`{"donation_id": 999, "status": "paid"}`, "segredo").Code)
+
+	first := send(body, "segredo")
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Contains(t, first.Body.String(), `"status":"completed"`)
+
+	second := send(body, "segredo")
+	require.Equal(t, http.StatusOK, second.Code, "Webhooks repetidos são idempotentes")
+	assert.Equal(t, first.Body.String(), second.Body.String())
+}
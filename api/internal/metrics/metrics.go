@@ -0,0 +1,117 @@
+// Package metrics define as métricas Prometheus da API, expostas em /metrics
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Namespace prefixa o nome de todas as métricas da API
+const Namespace = "levitate"
+
+// UnmatchedRoute é o rótulo de rota das requisições que não correspondem a nenhuma rota, para
+// que caminhos arbitrários não criem uma série por URL
+const UnmatchedRoute = "unmatched"
+
+var (
+	// HTTPRequests conta as requisições atendidas por método, rota e status da resposta
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "http_requests_total",
+		Help:      "Requisições HTTP atendidas, por método, rota e status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration mede a latência das requisições por método, rota e status da resposta
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latência das requisições HTTP em segundos, por método, rota e status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// DonationsCreated conta as doações criadas (ainda pendentes de pagamento)
+	DonationsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "donations_created_total",
+		Help:      "Doações criadas desde o início do processo.",
+	})
+
+	// RateLimitRejections conta as requisições recusadas pelos limitadores, por rota
+	RateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requisições recusadas por excederem o limite de requisições, por rota.",
+	}, []string{"route"})
+)
+
+// Register registra no registry as métricas da API e as do runtime Go e do processo.
+// Registrar o mesmo coletor duas vezes no mesmo registry não é considerado erro.
+func Register(reg prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{
+		HTTPRequests,
+		HTTPRequestDuration,
+		DonationsCreated,
+		RateLimitRejections,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	} {
+		if err := reg.Register(collector); err != nil {
+			var already prometheus.AlreadyRegisteredError
+			if !errors.As(err, &already) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Inventory são as quantidades de registros mantidos em memória no momento da coleta
+type Inventory struct {
+	NGOs              int
+	Donations         int
+	Expenses          int
+	DonationsByStatus map[string]int
+}
+
+var (
+	ngosDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "ngos"),
+		"ONGs mantidas em memória.", nil, nil)
+	donationsDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "donations"),
+		"Doações mantidas em memória.", nil, nil)
+	expensesDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "expenses"),
+		"Gastos mantidos em memória.", nil, nil)
+	donationsByStatusDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "donations_by_status"),
+		"Doações mantidas em memória, por status.", []string{"status"}, nil)
+)
+
+// inventoryCollector lê as quantidades a cada coleta, em vez de manter gauges atualizados
+// a cada alteração nos serviços
+type inventoryCollector struct {
+	snapshot func() Inventory
+}
+
+// NewInventoryCollector cria o coletor das quantidades em memória retornadas por snapshot
+func NewInventoryCollector(snapshot func() Inventory) prometheus.Collector {
+	return inventoryCollector{snapshot: snapshot}
+}
+
+// Describe envia as descrições das métricas do coletor
+func (c inventoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ngosDesc
+	ch <- donationsDesc
+	ch <- expensesDesc
+	ch <- donationsByStatusDesc
+}
+
+// Collect envia as quantidades atuais
+func (c inventoryCollector) Collect(ch chan<- prometheus.Metric) {
+	inventory := c.snapshot()
+	ch <- prometheus.MustNewConstMetric(ngosDesc, prometheus.GaugeValue, float64(inventory.NGOs))
+	ch <- prometheus.MustNewConstMetric(donationsDesc, prometheus.GaugeValue, float64(inventory.Donations))
+	ch <- prometheus.MustNewConstMetric(expensesDesc, prometheus.GaugeValue, float64(inventory.Expenses))
+	for status, count := range inventory.DonationsByStatus {
+		ch <- prometheus.MustNewConstMetric(donationsByStatusDesc, prometheus.GaugeValue, float64(count), status)
+	}
+}
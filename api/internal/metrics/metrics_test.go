@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterIsIdempotent(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, Register(registry))
+	assert.NoError(t, Register(registry))
+}
+
+func TestInventoryCollectorReadsSnapshotOnCollect(t *testing.T) {
+	inventory := Inventory{NGOs: 3, Donations: 5, Expenses: 2, DonationsByStatus: map[string]int{"completed": 4, "pending": 1}}
+	collector := NewInventoryCollector(func() Inventory { return inventory })
+
+	expected := `
+# HELP levitate_donations_by_status Doações mantidas em memória, por status.
+# TYPE levitate_donations_by_status gauge
+levitate_donations_by_status{status="completed"} 4
+levitate_donations_by_status{status="pending"} 1
+# HELP levitate_ngos ONGs mantidas em memória.
+# TYPE levitate_ngos gauge
+levitate_ngos 3
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"levitate_ngos", "levitate_donations_by_status"))
+
+	// Cada coleta reflete o estado atual
+	inventory.NGOs = 4
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP levitate_ngos ONGs mantidas em memória.
+# TYPE levitate_ngos gauge
+levitate_ngos 4
+`), "levitate_ngos"))
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"trackable-donations/api/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth exige um token JWT de administrador válido no header
+// "Authorization: Bearer <token>" e guarda o ID do administrador no contexto
+func AdminAuth(tokens *auth.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Acesso não autorizado"})
+			return
+		}
+
+		adminID, err := tokens.ParseToken(strings.TrimSpace(token))
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="admin", error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(auth.ContextAdminIDKey, adminID)
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := auth.NewManager("segredo-de-teste-com-32-caracteres!", time.Hour, nil)
+
+	router := gin.New()
+	router.GET("/admin", AdminAuth(manager), func(c *gin.Context) {
+		adminID, _ := auth.AdminIDFromContext(c)
+		c.JSON(http.StatusOK, gin.H{"admin_id": adminID})
+	})
+
+	token, _, err := manager.IssueToken(3)
+	require.NoError(t, err)
+	forged, _, err := auth.NewManager("outro-segredo", time.Hour, nil).IssueToken(3)
+	require.NoError(t, err)
+
+	for header, expected := range map[string]int{
+		"":                 http.StatusUnauthorized,
+		"3":                http.StatusUnauthorized,
+		"Basic " + token:   http.StatusUnauthorized,
+		"Bearer " + forged: http.StatusUnauthorized,
+		"Bearer " + token:  http.StatusOK,
+		"bearer  " + token: http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("Authorization", header)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, expected, w.Code, header)
+		if expected == http.StatusOK {
+			assert.JSONEq(t, `{"admin_id":3}`, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+	"trackable-donations/api/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics registra a quantidade e a latência das requisições por método, rota e status. A rota
+// é o padrão registrado (ex.: /api/v1/ngos/:id), e não o caminho da URL, para que os IDs não
+// criem uma série por recurso.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		route := metricsRoute(c)
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// metricsRoute retorna o rótulo de rota da requisição
+func metricsRoute(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return metrics.UnmatchedRoute
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsCountRequestsByRouteAndStatus(t *testing.T) {
+	rl := newRateLimiter(1, time.Minute, time.Now)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/ngos/:id", rl.RateLimit(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	ok := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/ngos/:id", "200")
+	limited := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/ngos/:id", "429")
+	unmatched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, metrics.UnmatchedRoute, "404")
+	rejections := metrics.RateLimitRejections.WithLabelValues("/ngos/:id")
+	before := []float64{testutil.ToFloat64(ok), testutil.ToFloat64(limited), testutil.ToFloat64(unmatched), testutil.ToFloat64(rejections)}
+
+	for _, path := range []string{"/ngos/1", "/ngos/2", "/nao-existe"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// A rota é o padrão registrado, não o caminho com o ID
+	assert.Equal(t, before[0]+1, testutil.ToFloat64(ok))
+	assert.Equal(t, before[1]+1, testutil.ToFloat64(limited))
+	assert.Equal(t, before[2]+1, testutil.ToFloat64(unmatched))
+	assert.Equal(t, before[3]+1, testutil.ToFloat64(rejections))
+}
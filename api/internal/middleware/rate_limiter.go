@@ -2,32 +2,112 @@ package middleware
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"sync"
 	"time"
+	"trackable-donations/api/internal/metrics"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RateLimiter implementa limitação de requisições por IP
+// Limiter é implementado pelos limitadores de requisições por IP usados nas rotas
+// (RateLimiter e TokenBucketLimiter); Close encerra a rotina de limpeza
+type Limiter interface {
+	RateLimit() gin.HandlerFunc
+	io.Closer
+}
+
+// RateLimiter implementa limitação de requisições por IP com uma janela deslizante
 type RateLimiter struct {
 	sync.Mutex
 	ipLimits     map[string][]time.Time
 	maxRequests  int
 	windowLength time.Duration
 	enabled      bool
+	now          func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
 }
 
-// NewRateLimiter cria um novo limitador de requisições
+// NewRateLimiter cria um novo limitador de requisições. Uma rotina em segundo plano
+// descarta, a cada janela, os IPs sem requisições recentes; use Close para encerrá-la.
 func NewRateLimiter(maxRequests int, windowLength time.Duration) *RateLimiter {
+	rl := newRateLimiter(maxRequests, windowLength, time.Now)
+
+	ticker := time.NewTicker(windowLength)
+	go func() {
+		defer ticker.Stop()
+		rl.cleanupLoop(ticker.C)
+	}()
+
+	return rl
+}
+
+func newRateLimiter(maxRequests int, windowLength time.Duration, now func() time.Time) *RateLimiter {
 	return &RateLimiter{
 		ipLimits:     make(map[string][]time.Time),
 		maxRequests:  maxRequests,
 		windowLength: windowLength,
 		enabled:      true,
+		now:          now,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
 	}
 }
 
+// cleanupLoop executa a limpeza a cada tick até o limitador ser fechado
+func (rl *RateLimiter) cleanupLoop(ticks <-chan time.Time) {
+	defer close(rl.done)
+	for {
+		select {
+		case <-ticks:
+			rl.cleanup()
+		case <-rl.stop:
+			return
+		}
+	}
+}
+
+// cleanup remove os IPs cujas requisições já saíram da janela
+func (rl *RateLimiter) cleanup() {
+	rl.Lock()
+	defer rl.Unlock()
+
+	validTime := rl.now().Add(-rl.windowLength)
+	for ip := range rl.ipLimits {
+		if rl.prune(ip, validTime) == 0 {
+			delete(rl.ipLimits, ip)
+		}
+	}
+}
+
+// prune descarta as requisições do IP anteriores a validTime e retorna quantas restaram
+func (rl *RateLimiter) prune(ip string, validTime time.Time) int {
+	var validRequests []time.Time
+	for _, t := range rl.ipLimits[ip] {
+		if t.After(validTime) {
+			validRequests = append(validRequests, t)
+		}
+	}
+	rl.ipLimits[ip] = validRequests
+	return len(validRequests)
+}
+
+// Stop encerra a rotina de limpeza e aguarda o seu término
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() { close(rl.stop) })
+	<-rl.done
+}
+
+// Close encerra a rotina de limpeza, permitindo fechar o limitador junto com os demais componentes
+func (rl *RateLimiter) Close() error {
+	rl.Stop()
+	return nil
+}
+
 // RateLimit retorna um middleware Gin para limitar requisições
 func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -43,20 +123,9 @@ func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 		defer rl.Unlock()
 
 		// Remover requisições antigas do período de janela
-		now := time.Now()
+		now := rl.now()
 		validTime := now.Add(-rl.windowLength)
-
-		if _, exists := rl.ipLimits[ip]; exists {
-			var validRequests []time.Time
-			for _, t := range rl.ipLimits[ip] {
-				if t.After(validTime) {
-					validRequests = append(validRequests, t)
-				}
-			}
-			rl.ipLimits[ip] = validRequests
-		} else {
-			rl.ipLimits[ip] = []time.Time{}
-		}
+		rl.prune(ip, validTime)
 
 		// Verificar limite
 		if len(rl.ipLimits[ip]) >= rl.maxRequests {
@@ -66,6 +135,7 @@ func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 			resetTime := validTime.Add(rl.windowLength)
 			c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", resetTime.Unix()))
 
+			metrics.RateLimitRejections.WithLabelValues(metricsRoute(c)).Inc()
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 				"error": "Limite de requisições excedido. Tente novamente mais tarde.",
 			})
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterCleanupAndClose(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	rl := newRateLimiter(10, time.Minute, func() time.Time { return clock })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(rl.RateLimit())
+	router.GET("/ngos", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ngos", nil))
+	require.Len(t, rl.GetLimits(), 1)
+
+	// Ainda dentro da janela: o IP é mantido
+	rl.cleanup()
+	assert.Len(t, rl.GetLimits(), 1)
+
+	// Fora da janela: a rotina de limpeza descarta o IP no próximo tick
+	clock = clock.Add(2 * time.Minute)
+	ticks := make(chan time.Time)
+	go rl.cleanupLoop(ticks)
+	ticks <- clock
+	assert.Eventually(t, func() bool { return len(rl.GetLimits()) == 0 }, time.Second, time.Millisecond)
+
+	require.NoError(t, rl.Close())
+	select {
+	case <-rl.done:
+	default:
+		t.Fatal("a rotina de limpeza deveria ter terminado")
+	}
+	assert.NoError(t, rl.Close(), "Close deve ser idempotente")
+}
+
+func TestRateLimiterCleanupReleasesExpiredIPs(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	rl := newRateLimiter(10, time.Minute, func() time.Time { return clock })
+
+	for i := 0; i < 10000; i++ {
+		rl.ipLimits[fmt.Sprintf("10.0.%d.%d", i/256, i%256)] = []time.Time{clock}
+	}
+	require.Len(t, rl.GetLimits(), 10000)
+
+	clock = clock.Add(time.Minute + time.Second)
+	ticks := make(chan time.Time)
+	go rl.cleanupLoop(ticks)
+	ticks <- clock
+	rl.Stop()
+
+	assert.Empty(t, rl.ipLimits)
+}
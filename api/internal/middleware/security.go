@@ -1,31 +1,97 @@
 package middleware
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
-// SecureHeaders adiciona headers de segurança às respostas HTTP
+// Políticas de conteúdo usadas pelos perfis padrão
+const (
+	strictContentSecurityPolicy = "default-src 'self'; script-src 'self'; connect-src 'self'; img-src 'self'; style-src 'self';"
+	// O Swagger UI usa scripts e estilos inline e a página de teste carrega os assets do unpkg.com
+	docsContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline' https://unpkg.com; connect-src 'self'; img-src 'self' data: https://unpkg.com; style-src 'self' 'unsafe-inline' https://unpkg.com;"
+)
+
+// SecurityHeaderProfile define os headers de segurança aplicados a um grupo de rotas
+type SecurityHeaderProfile struct {
+	ContentSecurityPolicy   string
+	FrameOptions            string
+	StrictTransportSecurity string // Vazio desativa o HSTS
+	NoStore                 bool   // Desativa o cache das respostas
+}
+
+// SecureHeadersConfig define os perfis de headers das rotas da API e das rotas de documentação
+type SecureHeadersConfig struct {
+	API          SecurityHeaderProfile
+	Docs         SecurityHeaderProfile
+	DocsPrefixes []string
+}
+
+// NewSecureHeadersConfig retorna a configuração padrão para o ambiente informado.
+// A API usa sempre a política estrita; o HSTS só é enviado em produção.
+func NewSecureHeadersConfig(env string) SecureHeadersConfig {
+	hsts := ""
+	if env == "production" {
+		hsts = "max-age=31536000; includeSubDomains"
+	}
+
+	return SecureHeadersConfig{
+		API: SecurityHeaderProfile{
+			ContentSecurityPolicy:   strictContentSecurityPolicy,
+			FrameOptions:            "DENY",
+			StrictTransportSecurity: hsts,
+			NoStore:                 true,
+		},
+		Docs: SecurityHeaderProfile{
+			ContentSecurityPolicy:   docsContentSecurityPolicy,
+			FrameOptions:            "SAMEORIGIN",
+			StrictTransportSecurity: hsts,
+		},
+		DocsPrefixes: []string{"/swagger/", "/swagger-test"},
+	}
+}
+
+// SecureHeaders adiciona headers de segurança às respostas HTTP usando a configuração de produção
 func SecureHeaders() gin.HandlerFunc {
+	return SecureHeadersWithConfig(NewSecureHeadersConfig("production"))
+}
+
+// SecureHeadersWithConfig adiciona headers de segurança escolhendo o perfil pelo caminho da requisição
+func SecureHeadersWithConfig(config SecureHeadersConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		profile := config.API
+		for _, prefix := range config.DocsPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				profile = config.Docs
+				break
+			}
+		}
+
 		// Strict Transport Security - força HTTPS
-		c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		if profile.StrictTransportSecurity != "" {
+			c.Header("Strict-Transport-Security", profile.StrictTransportSecurity)
+		}
 
 		// Evita MIME type sniffing
 		c.Header("X-Content-Type-Options", "nosniff")
 
 		// Previne ataques de clickjacking
-		c.Header("X-Frame-Options", "DENY")
+		c.Header("X-Frame-Options", profile.FrameOptions)
 
 		// Proteção XSS
 		c.Header("X-XSS-Protection", "1; mode=block")
 
 		// Define política de origens permitidas para recursos
-		c.Header("Content-Security-Policy", "default-src 'self'; script-src 'self'; connect-src 'self'; img-src 'self'; style-src 'self';")
+		c.Header("Content-Security-Policy", profile.ContentSecurityPolicy)
 
 		// Desativa cache para APIs
-		c.Header("Cache-Control", "no-store, no-cache, must-revalidate, proxy-revalidate")
-		c.Header("Pragma", "no-cache")
-		c.Header("Expires", "0")
+		if profile.NoStore {
+			c.Header("Cache-Control", "no-store, no-cache, must-revalidate, proxy-revalidate")
+			c.Header("Pragma", "no-cache")
+			c.Header("Expires", "0")
+		}
 
 		c.Next()
 	}
@@ -47,17 +113,43 @@ func RedirectHTTP() gin.HandlerFunc {
 	}
 }
 
-// CORS configura headers CORS para permitir acessos de outras origens
-func CORS() gin.HandlerFunc {
+// CORS libera o acesso de outras origens apenas para as listadas em allowedOrigins: o
+// header Origin da requisição é devolvido quando está na lista e, caso contrário, nenhum
+// header CORS é enviado. A origem "*" libera qualquer origem, sem credenciais (apenas
+// para desenvolvimento).
+func CORS(allowedOrigins []string) gin.HandlerFunc {
+	allowAny := false
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAny = true
+		}
+		allowed[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, X-Admin-ID")
-		c.Header("Access-Control-Max-Age", "86400") // 24 horas
-
-		// Se for uma requisição OPTIONS (preflight), responda imediatamente
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		origin := c.GetHeader("Origin")
+		if !allowAny {
+			// A resposta depende da origem, então caches não podem reaproveitá-la entre origens
+			c.Header("Vary", "Origin")
+		}
+
+		if origin != "" && (allowAny || allowed[strings.ToLower(origin)]) {
+			if allowAny {
+				c.Header("Access-Control-Allow-Origin", "*")
+			} else {
+				c.Header("Access-Control-Allow-Origin", origin)
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, X-NGO-Key")
+			c.Header("Access-Control-Max-Age", "86400") // 24 horas
+		}
+
+		// Se for uma requisição OPTIONS (preflight), responda imediatamente; sem os headers
+		// acima, o navegador bloqueia a requisição de origens não permitidas
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
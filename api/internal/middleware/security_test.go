@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecureHeadersProfilesByRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SecureHeadersWithConfig(NewSecureHeadersConfig("production")))
+	router.GET("/swagger/*any", func(c *gin.Context) { c.String(http.StatusOK, "docs") })
+	router.GET("/ngos", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+
+	docs := httptest.NewRecorder()
+	router.ServeHTTP(docs, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+	assert.Contains(t, docs.Header().Get("Content-Security-Policy"), "https://unpkg.com")
+	assert.Empty(t, docs.Header().Get("Cache-Control"), "A documentação não deve herdar a política no-store da API")
+
+	api := httptest.NewRecorder()
+	router.ServeHTTP(api, httptest.NewRequest(http.MethodGet, "/ngos", nil))
+	assert.Equal(t, strictContentSecurityPolicy, api.Header().Get("Content-Security-Policy"))
+	assert.NotContains(t, api.Header().Get("Content-Security-Policy"), "unpkg.com")
+	assert.Contains(t, api.Header().Get("Cache-Control"), "no-store")
+	assert.NotEmpty(t, api.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecureHeadersOmitHSTSOutsideProduction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SecureHeadersWithConfig(NewSecureHeadersConfig("development")))
+	router.GET("/ngos", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ngos", nil))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+}
+
+func TestCORSEchoesOnlyAllowedOrigins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORS([]string{"https://levitate.org", "http://localhost:3000/"}))
+	router.GET("/ngos", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+
+	request := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/ngos", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	allowed := request(http.MethodGet, "https://levitate.org")
+	assert.Equal(t, http.StatusOK, allowed.Code)
+	assert.Equal(t, "https://levitate.org", allowed.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", allowed.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Origin", allowed.Header().Get("Vary"))
+
+	assert.Equal(t, "http://localhost:3000", request(http.MethodGet, "http://localhost:3000").Header().Get("Access-Control-Allow-Origin"),
+		"A barra final da configuração não deve impedir a correspondência")
+
+	denied := request(http.MethodGet, "https://malicioso.example")
+	assert.Equal(t, http.StatusOK, denied.Code)
+	assert.Empty(t, denied.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, denied.Header().Get("Access-Control-Allow-Methods"))
+
+	preflight := request(http.MethodOptions, "https://levitate.org")
+	assert.Equal(t, http.StatusNoContent, preflight.Code)
+	assert.Equal(t, "https://levitate.org", preflight.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, preflight.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	deniedPreflight := request(http.MethodOptions, "https://malicioso.example")
+	assert.Equal(t, http.StatusNoContent, deniedPreflight.Code)
+	assert.Empty(t, deniedPreflight.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSWildcardAllowsAnyOriginWithoutCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORS([]string{"*"}))
+	router.GET("/ngos", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+
+	req := httptest.NewRequest(http.MethodGet, "/ngos", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
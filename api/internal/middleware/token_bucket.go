@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+	"trackable-donations/api/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenBucketLimiter limita requisições por IP com um balde de tokens: cada IP começa
+// com o balde cheio, cada requisição consome um token e os tokens são repostos a uma
+// taxa constante. Rajadas ficam limitadas à capacidade e a memória por IP é constante.
+type TokenBucketLimiter struct {
+	sync.Mutex
+	buckets         map[string]*tokenBucket
+	capacity        float64
+	refillPerSecond float64
+	enabled         bool
+	now             func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// tokenBucket guarda os tokens disponíveis de um IP na última atualização
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewTokenBucketLimiter cria um limitador com a capacidade (rajada máxima) e a taxa de
+// reposição informadas. Uma rotina em segundo plano descarta os baldes que já voltaram a
+// ficar cheios, pois equivalem a um IP sem histórico; use Close para encerrá-la.
+func NewTokenBucketLimiter(capacity int, refillPerSecond float64) *TokenBucketLimiter {
+	tb := newTokenBucketLimiter(capacity, refillPerSecond, time.Now)
+
+	ticker := time.NewTicker(tb.cleanupInterval())
+	go func() {
+		defer ticker.Stop()
+		tb.cleanupLoop(ticker.C)
+	}()
+
+	return tb
+}
+
+func newTokenBucketLimiter(capacity int, refillPerSecond float64, now func() time.Time) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		buckets:         make(map[string]*tokenBucket),
+		capacity:        float64(capacity),
+		refillPerSecond: refillPerSecond,
+		enabled:         true,
+		now:             now,
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+}
+
+// cleanupInterval é o tempo para um balde vazio voltar a ficar cheio (no mínimo um segundo)
+func (tb *TokenBucketLimiter) cleanupInterval() time.Duration {
+	interval := time.Duration(tb.capacity / tb.refillPerSecond * float64(time.Second))
+	return max(interval, time.Second)
+}
+
+// refill repõe os tokens do balde conforme o tempo decorrido desde a última atualização
+func (tb *TokenBucketLimiter) refill(bucket *tokenBucket, now time.Time) {
+	elapsed := now.Sub(bucket.updated).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = math.Min(tb.capacity, bucket.tokens+elapsed*tb.refillPerSecond)
+		bucket.updated = now
+	}
+}
+
+// cleanupLoop executa a limpeza a cada tick até o limitador ser fechado
+func (tb *TokenBucketLimiter) cleanupLoop(ticks <-chan time.Time) {
+	defer close(tb.done)
+	for {
+		select {
+		case <-ticks:
+			tb.cleanup()
+		case <-tb.stop:
+			return
+		}
+	}
+}
+
+// cleanup remove os baldes cheios
+func (tb *TokenBucketLimiter) cleanup() {
+	tb.Lock()
+	defer tb.Unlock()
+
+	now := tb.now()
+	for ip, bucket := range tb.buckets {
+		tb.refill(bucket, now)
+		if bucket.tokens >= tb.capacity {
+			delete(tb.buckets, ip)
+		}
+	}
+}
+
+// Stop encerra a rotina de limpeza e aguarda o seu término
+func (tb *TokenBucketLimiter) Stop() {
+	tb.stopOnce.Do(func() { close(tb.stop) })
+	<-tb.done
+}
+
+// Close encerra a rotina de limpeza, permitindo fechar o limitador junto com os demais componentes
+func (tb *TokenBucketLimiter) Close() error {
+	tb.Stop()
+	return nil
+}
+
+// RateLimit retorna um middleware Gin para limitar requisições, com os mesmos headers
+// X-RateLimit-* do RateLimiter
+func (tb *TokenBucketLimiter) RateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tb.Lock()
+		if !tb.enabled {
+			tb.Unlock()
+			c.Next()
+			return
+		}
+
+		ip := c.ClientIP()
+		now := tb.now()
+		bucket, exists := tb.buckets[ip]
+		if !exists {
+			bucket = &tokenBucket{tokens: tb.capacity, updated: now}
+			tb.buckets[ip] = bucket
+		}
+		tb.refill(bucket, now)
+
+		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", int(tb.capacity)))
+
+		if bucket.tokens < 1 {
+			// Momento em que o próximo token estará disponível
+			wait := time.Duration((1 - bucket.tokens) / tb.refillPerSecond * float64(time.Second))
+			tb.Unlock()
+
+			c.Header("X-RateLimit-Remaining", "0")
+			c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", now.Add(wait).Unix()))
+			metrics.RateLimitRejections.WithLabelValues(metricsRoute(c)).Inc()
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Limite de requisições excedido. Tente novamente mais tarde.",
+			})
+			return
+		}
+
+		bucket.tokens--
+		remaining := int(bucket.tokens)
+		tb.Unlock()
+
+		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
+		c.Next()
+	}
+}
+
+// SetEnabled ativa ou desativa o limitador (útil para ambientes de desenvolvimento)
+func (tb *TokenBucketLimiter) SetEnabled(enabled bool) {
+	tb.Lock()
+	defer tb.Unlock()
+	tb.enabled = enabled
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucketLimiterAllowsBurstThenRefills(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tb := newTokenBucketLimiter(3, 1, func() time.Time { return clock })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(tb.RateLimit())
+	router.GET("/ngos", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ngos", nil))
+		return w
+	}
+
+	// A rajada inicial é limitada à capacidade
+	for remaining := 2; remaining >= 0; remaining-- {
+		w := request()
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, fmt.Sprint(remaining), w.Header().Get("X-RateLimit-Remaining"))
+	}
+
+	w := request()
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, fmt.Sprint(clock.Add(time.Second).Unix()), w.Header().Get("X-RateLimit-Reset"))
+
+	// Um token é reposto por segundo
+	clock = clock.Add(time.Second)
+	assert.Equal(t, http.StatusOK, request().Code)
+	assert.Equal(t, http.StatusTooManyRequests, request().Code)
+}
+
+func TestTokenBucketLimiterCleanupDropsFullBuckets(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tb := newTokenBucketLimiter(10, 2, func() time.Time { return clock })
+	tb.buckets["10.0.0.1"] = &tokenBucket{tokens: 0, updated: clock}
+	tb.buckets["10.0.0.2"] = &tokenBucket{tokens: 9, updated: clock}
+
+	// Depois de 1s o segundo balde está cheio; o primeiro ainda não
+	clock = clock.Add(time.Second)
+	ticks := make(chan time.Time)
+	go tb.cleanupLoop(ticks)
+	ticks <- clock
+	tb.Stop()
+
+	require.Len(t, tb.buckets, 1)
+	assert.InDelta(t, 2.0, tb.buckets["10.0.0.1"].tokens, 1e-9)
+	assert.NoError(t, tb.Close(), "Close deve ser idempotente")
+}
@@ -2,59 +2,231 @@ package models
 
 import (
 	"time"
+	"trackable-donations/blockchain-node/core"
 )
 
 // Modelos de dados para PostgreSQL
 
 type Donation struct {
-	ID              uint      `json:"id" gorm:"primaryKey"`
-	Amount          float64   `json:"amount"`
-	DonorID         uint      `json:"donor_id"`
-	NGOID           uint      `json:"ngo_id"`
-	CreatedAt       time.Time `json:"created_at"`
-	Status          string    `json:"status"`
-	TransactionHash string    `json:"transaction_hash,omitempty"`
+	ID              uint              `json:"id" gorm:"primaryKey"`
+	Amount          float64           `json:"amount"`        // Em reais, já convertido quando a doação é em outra moeda
+	Tip             float64           `json:"tip,omitempty"` // Gorjeta para a plataforma (não conta no saldo da ONG), em reais
+	DonorID         uint              `json:"donor_id"`
+	NGOID           uint              `json:"ngo_id"`
+	CreatedAt       time.Time         `json:"created_at"`
+	Status          string            `json:"status"` // pending, completed, refunded ou expired (pendente além de PENDING_DONATION_TTL)
+	TransactionHash string            `json:"transaction_hash,omitempty"`
+	ReminderSentAt  *time.Time        `json:"reminder_sent_at,omitempty"`                // Lembrete de pagamento pendente já enviado
+	Metadata        map[string]string `json:"metadata,omitempty" gorm:"serializer:json"` // Campos livres de parceiros (ex.: ID no CRM)
+	DonorAnonymous  *bool             `json:"donor_anonymous,omitempty"`                 // Oculta o nome do doador no explorador público (vazio = anônimo)
+
+	// Moeda em que o doador pagou (vazio em registros antigos = BRL)
+	Currency       string  `json:"currency,omitempty"`        // Código ISO 4217 (ver SupportedCurrencies)
+	OriginalAmount float64 `json:"original_amount,omitempty"` // Valor na moeda original
+	ExchangeRate   float64 `json:"exchange_rate,omitempty"`   // Reais por unidade da moeda original na criação
+
+	// Estorno (ver DonationService.RefundDonation)
+	RefundTransactionHash string     `json:"refund_transaction_hash,omitempty"` // Bloco com a transação reversa
+	RefundReason          string     `json:"refund_reason,omitempty"`
+	RefundedAt            *time.Time `json:"refunded_at,omitempty"`
+
+	// Importação de campanhas anteriores (ver DonationService.ImportDonations): a doação foi
+	// recebida fora da plataforma e não tem transação na blockchain
+	Imported    bool   `json:"imported,omitempty"`
+	ExternalRef string `json:"external_ref,omitempty"` // Identificador na plataforma de origem
+
+	// CampaignID é a campanha da ONG à qual a doação é atribuída (0 = sem campanha)
+	CampaignID uint `json:"campaign_id,omitempty"`
 }
 
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email" gorm:"uniqueIndex"`
-	CreatedAt time.Time `json:"created_at"`
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Name  string `json:"name"`
+	Email string `json:"email" gorm:"uniqueIndex"`
+	// PublicRecognition indica que o doador aceita aparecer no ranking público de doadores
+	PublicRecognition bool      `json:"public_recognition"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// UserRequest representa os dados de cadastro de um novo doador
+type UserRequest struct {
+	Name              string `json:"name" binding:"required"`
+	Email             string `json:"email" binding:"required"`
+	PublicRecognition bool   `json:"public_recognition"`
 }
 
 // NGO representa uma organização não governamental
 type NGO struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	Name          string    `json:"name"`
-	Description   string    `json:"description"`
-	Category      string    `json:"category"`
-	CNPJ          string    `json:"cnpj"`
-	Email         string    `json:"email"`
-	Phone         string    `json:"phone"`
-	Address       string    `json:"address"`
-	LogoURL       string    `json:"logo_url"`
-	DocumentsIPFS string    `json:"documents_ipfs,omitempty"`
-	BlockchainRef string    `json:"blockchain_ref,omitempty"`
-	ResponsibleID uint      `json:"responsible_id"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Category      string `json:"category"`
+	CNPJ          string `json:"cnpj"`
+	Email         string `json:"email"`
+	Phone         string `json:"phone"`
+	Address       string `json:"address"`
+	State         string `json:"state,omitempty"`  // UF da sede (ex.: SP)
+	Region        string `json:"region,omitempty"` // Região do Brasil derivada da UF (ver StateRegions)
+	LogoURL       string `json:"logo_url"`
+	DocumentsIPFS string `json:"documents_ipfs,omitempty"`
+	BlockchainRef string `json:"blockchain_ref,omitempty"`
+	ResponsibleID uint   `json:"responsible_id"`
+	HideContact   bool   `json:"hide_contact"`          // ONG optou por não divulgar email/telefone aos doadores
+	Status        string `json:"status"`                // active, suspended, merged
+	MergedInto    uint   `json:"merged_into,omitempty"` // ONG canônica quando o registro foi mesclado
+	// SuspensionReason é o motivo informado pelo administrador ao suspender a ONG
+	SuspensionReason string `json:"suspension_reason,omitempty"`
+	// Limites próprios do valor das doações à ONG, em reais (vazio = limites gerais da plataforma)
+	MinDonationAmount *float64 `json:"min_donation_amount,omitempty"`
+	MaxDonationAmount *float64 `json:"max_donation_amount,omitempty"`
+	// APIKeyHash é o hash da chave de API com que a ONG envia gastos (a chave não é armazenada)
+	APIKeyHash string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Status possíveis de uma ONG
+const (
+	NGOActive    = "active"
+	NGOSuspended = "suspended" // Não recebe novas doações, mas continua visível na transparência
+	NGOMerged    = "merged"
+)
+
+// Campaign é uma campanha de arrecadação de uma ONG, à qual as doações podem ser atribuídas
+type Campaign struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	NGOID       uint      `json:"ngo_id" gorm:"index"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Goal        float64   `json:"goal,omitempty"` // Meta de arrecadação em reais (0 = sem meta)
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CampaignRequest representa o cadastro de uma campanha pela ONG
+type CampaignRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description string  `json:"description,omitempty" binding:"max=1000"`
+	Goal        float64 `json:"goal,omitempty" binding:"omitempty,gt=0"`
+}
+
+// NGOMergeRequest representa uma solicitação de mesclagem de ONGs duplicadas
+type NGOMergeRequest struct {
+	CanonicalID uint `json:"canonical_id" binding:"required"`
+	DuplicateID uint `json:"duplicate_id" binding:"required"`
+}
+
+// NGOUpdateRequest representa a atualização do perfil de uma ONG aprovada; campos omitidos
+// não são alterados. O CNPJ é imutável e só é aceito se for igual ao atual.
+type NGOUpdateRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Category    *string `json:"category,omitempty"`
+	Email       *string `json:"email,omitempty"`
+	Phone       *string `json:"phone,omitempty"`
+	Address     *string `json:"address,omitempty"`
+	State       *string `json:"state,omitempty"` // UF da sede; a região é recalculada
+	LogoURL     *string `json:"logo_url,omitempty"`
+	HideContact *bool   `json:"hide_contact,omitempty"`
+	CNPJ        *string `json:"cnpj,omitempty"`
+}
+
+// AdminLoginRequest representa as credenciais de login de um administrador
+type AdminLoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// AdminLoginResponse representa o token de acesso emitido no login do administrador
+type AdminLoginResponse struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // Estrutura para request de doação
 type DonationRequest struct {
-	Amount        float64 `json:"amount" binding:"required,gt=0"`
-	DonorID       uint    `json:"donor_id" binding:"required"`
-	NGOID         uint    `json:"ngo_id" binding:"required"`
-	DonorDocument string  `json:"donor_document,omitempty"` // CPF ou CNPJ do doador (será anonimizado)
+	Amount        float64           `json:"amount" binding:"required,gt=0"`
+	Tip           float64           `json:"tip,omitempty" binding:"omitempty,gte=0"` // Gorjeta opcional para a plataforma
+	DonorID       uint              `json:"donor_id" binding:"required"`
+	NGOID         uint              `json:"ngo_id" binding:"required"`
+	DonorDocument string            `json:"donor_document,omitempty"` // CPF ou CNPJ do doador (será anonimizado)
+	Metadata      map[string]string `json:"metadata,omitempty"`       // Campos livres de parceiros (ex.: ID no CRM)
+	Currency      string            `json:"currency,omitempty"`       // Moeda de Amount e Tip, ISO 4217 (padrão: BRL)
+	// DonorAnonymous oculta o nome do doador no explorador público; omitido, a doação é anônima
+	DonorAnonymous *bool `json:"donor_anonymous,omitempty"`
+	// CampaignID atribui a doação a uma campanha da ONG de NGOID (ver Campaign)
+	CampaignID uint `json:"campaign_id,omitempty"`
+}
+
+// AnonymousDonorName é exibido no lugar do nome do doador nas doações anônimas
+const AnonymousDonorName = "Doador anônimo"
+
+// DefaultCurrency é a moeda da plataforma, em que todos os totais são calculados
+const DefaultCurrency = "BRL"
+
+// SupportedCurrencies são as moedas aceitas nas doações (códigos ISO 4217)
+var SupportedCurrencies = []string{"BRL", "USD", "EUR"}
+
+// RecurringDonation representa uma assinatura de doação recorrente
+type RecurringDonation struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Amount       float64   `json:"amount"`
+	DonorID      uint      `json:"donor_id"`
+	NGOID        uint      `json:"ngo_id"`
+	IntervalDays int       `json:"interval_days"`
+	Status       string    `json:"status"` // active, paused
+	NextRunAt    time.Time `json:"next_run_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Status possíveis de uma doação recorrente
+const (
+	RecurringActive = "active"
+	RecurringPaused = "paused"
+)
+
+// ChainBlock é um bloco da blockchain das doações, persistido para que os hashes
+// registrados nas doações continuem verificáveis depois que a API reinicia
+type ChainBlock struct {
+	ID    uint       `json:"id" gorm:"primaryKey"` // Igual ao índice do bloco
+	Block core.Block `json:"block" gorm:"serializer:json"`
+}
+
+// ChainKey é a chave privada de um titular (doador ou ONG) na blockchain, guardada pela
+// plataforma, que assina as transações em nome dele. A chave nunca é salva em claro.
+type ChainKey struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	Account      string `json:"account" gorm:"uniqueIndex"`
+	EncryptedKey string `json:"-"` // DER (SEC 1) cifrado com utils.EncryptSensitiveData (DATA_ENCRYPTION_KEY)
+}
+
+// Estrutura para request de doação recorrente
+type RecurringDonationRequest struct {
+	Amount       float64 `json:"amount" binding:"required,gt=0"`
+	DonorID      uint    `json:"donor_id" binding:"required"`
+	NGOID        uint    `json:"ngo_id" binding:"required"`
+	IntervalDays int     `json:"interval_days,omitempty" binding:"omitempty,gte=1"` // Padrão: 30 dias
+}
+
+// RefundRequest representa o pedido de estorno de uma doação
+type RefundRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// PaymentWebhookPayload representa a notificação assíncrona do gateway de pagamento
+type PaymentWebhookPayload struct {
+	DonationID uint   `json:"donation_id" binding:"required"`
+	Status     string `json:"status" binding:"required"` // "paid" confirma a doação; demais status são apenas registrados
+	GatewayRef string `json:"gateway_ref"`               // Identificador do pagamento no gateway
 }
 
 // Estrutura para resposta de doação
 type DonationResponse struct {
-	ID              uint   `json:"id"`
-	Status          string `json:"status"`
-	PaymentURL      string `json:"payment_url,omitempty"`
-	TransactionHash string `json:"transaction_hash,omitempty"`
+	ID              uint              `json:"id"`
+	Status          string            `json:"status"`
+	PaymentURL      string            `json:"payment_url,omitempty"`
+	TransactionHash string            `json:"transaction_hash,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
 }
 
 // Mock de Payment Gateway
@@ -77,16 +249,35 @@ type ResourceUsage struct {
 
 // DonationReceipt representa o comprovante de doação
 type DonationReceipt struct {
-	ID              uint      `json:"id"`
+	ID              uint      `json:"id" gorm:"primaryKey"`
 	DonationID      uint      `json:"donation_id"`
 	DonorName       string    `json:"donor_name"`
 	DonorEmail      string    `json:"donor_email"`
 	NGOName         string    `json:"ngo_name"`
 	Amount          float64   `json:"amount"`
+	Tip             float64   `json:"tip,omitempty"`
+	TotalCharged    float64   `json:"total_charged"`
 	Date            time.Time `json:"date"`
 	TransactionHash string    `json:"transaction_hash"`
 	IPFSHash        string    `json:"ipfs_hash"`
 	PdfURL          string    `json:"pdf_url"`
+	Preview         bool      `json:"preview,omitempty" gorm:"-"` // Prévia de uma doação ainda não paga
+}
+
+// PaymentReminder representa o lembrete enviado ao doador de uma doação ainda não paga
+type PaymentReminder struct {
+	DonationID uint    `json:"donation_id"`
+	DonorName  string  `json:"donor_name"`
+	DonorEmail string  `json:"donor_email"`
+	NGOName    string  `json:"ngo_name"`
+	Amount     float64 `json:"amount"`
+	PaymentURL string  `json:"payment_url"`
+}
+
+// PlatformLedger representa o registro das gorjetas destinadas à plataforma
+type PlatformLedger struct {
+	TotalTips float64 `json:"total_tips"`
+	TipsCount int     `json:"tips_count"`
 }
 
 // ImpactMetrics representa as métricas de impacto de doações
@@ -117,33 +308,117 @@ type ExpenseRequest struct {
 	Category    string  `json:"category" binding:"required"`
 }
 
+// PoolExpenseRequest representa um gasto custeado pelo saldo geral da ONG, retirado das
+// doações concluídas mais antigas que ainda têm saldo
+type PoolExpenseRequest struct {
+	NGOID       uint    `json:"ngo_id" binding:"required"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Description string  `json:"description" binding:"required"`
+	Category    string  `json:"category" binding:"required"`
+}
+
+// ExpenseSource é a parte de um gasto retirada de uma doação
+type ExpenseSource struct {
+	DonationID uint    `json:"donation_id"`
+	Amount     float64 `json:"amount"`
+}
+
+// drawnFrom retorna quanto de um gasto foi retirado da doação: o valor integral quando o
+// gasto está vinculado a ela, ou a parte correspondente nas fontes de um gasto do saldo geral
+func drawnFrom(donationID, expenseDonationID uint, amount float64, sources []ExpenseSource) float64 {
+	if len(sources) == 0 {
+		if expenseDonationID == donationID {
+			return amount
+		}
+		return 0
+	}
+	drawn := 0.0
+	for _, source := range sources {
+		if source.DonationID == donationID {
+			drawn += source.Amount
+		}
+	}
+	return drawn
+}
+
 // Expense representa um gasto registrado por uma ONG
 type Expense struct {
-	ID            uint      `json:"id" gorm:"primaryKey"`
-	DonationID    uint      `json:"donation_id"`
-	NGOID         uint      `json:"ngo_id"`
-	Amount        float64   `json:"amount"`
-	Description   string    `json:"description"`
-	Category      string    `json:"category"`
-	ReceiptIPFS   string    `json:"receipt_ipfs,omitempty"`
-	BlockchainRef string    `json:"blockchain_ref,omitempty"`
-	Status        string    `json:"status"` // pendente, aprovado, rejeitado
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	DonationID      uint      `json:"donation_id"`
+	NGOID           uint      `json:"ngo_id"`
+	Amount          float64   `json:"amount"`
+	Description     string    `json:"description"`
+	Category        string    `json:"category"`
+	ReceiptIPFS     string    `json:"receipt_ipfs,omitempty"`
+	BlockchainRef   string    `json:"blockchain_ref,omitempty"`
+	Status          string    `json:"status"`                     // pendente, aprovado, rejeitado
+	RejectionReason string    `json:"rejection_reason,omitempty"` // Motivo informado pelo administrador ao rejeitar
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	// Sources são as doações que custeiam um gasto do saldo geral da ONG (DonationID zero);
+	// vazio nos gastos vinculados a uma única doação
+	Sources []ExpenseSource `json:"sources,omitempty" gorm:"serializer:json"`
+}
+
+// DrawnFrom retorna quanto do gasto foi retirado da doação
+func (e Expense) DrawnFrom(donationID uint) float64 {
+	return drawnFrom(donationID, e.DonationID, e.Amount, e.Sources)
 }
 
 // ExpenseResponse representa a resposta do registro de um gasto
 type ExpenseResponse struct {
-	ID            uint      `json:"id"`
-	DonationID    uint      `json:"donation_id"`
-	NGOID         uint      `json:"ngo_id"`
-	Amount        float64   `json:"amount"`
-	Description   string    `json:"description"`
-	Category      string    `json:"category"`
-	ReceiptIPFS   string    `json:"receipt_ipfs,omitempty"`
-	BlockchainRef string    `json:"blockchain_ref,omitempty"`
-	Status        string    `json:"status"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID              uint      `json:"id"`
+	DonationID      uint      `json:"donation_id"`
+	NGOID           uint      `json:"ngo_id"`
+	Amount          float64   `json:"amount"`
+	Description     string    `json:"description"`
+	Category        string    `json:"category"`
+	ReceiptIPFS     string    `json:"receipt_ipfs,omitempty"`
+	BlockchainRef   string    `json:"blockchain_ref,omitempty"`
+	Status          string    `json:"status"`
+	RejectionReason string    `json:"rejection_reason,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	// Sources são as doações que custeiam um gasto do saldo geral da ONG (ver Expense.Sources)
+	Sources []ExpenseSource `json:"sources,omitempty"`
+}
+
+// DrawnFrom retorna quanto do gasto foi retirado da doação
+func (e ExpenseResponse) DrawnFrom(donationID uint) float64 {
+	return drawnFrom(donationID, e.DonationID, e.Amount, e.Sources)
+}
+
+// PendingExpense é um gasto na fila de revisão dos administradores, com o nome da ONG para
+// exibição; receipt_ipfs traz o comprovante enviado, quando houver
+type PendingExpense struct {
+	ExpenseResponse
+	NGOName string `json:"ngo_name"`
+}
+
+// PendingExpenseListResult representa uma página da fila de gastos pendentes, do mais antigo
+// para o mais recente
+type PendingExpenseListResult struct {
+	Expenses []PendingExpense `json:"expenses"`
+	Total    int              `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// DonationBalance resume quanto de uma doação concluída já foi gasto e quanto ainda está livre
+type DonationBalance struct {
+	DonationID uint    `json:"donation_id"`
+	Total      float64 `json:"total"`
+	Spent      float64 `json:"spent"`     // Gastos aprovados
+	Pending    float64 `json:"pending"`   // Gastos aguardando aprovação
+	Available  float64 `json:"available"` // Total menos os gastos aprovados e pendentes
+}
+
+// DonationContribution representa quanto de uma doação foi usado para custear um gasto
+type DonationContribution struct {
+	DonationID      uint      `json:"donation_id"`
+	DonationAmount  float64   `json:"donation_amount"`
+	AmountDrawn     float64   `json:"amount_drawn"`
+	TransactionHash string    `json:"transaction_hash,omitempty"`
+	DonatedAt       time.Time `json:"donated_at"`
 }
 
 // Enum para categorias de gastos
@@ -157,6 +432,46 @@ var ExpenseCategories = []string{
 	"Outros",
 }
 
+// Enum para status de gastos
+var ExpenseStatuses = []string{
+	"pendente",
+	"aprovado",
+	"rejeitado",
+}
+
+// ExpenseFilter restringe as listagens de gastos; campos vazios não filtram
+type ExpenseFilter struct {
+	Category string // Uma de ExpenseCategories, sem diferenciar maiúsculas de minúsculas
+	Status   string // Um de ExpenseStatuses
+}
+
+// Enum para categorias de atuação das ONGs
+var NGOCategories = []string{
+	"Alimentação",
+	"Saúde",
+	"Educação",
+	"Infraestrutura",
+	"Assistência Social",
+	"Habitação",
+	"Meio Ambiente",
+	"Proteção Animal",
+	"Cultura",
+	"Outros",
+}
+
+// Regiões do Brasil, na ordem usada pelo dashboard
+var BrazilianRegions = []string{"Norte", "Nordeste", "Centro-Oeste", "Sudeste", "Sul"}
+
+// StateRegions associa cada UF à sua região
+var StateRegions = map[string]string{
+	"AC": "Norte", "AP": "Norte", "AM": "Norte", "PA": "Norte", "RO": "Norte", "RR": "Norte", "TO": "Norte",
+	"AL": "Nordeste", "BA": "Nordeste", "CE": "Nordeste", "MA": "Nordeste", "PB": "Nordeste",
+	"PE": "Nordeste", "PI": "Nordeste", "RN": "Nordeste", "SE": "Nordeste",
+	"DF": "Centro-Oeste", "GO": "Centro-Oeste", "MT": "Centro-Oeste", "MS": "Centro-Oeste",
+	"ES": "Sudeste", "MG": "Sudeste", "RJ": "Sudeste", "SP": "Sudeste",
+	"PR": "Sul", "RS": "Sul", "SC": "Sul",
+}
+
 // NGORegistrationRequest representa uma solicitação de registro de ONG
 type NGORegistrationRequest struct {
 	Name          string `json:"name" binding:"required"`
@@ -166,8 +481,11 @@ type NGORegistrationRequest struct {
 	Email         string `json:"email" binding:"required,email"`
 	Phone         string `json:"phone" binding:"required"`
 	Address       string `json:"address" binding:"required"`
+	State         string `json:"state" binding:"required"` // UF da sede (ex.: SP)
 	ResponsibleID uint   `json:"responsible_id" binding:"required"`
 	LogoURL       string `json:"logo_url"`
+	HideContact   bool   `json:"hide_contact"`                         // Não divulgar email/telefone publicamente
+	CallbackURL   string `json:"callback_url" binding:"omitempty,url"` // URL http(s) que recebe os eventos de status (opcional)
 }
 
 // NGORegistrationStatus representa o status de um registro de ONG
@@ -192,16 +510,60 @@ type NGORegistration struct {
 	Email             string                `json:"email"`
 	Phone             string                `json:"phone"`
 	Address           string                `json:"address"`
+	State             string                `json:"state"`
+	Region            string                `json:"region"`
 	ResponsibleID     uint                  `json:"responsible_id"`
 	LogoURL           string                `json:"logo_url,omitempty"`
+	HideContact       bool                  `json:"hide_contact"`
 	DocumentsIPFS     string                `json:"documents_ipfs,omitempty"`
 	BlockchainRef     string                `json:"blockchain_ref,omitempty"`
 	Status            NGORegistrationStatus `json:"status"`
 	AdminComments     string                `json:"admin_comments,omitempty"`
+	CallbackURL       string                `json:"callback_url,omitempty"` // Recebe os eventos de mudança de status (ver NGOStatusEvent)
+	WebhookSecret     string                `json:"-"`                      // Assina os eventos; informado apenas na criação do registro
 	CreatedAt         time.Time             `json:"created_at"`
 	UpdatedAt         time.Time             `json:"updated_at"`
 }
 
+// Tipos dos eventos enviados à CallbackURL do registro de ONG
+const (
+	NGOEventRegistrationApproved = "ngo_registration.approved"
+	NGOEventRegistrationRejected = "ngo_registration.rejected"
+	NGOEventRegistrationReopened = "ngo_registration.reopened"
+)
+
+// NGOStatusEvent é o evento enviado à CallbackURL quando o status do registro de ONG muda
+type NGOStatusEvent struct {
+	ID             string                `json:"id"`
+	Type           string                `json:"type"`
+	RegistrationID uint                  `json:"registration_id"`
+	NGOID          uint                  `json:"ngo_id,omitempty"` // ONG criada na aprovação
+	Status         NGORegistrationStatus `json:"status"`
+	Comments       string                `json:"comments,omitempty"` // Comentários da aprovação ou motivo da rejeição ou da reabertura
+	OccurredAt     time.Time             `json:"occurred_at"`
+}
+
+// WebhookDelivery registra a entrega de um evento de status à CallbackURL de uma ONG
+type WebhookDelivery struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	EventID        string    `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	RegistrationID uint      `json:"registration_id" gorm:"index"`
+	URL            string    `json:"url"`
+	Attempts       int       `json:"attempts"`
+	Delivered      bool      `json:"delivered"`
+	Error          string    `json:"error,omitempty"` // Erro da última tentativa, quando não entregue
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PendingNGORegistration é um registro aguardando ação dos administradores, com o tempo
+// de espera desde a solicitação
+type PendingNGORegistration struct {
+	NGORegistration
+	AgeSeconds int64  `json:"age_seconds"`
+	Age        string `json:"age"` // Ex.: 26h3m0s
+}
+
 // NGODocumentUploadRequest representa uma solicitação de upload de documentos
 type NGODocumentUploadRequest struct {
 	RegistrationID uint   `json:"registration_id" binding:"required"`
@@ -226,53 +588,255 @@ type AuditResult struct {
 	ValidationErrors []string  `json:"validation_errors,omitempty"`
 }
 
+// DonationVerification é o resultado da verificação pública de uma doação: a transação na
+// blockchain e o comprovante no IPFS, conferidos no momento da consulta
+type DonationVerification struct {
+	DonationID      uint      `json:"donation_id"`
+	BlockchainValid bool      `json:"blockchain_valid"`
+	IPFSValid       bool      `json:"ipfs_valid"`
+	BlockIndex      *int      `json:"block_index"` // Bloco da transação; nulo quando não foi encontrada
+	TransactionHash string    `json:"transaction_hash,omitempty"`
+	ReceiptIPFS     string    `json:"receipt_ipfs,omitempty"`
+	VerifiedAt      time.Time `json:"verified_at"`
+	Errors          []string  `json:"errors"`
+}
+
+// AuditAction representa o tipo de ação registrada no log de auditoria
+type AuditAction string
+
+const (
+	AuditActionNGORegistrationCreated AuditAction = "ngo_registration_created"
+	AuditActionCNPJValidated          AuditAction = "cnpj_validated"
+	AuditActionDocumentsUploaded      AuditAction = "documents_uploaded"
+	AuditActionNGOApproved            AuditAction = "ngo_approved"
+	AuditActionNGORejected            AuditAction = "ngo_rejected"
+	AuditActionNGOReopened            AuditAction = "ngo_reopened"
+	AuditActionNGOMerged              AuditAction = "ngo_merged"
+	AuditActionNGOSuspended           AuditAction = "ngo_suspended"
+	AuditActionNGOUpdated             AuditAction = "ngo_updated"
+	AuditActionExpenseApproved        AuditAction = "expense_approved"
+	AuditActionExpenseRejected        AuditAction = "expense_rejected"
+	AuditActionAuditPerformed         AuditAction = "audit_performed"
+	AuditActionDonationRefunded       AuditAction = "donation_refunded"
+	AuditActionDonationsImported      AuditAction = "donations_imported"
+	AuditActionNGOAPIKeyIssued        AuditAction = "ngo_api_key_issued"
+)
+
+// AuditActions lista todas as ações de auditoria conhecidas
+var AuditActions = []AuditAction{
+	AuditActionNGORegistrationCreated,
+	AuditActionCNPJValidated,
+	AuditActionDocumentsUploaded,
+	AuditActionNGOApproved,
+	AuditActionNGORejected,
+	AuditActionNGOReopened,
+	AuditActionNGOMerged,
+	AuditActionNGOSuspended,
+	AuditActionExpenseApproved,
+	AuditActionExpenseRejected,
+	AuditActionAuditPerformed,
+	AuditActionDonationRefunded,
+	AuditActionDonationsImported,
+	AuditActionNGOAPIKeyIssued,
+}
+
+// IsValid verifica se a ação pertence ao conjunto de ações conhecidas
+func (a AuditAction) IsValid() bool {
+	for _, action := range AuditActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
 // AuditLog representa um registro de auditoria
 type AuditLog struct {
-	ID               uint      `json:"id" gorm:"primaryKey"`
-	AdminID          uint      `json:"admin_id"`
-	Action           string    `json:"action"`
-	EntityType       string    `json:"entity_type"`
-	EntityID         uint      `json:"entity_id"`
-	PreviousState    string    `json:"previous_state,omitempty"`
-	NewState         string    `json:"new_state,omitempty"`
-	Comments         string    `json:"comments,omitempty"`
-	BlockchainValid  bool      `json:"blockchain_valid,omitempty"`
-	IPFSValid        bool      `json:"ipfs_valid,omitempty"`
-	ValidationErrors []string  `json:"validation_errors,omitempty"`
-	CreatedAt        time.Time `json:"created_at"`
+	ID               uint        `json:"id" gorm:"primaryKey"`
+	AdminID          uint        `json:"admin_id"`
+	Action           AuditAction `json:"action"`
+	EntityType       string      `json:"entity_type"`
+	EntityID         uint        `json:"entity_id"`
+	PreviousState    string      `json:"previous_state,omitempty"`
+	NewState         string      `json:"new_state,omitempty"`
+	Comments         string      `json:"comments,omitempty"`
+	BlockchainValid  bool        `json:"blockchain_valid,omitempty"`
+	IPFSValid        bool        `json:"ipfs_valid,omitempty"`
+	ValidationErrors []string    `json:"validation_errors,omitempty" gorm:"serializer:json"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+// AuditLogQuery representa os filtros da consulta ao log de auditoria; campos vazios não filtram
+type AuditLogQuery struct {
+	EntityType string
+	EntityID   uint
+	Action     AuditAction
+	AdminID    uint
+	StartDate  time.Time // Inclusivo
+	EndDate    time.Time // Inclusivo
+	Page       int
+	PageSize   int
+}
+
+// AuditLogResult representa uma página de logs de auditoria, do mais recente ao mais antigo
+type AuditLogResult struct {
+	Logs     []AuditLog `json:"logs"`
+	Total    int        `json:"total"`
+	Page     int        `json:"page"`
+	PageSize int        `json:"page_size"`
+}
+
+// NGORegistrationQuery representa os filtros, a ordenação e a paginação da listagem de
+// registros de ONGs; campos vazios não filtram
+type NGORegistrationQuery struct {
+	Status   NGORegistrationStatus
+	CNPJ     string
+	Sort     string // Ver RegistrationSort* (padrão: date_desc)
+	Page     int
+	PageSize int
+}
+
+// Ordenações aceitas na listagem de registros de ONGs, pela data da solicitação
+const (
+	RegistrationSortDateDesc = "date_desc" // Mais recentes primeiro
+	RegistrationSortDateAsc  = "date_asc"
+)
+
+// NGORegistrationListResult representa uma página da listagem de registros de ONGs
+type NGORegistrationListResult struct {
+	Registrations []NGORegistration `json:"registrations"`
+	Total         int               `json:"total"`
+	Page          int               `json:"page"`
+	PageSize      int               `json:"page_size"`
+}
+
+// DonationImportRow é uma doação histórica a importar de uma campanha feita em outra plataforma
+type DonationImportRow struct {
+	DonorEmail  string  `json:"donor_email"`
+	NGOID       uint    `json:"ngo_id"`
+	Amount      float64 `json:"amount"`       // Em reais
+	Date        string  `json:"date"`         // AAAA-MM-DD ou RFC 3339
+	ExternalRef string  `json:"external_ref"` // Identificador na plataforma de origem (opcional)
+}
+
+// Situação de cada linha de uma importação de doações
+const (
+	ImportRowImported = "imported" // Doação criada
+	ImportRowInvalid  = "invalid"  // Linha com erro, não importada
+	ImportRowSkipped  = "skipped"  // Linha válida, não importada porque a importação foi rejeitada
+)
+
+// DonationImportRowResult é o resultado de uma linha da importação (Row começa em 1)
+type DonationImportRowResult struct {
+	Row        int    `json:"row"`
+	Status     string `json:"status"`
+	DonationID uint   `json:"donation_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DonationImportResult resume uma importação de doações, linha a linha
+type DonationImportResult struct {
+	Imported int                       `json:"imported"`
+	Invalid  int                       `json:"invalid"`
+	Rows     []DonationImportRowResult `json:"rows"`
+}
+
+// DonationFilter representa os filtros da listagem de doações dos administradores; campos
+// vazios (ou zero) não filtram. Ao contrário do explorador, inclui doações de qualquer status.
+type DonationFilter struct {
+	Status    string
+	NGOID     uint
+	DonorID   uint
+	StartDate time.Time // Inclusivo
+	EndDate   time.Time // Inclusivo
+	MinAmount float64
+	MaxAmount float64
+	Page      int
+	PageSize  int
+}
+
+// DonationListResult representa uma página da listagem de doações, da mais recente à mais antiga
+type DonationListResult struct {
+	Donations []Donation `json:"donations"`
+	Total     int        `json:"total"`
+	Page      int        `json:"page"`
+	PageSize  int        `json:"page_size"`
 }
 
 // TransactionExplorerQuery representa uma consulta para o explorador de transações
 type TransactionExplorerQuery struct {
-	TransactionHash string    `json:"transaction_hash,omitempty"`
-	NGOID           uint      `json:"ngo_id,omitempty"`
-	StartDate       time.Time `json:"start_date,omitempty"`
-	EndDate         time.Time `json:"end_date,omitempty"`
-	Page            int       `json:"page,omitempty"`
-	PageSize        int       `json:"page_size,omitempty"`
+	TransactionHash string            `json:"transaction_hash,omitempty"`
+	NGOID           uint              `json:"ngo_id,omitempty"`
+	CampaignID      uint              `json:"campaign_id,omitempty"`
+	StartDate       time.Time         `json:"start_date,omitempty"`
+	EndDate         time.Time         `json:"end_date,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`   // Todos os pares chave/valor devem coincidir
+	MinAmount       float64           `json:"min_amount,omitempty"` // Zero = sem limite inferior
+	MaxAmount       float64           `json:"max_amount,omitempty"` // Zero = sem limite superior
+	SortBy          string            `json:"sort_by,omitempty"`    // Ver ExplorerSort* (padrão: date_desc)
+	Status          string            `json:"status,omitempty"`     // Ver ExplorerStatus* (padrão: completed)
+	Page            int               `json:"page,omitempty"`
+	PageSize        int               `json:"page_size,omitempty"`
+	// After é o cursor (next_cursor de uma página anterior); informado, substitui Page e
+	// retorna as doações seguintes à última vista. Só vale para as ordenações por data.
+	After string `json:"after,omitempty"`
 }
 
+// Ordenações aceitas pela busca do explorador de transações
+const (
+	ExplorerSortDateAsc    = "date_asc"
+	ExplorerSortDateDesc   = "date_desc"
+	ExplorerSortAmountAsc  = "amount_asc"
+	ExplorerSortAmountDesc = "amount_desc"
+)
+
+// Status aceitos pela busca do explorador; doações pendentes nunca são públicas
+const (
+	ExplorerStatusCompleted = "completed"
+	ExplorerStatusRefunded  = "refunded"
+	ExplorerStatusAll       = "all" // Confirmadas e estornadas
+)
+
 // TransactionExplorerResult representa o resultado de uma busca no explorador de transações
 type TransactionExplorerResult struct {
 	Donations []DonationDetails `json:"donations"`
 	Total     int               `json:"total"`
-	Page      int               `json:"page"`
+	Page      int               `json:"page"` // Zero na paginação por cursor
 	PageSize  int               `json:"page_size"`
+	// NextCursor leva à próxima página pelo parâmetro after (vazio na última página ou nas
+	// ordenações por valor)
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // DonationDetails representa os detalhes de uma doação para o explorador
 type DonationDetails struct {
-	ID              uint      `json:"id"`
-	Amount          float64   `json:"amount"`
-	DonorName       string    `json:"donor_name"`
-	NGOName         string    `json:"ngo_name"`
-	NGOCategory     string    `json:"ngo_category"`
-	Date            time.Time `json:"date"`
-	Status          string    `json:"status"`
-	TransactionHash string    `json:"transaction_hash,omitempty"`
-	HasReceipt      bool      `json:"has_receipt"`
-	HasExpenses     bool      `json:"has_expenses"`
-	ExpensesCount   int       `json:"expenses_count,omitempty"`
+	ID              uint              `json:"id"`
+	Amount          float64           `json:"amount"`          // Em reais
+	Currency        string            `json:"currency"`        // Moeda em que o doador pagou
+	OriginalAmount  float64           `json:"original_amount"` // Valor na moeda original
+	DonorName       string            `json:"donor_name"`
+	NGOName         string            `json:"ngo_name"`
+	NGOCategory     string            `json:"ngo_category"`
+	Date            time.Time         `json:"date"`
+	Status          string            `json:"status"`
+	TransactionHash string            `json:"transaction_hash,omitempty"`
+	HasReceipt      bool              `json:"has_receipt"`
+	HasExpenses     bool              `json:"has_expenses"`
+	ExpensesCount   int               `json:"expenses_count,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"` // Apenas chaves liberadas para exibição pública
+	CampaignID      uint              `json:"campaign_id,omitempty"`
+}
+
+// DonationTrace reúne o caminho completo de uma doação no explorador: o comprovante, os usos
+// dos recursos e os gastos custeados por ela, com as referências no IPFS e na blockchain
+type DonationTrace struct {
+	Donation       DonationDetails   `json:"donation"`
+	Receipt        *DonationReceipt  `json:"receipt,omitempty"`
+	ResourceUsages []ResourceUsage   `json:"resource_usages"`
+	Expenses       []ExpenseResponse `json:"expenses"`
+	// TotalSpent soma os gastos aprovados e pendentes (os rejeitados aparecem, mas não contam)
+	TotalSpent       float64 `json:"total_spent"`
+	RemainingBalance float64 `json:"remaining_balance"`
 }
 
 // GlobalDashboardData representa os dados para o dashboard global
@@ -296,9 +860,30 @@ type CategorySummary struct {
 	Percentage  float64 `json:"percentage"`
 }
 
+// CategoryTimeSeries é a série temporal das doações concluídas para as ONGs de uma categoria
+type CategoryTimeSeries struct {
+	Category string             `json:"category"`
+	Points   []TimeSeriesBucket `json:"points"`
+}
+
+// TimeSeriesBucket soma as doações de um período da série; períodos sem doações aparecem zerados
+type TimeSeriesBucket struct {
+	Period string  `json:"period"` // Primeiro dia do período (AAAA-MM-DD); semanas começam na segunda-feira
+	Total  float64 `json:"total"`
+	Count  int     `json:"count"`
+}
+
+// Granularidades aceitas nas séries temporais do dashboard
+const (
+	GranularityDaily   = "daily"
+	GranularityWeekly  = "weekly"
+	GranularityMonthly = "monthly"
+)
+
 // MonthlyDonationData representa dados de doações por mês
 type MonthlyDonationData struct {
-	Month       string  `json:"month"`
+	Month       string  `json:"month"`        // Nome do mês em português, para exibição
+	MonthNumber int     `json:"month_number"` // 1 (janeiro) a 12 (dezembro), usado na ordenação
 	Year        int     `json:"year"`
 	TotalAmount float64 `json:"total_amount"`
 	Count       int     `json:"count"`
@@ -313,6 +898,59 @@ type NGODonationSummary struct {
 	Count       int     `json:"count"`
 }
 
+// CampaignDonation é uma doação concluída exibida na página pública de uma campanha
+type CampaignDonation struct {
+	ID              uint      `json:"id"`
+	Amount          float64   `json:"amount"`
+	DonorName       string    `json:"donor_name"` // AnonymousDonorName, salvo quando o doador optou por aparecer
+	Date            time.Time `json:"date"`
+	TransactionHash string    `json:"transaction_hash,omitempty"`
+}
+
+// CampaignProgress é o andamento da arrecadação de uma campanha
+type CampaignProgress struct {
+	Raised         float64 `json:"raised"`
+	DonationsCount int     `json:"donations_count"`
+	Goal           float64 `json:"goal,omitempty"`
+	Percentage     float64 `json:"percentage,omitempty"` // Fração da meta já arrecadada, em percentual
+}
+
+// CampaignDonationsResult é uma página das doações de uma campanha, com o andamento da arrecadação
+type CampaignDonationsResult struct {
+	Campaign  Campaign           `json:"campaign"`
+	Progress  CampaignProgress   `json:"progress"`
+	Donations []CampaignDonation `json:"donations"`
+	Total     int                `json:"total"`
+	Page      int                `json:"page"`
+	PageSize  int                `json:"page_size"`
+}
+
+// CampaignSummary soma as doações concluídas atribuídas a uma campanha
+type CampaignSummary struct {
+	CampaignID   uint    `json:"campaign_id"`
+	CampaignName string  `json:"campaign_name"`
+	TotalAmount  float64 `json:"total_amount"`
+	Count        int     `json:"count"`
+}
+
+// NGOCampaignDashboard reúne os totais das campanhas de uma ONG e o das doações concluídas
+// sem campanha
+type NGOCampaignDashboard struct {
+	NGOID              uint              `json:"ngo_id"`
+	Campaigns          []CampaignSummary `json:"campaigns"`
+	UnattributedAmount float64           `json:"unattributed_amount"`
+	UnattributedCount  int               `json:"unattributed_count"`
+}
+
+// TopDonor representa um doador no ranking público, com o total das suas doações concluídas
+type TopDonor struct {
+	DonorID         uint      `json:"donor_id"`
+	DonorName       string    `json:"donor_name"`
+	TotalAmount     float64   `json:"total_amount"`
+	Count           int       `json:"count"`
+	FirstDonationAt time.Time `json:"first_donation_at"`
+}
+
 // GeographicalDonationData representa dados de doações por região geográfica
 type GeographicalDonationData struct {
 	Region      string  `json:"region"`
@@ -331,3 +969,31 @@ type GlobalImpactMetrics struct {
 	HousesBuilt       int `json:"houses_built"`
 	EmergenciesServed int `json:"emergencies_served"`
 }
+
+// RetentionMetrics representa métricas de retenção de doadores
+type RetentionMetrics struct {
+	TotalDonors                       int     `json:"total_donors"`
+	OneTimeDonors                     int     `json:"one_time_donors"`
+	ReturningDonors                   int     `json:"returning_donors"`
+	ConsecutiveMonthDonors            int     `json:"consecutive_month_donors"`
+	RepeatDonorRate                   float64 `json:"repeat_donor_rate"`
+	AverageDonationsPerReturningDonor float64 `json:"average_donations_per_returning_donor"`
+}
+
+// CategoryUsage representa uma categoria efetivamente em uso e quantos registros a utilizam
+type CategoryUsage struct {
+	Category      string `json:"category"`
+	NGOsCount     int    `json:"ngos_count"`
+	ExpensesCount int    `json:"expenses_count"`
+}
+
+// ProjectedImpact representa a projeção de 12 meses do impacto de um doador
+type ProjectedImpact struct {
+	DonorID              uint    `json:"donor_id"`
+	Basis                string  `json:"basis"` // recurring, historical, none
+	MonthlyAverage       float64 `json:"monthly_average"`
+	ProjectedAnnualTotal float64 `json:"projected_annual_total"`
+	PeopleHelped         int     `json:"people_helped"`
+	MealsProvided        int     `json:"meals_provided"`
+	MedicinesProvided    int     `json:"medicines_provided"`
+}
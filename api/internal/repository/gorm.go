@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"trackable-donations/api/internal/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open conecta ao PostgreSQL (ex.: postgres://user:password@db:5432/trackable_donations)
+// e cria ou atualiza as tabelas de todas as entidades
+func Open(databaseURL string) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{Logger: logger.Default.LogMode(logger.Warn)})
+	if err != nil {
+		return nil, fmt.Errorf("falha ao conectar ao banco de dados: %w", err)
+	}
+	if err := db.AutoMigrate(Models()...); err != nil {
+		return nil, fmt.Errorf("falha na migração do banco de dados: %w", err)
+	}
+	return db, nil
+}
+
+// NewGormStore cria os repositórios sobre a conexão GORM informada
+func NewGormStore(db *gorm.DB) *Store {
+	return &Store{
+		Donations:          gormRepository[models.Donation]{db},
+		NGOs:               gormRepository[models.NGO]{db},
+		Users:              gormRepository[models.User]{db},
+		Expenses:           gormRepository[models.Expense]{db},
+		ResourceUsages:     gormRepository[models.ResourceUsage]{db},
+		Receipts:           gormRepository[models.DonationReceipt]{db},
+		NGORegistrations:   gormRepository[models.NGORegistration]{db},
+		AuditLogs:          gormRepository[models.AuditLog]{db},
+		WebhookDeliveries:  gormRepository[models.WebhookDelivery]{db},
+		Campaigns:          gormRepository[models.Campaign]{db},
+		RecurringDonations: gormRepository[models.RecurringDonation]{db},
+		ChainBlocks:        gormRepository[models.ChainBlock]{db},
+		ChainKeys:          gormRepository[models.ChainKey]{db},
+		close: func() error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		},
+		ping: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+		transaction: func(fn func(tx *Store) error) error {
+			return db.Transaction(func(tx *gorm.DB) error {
+				return fn(NewGormStore(tx))
+			})
+		},
+	}
+}
+
+// gormRepository implementa Repository com GORM; os IDs vêm do auto-incremento do banco
+type gormRepository[T any] struct {
+	db *gorm.DB
+}
+
+func (r gormRepository[T]) Create(entity *T) error {
+	return r.db.Create(entity).Error
+}
+
+func (r gormRepository[T]) Save(entity *T) error {
+	return r.db.Save(entity).Error
+}
+
+func (r gormRepository[T]) FindAll() ([]T, error) {
+	var entities []T
+	err := r.db.Order("id").Find(&entities).Error
+	return entities, err
+}
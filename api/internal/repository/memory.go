@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"trackable-donations/api/internal/models"
+)
+
+// NewMemoryStore cria repositórios em memória, usados em desenvolvimento (sem DATABASE_URL)
+// e nos testes. Os dados se perdem quando o processo termina.
+func NewMemoryStore() *Store {
+	store := &Store{
+		Donations:          newMemoryRepository[models.Donation](),
+		NGOs:               newMemoryRepository[models.NGO](),
+		Users:              newMemoryRepository[models.User](),
+		Expenses:           newMemoryRepository[models.Expense](),
+		ResourceUsages:     newMemoryRepository[models.ResourceUsage](),
+		Receipts:           newMemoryRepository[models.DonationReceipt](),
+		NGORegistrations:   newMemoryRepository[models.NGORegistration](),
+		AuditLogs:          newMemoryRepository[models.AuditLog](),
+		WebhookDeliveries:  newMemoryRepository[models.WebhookDelivery](),
+		Campaigns:          newMemoryRepository[models.Campaign](),
+		RecurringDonations: newMemoryRepository[models.RecurringDonation](),
+		ChainBlocks:        newMemoryRepository[models.ChainBlock](),
+		ChainKeys:          newMemoryRepository[models.ChainKey](),
+	}
+	store.transaction = func(fn func(tx *Store) error) error {
+		return memoryTransaction(store, fn)
+	}
+	return store
+}
+
+// memoryTransaction executa fn sobre repositórios que registram as gravações feitas e, se
+// fn falhar, as desfaz da mais recente para a mais antiga
+func memoryTransaction(store *Store, fn func(tx *Store) error) error {
+	undo := &undoLog{}
+	tx := &Store{
+		Donations:          journaled(store.Donations, undo),
+		NGOs:               journaled(store.NGOs, undo),
+		Users:              journaled(store.Users, undo),
+		Expenses:           journaled(store.Expenses, undo),
+		ResourceUsages:     journaled(store.ResourceUsages, undo),
+		Receipts:           journaled(store.Receipts, undo),
+		NGORegistrations:   journaled(store.NGORegistrations, undo),
+		AuditLogs:          journaled(store.AuditLogs, undo),
+		WebhookDeliveries:  journaled(store.WebhookDeliveries, undo),
+		Campaigns:          journaled(store.Campaigns, undo),
+		RecurringDonations: journaled(store.RecurringDonations, undo),
+		ChainBlocks:        journaled(store.ChainBlocks, undo),
+		ChainKeys:          journaled(store.ChainKeys, undo),
+	}
+
+	if err := fn(tx); err != nil {
+		undo.rollback()
+		return err
+	}
+	return nil
+}
+
+// undoLog guarda as operações que desfazem as gravações de uma transação em memória
+type undoLog struct {
+	mu      sync.Mutex
+	actions []func()
+}
+
+func (l *undoLog) add(action func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.actions = append(l.actions, action)
+}
+
+func (l *undoLog) rollback() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := len(l.actions) - 1; i >= 0; i-- {
+		l.actions[i]()
+	}
+	l.actions = nil
+}
+
+// journalRepository grava no repositório em memória registrando como desfazer cada gravação
+type journalRepository[T any] struct {
+	repo *memoryRepository[T]
+	undo *undoLog
+}
+
+// journaled envolve o repositório em memória para uso em uma transação. Outros repositórios
+// (ex.: substitutos usados nos testes) são usados diretamente, sem desfazer as gravações.
+func journaled[T any](repo Repository[T], undo *undoLog) Repository[T] {
+	memory, ok := repo.(*memoryRepository[T])
+	if !ok {
+		return repo
+	}
+	return &journalRepository[T]{repo: memory, undo: undo}
+}
+
+func (r *journalRepository[T]) Create(entity *T) error {
+	if err := r.repo.Create(entity); err != nil {
+		return err
+	}
+	id := idField(entity).Uint()
+	r.undo.add(func() { r.repo.restore(id, nil) })
+	return nil
+}
+
+func (r *journalRepository[T]) Save(entity *T) error {
+	id := idField(entity).Uint()
+	previous, existed := r.repo.get(id)
+	if err := r.repo.Save(entity); err != nil {
+		return err
+	}
+	if id == 0 {
+		// Sem ID, Save cria a entidade
+		id = idField(entity).Uint()
+	}
+	r.undo.add(func() {
+		if existed {
+			r.repo.restore(id, &previous)
+		} else {
+			r.repo.restore(id, nil)
+		}
+	})
+	return nil
+}
+
+func (r *journalRepository[T]) FindAll() ([]T, error) {
+	return r.repo.FindAll()
+}
+
+// memoryRepository imita o auto-incremento do banco, atribuindo IDs sequenciais ao campo ID
+type memoryRepository[T any] struct {
+	mu       sync.Mutex
+	lastID   uint64
+	entities map[uint64]T
+}
+
+func newMemoryRepository[T any]() *memoryRepository[T] {
+	return &memoryRepository[T]{entities: map[uint64]T{}}
+}
+
+func (r *memoryRepository[T]) Create(entity *T) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idField(entity)
+	if id.Uint() == 0 {
+		r.lastID++
+		id.SetUint(r.lastID)
+	} else if _, exists := r.entities[id.Uint()]; exists {
+		return fmt.Errorf("registro %d já existe", id.Uint())
+	} else if id.Uint() > r.lastID {
+		r.lastID = id.Uint()
+	}
+
+	r.entities[id.Uint()] = *entity
+	return nil
+}
+
+func (r *memoryRepository[T]) Save(entity *T) error {
+	if idField(entity).Uint() == 0 {
+		return r.Create(entity)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := idField(entity).Uint()
+	if id > r.lastID {
+		r.lastID = id
+	}
+	r.entities[id] = *entity
+	return nil
+}
+
+func (r *memoryRepository[T]) FindAll() ([]T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entities := make([]T, 0, len(r.entities))
+	for id := uint64(1); id <= r.lastID; id++ {
+		if entity, ok := r.entities[id]; ok {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, nil
+}
+
+// get retorna a entidade com o ID informado, se existir
+func (r *memoryRepository[T]) get(id uint64) (T, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entity, ok := r.entities[id]
+	return entity, ok
+}
+
+// restore volta a entidade ao valor anterior, removendo-a quando ele é nil. A sequência de
+// IDs não volta atrás, como o auto-incremento do banco em uma transação desfeita.
+func (r *memoryRepository[T]) restore(id uint64, previous *T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if previous == nil {
+		delete(r.entities, id)
+		return
+	}
+	r.entities[id] = *previous
+}
+
+// idField retorna o campo ID (uint) da entidade
+func idField[T any](entity *T) reflect.Value {
+	return reflect.ValueOf(entity).Elem().FieldByName("ID")
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepositoryAssignsSequentialIDs(t *testing.T) {
+	repo := newMemoryRepository[models.NGO]()
+
+	first := models.NGO{Name: "Primeira"}
+	second := models.NGO{Name: "Segunda"}
+	require.NoError(t, repo.Create(&first))
+	require.NoError(t, repo.Create(&second))
+	assert.Equal(t, uint(1), first.ID)
+	assert.Equal(t, uint(2), second.ID)
+
+	// IDs explícitos são aceitos e a sequência continua a partir deles
+	explicit := models.NGO{ID: 10, Name: "Importada"}
+	require.NoError(t, repo.Create(&explicit))
+	assert.Error(t, repo.Create(&models.NGO{ID: 10}), "IDs repetidos devem ser rejeitados")
+
+	next := models.NGO{Name: "Próxima"}
+	require.NoError(t, repo.Create(&next))
+	assert.Equal(t, uint(11), next.ID)
+}
+
+func TestMemoryRepositorySaveUpdatesAndKeepsOrder(t *testing.T) {
+	repo := newMemoryRepository[models.Expense]()
+	for _, description := range []string{"Aluguel", "Alimentos", "Transporte"} {
+		require.NoError(t, repo.Create(&models.Expense{Description: description, Status: "pendente"}))
+	}
+
+	updated := models.Expense{ID: 2, Description: "Alimentos", Status: "aprovado"}
+	require.NoError(t, repo.Save(&updated))
+
+	expenses, err := repo.FindAll()
+	require.NoError(t, err)
+	require.Len(t, expenses, 3)
+	assert.Equal(t, []uint{1, 2, 3}, []uint{expenses[0].ID, expenses[1].ID, expenses[2].ID})
+	assert.Equal(t, "aprovado", expenses[1].Status)
+}
+
+func TestMemoryTransactionRollsBackOnError(t *testing.T) {
+	store := NewMemoryStore()
+	kept := models.User{Name: "Ana"}
+	require.NoError(t, store.Users.Create(&kept))
+
+	failure := errors.New("falha")
+	err := store.Transaction(func(tx *Store) error {
+		require.NoError(t, tx.Users.Create(&models.User{Name: "Bruno"}))
+		renamed := kept
+		renamed.Name = "Ana Souza"
+		require.NoError(t, tx.Users.Save(&renamed))
+		require.NoError(t, tx.Donations.Create(&models.Donation{Amount: 10}))
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	users, err := store.Users.FindAll()
+	require.NoError(t, err)
+	assert.Equal(t, []models.User{kept}, users, "A criação e a alteração devem ser desfeitas")
+	donations, err := store.Donations.FindAll()
+	require.NoError(t, err)
+	assert.Empty(t, donations)
+
+	require.NoError(t, store.Transaction(func(tx *Store) error {
+		return tx.Users.Create(&models.User{Name: "Carla"})
+	}))
+	users, err = store.Users.FindAll()
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"trackable-donations/api/internal/models"
+)
+
+// Repository persiste as entidades de um tipo. Create atribui à entidade o ID gerado
+// pelo armazenamento (auto-incremento do banco).
+type Repository[T any] interface {
+	Create(entity *T) error
+	Save(entity *T) error
+	FindAll() ([]T, error)
+}
+
+// Store reúne os repositórios de todas as entidades persistidas
+type Store struct {
+	Donations          Repository[models.Donation]
+	NGOs               Repository[models.NGO]
+	Users              Repository[models.User]
+	Expenses           Repository[models.Expense]
+	ResourceUsages     Repository[models.ResourceUsage]
+	Receipts           Repository[models.DonationReceipt]
+	NGORegistrations   Repository[models.NGORegistration]
+	AuditLogs          Repository[models.AuditLog]
+	WebhookDeliveries  Repository[models.WebhookDelivery]
+	Campaigns          Repository[models.Campaign]
+	RecurringDonations Repository[models.RecurringDonation]
+	ChainBlocks        Repository[models.ChainBlock]
+	ChainKeys          Repository[models.ChainKey]
+
+	// close libera a conexão com o banco, quando houver
+	close func() error
+	// ping verifica a conexão com o banco, quando houver
+	ping func(ctx context.Context) error
+	// transaction executa fn em uma transação (ver Transaction)
+	transaction func(fn func(tx *Store) error) error
+}
+
+// Transaction executa fn sobre repositórios transacionais: se fn retornar erro, nada do que
+// foi gravado por meio de tx permanece. Gravações feitas fora de tx não fazem parte dela.
+func (s *Store) Transaction(fn func(tx *Store) error) error {
+	if s.transaction == nil {
+		return fn(s)
+	}
+	return s.transaction(fn)
+}
+
+// Ping verifica se o armazenamento está acessível; em memória, sempre está
+func (s *Store) Ping(ctx context.Context) error {
+	if s.ping == nil {
+		return nil
+	}
+	return s.ping(ctx)
+}
+
+// Close encerra a conexão com o armazenamento
+func (s *Store) Close() error {
+	if s.close == nil {
+		return nil
+	}
+	return s.close()
+}
+
+// Models retorna as entidades persistidas, na ordem da migração
+func Models() []any {
+	return []any{
+		&models.User{},
+		&models.NGO{},
+		&models.Donation{},
+		&models.DonationReceipt{},
+		&models.ResourceUsage{},
+		&models.Expense{},
+		&models.NGORegistration{},
+		&models.AuditLog{},
+		&models.WebhookDelivery{},
+		&models.Campaign{},
+		&models.RecurringDonation{},
+		&models.ChainBlock{},
+		&models.ChainKey{},
+	}
+}
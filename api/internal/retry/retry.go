@@ -0,0 +1,64 @@
+// Package retry repete chamadas a serviços externos (IPFS, gateway de pagamento, e-mail)
+// que podem falhar de forma transitória
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// Do executa fn até que ela tenha sucesso ou se esgotem as tentativas. Entre uma tentativa
+// e outra espera um intervalo que dobra a cada falha a partir de baseDelay, com uma variação
+// aleatória para que vários clientes não repitam as chamadas ao mesmo tempo. Retorna o erro
+// da última tentativa.
+func Do(fn func() error, attempts int, baseDelay time.Duration) error {
+	return DoContext(context.Background(), fn, attempts, baseDelay)
+}
+
+// DoContext é como Do, mas deixa de tentar quando ctx é cancelado ou atinge o prazo; nesse
+// caso o erro retornado inclui tanto o da última tentativa quanto o do contexto
+func DoContext(ctx context.Context, fn func() error, attempts int, baseDelay time.Duration) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return errors.Join(err, ctxErr)
+		}
+
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt == attempts-1 {
+			break
+		}
+
+		timer := time.NewTimer(backoff(baseDelay, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+// backoff retorna a espera após a falha da tentativa informada (começando em 0): um valor
+// aleatório entre metade e o total de baseDelay·2^attempt
+func backoff(baseDelay time.Duration, attempt int) time.Duration {
+	if baseDelay <= 0 {
+		return 0
+	}
+	delay := baseDelay << attempt
+	if delay <= 0 {
+		// Estouro do deslocamento em tentativas muito altas
+		delay = baseDelay
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
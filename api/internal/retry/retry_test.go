@@ -0,0 +1,77 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTransient = errors.New("serviço indisponível")
+
+func TestDoRetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	err := Do(func() error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	}, 5, time.Millisecond)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDoReturnsLastErrorWhenAttemptsRunOut(t *testing.T) {
+	calls := 0
+	err := Do(func() error {
+		calls++
+		return errTransient
+	}, 3, time.Millisecond)
+
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 3, calls)
+
+	// Zero tentativas ainda executa a chamada uma vez
+	calls = 0
+	assert.ErrorIs(t, Do(func() error { calls++; return errTransient }, 0, time.Millisecond), errTransient)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDoContextStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+	err := DoContext(ctx, func() error {
+		calls++
+		return errTransient
+	}, 10, time.Second)
+
+	assert.ErrorIs(t, err, errTransient)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, calls)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Um contexto já cancelado não chega a executar a chamada
+	calls = 0
+	err = DoContext(ctx, func() error { calls++; return nil }, 3, time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, calls)
+}
+
+func TestBackoffGrowsExponentiallyWithJitter(t *testing.T) {
+	for attempt := 0; attempt < 4; attempt++ {
+		full := 100 * time.Millisecond << attempt
+		for i := 0; i < 50; i++ {
+			delay := backoff(100*time.Millisecond, attempt)
+			assert.GreaterOrEqual(t, delay, full/2)
+			assert.LessOrEqual(t, delay, full)
+		}
+	}
+	assert.Zero(t, backoff(0, 3))
+}
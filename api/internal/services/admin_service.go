@@ -1,37 +1,154 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"math"
+	"net/mail"
 	"regexp"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 	"trackable-donations/api/internal/models"
 )
 
 // AdminService gerencia operações relacionadas a administração do sistema
 type AdminService struct {
+	// mu protege os registros de ONGs, o log de auditoria, a cópia das ONGs e o relógio.
+	// Quando também é preciso o lock de outro serviço, mu é obtido primeiro.
+	mu               sync.RWMutex
 	donations        []models.Donation
 	ngos             []models.NGO
 	ngoRegistrations []models.NGORegistration
 	auditLogs        []models.AuditLog
 	donationService  *DonationService
 	expenseService   *ExpenseService
+	// clock fornece o horário dos registros, alterações e do log de auditoria (ver SetClock)
+	clock Clock
+
+	// Eventos de status enviados às URLs de callback das ONGs (ver notifyStatusChange).
+	// webhookCtx é cancelado por Close, interrompendo as novas tentativas de entrega.
+	webhookMu         sync.Mutex
+	webhookSender     WebhookSender
+	webhookDeliveries []models.WebhookDelivery
+	webhooks          sync.WaitGroup
+	webhookCtx        context.Context
+	cancelWebhooks    context.CancelFunc
 }
 
-// NewAdminService cria uma nova instância do serviço de administração
+// NewAdminService cria uma nova instância do serviço de administração, carregando os
+// registros de ONGs e o log de auditoria persistidos no armazenamento do serviço de doações
 func NewAdminService(donationSvc *DonationService, expenseSvc *ExpenseService) *AdminService {
+	store := donationSvc.store
+	ngos, err := store.NGOs.FindAll()
+	if err != nil {
+		log.Printf("Erro ao carregar ONGs: %v", err)
+	}
+	registrations, err := store.NGORegistrations.FindAll()
+	if err != nil {
+		log.Printf("Erro ao carregar registros de ONGs: %v", err)
+	}
+	auditLogs, err := store.AuditLogs.FindAll()
+	if err != nil {
+		log.Printf("Erro ao carregar o log de auditoria: %v", err)
+	}
+	deliveries, err := store.WebhookDeliveries.FindAll()
+	if err != nil {
+		log.Printf("Erro ao carregar as entregas de eventos às ONGs: %v", err)
+	}
+
+	webhookCtx, cancelWebhooks := context.WithCancel(context.Background())
 	return &AdminService{
 		donations:        []models.Donation{},
-		ngos:             []models.NGO{},
-		ngoRegistrations: []models.NGORegistration{},
-		auditLogs:        []models.AuditLog{},
+		ngos:             append([]models.NGO{}, ngos...),
+		ngoRegistrations: append([]models.NGORegistration{}, registrations...),
+		auditLogs:        append([]models.AuditLog{}, auditLogs...),
 		donationService:  donationSvc,
 		expenseService:   expenseSvc,
+		clock:            RealClock{},
+
+		webhookSender:     NewHTTPWebhookSender(DefaultWebhookTimeout),
+		webhookDeliveries: append([]models.WebhookDelivery{}, deliveries...),
+		webhookCtx:        webhookCtx,
+		cancelWebhooks:    cancelWebhooks,
+	}
+}
+
+// SetClock define o relógio usado pelo serviço; deve ser chamado antes de o serviço ser usado
+func (s *AdminService) SetClock(clock Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+}
+
+// now retorna o horário atual do relógio do serviço; não deve ser chamado com s.mu bloqueado
+func (s *AdminService) now() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clock.Now()
+}
+
+// updateRegistration aplica a alteração a uma cópia do registro de ONG e, se ela for
+// persistida com sucesso, a reflete na lista em memória. Deve ser chamado com s.mu bloqueado.
+func (s *AdminService) updateRegistration(index int, update func(*models.NGORegistration)) (models.NGORegistration, error) {
+	registration := s.ngoRegistrations[index]
+	update(&registration)
+	registration.UpdatedAt = s.clock.Now()
+	if err := s.donationService.store.NGORegistrations.Save(&registration); err != nil {
+		return models.NGORegistration{}, fmt.Errorf("falha ao salvar o registro de ONG: %w", err)
+	}
+	s.ngoRegistrations[index] = registration
+	return registration, nil
+}
+
+// ErrNGORegistrationNotFound indica um ID de registro de ONG inexistente
+var ErrNGORegistrationNotFound = errors.New("registro de ONG não encontrado")
+
+// ErrNGODocumentsNotFound indica um registro de ONG que ainda não tem documentos no IPFS
+var ErrNGODocumentsNotFound = errors.New("documentos não foram enviados")
+
+// ErrInvalidState indica uma UF inexistente no registro de ONG
+var ErrInvalidState = errors.New("UF inválida")
+
+// ErrInvalidNGOCategory indica uma categoria fora de models.NGOCategories
+var ErrInvalidNGOCategory = errors.New("categoria de ONG inválida")
+
+// accentFolder remove os acentos usados em português, para comparar categorias
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "ê", "e", "è", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ò", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// normalizeCategory deixa a categoria em minúsculas, sem acentos e sem espaços extras
+func normalizeCategory(category string) string {
+	return accentFolder.Replace(strings.ToLower(strings.Join(strings.Fields(category), " ")))
+}
+
+// canonicalNGOCategory busca a categoria ignorando acentos e maiúsculas/minúsculas
+// e retorna a grafia oficial
+func canonicalNGOCategory(category string) (string, bool) {
+	normalized := normalizeCategory(category)
+	for _, valid := range models.NGOCategories {
+		if normalizeCategory(valid) == normalized {
+			return valid, true
+		}
 	}
+	return "", false
 }
 
 // RegisterNGO inicia o processo de registro de uma nova ONG
 func (s *AdminService) RegisterNGO(req models.NGORegistrationRequest) (models.NGORegistration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Verificar se o CNPJ já está em uso
 	for _, reg := range s.ngoRegistrations {
 		if reg.CNPJ == req.CNPJ {
@@ -45,42 +162,72 @@ func (s *AdminService) RegisterNGO(req models.NGORegistrationRequest) (models.NG
 		}
 	}
 
+	state := strings.ToUpper(strings.TrimSpace(req.State))
+	region, ok := models.StateRegions[state]
+	if !ok {
+		return models.NGORegistration{}, fmt.Errorf("%w: %q", ErrInvalidState, req.State)
+	}
+
+	category, ok := canonicalNGOCategory(req.Category)
+	if !ok {
+		return models.NGORegistration{}, fmt.Errorf("%w: %q (use uma de: %s)",
+			ErrInvalidNGOCategory, req.Category, strings.Join(models.NGOCategories, ", "))
+	}
+
+	// Os eventos de status só são assinados e enviados quando há URL de callback
+	var webhookSecret string
+	if req.CallbackURL != "" {
+		if err := validateCallbackURL(req.CallbackURL); err != nil {
+			return models.NGORegistration{}, err
+		}
+		webhookSecret = newWebhookSecret()
+	}
+
 	// Validar o formato do CNPJ
 	isValid, msg := s.validateCNPJFormat(req.CNPJ)
 
-	registrationID := uint(len(s.ngoRegistrations) + 1)
 	registration := models.NGORegistration{
-		ID:                registrationID,
 		Name:              req.Name,
 		Description:       req.Description,
-		Category:          req.Category,
+		Category:          category,
 		CNPJ:              req.CNPJ,
 		CNPJValid:         isValid,
 		CNPJValidationMsg: msg,
 		Email:             req.Email,
 		Phone:             req.Phone,
 		Address:           req.Address,
+		State:             state,
+		Region:            region,
 		ResponsibleID:     req.ResponsibleID,
 		LogoURL:           req.LogoURL,
+		HideContact:       req.HideContact,
+		CallbackURL:       req.CallbackURL,
+		WebhookSecret:     webhookSecret,
 		Status:            models.NGOStatusPending,
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
+		CreatedAt:         s.clock.Now(),
+		UpdatedAt:         s.clock.Now(),
 	}
 
+	if err := s.donationService.store.NGORegistrations.Create(&registration); err != nil {
+		return models.NGORegistration{}, fmt.Errorf("falha ao salvar o registro de ONG: %w", err)
+	}
 	s.ngoRegistrations = append(s.ngoRegistrations, registration)
 
 	// Registrar ação no log de auditoria
-	s.logAuditAction(0, "ngo_registration_created", "ngo_registration", registrationID, "",
+	s.logAuditAction(0, models.AuditActionNGORegistrationCreated, "ngo_registration", registration.ID,
+		"", string(models.NGOStatusPending),
 		fmt.Sprintf("Registro de ONG solicitado: %s (CNPJ: %s)", req.Name, req.CNPJ))
 
 	return registration, nil
 }
 
+// nonDigits casa os separadores de documentos formatados (ex.: 12.345.678/0001-95)
+var nonDigits = regexp.MustCompile(`[^0-9]`)
+
 // validateCNPJFormat valida o formato do CNPJ (somente verificação de formato)
 func (s *AdminService) validateCNPJFormat(cnpj string) (bool, string) {
 	// Remover caracteres não numéricos
-	re := regexp.MustCompile(`[^0-9]`)
-	cnpj = re.ReplaceAllString(cnpj, "")
+	cnpj = nonDigits.ReplaceAllString(cnpj, "")
 
 	// Verificar se tem 14 dígitos
 	if len(cnpj) != 14 {
@@ -143,6 +290,9 @@ func (s *AdminService) validateCNPJFormat(cnpj string) (bool, string) {
 
 // ValidateCNPJOnline realiza uma validação online do CNPJ (simulado)
 func (s *AdminService) ValidateCNPJOnline(registrationID uint) (models.NGORegistration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Encontrar o registro
 	var registration models.NGORegistration
 	var index int
@@ -158,69 +308,88 @@ func (s *AdminService) ValidateCNPJOnline(registrationID uint) (models.NGORegist
 	}
 
 	if !found {
-		return models.NGORegistration{}, errors.New("registro de ONG não encontrado")
+		return models.NGORegistration{}, ErrNGORegistrationNotFound
 	}
 
 	// Em um ambiente real, faria uma consulta a um serviço externo
 	// Aqui, simularemos com base na validação de formato
 	if registration.CNPJValid {
 		// Simulando consulta online bem-sucedida
-		s.ngoRegistrations[index].CNPJValid = true
-		s.ngoRegistrations[index].CNPJValidationMsg = "CNPJ verificado online e válido"
-		s.ngoRegistrations[index].Status = models.NGOStatusValidating
-		s.ngoRegistrations[index].UpdatedAt = time.Now()
+		updated, err := s.updateRegistration(index, func(r *models.NGORegistration) {
+			r.CNPJValid = true
+			r.CNPJValidationMsg = "CNPJ verificado online e válido"
+			r.Status = models.NGOStatusValidating
+		})
+		if err != nil {
+			return models.NGORegistration{}, err
+		}
 
 		// Registrar ação no log de auditoria
-		s.logAuditAction(0, "cnpj_validated", "ngo_registration", registrationID,
-			string(registration.Status), string(models.NGOStatusValidating))
+		s.logAuditAction(0, models.AuditActionCNPJValidated, "ngo_registration", registrationID,
+			string(registration.Status), string(models.NGOStatusValidating), updated.CNPJValidationMsg)
 
-		return s.ngoRegistrations[index], nil
+		return updated, nil
 	} else {
 		return models.NGORegistration{}, errors.New(registration.CNPJValidationMsg)
 	}
 }
 
-// UploadNGODocuments simula o upload de documentos para o IPFS
-func (s *AdminService) UploadNGODocuments(registrationID uint, fileContent []byte) (models.NGORegistration, error) {
+// UploadNGODocuments faz o upload dos documentos da ONG para o IPFS. Falhas no envio são
+// tentadas novamente até o cancelamento de ctx.
+func (s *AdminService) UploadNGODocuments(ctx context.Context, registrationID uint, fileContent []byte) (models.NGORegistration, error) {
 	// Encontrar o registro
-	var registration models.NGORegistration
-	var index int
-	found := false
+	registration, err := s.GetNGORegistrationByID(registrationID)
+	if err != nil {
+		return models.NGORegistration{}, err
+	}
+
+	// Verificar se o CNPJ foi validado
+	if !registration.CNPJValid {
+		return models.NGORegistration{}, errors.New("CNPJ deve ser validado antes do upload de documentos")
+	}
 
+	// O envio (com as novas tentativas) é feito sem o lock, para não bloquear o serviço
+	ipfsHash, err := addToIPFS(ctx, s.donationService.ipfsClient(), fileContent)
+	if err != nil {
+		return models.NGORegistration{}, fmt.Errorf("falha no upload dos documentos para o IPFS: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Os registros só são incluídos, nunca removidos, então o registro continua na lista
+	index := -1
 	for i, reg := range s.ngoRegistrations {
 		if reg.ID == registrationID {
-			registration = reg
 			index = i
-			found = true
 			break
 		}
 	}
-
-	if !found {
-		return models.NGORegistration{}, errors.New("registro de ONG não encontrado")
-	}
-
-	// Verificar se o CNPJ foi validado
-	if !registration.CNPJValid {
-		return models.NGORegistration{}, errors.New("CNPJ deve ser validado antes do upload de documentos")
+	if index < 0 {
+		return models.NGORegistration{}, ErrNGORegistrationNotFound
 	}
 
-	// Simular upload para IPFS
-	ipfsHash := fmt.Sprintf("Qm%s", generateMockHash(46))
-
 	// Atualizar o registro
-	s.ngoRegistrations[index].DocumentsIPFS = ipfsHash
-	s.ngoRegistrations[index].UpdatedAt = time.Now()
+	updated, err := s.updateRegistration(index, func(r *models.NGORegistration) {
+		r.DocumentsIPFS = ipfsHash
+	})
+	if err != nil {
+		return models.NGORegistration{}, err
+	}
 
 	// Registrar ação no log de auditoria
-	s.logAuditAction(0, "documents_uploaded", "ngo_registration", registrationID,
-		"", fmt.Sprintf("Documentos enviados para IPFS: %s", ipfsHash))
+	s.logAuditAction(0, models.AuditActionDocumentsUploaded, "ngo_registration", registrationID,
+		string(updated.Status), string(updated.Status), fmt.Sprintf("Documentos enviados para IPFS: %s", ipfsHash))
 
-	return s.ngoRegistrations[index], nil
+	return updated, nil
 }
 
-// ApproveNGO aprova o registro de uma ONG e cria a entrada na blockchain
-func (s *AdminService) ApproveNGO(registrationID uint, adminID uint, comments string) (models.NGO, error) {
+// ApproveNGO aprova o registro de uma ONG e cria a entrada na blockchain. Também retorna a
+// chave de API com que a ONG envia seus gastos, que não pode ser recuperada depois.
+func (s *AdminService) ApproveNGO(registrationID uint, adminID uint, comments string) (models.NGO, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Encontrar o registro
 	var registration models.NGORegistration
 	var regIndex int
@@ -236,31 +405,26 @@ func (s *AdminService) ApproveNGO(registrationID uint, adminID uint, comments st
 	}
 
 	if !found {
-		return models.NGO{}, errors.New("registro de ONG não encontrado")
+		return models.NGO{}, "", ErrNGORegistrationNotFound
 	}
 
 	// Verificar se todos os requisitos foram cumpridos
 	if !registration.CNPJValid {
-		return models.NGO{}, errors.New("CNPJ não foi validado")
+		return models.NGO{}, "", errors.New("CNPJ não foi validado")
 	}
 
 	if registration.DocumentsIPFS == "" {
-		return models.NGO{}, errors.New("documentos não foram enviados")
+		return models.NGO{}, "", ErrNGODocumentsNotFound
 	}
 
 	// Simular registro na blockchain
 	blockchainRef := generateMockTransactionHash()
 
-	// Atualizar o registro
-	s.ngoRegistrations[regIndex].BlockchainRef = blockchainRef
-	s.ngoRegistrations[regIndex].Status = models.NGOStatusApproved
-	s.ngoRegistrations[regIndex].AdminComments = comments
-	s.ngoRegistrations[regIndex].UpdatedAt = time.Now()
+	// Chave de API com que a ONG enviará seus gastos; só o hash é armazenado
+	apiKey, apiKeyHash := newNGOAPIKey()
 
-	// Criar uma nova ONG
-	ngoID := uint(len(s.ngos) + 1)
+	// Criar uma nova ONG (o ID vem do banco)
 	ngo := models.NGO{
-		ID:            ngoID,
 		Name:          registration.Name,
 		Description:   registration.Description,
 		Category:      registration.Category,
@@ -268,28 +432,55 @@ func (s *AdminService) ApproveNGO(registrationID uint, adminID uint, comments st
 		Email:         registration.Email,
 		Phone:         registration.Phone,
 		Address:       registration.Address,
+		State:         registration.State,
+		Region:        registration.Region,
 		LogoURL:       registration.LogoURL,
 		DocumentsIPFS: registration.DocumentsIPFS,
 		BlockchainRef: blockchainRef,
 		ResponsibleID: registration.ResponsibleID,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		HideContact:   registration.HideContact,
+		Status:        models.NGOActive,
+		APIKeyHash:    apiKeyHash,
+		CreatedAt:     s.clock.Now(),
+		UpdatedAt:     s.clock.Now(),
+	}
+
+	if err := s.donationService.store.NGOs.Create(&ngo); err != nil {
+		return models.NGO{}, "", fmt.Errorf("falha ao salvar a ONG: %w", err)
+	}
+
+	// Atualizar o registro
+	updated, err := s.updateRegistration(regIndex, func(r *models.NGORegistration) {
+		r.BlockchainRef = blockchainRef
+		r.Status = models.NGOStatusApproved
+		r.AdminComments = comments
+	})
+	if err != nil {
+		return models.NGO{}, "", err
 	}
 
 	s.ngos = append(s.ngos, ngo)
 
 	// Adicionar a ONG ao serviço de doações
-	s.donationService.ngos = append(s.donationService.ngos, ngo)
+	s.donationService.addNGO(ngo)
 
 	// Registrar ação no log de auditoria
-	s.logAuditAction(adminID, "ngo_approved", "ngo", ngoID,
-		string(models.NGOStatusValidating), string(models.NGOStatusApproved))
+	auditComments := fmt.Sprintf("Registro %d aprovado", registrationID)
+	if comments = strings.TrimSpace(comments); comments != "" {
+		auditComments += ": " + comments
+	}
+	s.logAuditAction(adminID, models.AuditActionNGOApproved, "ngo", ngo.ID,
+		string(models.NGOStatusValidating), string(models.NGOStatusApproved), auditComments)
 
-	return ngo, nil
+	s.notifyStatusChange(updated, models.NGOEventRegistrationApproved, ngo.ID, comments)
+	return ngo, apiKey, nil
 }
 
 // RejectNGO rejeita o registro de uma ONG
 func (s *AdminService) RejectNGO(registrationID uint, adminID uint, reason string) (models.NGORegistration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Encontrar o registro
 	var registration models.NGORegistration
 	var index int
@@ -305,38 +496,584 @@ func (s *AdminService) RejectNGO(registrationID uint, adminID uint, reason strin
 	}
 
 	if !found {
-		return models.NGORegistration{}, errors.New("registro de ONG não encontrado")
+		return models.NGORegistration{}, ErrNGORegistrationNotFound
 	}
 
 	// Atualizar o registro
-	s.ngoRegistrations[index].Status = models.NGOStatusRejected
-	s.ngoRegistrations[index].AdminComments = reason
-	s.ngoRegistrations[index].UpdatedAt = time.Now()
+	updated, err := s.updateRegistration(index, func(r *models.NGORegistration) {
+		r.Status = models.NGOStatusRejected
+		r.AdminComments = reason
+	})
+	if err != nil {
+		return models.NGORegistration{}, err
+	}
+
+	// Registrar ação no log de auditoria
+	s.logAuditAction(adminID, models.AuditActionNGORejected, "ngo_registration", registrationID,
+		string(registration.Status), string(models.NGOStatusRejected), fmt.Sprintf("Motivo da rejeição: %s", reason))
+
+	s.notifyStatusChange(updated, models.NGOEventRegistrationRejected, 0, reason)
+	return updated, nil
+}
+
+// ErrNGORegistrationNotRejected indica a reabertura de um registro de ONG que não foi rejeitado
+var ErrNGORegistrationNotRejected = errors.New("apenas registros rejeitados podem ser reabertos")
+
+// ReopenNGORegistration devolve um registro rejeitado ao status pendente, para que a ONG
+// possa corrigir o cadastro e passar novamente pela análise
+func (s *AdminService) ReopenNGORegistration(registrationID uint, adminID uint, reason string) (models.NGORegistration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := -1
+	for i, reg := range s.ngoRegistrations {
+		if reg.ID == registrationID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return models.NGORegistration{}, ErrNGORegistrationNotFound
+	}
+	if s.ngoRegistrations[index].Status != models.NGOStatusRejected {
+		return models.NGORegistration{}, ErrNGORegistrationNotRejected
+	}
+
+	updated, err := s.updateRegistration(index, func(r *models.NGORegistration) {
+		r.Status = models.NGOStatusPending
+		r.AdminComments = reason
+	})
+	if err != nil {
+		return models.NGORegistration{}, err
+	}
+
+	s.logAuditAction(adminID, models.AuditActionNGOReopened, "ngo_registration", registrationID,
+		string(models.NGOStatusRejected), string(models.NGOStatusPending), fmt.Sprintf("Motivo da reabertura: %s", reason))
+
+	s.notifyStatusChange(updated, models.NGOEventRegistrationReopened, 0, reason)
+	return updated, nil
+}
+
+// ApproveExpense aprova um gasto pendente com comprovante, liberando-o para a transparência
+func (s *AdminService) ApproveExpense(expenseID uint, adminID uint) error {
+	if err := s.expenseService.reviewExpense(expenseID, "aprovado", ""); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logAuditAction(adminID, models.AuditActionExpenseApproved, "expense", expenseID, "pendente", "aprovado",
+		"Comprovante conferido e despesa aprovada")
+	return nil
+}
+
+// RejectExpense rejeita um gasto pendente. Gastos rejeitados nunca aparecem na
+// transparência nem consomem o saldo da doação.
+func (s *AdminService) RejectExpense(expenseID uint, adminID uint, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("o motivo da rejeição é obrigatório")
+	}
+	if err := s.expenseService.reviewExpense(expenseID, "rejeitado", reason); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logAuditAction(adminID, models.AuditActionExpenseRejected, "expense", expenseID, "pendente", "rejeitado",
+		fmt.Sprintf("Motivo da rejeição: %s", reason))
+	return nil
+}
+
+var (
+	// ErrNGOAlreadySuspended indica que a ONG já está suspensa
+	ErrNGOAlreadySuspended = errors.New("a ONG já está suspensa")
+	// ErrNGOMerged indica que a ONG foi mesclada em outra e não pode mais ser alterada
+	ErrNGOMerged = errors.New("a ONG foi mesclada em outra ONG")
+)
+
+// SuspendNGO suspende uma ONG (ex.: perda do registro), impedindo novas doações. As
+// doações e despesas anteriores continuam rastreáveis nas visões de transparência.
+func (s *AdminService) SuspendNGO(ngoID uint, adminID uint, reason string) (models.NGO, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return models.NGO{}, errors.New("o motivo da suspensão é obrigatório")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.donationService.mu.Lock()
+	index := -1
+	for i, ngo := range s.donationService.ngos {
+		if ngo.ID == ngoID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		s.donationService.mu.Unlock()
+		return models.NGO{}, ErrNGONotFound
+	}
+
+	suspended := s.donationService.ngos[index]
+	previousStatus := suspended.Status
+	switch previousStatus {
+	case models.NGOSuspended:
+		s.donationService.mu.Unlock()
+		return models.NGO{}, ErrNGOAlreadySuspended
+	case models.NGOMerged:
+		s.donationService.mu.Unlock()
+		return models.NGO{}, ErrNGOMerged
+	}
+
+	suspended.Status = models.NGOSuspended
+	suspended.SuspensionReason = reason
+	suspended.UpdatedAt = s.clock.Now()
+	if err := s.donationService.store.NGOs.Save(&suspended); err != nil {
+		s.donationService.mu.Unlock()
+		return models.NGO{}, fmt.Errorf("falha ao salvar a ONG: %w", err)
+	}
+	s.donationService.replaceNGO(index, suspended)
+	s.donationService.mu.Unlock()
+
+	// Manter a cópia do serviço de administração em sincronia
+	for i := range s.ngos {
+		if s.ngos[i].ID == ngoID {
+			s.ngos[i] = suspended
+		}
+	}
+
+	s.logAuditAction(adminID, models.AuditActionNGOSuspended, "ngo", ngoID, previousStatus, models.NGOSuspended,
+		fmt.Sprintf("Motivo da suspensão: %s", reason))
+	return suspended, nil
+}
+
+// ErrNGOCNPJImmutable indica uma tentativa de alterar o CNPJ de uma ONG aprovada
+var ErrNGOCNPJImmutable = errors.New("o CNPJ da ONG não pode ser alterado")
+
+// UpdateNGO atualiza o perfil de uma ONG aprovada (contato, descrição, logo etc.). Apenas
+// os campos informados mudam; o CNPJ e o ID são imutáveis. A alteração é registrada no
+// log de auditoria com os valores anteriores e os novos dos campos modificados.
+func (s *AdminService) UpdateNGO(ngoID uint, req models.NGOUpdateRequest, adminID uint) (models.NGO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.donationService.mu.Lock()
+	index := -1
+	for i, ngo := range s.donationService.ngos {
+		if ngo.ID == ngoID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		s.donationService.mu.Unlock()
+		return models.NGO{}, ErrNGONotFound
+	}
+
+	current := s.donationService.ngos[index]
+	if current.Status == models.NGOMerged {
+		s.donationService.mu.Unlock()
+		return models.NGO{}, ErrNGOMerged
+	}
+	if req.CNPJ != nil && nonDigits.ReplaceAllString(*req.CNPJ, "") != nonDigits.ReplaceAllString(current.CNPJ, "") {
+		s.donationService.mu.Unlock()
+		return models.NGO{}, ErrNGOCNPJImmutable
+	}
+
+	updated, before, after, err := applyNGOUpdate(current, req)
+	if err != nil {
+		s.donationService.mu.Unlock()
+		return models.NGO{}, err
+	}
+	if len(after) == 0 {
+		s.donationService.mu.Unlock()
+		return current, nil
+	}
+
+	updated.UpdatedAt = s.clock.Now()
+	if err := s.donationService.store.NGOs.Save(&updated); err != nil {
+		s.donationService.mu.Unlock()
+		return models.NGO{}, fmt.Errorf("falha ao salvar a ONG: %w", err)
+	}
+	s.donationService.replaceNGO(index, updated)
+	s.donationService.aggregatesVersion.Add(1)
+	s.donationService.mu.Unlock()
+
+	// Manter a cópia do serviço de administração em sincronia
+	for i := range s.ngos {
+		if s.ngos[i].ID == ngoID {
+			s.ngos[i] = updated
+		}
+	}
+
+	previousState, _ := json.Marshal(before)
+	newState, _ := json.Marshal(after)
+	fields := make([]string, 0, len(after))
+	for field := range after {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	s.logAuditAction(adminID, models.AuditActionNGOUpdated, "ngo", ngoID, string(previousState), string(newState),
+		fmt.Sprintf("Campos alterados: %s", strings.Join(fields, ", ")))
+	return updated, nil
+}
+
+// applyNGOUpdate valida os campos informados e os aplica a uma cópia da ONG, retornando
+// os valores anteriores e os novos dos campos que de fato mudaram
+func applyNGOUpdate(ngo models.NGO, req models.NGOUpdateRequest) (models.NGO, map[string]any, map[string]any, error) {
+	before := map[string]any{}
+	after := map[string]any{}
+	setString := func(field string, target *string, value *string) {
+		if value == nil || *value == *target {
+			return
+		}
+		before[field], after[field] = *target, *value
+		*target = *value
+	}
+
+	trimmed := func(value *string) *string {
+		if value == nil {
+			return nil
+		}
+		v := strings.TrimSpace(*value)
+		return &v
+	}
+
+	name, email := trimmed(req.Name), trimmed(req.Email)
+	if name != nil && *name == "" {
+		return models.NGO{}, nil, nil, errors.New("o nome da ONG não pode ficar vazio")
+	}
+	if email != nil {
+		if addr, err := mail.ParseAddress(*email); err != nil || addr.Address != *email {
+			return models.NGO{}, nil, nil, ErrInvalidEmail
+		}
+	}
+
+	category := req.Category
+	if category != nil {
+		canonical, ok := canonicalNGOCategory(*category)
+		if !ok {
+			return models.NGO{}, nil, nil, fmt.Errorf("%w: %q (use uma de: %s)",
+				ErrInvalidNGOCategory, *category, strings.Join(models.NGOCategories, ", "))
+		}
+		category = &canonical
+	}
+
+	state := req.State
+	if state != nil {
+		uf := strings.ToUpper(strings.TrimSpace(*state))
+		region, ok := models.StateRegions[uf]
+		if !ok {
+			return models.NGO{}, nil, nil, fmt.Errorf("%w: %q", ErrInvalidState, *state)
+		}
+		state = &uf
+		setString("region", &ngo.Region, &region)
+	}
+
+	setString("name", &ngo.Name, name)
+	setString("description", &ngo.Description, trimmed(req.Description))
+	setString("category", &ngo.Category, category)
+	setString("email", &ngo.Email, email)
+	setString("phone", &ngo.Phone, trimmed(req.Phone))
+	setString("address", &ngo.Address, trimmed(req.Address))
+	setString("state", &ngo.State, state)
+	setString("logo_url", &ngo.LogoURL, trimmed(req.LogoURL))
+	if req.HideContact != nil && *req.HideContact != ngo.HideContact {
+		before["hide_contact"], after["hide_contact"] = ngo.HideContact, *req.HideContact
+		ngo.HideContact = *req.HideContact
+	}
+	return ngo, before, after, nil
+}
+
+// RefundDonation estorna uma doação concluída e registra a operação no log de auditoria
+func (s *AdminService) RefundDonation(donationID uint, adminID uint, reason string) error {
+	if err := s.expenseService.RefundDonation(donationID, reason); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logAuditAction(adminID, models.AuditActionDonationRefunded, "donation", donationID, "completed", "refunded",
+		fmt.Sprintf("Motivo do estorno: %s", strings.TrimSpace(reason)))
+	return nil
+}
+
+// MergeNGOs mescla uma ONG duplicada na ONG canônica: transfere doações e despesas,
+// desativa o registro duplicado e registra a operação no log de auditoria
+func (s *AdminService) MergeNGOs(canonicalID, duplicateID uint, adminID uint) error {
+	if canonicalID == duplicateID {
+		return errors.New("a ONG canônica e a duplicada devem ser diferentes")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	canonical, err := s.donationService.GetNGOByID(canonicalID)
+	if err != nil {
+		return fmt.Errorf("ONG canônica: %v", err)
+	}
+	duplicate, err := s.donationService.GetNGOByID(duplicateID)
+	if err != nil {
+		return fmt.Errorf("ONG duplicada: %v", err)
+	}
+
+	if canonical.Status == models.NGOMerged {
+		return errors.New("a ONG canônica já foi mesclada em outra ONG")
+	}
+	if duplicate.Status == models.NGOMerged {
+		return errors.New("a ONG duplicada já foi mesclada anteriormente")
+	}
+
+	// Falhas de persistência não interrompem a mescla; são reunidas e retornadas ao final
+	store := s.donationService.store
+	var saveErrs []error
+
+	// Transferir doações, comprovantes e usos de recursos
+	s.donationService.mu.Lock()
+	movedDonations := 0
+	var movedAmount float64
+	donationIDs := make(map[uint]bool)
+	for i, donation := range s.donationService.donations {
+		if donation.NGOID == duplicateID {
+			s.donationService.donations[i].NGOID = canonicalID
+			saveErrs = append(saveErrs, store.Donations.Save(&s.donationService.donations[i]))
+			donationIDs[donation.ID] = true
+			movedDonations++
+			if donation.Status == "completed" {
+				movedAmount += donation.Amount
+			}
+		}
+	}
+
+	for i, receipt := range s.donationService.receipts {
+		if donationIDs[receipt.DonationID] {
+			s.donationService.receipts[i].NGOName = canonical.Name
+			saveErrs = append(saveErrs, store.Receipts.Save(&s.donationService.receipts[i]))
+		}
+	}
+
+	for i, usage := range s.donationService.resourceUsages {
+		if donationIDs[usage.DonationID] {
+			s.donationService.resourceUsages[i].NGOName = canonical.Name
+			saveErrs = append(saveErrs, store.ResourceUsages.Save(&s.donationService.resourceUsages[i]))
+		}
+	}
+
+	// As campanhas acompanham as doações atribuídas a elas
+	for i, campaign := range s.donationService.campaigns {
+		if campaign.NGOID == duplicateID {
+			s.donationService.campaigns[i].NGOID = canonicalID
+			saveErrs = append(saveErrs, store.Campaigns.Save(&s.donationService.campaigns[i]))
+		}
+	}
+
+	// As doações recorrentes passam a ser cobradas para a ONG canônica, que continua
+	// recebendo doações; a duplicada deixa de aceitá-las
+	for i, recurring := range s.donationService.recurringDonations {
+		if recurring.NGOID == duplicateID {
+			s.donationService.recurringDonations[i].NGOID = canonicalID
+			saveErrs = append(saveErrs, store.RecurringDonations.Save(&s.donationService.recurringDonations[i]))
+		}
+	}
+	s.donationService.mu.Unlock()
+
+	// Transferir despesas
+	movedExpenses, expenseErrs := s.expenseService.reassignNGO(duplicateID, canonicalID)
+	saveErrs = append(saveErrs, expenseErrs...)
+
+	// Desativar a ONG duplicada em todos os serviços que mantêm uma cópia
+	deactivate := func(ngo *models.NGO) {
+		ngo.Status = models.NGOMerged
+		ngo.MergedInto = canonicalID
+		ngo.UpdatedAt = s.clock.Now()
+	}
+	s.donationService.mu.Lock()
+	for i, ngo := range s.donationService.ngos {
+		if ngo.ID == duplicateID {
+			deactivate(&ngo)
+			s.donationService.replaceNGO(i, ngo)
+			saveErrs = append(saveErrs, store.NGOs.Save(&ngo))
+		}
+	}
+	s.donationService.aggregatesVersion.Add(1)
+	s.donationService.mu.Unlock()
+	for i := range s.ngos {
+		if s.ngos[i].ID == duplicateID {
+			deactivate(&s.ngos[i])
+		}
+	}
 
 	// Registrar ação no log de auditoria
-	s.logAuditAction(adminID, "ngo_rejected", "ngo_registration", registrationID,
-		string(registration.Status), string(models.NGOStatusRejected))
+	s.logAuditAction(adminID, models.AuditActionNGOMerged, "ngo", canonicalID,
+		fmt.Sprintf("ONG duplicada: %d (%s)", duplicateID, duplicate.Name),
+		fmt.Sprintf("%d doações (R$ %.2f) e %d despesas transferidas para a ONG %d (%s)",
+			movedDonations, movedAmount, movedExpenses, canonicalID, canonical.Name),
+		fmt.Sprintf("ONG %d mesclada na ONG %d", duplicateID, canonicalID))
+
+	if err := errors.Join(saveErrs...); err != nil {
+		return fmt.Errorf("falha ao salvar a mescla de ONGs: %w", err)
+	}
+	return nil
+}
+
+// Paginação padrão e máxima da listagem de registros de ONGs
+const (
+	defaultRegistrationPageSize = 20
+	maxRegistrationPageSize     = 100
+)
+
+var (
+	// ErrInvalidRegistrationStatus indica um filtro de status de registro desconhecido
+	ErrInvalidRegistrationStatus = errors.New("status de registro inválido (use pendente, validando, aprovado ou rejeitado)")
+	// ErrInvalidRegistrationSort indica uma ordenação de registros desconhecida
+	ErrInvalidRegistrationSort = errors.New("ordenação inválida (use date_desc ou date_asc)")
+)
+
+// GetNGORegistrations lista os registros de ONGs, combinando os filtros de status e CNPJ,
+// e retorna a página pedida ordenada pela data da solicitação (padrão: mais recentes primeiro)
+func (s *AdminService) GetNGORegistrations(query models.NGORegistrationQuery) (models.NGORegistrationListResult, error) {
+	if query.Status != "" {
+		if err := validateRegistrationStatus(query.Status); err != nil {
+			return models.NGORegistrationListResult{}, err
+		}
+	}
+	if query.Sort == "" {
+		query.Sort = models.RegistrationSortDateDesc
+	}
+	if query.Sort != models.RegistrationSortDateDesc && query.Sort != models.RegistrationSortDateAsc {
+		return models.NGORegistrationListResult{}, fmt.Errorf("%w: %q", ErrInvalidRegistrationSort, query.Sort)
+	}
+
+	result := models.NGORegistrationListResult{Registrations: []models.NGORegistration{}, Page: query.Page, PageSize: query.PageSize}
+	if result.Page <= 0 {
+		result.Page = 1
+	}
+	if result.PageSize <= 0 {
+		result.PageSize = defaultRegistrationPageSize
+	}
+	if result.PageSize > maxRegistrationPageSize {
+		result.PageSize = maxRegistrationPageSize
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []models.NGORegistration
+	for _, reg := range s.ngoRegistrations {
+		if (query.Status != "" && reg.Status != query.Status) || (query.CNPJ != "" && reg.CNPJ != query.CNPJ) {
+			continue
+		}
+		matches = append(matches, reg)
+	}
 
-	return s.ngoRegistrations[index], nil
+	// No mesmo instante, o ID desempata na mesma direção da data
+	newestFirst := query.Sort == models.RegistrationSortDateDesc
+	sort.SliceStable(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.After(matches[j].CreatedAt) == newestFirst
+		}
+		return (matches[i].ID > matches[j].ID) == newestFirst
+	})
+
+	result.Total = len(matches)
+	start := (result.Page - 1) * result.PageSize
+	if start < len(matches) {
+		end := min(start+result.PageSize, len(matches))
+		result.Registrations = matches[start:end]
+	}
+	return result, nil
 }
 
-// GetNGORegistrations retorna todos os registros de ONGs
-func (s *AdminService) GetNGORegistrations() []models.NGORegistration {
-	return s.ngoRegistrations
+// validateRegistrationStatus verifica se o status de registro é conhecido
+func validateRegistrationStatus(status models.NGORegistrationStatus) error {
+	switch status {
+	case models.NGOStatusPending, models.NGOStatusValidating, models.NGOStatusApproved, models.NGOStatusRejected:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidRegistrationStatus, status)
+	}
+}
+
+// GetNGORegistrationsByStatus retorna os registros de ONGs no status informado
+func (s *AdminService) GetNGORegistrationsByStatus(status models.NGORegistrationStatus) ([]models.NGORegistration, error) {
+	if err := validateRegistrationStatus(status); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := []models.NGORegistration{}
+	for _, reg := range s.ngoRegistrations {
+		if reg.Status == status {
+			results = append(results, reg)
+		}
+	}
+	return results, nil
+}
+
+// GetPendingNGORegistrations retorna a fila de registros que aguardam ação (pendentes ou em
+// validação), do mais antigo para o mais recente, com o tempo de espera de cada um
+func (s *AdminService) GetPendingNGORegistrations() []models.PendingNGORegistration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+	pending := []models.PendingNGORegistration{}
+	for _, reg := range s.ngoRegistrations {
+		if reg.Status != models.NGOStatusPending && reg.Status != models.NGOStatusValidating {
+			continue
+		}
+		age := now.Sub(reg.CreatedAt).Round(time.Second)
+		pending = append(pending, models.PendingNGORegistration{
+			NGORegistration: reg,
+			AgeSeconds:      int64(age.Seconds()),
+			Age:             age.String(),
+		})
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+	return pending
 }
 
 // GetNGORegistrationByID retorna um registro de ONG pelo ID
 func (s *AdminService) GetNGORegistrationByID(registrationID uint) (models.NGORegistration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	for _, reg := range s.ngoRegistrations {
 		if reg.ID == registrationID {
 			return reg, nil
 		}
 	}
-	return models.NGORegistration{}, errors.New("registro de ONG não encontrado")
+	return models.NGORegistration{}, ErrNGORegistrationNotFound
+}
+
+// GetNGODocuments baixa do IPFS os documentos enviados para o registro de ONG
+func (s *AdminService) GetNGODocuments(registrationID uint) ([]byte, error) {
+	registration, err := s.GetNGORegistrationByID(registrationID)
+	if err != nil {
+		return nil, err
+	}
+	if registration.DocumentsIPFS == "" {
+		return nil, ErrNGODocumentsNotFound
+	}
+
+	content, err := s.donationService.ipfsClient().Cat(registration.DocumentsIPFS)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter os documentos do IPFS: %w", err)
+	}
+	return content, nil
 }
 
 // GetNGORegistrationsByCNPJ retorna registros de ONGs pelo CNPJ
 func (s *AdminService) GetNGORegistrationsByCNPJ(cnpj string) []models.NGORegistration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var results []models.NGORegistration
 
 	for _, reg := range s.ngoRegistrations {
@@ -353,36 +1090,47 @@ func (s *AdminService) AuditEntity(req models.AuditRequest, adminID uint) (model
 	result := models.AuditResult{
 		EntityType:     req.EntityType,
 		EntityID:       req.EntityID,
-		ValidationDate: time.Now(),
+		ValidationDate: s.now(),
 	}
 
 	var blockchainRef string
 	var ipfsRef string
 	var validationErrors []string
+	// chainTransactionID é o ID da transação registrada na blockchain, quando a entidade tem uma
+	var chainTransactionID string
 
 	switch req.EntityType {
 	case "ngo":
 		// Verificar se a ONG existe
 		found := false
+		var problem string
+		s.mu.RLock()
 		for _, ngo := range s.ngos {
 			if ngo.ID == req.EntityID {
 				blockchainRef = ngo.BlockchainRef
 				ipfsRef = ngo.DocumentsIPFS
 				found = true
+				problem = s.verifyNGOChainBalance(req.EntityID)
 				break
 			}
 		}
+		s.mu.RUnlock()
 
 		if !found {
 			return result, errors.New("ONG não encontrada")
 		}
 
+		if problem != "" {
+			validationErrors = append(validationErrors, problem)
+		}
+
 	case "donation":
 		// Verificar se a doação existe
 		found := false
-		for _, donation := range s.donationService.donations {
+		for _, donation := range s.donationService.snapshotDonations() {
 			if donation.ID == req.EntityID {
 				blockchainRef = donation.TransactionHash
+				chainTransactionID = donationTransactionID(donation.ID)
 				found = true
 				break
 			}
@@ -393,7 +1141,7 @@ func (s *AdminService) AuditEntity(req models.AuditRequest, adminID uint) (model
 		}
 
 		// Encontrar o recibo relacionado
-		for _, receipt := range s.donationService.receipts {
+		for _, receipt := range s.donationService.snapshotReceipts() {
 			if receipt.DonationID == req.EntityID {
 				ipfsRef = receipt.IPFSHash
 				break
@@ -403,7 +1151,7 @@ func (s *AdminService) AuditEntity(req models.AuditRequest, adminID uint) (model
 	case "expense":
 		// Verificar se a despesa existe
 		found := false
-		for _, expense := range s.expenseService.expenses {
+		for _, expense := range s.expenseService.snapshotExpenses() {
 			if expense.ID == req.EntityID {
 				blockchainRef = expense.BlockchainRef
 				ipfsRef = expense.ReceiptIPFS
@@ -420,13 +1168,13 @@ func (s *AdminService) AuditEntity(req models.AuditRequest, adminID uint) (model
 		return result, fmt.Errorf("tipo de entidade desconhecido: %s", req.EntityType)
 	}
 
-	// Verificar a validade na blockchain (simulado)
-	blockchainValid := s.verifyBlockchainReference(blockchainRef)
+	// Verificar a validade na blockchain
+	blockchainValid := s.verifyBlockchainReference(blockchainRef, chainTransactionID)
 	if !blockchainValid {
 		validationErrors = append(validationErrors, "Referência na blockchain inválida ou não encontrada")
 	}
 
-	// Verificar a validade no IPFS (simulado)
+	// Verificar se o conteúdo existe no IPFS
 	ipfsValid := s.verifyIPFSReference(ipfsRef)
 	if !ipfsValid {
 		validationErrors = append(validationErrors, "Referência no IPFS inválida ou não encontrada")
@@ -444,19 +1192,53 @@ func (s *AdminService) AuditEntity(req models.AuditRequest, adminID uint) (model
 		comments = fmt.Sprintf("Auditoria com erros: %v", validationErrors)
 	}
 
-	s.logAuditAction(adminID, "audit_performed", req.EntityType, req.EntityID, "", comments)
+	s.mu.Lock()
+	s.logAuditAction(adminID, models.AuditActionAuditPerformed, req.EntityType, req.EntityID, "", "", comments)
+	s.mu.Unlock()
 
 	return result, nil
 }
 
-// verifyBlockchainReference verifica a validade de uma referência blockchain (simulado)
-func (s *AdminService) verifyBlockchainReference(reference string) bool {
-	// Em um ambiente real, verificaria a transação na blockchain
-	// Aqui, verificamos apenas se o formato parece válido
+// verifyNGOChainBalance confere se o saldo da ONG na blockchain cobre o total das suas
+// doações confirmadas, exceto as importadas, que nunca foram registradas na cadeia. As ONGs
+// mescladas nela entram na conta, pois suas doações foram transferidas para a canônica mas
+// continuam registradas no endereço original. Retorna a descrição do problema, ou vazio
+// quando o saldo confere. Deve ser chamado com s.mu bloqueado.
+func (s *AdminService) verifyNGOChainBalance(ngoID uint) string {
+	accounts := []string{ngoChainAccount(ngoID)}
+	for _, ngo := range s.ngos {
+		if ngo.MergedInto == ngoID {
+			accounts = append(accounts, ngoChainAccount(ngo.ID))
+		}
+	}
+
+	var recorded float64
+	for _, donation := range s.donationService.snapshotDonations() {
+		// As doações importadas não têm transação na blockchain (ver ImportDonations)
+		if donation.NGOID == ngoID && donation.Status == "completed" && !donation.Imported {
+			recorded += donation.Amount
+		}
+	}
+
+	onChain := s.donationService.chainBalance(accounts...)
+	if math.Round(onChain*100) < math.Round(recorded*100) {
+		return fmt.Sprintf("Saldo da ONG na blockchain (%.2f) menor que o total das doações registradas (%.2f)", onChain, recorded)
+	}
+	return ""
+}
+
+// verifyBlockchainReference verifica a validade de uma referência blockchain. Quando a
+// entidade tem uma transação registrada, localiza-a no bloco referenciado; caso
+// contrário (ONGs e despesas, ainda simuladas), verifica apenas o formato.
+func (s *AdminService) verifyBlockchainReference(reference, transactionID string) bool {
 	if reference == "" {
 		return false
 	}
 
+	if transactionID != "" {
+		return s.donationService.blockchainHasTransaction(reference, transactionID)
+	}
+
 	// Verificar se começa com "0x"
 	if len(reference) < 2 || reference[:2] != "0x" {
 		return false
@@ -472,34 +1254,23 @@ func (s *AdminService) verifyBlockchainReference(reference string) bool {
 	return hexPattern.MatchString(reference)
 }
 
-// verifyIPFSReference verifica a validade de uma referência IPFS (simulado)
+// verifyIPFSReference verifica se a referência aponta para um conteúdo existente no IPFS
 func (s *AdminService) verifyIPFSReference(reference string) bool {
-	// Em um ambiente real, verificaria se o arquivo existe no IPFS
-	// Aqui, verificamos apenas se o formato parece válido
-	if reference == "" {
-		return false
-	}
-
-	// Verificar se começa com "Qm"
-	if len(reference) < 2 || reference[:2] != "Qm" {
-		return false
-	}
-
-	// Verificar se tem o comprimento adequado (aproximadamente Qm + 44 caracteres base58)
-	if len(reference) < 46 {
-		return false
-	}
-
-	return true
+	return s.donationService.ipfsContentExists(reference)
 }
 
 // GetAuditLogs retorna os logs de auditoria
 func (s *AdminService) GetAuditLogs() []models.AuditLog {
-	return s.auditLogs
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]models.AuditLog(nil), s.auditLogs...)
 }
 
 // GetAuditLogsByEntityType retorna logs de auditoria por tipo de entidade
 func (s *AdminService) GetAuditLogsByEntityType(entityType string) []models.AuditLog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var logs []models.AuditLog
 
 	for _, log := range s.auditLogs {
@@ -513,6 +1284,9 @@ func (s *AdminService) GetAuditLogsByEntityType(entityType string) []models.Audi
 
 // GetAuditLogsByEntityID retorna logs de auditoria por ID de entidade
 func (s *AdminService) GetAuditLogsByEntityID(entityType string, entityID uint) []models.AuditLog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var logs []models.AuditLog
 
 	for _, log := range s.auditLogs {
@@ -524,22 +1298,136 @@ func (s *AdminService) GetAuditLogsByEntityID(entityType string, entityID uint)
 	return logs
 }
 
-// logAuditAction registra uma ação de auditoria
-func (s *AdminService) logAuditAction(adminID uint, action string, entityType string, entityID uint,
-	previousState string, newState string) {
+// Paginação padrão e máxima da consulta ao log de auditoria
+const (
+	defaultAuditLogPageSize = 20
+	maxAuditLogPageSize     = 100
+)
+
+// SearchAuditLogs filtra o log de auditoria combinando todos os filtros informados e
+// retorna a página pedida, ordenada da ação mais recente para a mais antiga
+func (s *AdminService) SearchAuditLogs(query models.AuditLogQuery) models.AuditLogResult {
+	result := models.AuditLogResult{Logs: []models.AuditLog{}, Page: query.Page, PageSize: query.PageSize}
+	if result.Page <= 0 {
+		result.Page = 1
+	}
+	if result.PageSize <= 0 {
+		result.PageSize = defaultAuditLogPageSize
+	}
+	if result.PageSize > maxAuditLogPageSize {
+		result.PageSize = maxAuditLogPageSize
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []models.AuditLog
+	for _, entry := range s.auditLogs {
+		switch {
+		case query.EntityType != "" && entry.EntityType != query.EntityType,
+			query.EntityID != 0 && entry.EntityID != query.EntityID,
+			query.Action != "" && entry.Action != query.Action,
+			query.AdminID != 0 && entry.AdminID != query.AdminID,
+			!query.StartDate.IsZero() && entry.CreatedAt.Before(query.StartDate),
+			!query.EndDate.IsZero() && entry.CreatedAt.After(query.EndDate):
+			continue
+		}
+		matches = append(matches, entry)
+	}
+
+	// Mais recentes primeiro; no mesmo instante, o registrado por último vem antes
+	sort.SliceStable(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.After(matches[j].CreatedAt)
+		}
+		return matches[i].ID > matches[j].ID
+	})
+
+	result.Total = len(matches)
+	start := (result.Page - 1) * result.PageSize
+	if start < len(matches) {
+		end := min(start+result.PageSize, len(matches))
+		result.Logs = matches[start:end]
+	}
+	return result
+}
 
-	logID := uint(len(s.auditLogs) + 1)
-	log := models.AuditLog{
-		ID:            logID,
+// Paginação padrão e máxima da listagem de doações dos administradores
+const (
+	defaultDonationListPageSize = 20
+	maxDonationListPageSize     = 100
+)
+
+// ListDonations lista as doações de todos os status (pendentes, estornadas etc.), combinando
+// os filtros informados, e retorna a página pedida da doação mais recente para a mais antiga
+func (s *AdminService) ListDonations(filter models.DonationFilter) (models.DonationListResult, error) {
+	if filter.MinAmount > 0 && filter.MaxAmount > 0 && filter.MinAmount > filter.MaxAmount {
+		return models.DonationListResult{}, ErrInvalidAmountRange
+	}
+
+	result := models.DonationListResult{Donations: []models.Donation{}, Page: filter.Page, PageSize: filter.PageSize}
+	if result.Page <= 0 {
+		result.Page = 1
+	}
+	if result.PageSize <= 0 {
+		result.PageSize = defaultDonationListPageSize
+	}
+	if result.PageSize > maxDonationListPageSize {
+		result.PageSize = maxDonationListPageSize
+	}
+
+	var matches []models.Donation
+	for _, donation := range s.donationService.snapshotDonations() {
+		switch {
+		case filter.Status != "" && !strings.EqualFold(donation.Status, filter.Status),
+			filter.NGOID != 0 && donation.NGOID != filter.NGOID,
+			filter.DonorID != 0 && donation.DonorID != filter.DonorID,
+			!filter.StartDate.IsZero() && donation.CreatedAt.Before(filter.StartDate),
+			!filter.EndDate.IsZero() && donation.CreatedAt.After(filter.EndDate),
+			// Faixa de valor (zero não limita)
+			filter.MinAmount > 0 && donation.Amount < filter.MinAmount,
+			filter.MaxAmount > 0 && donation.Amount > filter.MaxAmount:
+			continue
+		}
+		matches = append(matches, donation)
+	}
+
+	// Mais recentes primeiro; no mesmo instante, a registrada por último vem antes
+	sort.SliceStable(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.After(matches[j].CreatedAt)
+		}
+		return matches[i].ID > matches[j].ID
+	})
+
+	result.Total = len(matches)
+	start := (result.Page - 1) * result.PageSize
+	if start < len(matches) {
+		end := min(start+result.PageSize, len(matches))
+		result.Donations = matches[start:end]
+	}
+	return result, nil
+}
+
+// logAuditAction registra uma ação de auditoria. Os estados descrevem a transição da
+// entidade; comments guarda o texto livre (motivo, observações do administrador etc.).
+// Deve ser chamado com s.mu bloqueado.
+func (s *AdminService) logAuditAction(adminID uint, action models.AuditAction, entityType string, entityID uint,
+	previousState, newState, comments string) {
+
+	entry := models.AuditLog{
 		AdminID:       adminID,
 		Action:        action,
 		EntityType:    entityType,
 		EntityID:      entityID,
 		PreviousState: previousState,
 		NewState:      newState,
-		Comments:      newState, // Usando o newState como comentário para simplificar
-		CreatedAt:     time.Now(),
+		Comments:      comments,
+		CreatedAt:     s.clock.Now(),
 	}
 
-	s.auditLogs = append(s.auditLogs, log)
+	if err := s.donationService.store.AuditLogs.Create(&entry); err != nil {
+		log.Printf("Erro ao salvar log de auditoria (%s %s %d): %v", action, entityType, entityID, err)
+	}
+	s.auditLogs = append(s.auditLogs, entry)
 }
@@ -0,0 +1,605 @@
+package services
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/repository"
+	"trackable-donations/api/internal/utils"
+	"trackable-donations/blockchain-node/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// completeDonation cria e confirma uma doação, retornando seu ID
+func completeDonation(t *testing.T, svc *DonationService, req models.DonationRequest) uint {
+	t.Helper()
+	resp, err := svc.ProcessDonation(req)
+	require.NoError(t, err)
+	_, err = svc.MockPaymentConfirmation(resp.ID)
+	require.NoError(t, err)
+	return resp.ID
+}
+
+func TestMergeNGOs(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+	transparencySvc := NewTransparencyService(donationSvc, expenseSvc)
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	duplicateDonation := completeDonation(t, donationSvc, models.DonationRequest{Amount: 250, DonorID: 2, NGOID: 2})
+
+	expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{
+		DonationID: duplicateDonation, NGOID: 2, Amount: 50, Description: "Medicamentos", Category: "Saúde",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, adminSvc.MergeNGOs(1, 2, 7))
+
+	for _, donation := range donationSvc.donations {
+		assert.Equal(t, uint(1), donation.NGOID, "Todas as doações devem pertencer à ONG canônica")
+	}
+	expenses, err := expenseSvc.GetExpensesByNGO(1, models.ExpenseFilter{})
+	require.NoError(t, err)
+	require.Len(t, expenses, 1)
+	assert.Equal(t, expense.ID, expenses[0].ID)
+
+	summary, err := transparencySvc.GetNGOSummary(1)
+	require.NoError(t, err)
+	assert.Equal(t, 350.0, summary.TotalReceived)
+	assert.Equal(t, 2, summary.DonationsCount)
+
+	receipt, err := donationSvc.GetDonationReceipt(duplicateDonation)
+	require.NoError(t, err)
+	assert.Equal(t, "Alimentando Esperança", receipt.NGOName)
+
+	for _, ngo := range donationSvc.GetAllNGOs() {
+		assert.NotEqual(t, uint(2), ngo.ID, "A ONG duplicada não deve ser listada")
+	}
+	duplicate, err := donationSvc.GetNGOByID(2)
+	require.NoError(t, err)
+	assert.Equal(t, models.NGOMerged, duplicate.Status)
+	assert.Equal(t, uint(1), duplicate.MergedInto)
+
+	logs := adminSvc.GetAuditLogsByEntityID("ngo", 1)
+	require.Len(t, logs, 1)
+	assert.Equal(t, models.AuditActionNGOMerged, logs[0].Action)
+	assert.Equal(t, uint(7), logs[0].AdminID)
+}
+
+func TestMergedNGORejectsNewDonations(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+	require.NoError(t, adminSvc.MergeNGOs(1, 2, 7))
+
+	_, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 2})
+	assert.ErrorIs(t, err, ErrNGOMerged)
+
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	assert.NoError(t, err)
+}
+
+func TestMergeNGOsValidation(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	assert.Error(t, adminSvc.MergeNGOs(1, 1, 1), "Não deve mesclar uma ONG com ela mesma")
+	assert.Error(t, adminSvc.MergeNGOs(1, 99, 1), "A ONG duplicada deve existir")
+	assert.Error(t, adminSvc.MergeNGOs(99, 1, 1), "A ONG canônica deve existir")
+
+	require.NoError(t, adminSvc.MergeNGOs(1, 2, 1))
+	assert.Error(t, adminSvc.MergeNGOs(3, 2, 1), "Uma ONG já mesclada não pode ser mesclada novamente")
+	assert.Error(t, adminSvc.MergeNGOs(2, 3, 1), "Uma ONG mesclada não pode ser canônica")
+}
+
+func TestAuditDonationLocatesTransactionOnBlockchain(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 80, DonorID: 1, NGOID: 2})
+	donation := donationSvc.donations[donationID-1]
+
+	block := donationSvc.blockchain.LastBlock()
+	assert.Equal(t, "0x"+block.Hash(), donation.TransactionHash)
+	require.Len(t, block.Transactions, 1)
+	assert.Equal(t, 80.0, block.Transactions[0].Amount)
+	assert.Equal(t, donationSvc.chainAddress(ngoChainAccount(2)), block.Transactions[0].Receiver)
+
+	result, err := adminSvc.AuditEntity(models.AuditRequest{EntityType: "donation", EntityID: donationID}, 1)
+	require.NoError(t, err)
+	assert.True(t, result.BlockchainValid)
+
+	// Um bloco adulterado deixa de corresponder à referência da doação
+	donationSvc.blockchain.Chain[len(donationSvc.blockchain.Chain)-1].Transactions[0].Amount = 8000
+	result, err = adminSvc.AuditEntity(models.AuditRequest{EntityType: "donation", EntityID: donationID}, 1)
+	require.NoError(t, err)
+	assert.False(t, result.BlockchainValid)
+}
+
+func TestAuditNGOChecksOnChainBalance(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+	balanceProblems := func(ngoID uint) []string {
+		result, err := adminSvc.AuditEntity(models.AuditRequest{EntityType: "ngo", EntityID: ngoID}, 1)
+		require.NoError(t, err)
+		var problems []string
+		for _, problem := range result.ValidationErrors {
+			if strings.HasPrefix(problem, "Saldo da ONG") {
+				problems = append(problems, problem)
+			}
+		}
+		return problems
+	}
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 80, DonorID: 1, NGOID: 1})
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 20, DonorID: 2, NGOID: 2})
+	assert.Empty(t, balanceProblems(1))
+
+	// As doações da ONG mesclada continuam no endereço original da blockchain
+	require.NoError(t, adminSvc.MergeNGOs(1, 2, 7))
+	assert.Empty(t, balanceProblems(1))
+
+	// Uma cadeia sem as transações não cobre as doações registradas
+	donationSvc.SetBlockchain(core.NewBlockchain())
+	assert.Len(t, balanceProblems(1), 1)
+}
+
+func TestChainReferencesSurviveRestart(t *testing.T) {
+	store := repository.NewMemoryStore()
+	first, err := NewDonationServiceWithStore(store)
+	require.NoError(t, err)
+	require.NoError(t, first.Seed())
+	donationID := completeDonation(t, first, models.DonationRequest{Amount: 80, DonorID: 1, NGOID: 2})
+
+	// As chaves privadas não ficam em claro no armazenamento
+	keys, err := store.ChainKeys.FindAll()
+	require.NoError(t, err)
+	require.NotEmpty(t, keys)
+	for _, stored := range keys {
+		der, err := x509.MarshalECPrivateKey(first.chainKeys[stored.Account])
+		require.NoError(t, err)
+		assert.NotContains(t, stored.EncryptedKey, hex.EncodeToString(der))
+	}
+
+	// Um novo serviço sobre o mesmo armazenamento simula o reinício da API
+	second, err := NewDonationServiceWithStore(store)
+	require.NoError(t, err)
+	adminSvc := NewAdminService(second, NewExpenseService(second))
+
+	result, err := adminSvc.AuditEntity(models.AuditRequest{EntityType: "donation", EntityID: donationID}, 1)
+	require.NoError(t, err)
+	assert.True(t, result.BlockchainValid, "O hash registrado antes do reinício deve continuar verificável")
+
+	result, err = adminSvc.AuditEntity(models.AuditRequest{EntityType: "ngo", EntityID: 2}, 1)
+	require.NoError(t, err)
+	for _, problem := range result.ValidationErrors {
+		assert.NotContains(t, problem, "Saldo da ONG")
+	}
+
+	// Novas doações continuam a mesma cadeia
+	next := completeDonation(t, second, models.DonationRequest{Amount: 10, DonorID: 2, NGOID: 2})
+	assert.True(t, second.blockchainHasTransaction(second.donations[next-1].TransactionHash, donationTransactionID(next)))
+	assert.True(t, second.blockchain.IsValid())
+
+	// Sem a chave de criptografia com que foram salvas, as chaves não são carregadas
+	utils.SetEncryptionKey("outra-chave-de-criptografia-com-32-caracteres")
+	t.Cleanup(func() { utils.SetEncryptionKey("") })
+	_, err = NewDonationServiceWithStore(store)
+	assert.ErrorIs(t, err, utils.ErrDecryptionFailed)
+}
+
+func TestAdminServiceConcurrentActions(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	const total = 50
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			_, err := adminSvc.RegisterNGO(models.NGORegistrationRequest{
+				Name: fmt.Sprintf("ONG %d", i), Description: "Teste", Category: "Saúde", CNPJ: fmt.Sprintf("cnpj-%d", i),
+				Email: "contato@ong.org", Phone: "1199999999", Address: "Rua A", State: "SP", ResponsibleID: 1,
+			})
+			assert.NoError(t, err)
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := adminSvc.AuditEntity(models.AuditRequest{EntityType: "ngo", EntityID: 1}, 1)
+			assert.NoError(t, err)
+			adminSvc.SearchAuditLogs(models.AuditLogQuery{})
+		}()
+		go func() {
+			defer wg.Done()
+			adminSvc.SetClock(RealClock{})
+			_, err := adminSvc.GetNGORegistrations(models.NGORegistrationQuery{})
+			assert.NoError(t, err)
+			adminSvc.GetPendingNGORegistrations()
+		}()
+	}
+	wg.Wait()
+
+	registrations, err := adminSvc.GetNGORegistrations(models.NGORegistrationQuery{PageSize: 100})
+	require.NoError(t, err)
+	assert.Equal(t, total, registrations.Total)
+	// Um registro de criação e uma auditoria por iteração
+	assert.Equal(t, 2*total, adminSvc.SearchAuditLogs(models.AuditLogQuery{}).Total)
+}
+
+func TestRefundDonation(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+	transparencySvc := NewTransparencyService(donationSvc, expenseSvc)
+	dashboardSvc := NewDashboardService(donationSvc, expenseSvc)
+
+	kept := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	refunded := completeDonation(t, donationSvc, models.DonationRequest{Amount: 40, Tip: 4, DonorID: 2, NGOID: 1})
+
+	// Doações que já financiam gastos não podem ser estornadas
+	_, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: kept, NGOID: 1, Amount: 10, Description: "Cestas", Category: "Alimentação"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, adminSvc.RefundDonation(kept, 3, "pedido do doador"), ErrDonationHasExpenses)
+
+	require.NoError(t, adminSvc.RefundDonation(refunded, 3, "pagamento contestado"))
+	assert.ErrorIs(t, adminSvc.RefundDonation(refunded, 3, "de novo"), ErrDonationNotRefundable)
+	assert.ErrorIs(t, adminSvc.RefundDonation(999, 3, "inexistente"), ErrDonationNotFound)
+
+	donations, err := donationSvc.GetDonationsByDonorID(2)
+	require.NoError(t, err)
+	require.Len(t, donations, 1)
+	assert.Equal(t, "refunded", donations[0].Status)
+	assert.Equal(t, "pagamento contestado", donations[0].RefundReason)
+	assert.True(t, donationSvc.blockchainHasTransaction(donations[0].RefundTransactionHash, refundTransactionID(refunded)),
+		"A transação reversa deve estar na blockchain")
+	assert.Zero(t, donationSvc.GetPlatformLedger().TotalTips, "A gorjeta também é devolvida")
+
+	summary, err := transparencySvc.GetNGOSummary(1)
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, summary.TotalReceived)
+	assert.Equal(t, 1, summary.DonationsCount)
+	assert.Equal(t, 100.0, dashboardSvc.GetGlobalDashboard().TotalDonated)
+
+	logs := adminSvc.GetAuditLogs()
+	require.NotEmpty(t, logs)
+	assert.Equal(t, models.AuditActionDonationRefunded, logs[len(logs)-1].Action)
+}
+
+func TestSuspendNGO(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+	transparencySvc := NewTransparencyService(donationSvc, expenseSvc)
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 60, DonorID: 1, NGOID: 2})
+
+	ngo, err := adminSvc.SuspendNGO(2, 7, "registro cassado")
+	require.NoError(t, err)
+	assert.Equal(t, models.NGOSuspended, ngo.Status)
+	assert.Equal(t, "registro cassado", ngo.SuspensionReason)
+
+	_, err = adminSvc.SuspendNGO(2, 7, "de novo")
+	assert.ErrorIs(t, err, ErrNGOAlreadySuspended)
+	_, err = adminSvc.SuspendNGO(99, 7, "inexistente")
+	assert.ErrorIs(t, err, ErrNGONotFound)
+
+	// Novas doações são recusadas, inclusive recorrentes
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 10, DonorID: 1, NGOID: 2})
+	assert.ErrorIs(t, err, ErrNGOSuspended)
+	_, err = donationSvc.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 10, DonorID: 1, NGOID: 2})
+	assert.ErrorIs(t, err, ErrNGOSuspended)
+
+	for _, listed := range donationSvc.GetNGOsAcceptingDonations() {
+		assert.NotEqual(t, uint(2), listed.ID, "ONGs suspensas não aparecem para novas doações")
+	}
+
+	// O histórico continua rastreável
+	summary, err := transparencySvc.GetNGOSummary(2)
+	require.NoError(t, err)
+	assert.Equal(t, 60.0, summary.TotalReceived)
+	assert.Len(t, donationSvc.GetAllNGOs(), 3)
+
+	logs := adminSvc.GetAuditLogs()
+	require.NotEmpty(t, logs)
+	assert.Equal(t, models.AuditActionNGOSuspended, logs[len(logs)-1].Action)
+	assert.Equal(t, uint(7), logs[len(logs)-1].AdminID)
+}
+
+func TestUpdateNGO(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	email, phone, state := "novo@saudeparatodos.org.br", " (21) 4444-0000 ", "ba"
+	ngo, err := adminSvc.UpdateNGO(2, models.NGOUpdateRequest{Email: &email, Phone: &phone, State: &state}, 7)
+	require.NoError(t, err)
+	assert.Equal(t, email, ngo.Email)
+	assert.Equal(t, "(21) 4444-0000", ngo.Phone)
+	assert.Equal(t, "BA", ngo.State)
+	assert.Equal(t, "Nordeste", ngo.Region)
+	assert.Equal(t, "Saúde para Todos", ngo.Name, "Campos omitidos não mudam")
+
+	stored, err := donationSvc.GetNGOByID(2)
+	require.NoError(t, err)
+	assert.Equal(t, ngo, stored, "O serviço de doações deve ver o perfil atualizado")
+
+	logs := adminSvc.GetAuditLogsByEntityID("ngo", 2)
+	require.Len(t, logs, 1)
+	assert.Equal(t, models.AuditActionNGOUpdated, logs[0].Action)
+	assert.Equal(t, uint(7), logs[0].AdminID)
+	assert.JSONEq(t, `{"email":"contato@saudeparatodos.org.br","phone":"(21) 3333-2002","state":"RJ","region":"Sudeste"}`, logs[0].PreviousState)
+	assert.JSONEq(t, `{"email":"novo@saudeparatodos.org.br","phone":"(21) 4444-0000","state":"BA","region":"Nordeste"}`, logs[0].NewState)
+
+	// Sem alterações efetivas, nada é registrado
+	_, err = adminSvc.UpdateNGO(2, models.NGOUpdateRequest{Email: &email}, 7)
+	require.NoError(t, err)
+	assert.Len(t, adminSvc.GetAuditLogsByEntityID("ngo", 2), 1)
+}
+
+func TestUpdateNGOValidation(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	cnpj, invalidEmail, blank, category := "11.222.333/0001-81", "sem-arroba", " ", "Astronomia"
+	_, err := adminSvc.UpdateNGO(1, models.NGOUpdateRequest{CNPJ: &cnpj}, 7)
+	assert.ErrorIs(t, err, ErrNGOCNPJImmutable)
+	_, err = adminSvc.UpdateNGO(1, models.NGOUpdateRequest{Email: &invalidEmail}, 7)
+	assert.ErrorIs(t, err, ErrInvalidEmail)
+	_, err = adminSvc.UpdateNGO(1, models.NGOUpdateRequest{Name: &blank}, 7)
+	assert.Error(t, err)
+	_, err = adminSvc.UpdateNGO(1, models.NGOUpdateRequest{Category: &category}, 7)
+	assert.ErrorIs(t, err, ErrInvalidNGOCategory)
+	_, err = adminSvc.UpdateNGO(99, models.NGOUpdateRequest{}, 7)
+	assert.ErrorIs(t, err, ErrNGONotFound)
+
+	require.NoError(t, adminSvc.MergeNGOs(1, 3, 7))
+	_, err = adminSvc.UpdateNGO(3, models.NGOUpdateRequest{Name: &category}, 7)
+	assert.ErrorIs(t, err, ErrNGOMerged)
+
+	ngo, err := donationSvc.GetNGOByID(1)
+	require.NoError(t, err)
+	assert.Equal(t, "contato@alimentandoesperanca.org.br", ngo.Email, "Atualizações rejeitadas não alteram a ONG")
+}
+
+func TestSearchAuditLogsCombinesFiltersAndPaginates(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	march := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	adminSvc.auditLogs = []models.AuditLog{
+		{ID: 1, AdminID: 7, Action: models.AuditActionNGOApproved, EntityType: "ngo", EntityID: 1, CreatedAt: march},
+		{ID: 2, AdminID: 7, Action: models.AuditActionExpenseApproved, EntityType: "expense", EntityID: 4, CreatedAt: march.Add(time.Hour)},
+		{ID: 3, AdminID: 8, Action: models.AuditActionNGOApproved, EntityType: "ngo", EntityID: 2, CreatedAt: march.Add(48 * time.Hour)},
+		{ID: 4, AdminID: 7, Action: models.AuditActionNGOApproved, EntityType: "ngo", EntityID: 3, CreatedAt: march.AddDate(0, 1, 0)},
+		{ID: 5, AdminID: 7, Action: models.AuditActionNGOApproved, EntityType: "ngo", EntityID: 5, CreatedAt: march},
+	}
+
+	// "Todas as aprovações de ONG em março", mais recentes primeiro
+	result := adminSvc.SearchAuditLogs(models.AuditLogQuery{
+		Action:    models.AuditActionNGOApproved,
+		StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC),
+	})
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, []uint{3, 5, 1}, auditLogIDs(result.Logs), "Empates no horário: o registrado por último vem antes")
+
+	// "Todas as ações do admin 7", paginadas
+	result = adminSvc.SearchAuditLogs(models.AuditLogQuery{AdminID: 7, Page: 2, PageSize: 2})
+	assert.Equal(t, 4, result.Total)
+	assert.Equal(t, []uint{5, 1}, auditLogIDs(result.Logs))
+
+	result = adminSvc.SearchAuditLogs(models.AuditLogQuery{AdminID: 7, EntityType: "expense"})
+	assert.Equal(t, []uint{2}, auditLogIDs(result.Logs))
+
+	result = adminSvc.SearchAuditLogs(models.AuditLogQuery{AdminID: 7, Page: 9})
+	assert.Equal(t, 4, result.Total)
+	assert.Empty(t, result.Logs)
+}
+
+func auditLogIDs(logs []models.AuditLog) []uint {
+	ids := make([]uint, len(logs))
+	for i, entry := range logs {
+		ids[i] = entry.ID
+	}
+	return ids
+}
+
+func TestNGORegistrationWorkQueue(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	register := func(name, cnpj string) models.NGORegistration {
+		registration, err := adminSvc.RegisterNGO(models.NGORegistrationRequest{
+			Name: name, Description: "Teste", Category: "Saúde", CNPJ: cnpj,
+			Email: "contato@ong.org", Phone: "1199999999", Address: "Rua A", State: "SP", ResponsibleID: 1,
+		})
+		require.NoError(t, err)
+		return registration
+	}
+	recent := register("Recente", "11.222.333/0001-81")
+	oldest := register("Mais antiga", "11.444.777/0001-61")
+	rejected := register("Rejeitada", "00.000.000/0001-91")
+
+	_, err := adminSvc.ValidateCNPJOnline(oldest.ID)
+	require.NoError(t, err)
+	_, err = adminSvc.RejectNGO(rejected.ID, 1, "documentação falsa")
+	require.NoError(t, err)
+
+	// A ordem de espera vem da data da solicitação, não do ID
+	adminSvc.ngoRegistrations[0].CreatedAt = time.Now().Add(-2 * time.Hour)
+	adminSvc.ngoRegistrations[1].CreatedAt = time.Now().Add(-50 * time.Hour)
+
+	queue := adminSvc.GetPendingNGORegistrations()
+	require.Len(t, queue, 2)
+	assert.Equal(t, []uint{oldest.ID, recent.ID}, []uint{queue[0].ID, queue[1].ID})
+	assert.Equal(t, models.NGOStatusValidating, queue[0].Status)
+	assert.InDelta(t, 50*3600, queue[0].AgeSeconds, 5)
+	assert.Equal(t, "50h0m0s", queue[0].Age)
+
+	byStatus, err := adminSvc.GetNGORegistrationsByStatus(models.NGOStatusRejected)
+	require.NoError(t, err)
+	require.Len(t, byStatus, 1)
+	assert.Equal(t, rejected.ID, byStatus[0].ID)
+
+	_, err = adminSvc.GetNGORegistrationsByStatus("arquivado")
+	assert.ErrorIs(t, err, ErrInvalidRegistrationStatus)
+}
+
+func TestGetNGORegistrationsPaginatesAndSorts(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	adminSvc.SetClock(ClockFunc(func() time.Time { return now }))
+	var ids []uint
+	for i := 1; i <= 5; i++ {
+		registration, err := adminSvc.RegisterNGO(models.NGORegistrationRequest{
+			Name: fmt.Sprintf("ONG %d", i), Description: "Teste", Category: "Saúde", CNPJ: fmt.Sprintf("11.222.333/000%d-81", i),
+			Email: "contato@ong.org", Phone: "1199999999", Address: "Rua A", State: "SP", ResponsibleID: 1,
+		})
+		require.NoError(t, err)
+		ids = append(ids, registration.ID)
+		now = now.Add(24 * time.Hour)
+	}
+	_, err := adminSvc.RejectNGO(ids[1], 1, "documentação falsa")
+	require.NoError(t, err)
+
+	pageIDs := func(result models.NGORegistrationListResult) []uint {
+		var page []uint
+		for _, reg := range result.Registrations {
+			page = append(page, reg.ID)
+		}
+		return page
+	}
+
+	// Mais recentes primeiro por padrão
+	first, err := adminSvc.GetNGORegistrations(models.NGORegistrationQuery{PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 5, first.Total)
+	assert.Equal(t, []uint{ids[4], ids[3]}, pageIDs(first))
+
+	last, err := adminSvc.GetNGORegistrations(models.NGORegistrationQuery{Page: 3, PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{ids[0]}, pageIDs(last))
+
+	beyond, err := adminSvc.GetNGORegistrations(models.NGORegistrationQuery{Page: 4, PageSize: 2})
+	require.NoError(t, err)
+	assert.Empty(t, beyond.Registrations)
+	assert.Equal(t, 5, beyond.Total)
+
+	oldest, err := adminSvc.GetNGORegistrations(models.NGORegistrationQuery{Sort: models.RegistrationSortDateAsc, PageSize: 2, Page: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{ids[2], ids[3]}, pageIDs(oldest))
+
+	// Os filtros são aplicados antes da paginação
+	pending, err := adminSvc.GetNGORegistrations(models.NGORegistrationQuery{Status: models.NGOStatusPending, PageSize: 3, Page: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 4, pending.Total)
+	assert.Equal(t, []uint{ids[0]}, pageIDs(pending))
+
+	byCNPJ, err := adminSvc.GetNGORegistrations(models.NGORegistrationQuery{Status: models.NGOStatusRejected, CNPJ: "11.222.333/0002-81"})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{ids[1]}, pageIDs(byCNPJ))
+
+	_, err = adminSvc.GetNGORegistrations(models.NGORegistrationQuery{Sort: "name"})
+	assert.ErrorIs(t, err, ErrInvalidRegistrationSort)
+	_, err = adminSvc.GetNGORegistrations(models.NGORegistrationQuery{Status: "arquivado"})
+	assert.ErrorIs(t, err, ErrInvalidRegistrationStatus)
+}
+
+func TestRejectNGOKeepsReasonInAuditComments(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	registration, err := adminSvc.RegisterNGO(models.NGORegistrationRequest{
+		Name: "ONG Duvidosa", Description: "Teste", Category: "Saúde", CNPJ: "11.222.333/0001-81",
+		Email: "contato@duvidosa.org", Phone: "1199999999", Address: "Rua B", State: "SP", ResponsibleID: 1,
+	})
+	require.NoError(t, err)
+
+	_, err = adminSvc.RejectNGO(registration.ID, 3, "CNPJ suspeito")
+	require.NoError(t, err)
+
+	logs := adminSvc.SearchAuditLogs(models.AuditLogQuery{Action: models.AuditActionNGORejected}).Logs
+	require.Len(t, logs, 1)
+	assert.Contains(t, logs[0].Comments, "CNPJ suspeito")
+	assert.Equal(t, string(models.NGOStatusRejected), logs[0].NewState, "O novo estado não é sobrescrito pelo comentário")
+}
+
+func TestRegisterNGORejectsUnknownState(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	_, err := adminSvc.RegisterNGO(models.NGORegistrationRequest{
+		Name: "ONG Sem UF", Description: "Teste", Category: "Saúde", CNPJ: "11.222.333/0001-81",
+		Email: "contato@semuf.org", Phone: "1199999999", Address: "Rua D", State: "XX", ResponsibleID: 1,
+	})
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestRegisterNGOValidatesCategory(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+	req := models.NGORegistrationRequest{
+		Name: "ONG Verde", Description: "Teste", Category: "Reciclagem", CNPJ: "11.222.333/0001-81",
+		Email: "contato@verde.org", Phone: "1199999999", Address: "Rua E", State: "PR", ResponsibleID: 1,
+	}
+
+	_, err := adminSvc.RegisterNGO(req)
+	assert.ErrorIs(t, err, ErrInvalidNGOCategory)
+	assert.Contains(t, err.Error(), "Meio Ambiente", "A mensagem lista as categorias válidas")
+
+	req.Category = "  meio   AMBIENTE "
+	registration, err := adminSvc.RegisterNGO(req)
+	require.NoError(t, err)
+	assert.Equal(t, "Meio Ambiente", registration.Category)
+
+	req.CNPJ = "11.444.777/0001-61"
+	req.Category = "assistencia social"
+	registration, err = adminSvc.RegisterNGO(req)
+	require.NoError(t, err)
+	assert.Equal(t, "Assistência Social", registration.Category, "Acentos são ignorados na comparação e restaurados na gravação")
+}
+
+func TestListDonationsIncludesEveryStatus(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	completedID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	refundedID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 40, DonorID: 2, NGOID: 1})
+	require.NoError(t, adminSvc.RefundDonation(refundedID, 3, "Cobrança em duplicidade"))
+	pending, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 10, DonorID: 1, NGOID: 2})
+	require.NoError(t, err)
+
+	all, err := adminSvc.ListDonations(models.DonationFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, all.Total)
+	assert.Equal(t, []uint{pending.ID, refundedID, completedID},
+		[]uint{all.Donations[0].ID, all.Donations[1].ID, all.Donations[2].ID}, "Mais recentes primeiro")
+
+	refunded, err := adminSvc.ListDonations(models.DonationFilter{Status: "refunded"})
+	require.NoError(t, err)
+	require.Len(t, refunded.Donations, 1)
+	assert.Equal(t, refundedID, refunded.Donations[0].ID)
+
+	filtered, err := adminSvc.ListDonations(models.DonationFilter{DonorID: 1, NGOID: 1, MinAmount: 50})
+	require.NoError(t, err)
+	require.Len(t, filtered.Donations, 1)
+	assert.Equal(t, completedID, filtered.Donations[0].ID)
+
+	paged, err := adminSvc.ListDonations(models.DonationFilter{Page: 2, PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, paged.Total)
+	require.Len(t, paged.Donations, 1)
+	assert.Equal(t, completedID, paged.Donations[0].ID)
+
+	_, err = adminSvc.ListDonations(models.DonationFilter{MinAmount: 100, MaxAmount: 10})
+	assert.ErrorIs(t, err, ErrInvalidAmountRange)
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"trackable-donations/api/internal/models"
+)
+
+var (
+	// ErrCampaignNotFound indica uma campanha inexistente
+	ErrCampaignNotFound = errors.New("campanha não encontrada")
+	// ErrCampaignNGOMismatch indica uma doação atribuída a uma campanha de outra ONG
+	ErrCampaignNGOMismatch = errors.New("a campanha não pertence à ONG da doação")
+	// ErrCampaignNameTaken indica que a ONG já tem uma campanha com o mesmo nome
+	ErrCampaignNameTaken = errors.New("a ONG já tem uma campanha com este nome")
+	// ErrCampaignNameRequired indica uma campanha sem nome
+	ErrCampaignNameRequired = errors.New("o nome da campanha é obrigatório")
+)
+
+// Tamanho da página das doações de uma campanha: padrão e máximo por consulta
+const (
+	defaultCampaignDonationsPageSize = 20
+	maxCampaignDonationsPageSize     = 100
+)
+
+// CampaignService reúne as operações sobre as campanhas das ONGs. As campanhas ficam no
+// DonationService, que as persiste e as confere ao registrar doações (ver checkCampaign).
+type CampaignService struct {
+	donationService *DonationService
+}
+
+// NewCampaignService cria uma nova instância do serviço de campanhas
+func NewCampaignService(donationSvc *DonationService) *CampaignService {
+	return &CampaignService{donationService: donationSvc}
+}
+
+// CreateCampaign cadastra uma campanha da ONG. Os nomes são únicos dentro da ONG, sem
+// diferenciar maiúsculas de minúsculas; ONGs mescladas não recebem novas campanhas.
+func (s *CampaignService) CreateCampaign(ngoID uint, req models.CampaignRequest) (models.Campaign, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return models.Campaign{}, ErrCampaignNameRequired
+	}
+
+	s.donationService.mu.Lock()
+	defer s.donationService.mu.Unlock()
+
+	ngo, err := s.donationService.findNGO(ngoID)
+	if err != nil {
+		return models.Campaign{}, err
+	}
+	if ngo.Status == models.NGOMerged {
+		return models.Campaign{}, ErrNGOMerged
+	}
+	for _, campaign := range s.donationService.campaigns {
+		if campaign.NGOID == ngoID && strings.EqualFold(campaign.Name, name) {
+			return models.Campaign{}, ErrCampaignNameTaken
+		}
+	}
+
+	campaign := models.Campaign{
+		NGOID:       ngoID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Goal:        req.Goal,
+		CreatedAt:   s.donationService.clock.Now(),
+	}
+	if err := s.donationService.store.Campaigns.Create(&campaign); err != nil {
+		return models.Campaign{}, fmt.Errorf("falha ao salvar a campanha: %w", err)
+	}
+	s.donationService.campaigns = append(s.donationService.campaigns, campaign)
+	return campaign, nil
+}
+
+// ListCampaigns retorna as campanhas da ONG, da mais antiga para a mais recente
+func (s *CampaignService) ListCampaigns(ngoID uint) ([]models.Campaign, error) {
+	s.donationService.mu.RLock()
+	defer s.donationService.mu.RUnlock()
+
+	if _, err := s.donationService.findNGO(ngoID); err != nil {
+		return nil, err
+	}
+	return s.ngoCampaigns(ngoID), nil
+}
+
+// ngoCampaigns retorna as campanhas da ONG ordenadas pelo ID. Deve ser chamado com
+// s.donationService.mu bloqueado.
+func (s *CampaignService) ngoCampaigns(ngoID uint) []models.Campaign {
+	campaigns := []models.Campaign{}
+	for _, campaign := range s.donationService.campaigns {
+		if campaign.NGOID == ngoID {
+			campaigns = append(campaigns, campaign)
+		}
+	}
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].ID < campaigns[j].ID
+	})
+	return campaigns
+}
+
+// GetNGOCampaignDashboard soma as doações concluídas da ONG por campanha, incluindo as
+// campanhas ainda sem doações, da maior para a menor arrecadação; as doações sem campanha
+// são totalizadas à parte
+func (s *CampaignService) GetNGOCampaignDashboard(ngoID uint) (models.NGOCampaignDashboard, error) {
+	s.donationService.mu.RLock()
+	_, err := s.donationService.findNGO(ngoID)
+	campaigns := s.ngoCampaigns(ngoID)
+	s.donationService.mu.RUnlock()
+	if err != nil {
+		return models.NGOCampaignDashboard{}, err
+	}
+
+	dashboard := models.NGOCampaignDashboard{NGOID: ngoID, Campaigns: make([]models.CampaignSummary, len(campaigns))}
+	byID := make(map[uint]*models.CampaignSummary, len(campaigns))
+	for i, campaign := range campaigns {
+		dashboard.Campaigns[i] = models.CampaignSummary{CampaignID: campaign.ID, CampaignName: campaign.Name}
+		byID[campaign.ID] = &dashboard.Campaigns[i]
+	}
+
+	for _, donation := range s.donationService.snapshotDonations() {
+		if donation.NGOID != ngoID || donation.Status != "completed" {
+			continue
+		}
+		if summary, ok := byID[donation.CampaignID]; ok {
+			summary.TotalAmount += donation.Amount
+			summary.Count++
+			continue
+		}
+		dashboard.UnattributedAmount += donation.Amount
+		dashboard.UnattributedCount++
+	}
+
+	for i := range dashboard.Campaigns {
+		dashboard.Campaigns[i].TotalAmount = roundTwoDecimals(dashboard.Campaigns[i].TotalAmount)
+	}
+	dashboard.UnattributedAmount = roundTwoDecimals(dashboard.UnattributedAmount)
+
+	// Desempate pelo ID, para um resultado estável
+	sort.SliceStable(dashboard.Campaigns, func(i, j int) bool {
+		return dashboard.Campaigns[i].TotalAmount > dashboard.Campaigns[j].TotalAmount
+	})
+	return dashboard, nil
+}
+
+// GetCampaignDonations retorna uma página das doações concluídas da campanha, da mais recente
+// para a mais antiga, com o nome do doador oculto quando ele não optou por aparecer, e o
+// andamento da arrecadação. Doações estornadas ficam fora da lista e do total arrecadado.
+func (s *CampaignService) GetCampaignDonations(campaignID uint, page, pageSize int) (models.CampaignDonationsResult, error) {
+	s.donationService.mu.RLock()
+	campaign, found := models.Campaign{}, false
+	for _, c := range s.donationService.campaigns {
+		if c.ID == campaignID {
+			campaign, found = c, true
+			break
+		}
+	}
+	s.donationService.mu.RUnlock()
+	if !found {
+		return models.CampaignDonationsResult{}, ErrCampaignNotFound
+	}
+
+	result := models.CampaignDonationsResult{
+		Campaign:  campaign,
+		Progress:  models.CampaignProgress{Goal: campaign.Goal},
+		Donations: []models.CampaignDonation{},
+		Page:      page,
+		PageSize:  pageSize,
+	}
+	if result.Page <= 0 {
+		result.Page = 1
+	}
+	if result.PageSize <= 0 {
+		result.PageSize = defaultCampaignDonationsPageSize
+	}
+	if result.PageSize > maxCampaignDonationsPageSize {
+		result.PageSize = maxCampaignDonationsPageSize
+	}
+
+	var donations []models.Donation
+	for _, donation := range s.donationService.snapshotDonations() {
+		if donation.CampaignID == campaignID && donation.Status == "completed" {
+			donations = append(donations, donation)
+			result.Progress.Raised += donation.Amount
+		}
+	}
+	result.Progress.Raised = roundTwoDecimals(result.Progress.Raised)
+	result.Progress.DonationsCount = len(donations)
+	if campaign.Goal > 0 {
+		result.Progress.Percentage = roundTwoDecimals(result.Progress.Raised / campaign.Goal * 100)
+	}
+	result.Total = len(donations)
+
+	sort.SliceStable(donations, func(i, j int) bool {
+		return donations[i].CreatedAt.After(donations[j].CreatedAt)
+	})
+
+	users := s.donationService.snapshotUserIndex()
+	if start := (result.Page - 1) * result.PageSize; start < len(donations) {
+		for _, donation := range donations[start:min(start+result.PageSize, len(donations))] {
+			result.Donations = append(result.Donations, models.CampaignDonation{
+				ID:              donation.ID,
+				Amount:          donation.Amount,
+				DonorName:       publicDonorName(donation, users[donation.DonorID]),
+				Date:            donation.CreatedAt,
+				TransactionHash: donation.TransactionHash,
+			})
+		}
+	}
+	return result, nil
+}
+
+// checkCampaign verifica se a campanha existe e pertence à ONG, ao registrar uma doação.
+// Deve ser chamado com s.mu bloqueado.
+func (s *DonationService) checkCampaign(ngoID, campaignID uint) error {
+	for _, campaign := range s.campaigns {
+		if campaign.ID != campaignID {
+			continue
+		}
+		if campaign.NGOID != ngoID {
+			return ErrCampaignNGOMismatch
+		}
+		return nil
+	}
+	return ErrCampaignNotFound
+}
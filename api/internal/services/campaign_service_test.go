@@ -0,0 +1,175 @@
+package services
+
+import (
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCampaignRequiresUniqueNamePerNGO(t *testing.T) {
+	svc := NewCampaignService(NewDonationService())
+
+	campaign, err := svc.CreateCampaign(1, models.CampaignRequest{Name: " Natal Solidário ", Description: "Cestas de Natal"})
+	require.NoError(t, err)
+	assert.Equal(t, "Natal Solidário", campaign.Name)
+	assert.Equal(t, uint(1), campaign.NGOID)
+
+	_, err = svc.CreateCampaign(1, models.CampaignRequest{Name: "natal solidário"})
+	assert.ErrorIs(t, err, ErrCampaignNameTaken)
+
+	// Outra ONG pode usar o mesmo nome
+	_, err = svc.CreateCampaign(2, models.CampaignRequest{Name: "Natal Solidário"})
+	require.NoError(t, err)
+
+	_, err = svc.CreateCampaign(1, models.CampaignRequest{Name: "   "})
+	assert.ErrorIs(t, err, ErrCampaignNameRequired)
+	_, err = svc.CreateCampaign(9999, models.CampaignRequest{Name: "Inverno"})
+	assert.ErrorIs(t, err, ErrNGONotFound)
+
+	campaigns, err := svc.ListCampaigns(1)
+	require.NoError(t, err)
+	require.Len(t, campaigns, 1)
+	assert.Equal(t, campaign.ID, campaigns[0].ID)
+}
+
+func TestDonationCampaignMustBelongToNGO(t *testing.T) {
+	svc := NewDonationService()
+	campaign, err := NewCampaignService(svc).CreateCampaign(1, models.CampaignRequest{Name: "Inverno"})
+	require.NoError(t, err)
+
+	_, err = svc.ProcessDonation(models.DonationRequest{Amount: 50, DonorID: 1, NGOID: 2, CampaignID: campaign.ID})
+	assert.ErrorIs(t, err, ErrCampaignNGOMismatch)
+	_, err = svc.ProcessDonation(models.DonationRequest{Amount: 50, DonorID: 1, NGOID: 1, CampaignID: 9999})
+	assert.ErrorIs(t, err, ErrCampaignNotFound)
+
+	id := completeDonation(t, svc, models.DonationRequest{Amount: 50, DonorID: 1, NGOID: 1, CampaignID: campaign.ID})
+	donations, err := svc.GetDonationsByDonorID(1)
+	require.NoError(t, err)
+	require.NotEmpty(t, donations)
+	last := donations[len(donations)-1]
+	assert.Equal(t, id, last.ID)
+	assert.Equal(t, campaign.ID, last.CampaignID)
+}
+
+func TestNGOCampaignDashboardTotals(t *testing.T) {
+	donationSvc := NewDonationService()
+	campaignSvc := NewCampaignService(donationSvc)
+
+	winter, err := campaignSvc.CreateCampaign(1, models.CampaignRequest{Name: "Inverno"})
+	require.NoError(t, err)
+	christmas, err := campaignSvc.CreateCampaign(1, models.CampaignRequest{Name: "Natal"})
+	require.NoError(t, err)
+	empty, err := campaignSvc.CreateCampaign(1, models.CampaignRequest{Name: "Páscoa"})
+	require.NoError(t, err)
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 100.10, DonorID: 1, NGOID: 1, CampaignID: winter.ID})
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 200.20, DonorID: 2, NGOID: 1, CampaignID: winter.ID})
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 400, DonorID: 1, NGOID: 1, CampaignID: christmas.ID})
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 30, DonorID: 1, NGOID: 1})
+	// Pendentes e de outras ONGs não entram nos totais
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 999, DonorID: 1, NGOID: 1, CampaignID: winter.ID})
+	require.NoError(t, err)
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 70, DonorID: 1, NGOID: 2})
+
+	dashboard, err := campaignSvc.GetNGOCampaignDashboard(1)
+	require.NoError(t, err)
+	assert.Equal(t, []models.CampaignSummary{
+		{CampaignID: christmas.ID, CampaignName: "Natal", TotalAmount: 400, Count: 1},
+		{CampaignID: winter.ID, CampaignName: "Inverno", TotalAmount: 300.3, Count: 2},
+		{CampaignID: empty.ID, CampaignName: "Páscoa"},
+	}, dashboard.Campaigns)
+	assert.Equal(t, 30.0, dashboard.UnattributedAmount)
+	assert.Equal(t, 1, dashboard.UnattributedCount)
+
+	_, err = campaignSvc.GetNGOCampaignDashboard(9999)
+	assert.ErrorIs(t, err, ErrNGONotFound)
+}
+
+func TestPublicViewsFilterByCampaign(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	transparencySvc := NewTransparencyService(donationSvc, expenseSvc)
+	explorerSvc := NewExplorerService(donationSvc, expenseSvc)
+
+	campaign, err := NewCampaignService(donationSvc).CreateCampaign(1, models.CampaignRequest{Name: "Inverno"})
+	require.NoError(t, err)
+	inCampaign := completeDonation(t, donationSvc, models.DonationRequest{Amount: 80, DonorID: 1, NGOID: 1, CampaignID: campaign.ID})
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 20, DonorID: 1, NGOID: 1})
+
+	donations := transparencySvc.GetPublicDonations(campaign.ID)
+	require.Len(t, donations, 1)
+	assert.Equal(t, inCampaign, donations[0].ID)
+	assert.Equal(t, campaign.ID, donations[0].CampaignID)
+	assert.Len(t, transparencySvc.GetPublicDonations(0), 2)
+
+	ngoDonations, err := transparencySvc.GetDonationsByNGO(1, campaign.ID)
+	require.NoError(t, err)
+	require.Len(t, ngoDonations, 1)
+	assert.Equal(t, inCampaign, ngoDonations[0].ID)
+
+	result, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{CampaignID: campaign.ID})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Total)
+	assert.Equal(t, inCampaign, result.Donations[0].ID)
+	assert.Equal(t, campaign.ID, result.Donations[0].CampaignID)
+}
+
+func TestMergeNGOsMovesCampaigns(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+	campaignSvc := NewCampaignService(donationSvc)
+
+	campaign, err := campaignSvc.CreateCampaign(2, models.CampaignRequest{Name: "Inverno"})
+	require.NoError(t, err)
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 60, DonorID: 1, NGOID: 2, CampaignID: campaign.ID})
+
+	require.NoError(t, adminSvc.MergeNGOs(1, 2, 7))
+
+	campaigns, err := campaignSvc.ListCampaigns(1)
+	require.NoError(t, err)
+	require.Len(t, campaigns, 1)
+	assert.Equal(t, campaign.ID, campaigns[0].ID)
+
+	// A campanha continua aceitando doações, agora para a ONG canônica
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 10, DonorID: 1, NGOID: 1, CampaignID: campaign.ID})
+	assert.NoError(t, err)
+}
+
+func TestGetCampaignDonationsExcludesRefunds(t *testing.T) {
+	donationSvc := NewDonationService()
+	campaignSvc := NewCampaignService(donationSvc)
+
+	campaign, err := campaignSvc.CreateCampaign(1, models.CampaignRequest{Name: "Inverno", Goal: 1000})
+	require.NoError(t, err)
+	for _, amount := range []float64{100, 250.5, 40} {
+		completeDonation(t, donationSvc, models.DonationRequest{Amount: amount, DonorID: 1, NGOID: 1, CampaignID: campaign.ID})
+	}
+	refunded := completeDonation(t, donationSvc, models.DonationRequest{Amount: 500, DonorID: 2, NGOID: 1, CampaignID: campaign.ID})
+	require.NoError(t, NewExpenseService(donationSvc).RefundDonation(refunded, "pagamento contestado"))
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 70, DonorID: 1, NGOID: 1, CampaignID: campaign.ID})
+	require.NoError(t, err)
+
+	result, err := campaignSvc.GetCampaignDonations(campaign.ID, 1, 100)
+	require.NoError(t, err)
+	require.Len(t, result.Donations, 3)
+	listed := 0.0
+	for _, donation := range result.Donations {
+		assert.NotEqual(t, refunded, donation.ID)
+		assert.Equal(t, models.AnonymousDonorName, donation.DonorName)
+		listed += donation.Amount
+	}
+	assert.Equal(t, listed, result.Progress.Raised)
+	assert.Equal(t, 3, result.Progress.DonationsCount)
+	assert.Equal(t, 39.05, result.Progress.Percentage)
+
+	page, err := campaignSvc.GetCampaignDonations(campaign.ID, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	assert.Len(t, page.Donations, 1)
+
+	_, err = campaignSvc.GetCampaignDonations(9999, 1, 10)
+	assert.ErrorIs(t, err, ErrCampaignNotFound)
+}
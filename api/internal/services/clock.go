@@ -0,0 +1,23 @@
+package services
+
+import "time"
+
+// Clock informa o horário atual aos serviços; os testes usam um relógio fixo para
+// verificar de forma determinística a lógica que depende de datas
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock é o relógio do sistema, usado por padrão
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// ClockFunc adapta uma função ao Clock (ex.: um horário fixo nos testes)
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServicesUseInjectedClock(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+	dashboardSvc := NewDashboardService(donationSvc, expenseSvc)
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	clock := ClockFunc(func() time.Time { return now })
+	donationSvc.SetClock(clock)
+	expenseSvc.SetClock(clock)
+	adminSvc.SetClock(clock)
+	dashboardSvc.SetClock(clock)
+
+	march := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	now = time.Date(2024, 4, 2, 9, 30, 0, 0, time.UTC)
+	april := completeDonation(t, donationSvc, models.DonationRequest{Amount: 40, DonorID: 2, NGOID: 1})
+
+	donation, _ := donationSvc.findDonation(march)
+	assert.Equal(t, time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC), donation.CreatedAt)
+	donation, _ = donationSvc.findDonation(april)
+	assert.Equal(t, now, donation.CreatedAt)
+
+	monthly := dashboardSvc.GetGlobalDashboard().MonthlyDonations
+	require.Len(t, monthly, 2)
+	assert.Equal(t, models.MonthlyDonationData{Month: "Março", MonthNumber: 3, Year: 2024, TotalAmount: 100, Count: 1}, monthly[0])
+	assert.Equal(t, models.MonthlyDonationData{Month: "Abril", MonthNumber: 4, Year: 2024, TotalAmount: 40, Count: 1}, monthly[1])
+
+	expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: march, NGOID: 1, Amount: 30, Description: "Cestas básicas", Category: "Alimentação"})
+	require.NoError(t, err)
+	assert.Equal(t, now, expense.CreatedAt)
+
+	_, err = adminSvc.SuspendNGO(2, 1, "Documentação vencida")
+	require.NoError(t, err)
+	logs := adminSvc.GetAuditLogs()
+	require.NotEmpty(t, logs)
+	assert.Equal(t, now, logs[len(logs)-1].CreatedAt)
+}
@@ -4,14 +4,28 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"sync"
 	"time"
 	"trackable-donations/api/internal/models"
 )
 
+// DefaultDashboardCacheTTL é o tempo padrão durante o qual o dashboard global calculado é reaproveitado
+const DefaultDashboardCacheTTL = time.Minute
+
 // DashboardService gerencia as operações relacionadas ao dashboard global
 type DashboardService struct {
 	donationService *DonationService
 	expenseService  *ExpenseService
+
+	// O dashboard global fica em cache por cacheTTL ou até mudar a versão dos agregados
+	// do serviço de doações (ex.: uma nova doação concluída)
+	cacheMu       sync.Mutex
+	cacheTTL      time.Duration
+	cache         *models.GlobalDashboardData
+	cachedAt      time.Time
+	cachedVersion uint64
+	// clock fornece o horário usado na validade do cache (ver SetClock)
+	clock Clock
 }
 
 // NewDashboardService cria uma nova instância do serviço de dashboard
@@ -19,17 +33,58 @@ func NewDashboardService(donationSvc *DonationService, expenseSvc *ExpenseServic
 	return &DashboardService{
 		donationService: donationSvc,
 		expenseService:  expenseSvc,
+		cacheTTL:        DefaultDashboardCacheTTL,
+		clock:           RealClock{},
 	}
 }
 
-// GetGlobalDashboard obtém os dados para o dashboard global
+// SetClock define o relógio usado pelo serviço
+func (s *DashboardService) SetClock(clock Clock) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	s.clock = clock
+}
+
+// SetCacheTTL define por quanto tempo o dashboard global é reaproveitado (0 = sem cache)
+func (s *DashboardService) SetCacheTTL(ttl time.Duration) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	s.cacheTTL = ttl
+	s.cache = nil
+}
+
+// GetGlobalDashboard obtém os dados para o dashboard global. O resultado fica em cache
+// pelo tempo configurado e é recalculado antes disso quando uma doação é concluída ou
+// estornada; as listas retornadas são compartilhadas entre as chamadas e não devem ser alteradas.
 func (s *DashboardService) GetGlobalDashboard() models.GlobalDashboardData {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	// A versão é lida antes do cálculo: uma alteração concorrente invalida o resultado
+	version := s.donationService.aggregatesVersion.Load()
+	now := s.clock.Now()
+	if s.cache != nil && s.cachedVersion == version && now.Sub(s.cachedAt) < s.cacheTTL {
+		return *s.cache
+	}
+
+	dashboard := s.computeGlobalDashboard()
+	if s.cacheTTL > 0 {
+		s.cache = &dashboard
+		s.cachedAt = now
+		s.cachedVersion = version
+	}
+	return dashboard
+}
+
+// computeGlobalDashboard calcula o dashboard global a partir de todas as doações concluídas
+func (s *DashboardService) computeGlobalDashboard() models.GlobalDashboardData {
 	dashboard := models.GlobalDashboardData{}
+	ngos := s.donationService.snapshotNGOIndex()
 
 	// Filtrar apenas doações completadas
 	var completedDonations []models.Donation
 	donorMap := make(map[uint]struct{}) // Para contar doadores únicos
-	for _, donation := range s.donationService.donations {
+	for _, donation := range s.donationService.snapshotDonations() {
 		if donation.Status == "completed" {
 			completedDonations = append(completedDonations, donation)
 			donorMap[donation.DonorID] = struct{}{}
@@ -40,19 +95,19 @@ func (s *DashboardService) GetGlobalDashboard() models.GlobalDashboardData {
 	// Calcular totais
 	dashboard.TotalTransactions = len(completedDonations)
 	dashboard.TotalDonors = len(donorMap)
-	dashboard.TotalNGOs = len(s.donationService.ngos)
+	dashboard.TotalNGOs = len(s.donationService.GetAllNGOs())
 
 	// Calcular doações por categoria
-	dashboard.DonationsByCategory = s.calculateDonationsByCategory(completedDonations)
+	dashboard.DonationsByCategory = s.calculateDonationsByCategory(completedDonations, ngos)
 
 	// Calcular doações mensais
 	dashboard.MonthlyDonations = s.calculateMonthlyDonations(completedDonations)
 
 	// Calcular top ONGs
-	dashboard.TopNGOs = s.calculateTopNGOs(completedDonations, 5)
+	dashboard.TopNGOs = s.calculateTopNGOs(completedDonations, ngos, 5)
 
-	// Gerar dados geográficos simulados
-	dashboard.GeographicalData = s.generateGeographicalData()
+	// Calcular doações por região
+	dashboard.GeographicalData = s.calculateGeographicalData(completedDonations, ngos)
 
 	// Calcular métricas de impacto
 	dashboard.ImpactMetrics = s.calculateImpactMetrics(dashboard.TotalDonated)
@@ -60,13 +115,14 @@ func (s *DashboardService) GetGlobalDashboard() models.GlobalDashboardData {
 	return dashboard
 }
 
-// calculateDonationsByCategory calcula as doações por categoria
-func (s *DashboardService) calculateDonationsByCategory(donations []models.Donation) []models.CategorySummary {
+// calculateDonationsByCategory calcula as doações por categoria; ngos indexa as ONGs pelo ID
+// (ver snapshotNGOIndex)
+func (s *DashboardService) calculateDonationsByCategory(donations []models.Donation, ngos map[uint]models.NGO) []models.CategorySummary {
 	categoryMap := make(map[string]models.CategorySummary)
 
 	for _, donation := range donations {
-		ngo, err := s.donationService.GetNGOByID(donation.NGOID)
-		if err != nil {
+		ngo, ok := ngos[donation.NGOID]
+		if !ok {
 			continue
 		}
 
@@ -93,17 +149,25 @@ func (s *DashboardService) calculateDonationsByCategory(donations []models.Donat
 		totalAmount += summary.TotalAmount
 	}
 
-	// Calcular percentagens
+	// Ordenar por valor total (maior primeiro), com desempate por nome para um resultado estável
+	sort.Slice(categorySummaries, func(i, j int) bool {
+		if categorySummaries[i].TotalAmount != categorySummaries[j].TotalAmount {
+			return categorySummaries[i].TotalAmount > categorySummaries[j].TotalAmount
+		}
+		return categorySummaries[i].Category < categorySummaries[j].Category
+	})
+
+	// Calcular percentagens (0 a 100, com duas casas decimais)
 	if totalAmount > 0 {
+		sum := 0.0
 		for i := range categorySummaries {
-			categorySummaries[i].Percentage = math.Round((categorySummaries[i].TotalAmount/totalAmount)*100) / 100
+			categorySummaries[i].Percentage = roundTwoDecimals(categorySummaries[i].TotalAmount / totalAmount * 100)
+			sum += categorySummaries[i].Percentage
 		}
-	}
 
-	// Ordenar por valor total (maior primeiro)
-	sort.Slice(categorySummaries, func(i, j int) bool {
-		return categorySummaries[i].TotalAmount > categorySummaries[j].TotalAmount
-	})
+		// O resíduo do arredondamento vai para a maior categoria, para que a soma feche em 100
+		categorySummaries[0].Percentage = roundTwoDecimals(categorySummaries[0].Percentage + 100 - sum)
+	}
 
 	return categorySummaries
 }
@@ -127,6 +191,7 @@ func (s *DashboardService) calculateMonthlyDonations(donations []models.Donation
 		} else {
 			data = models.MonthlyDonationData{
 				Month:       monthName,
+				MonthNumber: int(donation.CreatedAt.Month()),
 				Year:        donation.CreatedAt.Year(),
 				TotalAmount: donation.Amount,
 				Count:       1,
@@ -146,8 +211,7 @@ func (s *DashboardService) calculateMonthlyDonations(donations []models.Donation
 		if monthlyData[i].Year != monthlyData[j].Year {
 			return monthlyData[i].Year < monthlyData[j].Year
 		}
-		// Compare month names (not ideal, but works for our simulated data)
-		return s.getMonthIndex(monthlyData[i].Month) < s.getMonthIndex(monthlyData[j].Month)
+		return monthlyData[i].MonthNumber < monthlyData[j].MonthNumber
 	})
 
 	return monthlyData
@@ -165,24 +229,14 @@ func (s *DashboardService) getMonthName(month int) string {
 	return ""
 }
 
-// getMonthIndex retorna o índice do mês a partir do nome
-func (s *DashboardService) getMonthIndex(monthName string) int {
-	months := map[string]int{
-		"Janeiro": 1, "Fevereiro": 2, "Março": 3, "Abril": 4,
-		"Maio": 5, "Junho": 6, "Julho": 7, "Agosto": 8,
-		"Setembro": 9, "Outubro": 10, "Novembro": 11, "Dezembro": 12,
-	}
-	return months[monthName]
-}
-
 // calculateTopNGOs calcula as ONGs com mais doações
-func (s *DashboardService) calculateTopNGOs(donations []models.Donation, limit int) []models.NGODonationSummary {
+func (s *DashboardService) calculateTopNGOs(donations []models.Donation, ngos map[uint]models.NGO, limit int) []models.NGODonationSummary {
 	ngoMap := make(map[uint]models.NGODonationSummary)
 
 	// Processar cada doação
 	for _, donation := range donations {
-		ngo, err := s.donationService.GetNGOByID(donation.NGOID)
-		if err != nil {
+		ngo, ok := ngos[donation.NGOID]
+		if !ok {
 			continue
 		}
 
@@ -221,42 +275,42 @@ func (s *DashboardService) calculateTopNGOs(donations []models.Donation, limit i
 	return ngoSummaries
 }
 
-// generateGeographicalData gera dados geográficos simulados
-func (s *DashboardService) generateGeographicalData() []models.GeographicalDonationData {
-	// Em um sistema real, estes dados viriam do banco de dados
-	// Aqui estamos simulando com regiões do Brasil
-	regions := []string{
-		"Norte", "Nordeste", "Centro-Oeste", "Sudeste", "Sul",
-	}
-
-	// Criar dados simulados
-	var geoData []models.GeographicalDonationData
-	totalDonations := float64(0)
-
-	// Contabilizar doações totais para calcular proporções realistas
-	for _, donation := range s.donationService.donations {
-		if donation.Status == "completed" {
-			totalDonations += donation.Amount
+// calculateGeographicalData soma as doações concluídas pela região da ONG que as recebeu.
+// Todas as regiões aparecem, mesmo sem doações; ONGs sem UF cadastrada ficam em "Não informada".
+func (s *DashboardService) calculateGeographicalData(donations []models.Donation, ngos map[uint]models.NGO) []models.GeographicalDonationData {
+	byRegion := make(map[string]models.GeographicalDonationData)
+	for _, donation := range donations {
+		region := unknownRegion
+		if ngo, ok := ngos[donation.NGOID]; ok && ngo.Region != "" {
+			region = ngo.Region
 		}
-	}
 
-	// Distribuir proporcionalmente com base em uma distribuição simulada
-	distribution := []float64{0.1, 0.15, 0.15, 0.4, 0.2} // 10%, 15%, 15%, 40%, 20%
+		data := byRegion[region]
+		data.TotalAmount += donation.Amount
+		data.Count++
+		byRegion[region] = data
+	}
 
-	for i, region := range regions {
-		amount := totalDonations * distribution[i]
-		count := int(float64(len(s.donationService.donations)) * distribution[i])
+	regions := models.BrazilianRegions
+	if _, ok := byRegion[unknownRegion]; ok {
+		regions = append(append([]string{}, regions...), unknownRegion)
+	}
 
+	geoData := make([]models.GeographicalDonationData, 0, len(regions))
+	for _, region := range regions {
+		data := byRegion[region]
 		geoData = append(geoData, models.GeographicalDonationData{
 			Region:      region,
-			TotalAmount: math.Round(amount*100) / 100, // Arredondar para 2 casas decimais
-			Count:       count,
+			TotalAmount: math.Round(data.TotalAmount*100) / 100, // Arredondar para 2 casas decimais
+			Count:       data.Count,
 		})
 	}
-
 	return geoData
 }
 
+// unknownRegion agrupa as doações para ONGs cadastradas sem UF
+const unknownRegion = "Não informada"
+
 // calculateImpactMetrics calcula métricas de impacto simuladas
 func (s *DashboardService) calculateImpactMetrics(totalDonated float64) models.GlobalImpactMetrics {
 	// Em um sistema real, esses dados seriam baseados em relatórios reais de impacto
@@ -287,7 +341,7 @@ func (s *DashboardService) calculateImpactMetrics(totalDonated float64) models.G
 func (s *DashboardService) GetDashboardByDateRange(startDate, endDate time.Time) models.GlobalDashboardData {
 	// Filtrar doações pelo intervalo de datas
 	var filteredDonations []models.Donation
-	for _, donation := range s.donationService.donations {
+	for _, donation := range s.donationService.snapshotDonations() {
 		if donation.Status == "completed" &&
 			(startDate.IsZero() || !donation.CreatedAt.Before(startDate)) &&
 			(endDate.IsZero() || !donation.CreatedAt.After(endDate)) {
@@ -306,10 +360,11 @@ func (s *DashboardService) GetDashboardByDateRange(startDate, endDate time.Time)
 
 	dashboard.TotalTransactions = len(filteredDonations)
 	dashboard.TotalDonors = len(donorMap)
-	dashboard.TotalNGOs = len(s.donationService.ngos)
-	dashboard.DonationsByCategory = s.calculateDonationsByCategory(filteredDonations)
+	dashboard.TotalNGOs = len(s.donationService.GetAllNGOs())
+	ngos := s.donationService.snapshotNGOIndex()
+	dashboard.DonationsByCategory = s.calculateDonationsByCategory(filteredDonations, ngos)
 	dashboard.MonthlyDonations = s.calculateMonthlyDonations(filteredDonations)
-	dashboard.TopNGOs = s.calculateTopNGOs(filteredDonations, 5)
+	dashboard.TopNGOs = s.calculateTopNGOs(filteredDonations, ngos, 5)
 	dashboard.ImpactMetrics = s.calculateImpactMetrics(dashboard.TotalDonated)
 
 	return dashboard
@@ -318,14 +373,15 @@ func (s *DashboardService) GetDashboardByDateRange(startDate, endDate time.Time)
 // GetDashboardByCategory obtém dados do dashboard para uma categoria específica
 func (s *DashboardService) GetDashboardByCategory(category string) models.GlobalDashboardData {
 	// Filtrar doações pela categoria da ONG
+	ngos := s.donationService.snapshotNGOIndex()
 	var filteredDonations []models.Donation
-	for _, donation := range s.donationService.donations {
+	for _, donation := range s.donationService.snapshotDonations() {
 		if donation.Status != "completed" {
 			continue
 		}
 
-		ngo, err := s.donationService.GetNGOByID(donation.NGOID)
-		if err != nil {
+		ngo, ok := ngos[donation.NGOID]
+		if !ok {
 			continue
 		}
 
@@ -348,17 +404,167 @@ func (s *DashboardService) GetDashboardByCategory(category string) models.Global
 
 	// Contar ONGs nesta categoria
 	var ngosInCategory int
-	for _, ngo := range s.donationService.ngos {
+	for _, ngo := range s.donationService.GetAllNGOs() {
 		if ngo.Category == category {
 			ngosInCategory++
 		}
 	}
 	dashboard.TotalNGOs = ngosInCategory
 
-	dashboard.DonationsByCategory = s.calculateDonationsByCategory(filteredDonations)
+	dashboard.DonationsByCategory = s.calculateDonationsByCategory(filteredDonations, ngos)
 	dashboard.MonthlyDonations = s.calculateMonthlyDonations(filteredDonations)
-	dashboard.TopNGOs = s.calculateTopNGOs(filteredDonations, 5)
+	dashboard.TopNGOs = s.calculateTopNGOs(filteredDonations, ngos, 5)
 	dashboard.ImpactMetrics = s.calculateImpactMetrics(dashboard.TotalDonated)
 
 	return dashboard
 }
+
+// GetDonorRetention calcula métricas de retenção com base nas doações concluídas.
+// Um doador é considerado recorrente quando doou em mais de um mês.
+func (s *DashboardService) GetDonorRetention() models.RetentionMetrics {
+	type monthKey struct {
+		year  int
+		month time.Month
+	}
+
+	donorMonths := make(map[uint]map[monthKey]bool)
+	donationsCount := make(map[uint]int)
+
+	for _, donation := range s.donationService.snapshotDonations() {
+		if donation.Status != "completed" {
+			continue
+		}
+
+		if donorMonths[donation.DonorID] == nil {
+			donorMonths[donation.DonorID] = make(map[monthKey]bool)
+		}
+		year, month, _ := donation.CreatedAt.Date()
+		donorMonths[donation.DonorID][monthKey{year, month}] = true
+		donationsCount[donation.DonorID]++
+	}
+
+	metrics := models.RetentionMetrics{TotalDonors: len(donorMonths)}
+	returningDonations := 0
+
+	for donorID, months := range donorMonths {
+		if len(months) < 2 {
+			metrics.OneTimeDonors++
+			continue
+		}
+
+		metrics.ReturningDonors++
+		returningDonations += donationsCount[donorID]
+
+		for key := range months {
+			next := time.Date(key.year, key.month+1, 1, 0, 0, 0, 0, time.UTC)
+			if months[monthKey{next.Year(), next.Month()}] {
+				metrics.ConsecutiveMonthDonors++
+				break
+			}
+		}
+	}
+
+	if metrics.TotalDonors > 0 {
+		metrics.RepeatDonorRate = roundTwoDecimals(float64(metrics.ReturningDonors) / float64(metrics.TotalDonors) * 100)
+	}
+	if metrics.ReturningDonors > 0 {
+		metrics.AverageDonationsPerReturningDonor = roundTwoDecimals(float64(returningDonations) / float64(metrics.ReturningDonors))
+	}
+
+	return metrics
+}
+
+// GetActiveCategories retorna as categorias distintas em uso por ONGs ativas e por seus gastos
+// não rejeitados, ordenadas por nome, com a quantidade de registros de cada uma
+func (s *DashboardService) GetActiveCategories() []models.CategoryUsage {
+	usage := make(map[string]*models.CategoryUsage)
+	get := func(category string) *models.CategoryUsage {
+		if usage[category] == nil {
+			usage[category] = &models.CategoryUsage{Category: category}
+		}
+		return usage[category]
+	}
+
+	activeNGOs := make(map[uint]bool)
+	for _, ngo := range s.donationService.GetAllNGOs() {
+		activeNGOs[ngo.ID] = true
+		get(ngo.Category).NGOsCount++
+	}
+
+	for _, expense := range s.expenseService.snapshotExpenses() {
+		if activeNGOs[expense.NGOID] && expense.Status != "rejeitado" {
+			get(expense.Category).ExpensesCount++
+		}
+	}
+
+	categories := make([]models.CategoryUsage, 0, len(usage))
+	for _, category := range usage {
+		categories = append(categories, *category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Category < categories[j].Category
+	})
+
+	return categories
+}
+
+// Quantidade de doadores no ranking público: padrão e máximo por consulta
+const (
+	DefaultTopDonorsLimit = 10
+	MaxTopDonorsLimit     = 100
+)
+
+// GetTopDonors retorna os doadores com maior total em doações concluídas, considerando apenas
+// quem optou pelo reconhecimento público (ver models.User.PublicRecognition); os demais ficam
+// fora do ranking. Empates favorecem quem doou primeiro.
+func (s *DashboardService) GetTopDonors(limit int) []models.TopDonor {
+	if limit <= 0 {
+		limit = DefaultTopDonorsLimit
+	}
+	if limit > MaxTopDonorsLimit {
+		limit = MaxTopDonorsLimit
+	}
+
+	users := s.donationService.snapshotUserIndex()
+	totals := make(map[uint]*models.TopDonor)
+	for _, donation := range s.donationService.snapshotDonations() {
+		if donation.Status != "completed" {
+			continue
+		}
+		user, ok := users[donation.DonorID]
+		if !ok || !user.PublicRecognition {
+			continue
+		}
+
+		donor := totals[user.ID]
+		if donor == nil {
+			donor = &models.TopDonor{DonorID: user.ID, DonorName: user.Name, FirstDonationAt: donation.CreatedAt}
+			totals[user.ID] = donor
+		}
+		donor.TotalAmount += donation.Amount
+		donor.Count++
+		if donation.CreatedAt.Before(donor.FirstDonationAt) {
+			donor.FirstDonationAt = donation.CreatedAt
+		}
+	}
+
+	donors := make([]models.TopDonor, 0, len(totals))
+	for _, donor := range totals {
+		donor.TotalAmount = roundTwoDecimals(donor.TotalAmount)
+		donors = append(donors, *donor)
+	}
+	sort.Slice(donors, func(i, j int) bool {
+		if donors[i].TotalAmount != donors[j].TotalAmount {
+			return donors[i].TotalAmount > donors[j].TotalAmount
+		}
+		if !donors[i].FirstDonationAt.Equal(donors[j].FirstDonationAt) {
+			return donors[i].FirstDonationAt.Before(donors[j].FirstDonationAt)
+		}
+		return donors[i].DonorID < donors[j].DonorID
+	})
+
+	if len(donors) > limit {
+		donors = donors[:limit]
+	}
+	return donors
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryPercentagesSumToHundred(t *testing.T) {
+	donationSvc := NewDonationService()
+	dashboardSvc := NewDashboardService(donationSvc, NewExpenseService(donationSvc))
+
+	// Uma doação de mesmo valor para cada categoria (Alimentação, Saúde e Educação)
+	for ngoID := uint(1); ngoID <= 3; ngoID++ {
+		completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: ngoID})
+	}
+
+	categories := dashboardSvc.GetGlobalDashboard().DonationsByCategory
+	require.Len(t, categories, 3)
+
+	sum := 0.0
+	for _, category := range categories {
+		assert.InDelta(t, 33.33, category.Percentage, 0.011, "Percentagem deve estar na escala de 0 a 100")
+		sum += category.Percentage
+	}
+	assert.InDelta(t, 100.0, sum, 1e-9)
+	assert.Equal(t, 33.34, categories[0].Percentage, "O resíduo do arredondamento fica com a primeira categoria")
+}
+
+func TestCategoryPercentagesFollowDonationSplit(t *testing.T) {
+	donationSvc := NewDonationService()
+	dashboardSvc := NewDashboardService(donationSvc, NewExpenseService(donationSvc))
+
+	// Alimentação 50%, Saúde 30% e Educação 20%
+	for ngoID, amount := range map[uint]float64{1: 500, 2: 300, 3: 200} {
+		completeDonation(t, donationSvc, models.DonationRequest{Amount: amount, DonorID: 1, NGOID: ngoID})
+	}
+
+	categories := dashboardSvc.GetGlobalDashboard().DonationsByCategory
+	require.Len(t, categories, 3)
+	assert.Equal(t, []string{"Alimentação", "Saúde", "Educação"},
+		[]string{categories[0].Category, categories[1].Category, categories[2].Category})
+	assert.Equal(t, []float64{50, 30, 20},
+		[]float64{categories[0].Percentage, categories[1].Percentage, categories[2].Percentage})
+}
+
+func TestGetDonorRetention(t *testing.T) {
+	donationSvc := NewDonationService()
+	dashboardSvc := NewDashboardService(donationSvc, NewExpenseService(donationSvc))
+	donationSvc.users = append(donationSvc.users, models.User{ID: 3, Name: "Ana Souza", Email: "ana@example.com"})
+
+	month := func(m time.Month) time.Time { return time.Date(2024, m, 10, 0, 0, 0, 0, time.UTC) }
+	gifts := []struct {
+		donorID uint
+		date    time.Time
+	}{
+		{1, month(time.January)}, {1, month(time.February)}, {1, month(time.February)}, // meses consecutivos
+		{2, month(time.January)},                         // doação única
+		{3, month(time.January)}, {3, month(time.March)}, // recorrente, mas com intervalo
+	}
+	for _, gift := range gifts {
+		completeDonation(t, donationSvc, models.DonationRequest{Amount: 50, DonorID: gift.donorID, NGOID: 1})
+		donationSvc.donations[len(donationSvc.donations)-1].CreatedAt = gift.date
+	}
+
+	// Doações pendentes não contam para a retenção
+	_, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 50, DonorID: 2, NGOID: 1})
+	require.NoError(t, err)
+
+	metrics := dashboardSvc.GetDonorRetention()
+	assert.Equal(t, 3, metrics.TotalDonors)
+	assert.Equal(t, 1, metrics.OneTimeDonors)
+	assert.Equal(t, 2, metrics.ReturningDonors)
+	assert.Equal(t, 1, metrics.ConsecutiveMonthDonors)
+	assert.Equal(t, 66.67, metrics.RepeatDonorRate)
+	assert.Equal(t, 2.5, metrics.AverageDonationsPerReturningDonor)
+}
+
+func TestGetActiveCategories(t *testing.T) {
+	donationSvc := NewDonationService()
+	dashboardSvc := NewDashboardService(donationSvc, NewExpenseService(donationSvc))
+
+	// Deixar as ONGs de demonstração em apenas duas categorias
+	ngo := donationSvc.ngos[2]
+	ngo.Category = "Saúde"
+	donationSvc.replaceNGO(2, ngo)
+
+	categories := dashboardSvc.GetActiveCategories()
+	assert.Equal(t, []models.CategoryUsage{
+		{Category: "Alimentação", NGOsCount: 1},
+		{Category: "Saúde", NGOsCount: 2},
+	}, categories)
+}
+
+func TestMonthlyDonationsOrderedAcrossYears(t *testing.T) {
+	donationSvc := NewDonationService()
+	dashboardSvc := NewDashboardService(donationSvc, NewExpenseService(donationSvc))
+
+	// Fora de ordem de propósito: janeiro/2025 antes de dezembro/2024
+	donations := []models.Donation{
+		{Amount: 30, CreatedAt: time.Date(2025, time.January, 10, 0, 0, 0, 0, time.UTC)},
+		{Amount: 20, CreatedAt: time.Date(2024, time.December, 20, 0, 0, 0, 0, time.UTC)},
+		{Amount: 5, CreatedAt: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{Amount: 10, CreatedAt: time.Date(2024, time.December, 5, 0, 0, 0, 0, time.UTC)},
+	}
+
+	monthly := dashboardSvc.calculateMonthlyDonations(donations)
+	require.Len(t, monthly, 3)
+	assert.Equal(t, models.MonthlyDonationData{Month: "Dezembro", MonthNumber: 12, Year: 2024, TotalAmount: 30, Count: 2}, monthly[0])
+	assert.Equal(t, models.MonthlyDonationData{Month: "Janeiro", MonthNumber: 1, Year: 2025, TotalAmount: 30, Count: 1}, monthly[1])
+	assert.Equal(t, models.MonthlyDonationData{Month: "Fevereiro", MonthNumber: 2, Year: 2025, TotalAmount: 5, Count: 1}, monthly[2])
+}
+
+func TestGeographicalDataSumsDonationsByNGORegion(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+	dashboardSvc := NewDashboardService(donationSvc, NewExpenseService(donationSvc))
+
+	// ONG aprovada na Bahia (Nordeste); as ONGs de demonstração ficam no Sudeste
+	registration, err := adminSvc.RegisterNGO(models.NGORegistrationRequest{
+		Name: "Sertão Vivo", Description: "Cisternas", Category: "Infraestrutura", CNPJ: "11.222.333/0001-81",
+		Email: "contato@sertaovivo.org", Phone: "7133334444", Address: "Rua C", State: "ba", ResponsibleID: 1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Nordeste", registration.Region)
+	_, err = adminSvc.ValidateCNPJOnline(registration.ID)
+	require.NoError(t, err)
+	_, err = adminSvc.UploadNGODocuments(context.Background(), registration.ID, []byte("estatuto"))
+	require.NoError(t, err)
+	ngo, _, err := adminSvc.ApproveNGO(registration.ID, 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, "BA", ngo.State)
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 50, DonorID: 2, NGOID: 2})
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 70, DonorID: 1, NGOID: ngo.ID})
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 999, DonorID: 1, NGOID: ngo.ID}) // Pendente
+	require.NoError(t, err)
+
+	byRegion := map[string]models.GeographicalDonationData{}
+	for _, data := range dashboardSvc.GetGlobalDashboard().GeographicalData {
+		byRegion[data.Region] = data
+	}
+	assert.Len(t, byRegion, len(models.BrazilianRegions), "Todas as regiões aparecem, sem a de UF não informada")
+	assert.Equal(t, models.GeographicalDonationData{Region: "Sudeste", TotalAmount: 150, Count: 2}, byRegion["Sudeste"])
+	assert.Equal(t, models.GeographicalDonationData{Region: "Nordeste", TotalAmount: 70, Count: 1}, byRegion["Nordeste"])
+	for _, region := range []string{"Norte", "Centro-Oeste", "Sul"} {
+		assert.Zero(t, byRegion[region].TotalAmount, region)
+		assert.Zero(t, byRegion[region].Count, region)
+	}
+}
+
+func TestGlobalDashboardCache(t *testing.T) {
+	donationSvc := NewDonationService()
+	dashboardSvc := NewDashboardService(donationSvc, NewExpenseService(donationSvc))
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	dashboardSvc.SetClock(ClockFunc(func() time.Time { return now }))
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	assert.Equal(t, 100.0, dashboardSvc.GetGlobalDashboard().TotalDonated)
+
+	// Alterações que não passam pelo serviço de doações só aparecem ao expirar o cache
+	donationSvc.mu.Lock()
+	donationSvc.donations[0].Amount = 150
+	donationSvc.mu.Unlock()
+	assert.Equal(t, 100.0, dashboardSvc.GetGlobalDashboard().TotalDonated, "Dentro do TTL o resultado vem do cache")
+
+	now = now.Add(DefaultDashboardCacheTTL)
+	assert.Equal(t, 150.0, dashboardSvc.GetGlobalDashboard().TotalDonated, "Após o TTL o dashboard é recalculado")
+
+	// Uma nova doação concluída invalida o cache imediatamente
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 50, DonorID: 2, NGOID: 2})
+	dashboard := dashboardSvc.GetGlobalDashboard()
+	assert.Equal(t, 200.0, dashboard.TotalDonated)
+	assert.Equal(t, 2, dashboard.TotalTransactions)
+}
+
+func BenchmarkGlobalDashboardUncached(b *testing.B) {
+	benchmarkGlobalDashboard(b, 0)
+}
+
+func BenchmarkGlobalDashboardCached(b *testing.B) {
+	benchmarkGlobalDashboard(b, DefaultDashboardCacheTTL)
+}
+
+func benchmarkGlobalDashboard(b *testing.B, ttl time.Duration) {
+	donationSvc := NewDonationService()
+	dashboardSvc := NewDashboardService(donationSvc, NewExpenseService(donationSvc))
+	dashboardSvc.SetCacheTTL(ttl)
+	seedCompletedDonations(donationSvc, 50000, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dashboardSvc.GetGlobalDashboard()
+	}
+}
+
+func TestGetTopDonorsOnlyListsOptedInDonors(t *testing.T) {
+	donationSvc := NewDonationService()
+	dashboardSvc := NewDashboardService(donationSvc, NewExpenseService(donationSvc))
+
+	ana, err := donationSvc.RegisterUser("Ana Souza", "ana@example.com", true)
+	require.NoError(t, err)
+	bruno, err := donationSvc.RegisterUser("Bruno Lima", "bruno@example.com", true)
+	require.NoError(t, err)
+	carla, err := donationSvc.RegisterUser("Carla Dias", "carla@example.com", true)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, d := range []struct {
+		donorID uint
+		amount  float64
+		day     int
+		status  string
+	}{
+		{1, 1000, 0, "completed"}, // João não optou pelo reconhecimento público
+		{bruno.ID, 150, 5, "completed"},
+		{ana.ID, 100, 9, "completed"},
+		{ana.ID, 50, 2, "completed"}, // Ana e Bruno empatam em 150; Ana doou primeiro
+		{carla.ID, 120, 1, "completed"},
+		{carla.ID, 500, 3, "pending"},
+	} {
+		donationSvc.donations = append(donationSvc.donations, models.Donation{
+			ID: uint(len(donationSvc.donations) + 1), Amount: d.amount, DonorID: d.donorID, NGOID: 1,
+			CreatedAt: start.AddDate(0, 0, d.day), Status: d.status,
+		})
+	}
+
+	donors := dashboardSvc.GetTopDonors(0)
+	require.Len(t, donors, 3)
+	assert.Equal(t, models.TopDonor{
+		DonorID: ana.ID, DonorName: "Ana Souza", TotalAmount: 150, Count: 2, FirstDonationAt: start.AddDate(0, 0, 2),
+	}, donors[0])
+	assert.Equal(t, []uint{ana.ID, bruno.ID, carla.ID}, []uint{donors[0].DonorID, donors[1].DonorID, donors[2].DonorID})
+	assert.Equal(t, 120.0, donors[2].TotalAmount, "Doações pendentes não entram no total")
+
+	assert.Len(t, dashboardSvc.GetTopDonors(2), 2)
+}
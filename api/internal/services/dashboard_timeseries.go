@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+	"trackable-donations/api/internal/models"
+)
+
+var (
+	// ErrInvalidGranularity indica uma granularidade de série temporal desconhecida
+	ErrInvalidGranularity = errors.New("granularidade inválida: use daily, weekly ou monthly")
+	// ErrInvalidDateRange indica um período com início posterior ao fim
+	ErrInvalidDateRange = errors.New("a data inicial não pode ser posterior à data final")
+	// ErrTimeSeriesTooLong indica um período com mais pontos do que a série admite
+	ErrTimeSeriesTooLong = fmt.Errorf("o período gera mais de %d pontos: reduza o intervalo ou use uma granularidade maior", maxTimeSeriesBuckets)
+)
+
+// maxTimeSeriesBuckets limita os pontos de cada série (ex.: pouco menos de 3 anos em granularidade diária)
+const maxTimeSeriesBuckets = 1000
+
+// GetCategoryTimeSeries soma as doações concluídas entre startDate e endDate (inclusivos) por
+// categoria da ONG e por período da granularidade. Todas as categorias oficiais aparecem, e
+// cada série tem um ponto por período do intervalo, zerado quando não houve doações.
+func (s *DashboardService) GetCategoryTimeSeries(startDate, endDate time.Time, granularity string) ([]models.CategoryTimeSeries, error) {
+	if granularity == "" {
+		granularity = models.GranularityMonthly
+	}
+	switch granularity {
+	case models.GranularityDaily, models.GranularityWeekly, models.GranularityMonthly:
+	default:
+		return nil, ErrInvalidGranularity
+	}
+	if startDate.After(endDate) {
+		return nil, ErrInvalidDateRange
+	}
+
+	// Os períodos são contados no fuso da data inicial
+	var periods []time.Time
+	for period := periodStart(startDate, granularity); !period.After(endDate); period = nextPeriod(period, granularity) {
+		if len(periods) == maxTimeSeriesBuckets {
+			return nil, ErrTimeSeriesTooLong
+		}
+		periods = append(periods, period)
+	}
+	bucketIndex := make(map[time.Time]int, len(periods))
+	for i, period := range periods {
+		bucketIndex[period] = i
+	}
+
+	newSeries := func(category string) *models.CategoryTimeSeries {
+		series := &models.CategoryTimeSeries{Category: category, Points: make([]models.TimeSeriesBucket, len(periods))}
+		for i, period := range periods {
+			series.Points[i].Period = period.Format("2006-01-02")
+		}
+		return series
+	}
+	byCategory := make(map[string]*models.CategoryTimeSeries)
+	for _, category := range models.NGOCategories {
+		byCategory[category] = newSeries(category)
+	}
+
+	ngos := s.donationService.snapshotNGOIndex()
+	var extraCategories []string
+	for _, donation := range s.donationService.snapshotDonations() {
+		if donation.Status != "completed" || donation.CreatedAt.Before(startDate) || donation.CreatedAt.After(endDate) {
+			continue
+		}
+		ngo, ok := ngos[donation.NGOID]
+		if !ok {
+			continue
+		}
+
+		series, ok := byCategory[ngo.Category]
+		if !ok {
+			// Categorias anteriores à lista oficial também entram, depois das oficiais
+			series = newSeries(ngo.Category)
+			byCategory[ngo.Category] = series
+			extraCategories = append(extraCategories, ngo.Category)
+		}
+		bucket := &series.Points[bucketIndex[periodStart(donation.CreatedAt.In(startDate.Location()), granularity)]]
+		bucket.Total += donation.Amount
+		bucket.Count++
+	}
+	sort.Strings(extraCategories)
+
+	result := make([]models.CategoryTimeSeries, 0, len(byCategory))
+	for _, category := range slices.Concat(models.NGOCategories, extraCategories) {
+		series := byCategory[category]
+		for i := range series.Points {
+			series.Points[i].Total = roundTwoDecimals(series.Points[i].Total)
+		}
+		result = append(result, *series)
+	}
+	return result, nil
+}
+
+// periodStart retorna o início do período que contém t: o dia, a segunda-feira da semana ou
+// o primeiro dia do mês
+func periodStart(t time.Time, granularity string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch granularity {
+	case models.GranularityWeekly:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case models.GranularityMonthly:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+// nextPeriod retorna o início do período seguinte
+func nextPeriod(period time.Time, granularity string) time.Time {
+	switch granularity {
+	case models.GranularityWeekly:
+		return period.AddDate(0, 0, 7)
+	case models.GranularityMonthly:
+		return period.AddDate(0, 1, 0)
+	default:
+		return period.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryTimeSeriesAcrossMonths(t *testing.T) {
+	donationSvc := NewDonationService()
+	dashboardSvc := NewDashboardService(donationSvc, NewExpenseService(donationSvc))
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	donationSvc.SetClock(ClockFunc(func() time.Time { return now }))
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1}) // Alimentação
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 50.5, DonorID: 2, NGOID: 1})
+	now = time.Date(2024, 3, 31, 23, 0, 0, 0, time.UTC)
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 30, DonorID: 1, NGOID: 2}) // Saúde
+	now = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 999, DonorID: 1, NGOID: 2}) // Fora do período
+	_, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 70, DonorID: 1, NGOID: 1})
+	require.NoError(t, err) // Pendente não conta
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 4, 30, 23, 59, 59, 0, time.UTC)
+	series, err := dashboardSvc.GetCategoryTimeSeries(start, end, models.GranularityMonthly)
+	require.NoError(t, err)
+	require.Len(t, series, len(models.NGOCategories), "Todas as categorias oficiais aparecem")
+
+	byCategory := make(map[string][]models.TimeSeriesBucket)
+	for _, s := range series {
+		require.Len(t, s.Points, 4, s.Category)
+		byCategory[s.Category] = s.Points
+	}
+	assert.Equal(t, []models.TimeSeriesBucket{
+		{Period: "2024-01-01", Total: 150.5, Count: 2},
+		{Period: "2024-02-01"},
+		{Period: "2024-03-01"},
+		{Period: "2024-04-01"},
+	}, byCategory["Alimentação"])
+	assert.Equal(t, models.TimeSeriesBucket{Period: "2024-03-01", Total: 30, Count: 1}, byCategory["Saúde"][2])
+	assert.Zero(t, byCategory["Saúde"][3].Total)
+	assert.Equal(t, 0, byCategory["Educação"][0].Count)
+
+	weekly, err := dashboardSvc.GetCategoryTimeSeries(start, end, models.GranularityWeekly)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-01", weekly[0].Points[0].Period, "01/01/2024 é uma segunda-feira")
+	assert.Equal(t, "2024-01-08", weekly[0].Points[1].Period)
+	assert.Equal(t, 150.5, weekly[0].Points[1].Total)
+
+	daily, err := dashboardSvc.GetCategoryTimeSeries(start, end, models.GranularityDaily)
+	require.NoError(t, err)
+	assert.Len(t, daily[0].Points, 121)
+
+	_, err = dashboardSvc.GetCategoryTimeSeries(start, end, "yearly")
+	assert.ErrorIs(t, err, ErrInvalidGranularity)
+	_, err = dashboardSvc.GetCategoryTimeSeries(end, start, models.GranularityDaily)
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+	_, err = dashboardSvc.GetCategoryTimeSeries(start, start.AddDate(5, 0, 0), models.GranularityDaily)
+	assert.ErrorIs(t, err, ErrTimeSeriesTooLong)
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"strings"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/utils"
+	"trackable-donations/blockchain-node/core"
+)
+
+// SetBlockchain define a blockchain em que as doações confirmadas são registradas
+func (s *DonationService) SetBlockchain(blockchain *core.Blockchain) {
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+	s.blockchain = blockchain
+}
+
+// loadChain restaura os blocos e as chaves dos titulares salvos no store, para que os hashes
+// registrados antes de reiniciar a API continuem verificáveis. Sem blocos salvos, persiste
+// o bloco gênesis da cadeia nova.
+func (s *DonationService) loadChain() error {
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+
+	blocks, err := s.store.ChainBlocks.FindAll()
+	if err != nil {
+		return fmt.Errorf("falha ao carregar a blockchain: %w", err)
+	}
+	if len(blocks) == 0 {
+		for _, block := range s.blockchain.Chain {
+			if err := s.store.ChainBlocks.Create(&models.ChainBlock{ID: uint(block.Index), Block: block}); err != nil {
+				return fmt.Errorf("falha ao salvar o bloco %d: %w", block.Index, err)
+			}
+		}
+	} else {
+		chain := make([]core.Block, len(blocks))
+		for i, stored := range blocks {
+			chain[i] = stored.Block
+		}
+		s.blockchain.Chain = chain
+	}
+
+	keys, err := s.store.ChainKeys.FindAll()
+	if err != nil {
+		return fmt.Errorf("falha ao carregar as chaves da blockchain: %w", err)
+	}
+	s.chainKeys = make(map[string]*ecdsa.PrivateKey, len(keys))
+	for _, stored := range keys {
+		der, err := utils.DecryptSensitiveData(stored.EncryptedKey)
+		if err != nil {
+			return fmt.Errorf("chave da blockchain inválida para %s: %w", stored.Account, err)
+		}
+		key, err := x509.ParseECPrivateKey(der)
+		if err != nil {
+			return fmt.Errorf("chave da blockchain inválida para %s: %w", stored.Account, err)
+		}
+		s.chainKeys[stored.Account] = key
+	}
+	return nil
+}
+
+// donationTransactionID identifica na blockchain a transação de uma doação
+func donationTransactionID(donationID uint) string {
+	return fmt.Sprintf("donation-%d", donationID)
+}
+
+// ngoChainAccount identifica a ONG no chaveiro da blockchain
+func ngoChainAccount(ngoID uint) string {
+	return fmt.Sprintf("ngo-%d", ngoID)
+}
+
+// donorChainAccount identifica o doador no chaveiro da blockchain, sem expor o seu ID
+func donorChainAccount(donorID uint) string {
+	return utils.HashSensitiveData(fmt.Sprintf("donor-%d", donorID), false)
+}
+
+// chainKey retorna a chave do titular, criando-a no primeiro uso. A plataforma guarda as
+// chaves de doadores e ONGs e assina em nome deles. Deve ser chamado com s.chainMu bloqueado.
+func (s *DonationService) chainKey(account string) *ecdsa.PrivateKey {
+	if key, ok := s.chainKeys[account]; ok {
+		return key
+	}
+	if s.chainKeys == nil {
+		s.chainKeys = map[string]*ecdsa.PrivateKey{}
+	}
+
+	key, _, err := core.GenerateKeyPair()
+	if err != nil {
+		panic(fmt.Sprintf("falha ao gerar chave da blockchain: %v", err))
+	}
+	s.chainKeys[account] = key
+
+	// Sem a chave, o saldo do titular na cadeia não poderia ser consultado após reiniciar.
+	// Ela é salva cifrada: quem lê o banco ou um backup não pode assinar pelo titular.
+	der, err := x509.MarshalECPrivateKey(key)
+	var encrypted string
+	if err == nil {
+		encrypted, err = utils.EncryptSensitiveData(der)
+	}
+	if err == nil {
+		err = s.store.ChainKeys.Create(&models.ChainKey{Account: account, EncryptedKey: encrypted})
+	}
+	if err != nil {
+		log.Printf("Erro ao salvar a chave da blockchain de %s: %v", account, err)
+	}
+	return key
+}
+
+// chainAddress retorna o endereço do titular na blockchain
+func (s *DonationService) chainAddress(account string) string {
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+	return core.Address(&s.chainKey(account).PublicKey)
+}
+
+// chainBalance soma o saldo confirmado dos titulares na blockchain
+func (s *DonationService) chainBalance(accounts ...string) float64 {
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+
+	var balance float64
+	for _, account := range accounts {
+		if key, ok := s.chainKeys[account]; ok {
+			balance += s.blockchain.Balance(core.Address(&key.PublicKey))
+		}
+	}
+	return balance
+}
+
+// recordTransfer assina a transferência entre os titulares, minera o bloco que a
+// contém e retorna o hash desse bloco (com prefixo 0x)
+func (s *DonationService) recordTransfer(id, from, to string, amount float64) string {
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+
+	senderKey := s.chainKey(from)
+	tx := core.Transaction{
+		ID:       id,
+		Amount:   amount,
+		Sender:   core.Address(&senderKey.PublicKey),
+		Receiver: core.Address(&s.chainKey(to).PublicKey),
+	}
+	if err := core.Sign(&tx, senderKey); err != nil {
+		// A chave é sempre a do próprio remetente
+		panic(err)
+	}
+
+	bc := s.blockchain
+	bc.CurrentTransactions = append(bc.CurrentTransactions, tx)
+	block := bc.NewBlock(bc.ProofOfWork(bc.LastBlock().Proof))
+	if err := s.store.ChainBlocks.Save(&models.ChainBlock{ID: uint(block.Index), Block: block}); err != nil {
+		log.Printf("Erro ao salvar o bloco %d da blockchain: %v", block.Index, err)
+	}
+	return "0x" + block.Hash()
+}
+
+// recordDonationOnBlockchain registra a doação como transação do doador para a ONG,
+// minera o bloco que a contém e retorna o hash desse bloco, usado como TransactionHash
+func (s *DonationService) recordDonationOnBlockchain(donation models.Donation) string {
+	return s.recordTransfer(donationTransactionID(donation.ID),
+		donorChainAccount(donation.DonorID), ngoChainAccount(donation.NGOID), donation.Amount)
+}
+
+// refundTransactionID identifica na blockchain a transação reversa de uma doação estornada
+func refundTransactionID(donationID uint) string {
+	return fmt.Sprintf("refund-%d", donationID)
+}
+
+// recordRefundOnBlockchain registra a transação reversa do estorno (da ONG de volta ao
+// doador), minera o bloco que a contém e retorna o hash desse bloco (com prefixo 0x)
+func (s *DonationService) recordRefundOnBlockchain(donation models.Donation) string {
+	return s.recordTransfer(refundTransactionID(donation.ID),
+		ngoChainAccount(donation.NGOID), donorChainAccount(donation.DonorID), donation.Amount)
+}
+
+// blockchainHasTransaction indica se o bloco com o hash informado está íntegro e contém a
+// transação com o ID informado
+func (s *DonationService) blockchainHasTransaction(blockHash, transactionID string) bool {
+	_, found := s.blockchainTransactionBlock(blockHash, transactionID)
+	return found
+}
+
+// blockchainTransactionBlock retorna o índice do bloco com o hash informado, desde que ele
+// contenha a transação com o ID informado e esteja íntegro. Apenas esse bloco é verificado
+// (core.Blockchain.ValidBlock), e não a cadeia inteira, porque a verificação é exposta em
+// uma rota pública.
+func (s *DonationService) blockchainTransactionBlock(blockHash, transactionID string) (int, bool) {
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+
+	hash := strings.TrimPrefix(strings.ToLower(blockHash), "0x")
+	for position, block := range s.blockchain.Chain {
+		if block.Hash() != hash {
+			continue
+		}
+		for _, transaction := range block.Transactions {
+			if transaction.ID == transactionID && s.blockchain.ValidBlock(position) {
+				return block.Index, true
+			}
+		}
+		return 0, false
+	}
+	return 0, false
+}
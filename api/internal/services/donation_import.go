@@ -0,0 +1,313 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/repository"
+)
+
+// MaxDonationImportRows é a quantidade máxima de doações aceitas em uma importação
+const MaxDonationImportRows = 5000
+
+var (
+	// ErrDonationImportInvalid indica que há linhas inválidas e, sem importação parcial, nada foi importado
+	ErrDonationImportInvalid = errors.New("há linhas inválidas na importação; nenhuma doação foi importada")
+	// ErrDonationImportEmpty indica uma importação sem doações
+	ErrDonationImportEmpty = errors.New("nenhuma doação para importar")
+	// ErrDonationImportTooLarge indica uma importação com mais linhas que o permitido
+	ErrDonationImportTooLarge = fmt.Errorf("a importação aceita no máximo %d doações por vez", MaxDonationImportRows)
+	// ErrInvalidImportCSV indica um CSV de importação que não pôde ser lido
+	ErrInvalidImportCSV = errors.New("CSV de importação inválido")
+	// ErrDonationImportFailed indica uma falha ao gravar a importação, que foi desfeita por inteiro
+	ErrDonationImportFailed = errors.New("falha ao gravar a importação; nenhuma doação foi importada")
+)
+
+// importRef identifica uma doação importada pela referência externa dentro da ONG
+type importRef struct {
+	ngoID uint
+	ref   string
+}
+
+// ImportDonations importa doações históricas de uma campanha feita em outra plataforma. Elas
+// entram como concluídas, marcadas como importadas e sem transação na blockchain; os doadores
+// são encontrados pelo e-mail ou cadastrados. Todas as linhas são validadas antes da gravação:
+// havendo linhas inválidas nada é importado e ErrDonationImportInvalid é retornado junto com
+// o resultado de cada linha, a menos que partial seja verdadeiro, quando só as válidas entram.
+// Sem partial, a gravação também é tudo ou nada: se alguma falhar, ela é desfeita por inteiro
+// e ErrDonationImportFailed é retornado.
+func (s *DonationService) ImportDonations(rows []models.DonationImportRow, partial bool) (models.DonationImportResult, error) {
+	if len(rows) == 0 {
+		return models.DonationImportResult{}, ErrDonationImportEmpty
+	}
+	if len(rows) > MaxDonationImportRows {
+		return models.DonationImportResult{}, ErrDonationImportTooLarge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Referências externas já importadas, para que repetir um arquivo não duplique doações
+	refs := make(map[importRef]bool)
+	for _, donation := range s.donations {
+		if donation.ExternalRef != "" {
+			refs[importRef{donation.NGOID, donation.ExternalRef}] = true
+		}
+	}
+
+	result := models.DonationImportResult{Rows: make([]models.DonationImportRowResult, len(rows))}
+	donations := make([]models.Donation, len(rows))
+	emails := make([]string, len(rows))
+	for i, row := range rows {
+		result.Rows[i].Row = i + 1
+		donation, email, err := s.validateImportRow(row, refs)
+		if err != nil {
+			result.Rows[i].Status = models.ImportRowInvalid
+			result.Rows[i].Error = err.Error()
+			result.Invalid++
+			continue
+		}
+		donations[i], emails[i] = donation, email
+	}
+
+	if result.Invalid > 0 && !partial {
+		for i := range result.Rows {
+			if result.Rows[i].Status == "" {
+				result.Rows[i].Status = models.ImportRowSkipped
+			}
+		}
+		return result, ErrDonationImportInvalid
+	}
+
+	// As doações e os doadores novos só entram em memória depois de gravados
+	var imported []models.Donation
+	var donors []models.User
+	save := func(store *repository.Store) error {
+		for i := range donations {
+			if result.Rows[i].Status == models.ImportRowInvalid {
+				continue
+			}
+
+			donation := donations[i]
+			donor, err := s.importDonor(store, emails[i], &donors)
+			if err == nil {
+				donation.DonorID = donor.ID
+				if err = store.Donations.Create(&donation); err != nil {
+					err = fmt.Errorf("falha ao salvar a doação: %w", err)
+				}
+			}
+			if err != nil {
+				result.Rows[i].Status = models.ImportRowInvalid
+				result.Rows[i].Error = err.Error()
+				result.Invalid++
+				if !partial {
+					return fmt.Errorf("linha %d: %w", i+1, err)
+				}
+				continue
+			}
+
+			imported = append(imported, donation)
+			result.Rows[i].Status = models.ImportRowImported
+			result.Rows[i].DonationID = donation.ID
+			result.Imported++
+		}
+		return nil
+	}
+
+	if partial {
+		// As falhas ficam no resultado de cada linha, e as demais linhas são gravadas
+		_ = save(s.store)
+	} else if err := s.store.Transaction(save); err != nil {
+		for i := range result.Rows {
+			if result.Rows[i].Status != models.ImportRowInvalid {
+				result.Rows[i].Status = models.ImportRowSkipped
+				result.Rows[i].DonationID = 0
+			}
+		}
+		result.Imported = 0
+		return result, fmt.Errorf("%w: %w", ErrDonationImportFailed, err)
+	}
+
+	for _, donor := range donors {
+		s.users = append(s.users, donor)
+		s.userIndex[donor.ID] = donor
+	}
+	s.donations = append(s.donations, imported...)
+	if result.Imported > 0 {
+		s.aggregatesVersion.Add(1)
+	}
+	return result, nil
+}
+
+// validateImportRow valida uma linha da importação e monta a doação correspondente, ainda sem
+// doador; refs recebe a referência externa da linha. Deve ser chamado com s.mu bloqueado.
+func (s *DonationService) validateImportRow(row models.DonationImportRow, refs map[importRef]bool) (models.Donation, string, error) {
+	email := strings.TrimSpace(row.DonorEmail)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return models.Donation{}, "", fmt.Errorf("donor_email: %w", ErrInvalidEmail)
+	}
+
+	ngo, err := s.findNGO(row.NGOID)
+	if err != nil {
+		return models.Donation{}, "", fmt.Errorf("ngo_id: %w", err)
+	}
+	switch ngo.Status {
+	case models.NGOSuspended:
+		return models.Donation{}, "", fmt.Errorf("ngo_id: %w", ErrNGOSuspended)
+	case models.NGOMerged:
+		return models.Donation{}, "", fmt.Errorf("ngo_id: %w", ErrNGOMerged)
+	}
+
+	if row.Amount <= 0 {
+		return models.Donation{}, "", errors.New("amount: deve ser maior que zero")
+	}
+
+	date, err := parseImportDate(row.Date)
+	if err != nil {
+		return models.Donation{}, "", err
+	}
+	if date.After(s.clock.Now()) {
+		return models.Donation{}, "", errors.New("date: não pode estar no futuro")
+	}
+
+	ref := strings.TrimSpace(row.ExternalRef)
+	if ref != "" {
+		key := importRef{ngo.ID, ref}
+		if refs[key] {
+			return models.Donation{}, "", fmt.Errorf("external_ref: %q já foi importada para esta ONG", ref)
+		}
+		refs[key] = true
+	}
+
+	return models.Donation{
+		Amount:         row.Amount,
+		NGOID:          ngo.ID,
+		CreatedAt:      date,
+		Status:         "completed",
+		Currency:       models.DefaultCurrency,
+		OriginalAmount: row.Amount,
+		ExchangeRate:   1,
+		Imported:       true,
+		ExternalRef:    ref,
+	}, email, nil
+}
+
+// parseImportDate aceita a data da doação como AAAA-MM-DD (meia-noite UTC) ou RFC 3339
+func parseImportDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	return time.Time{}, errors.New("date: deve estar no formato AAAA-MM-DD ou RFC 3339")
+}
+
+// importDonor retorna o doador com o e-mail informado, sem diferenciar maiúsculas de
+// minúsculas, entre os cadastrados e os já criados nesta importação (created); quando não
+// existe, cadastra-o em store e o acrescenta a created. Sem o nome na origem, o e-mail é
+// usado como nome. Deve ser chamado com s.mu bloqueado para escrita.
+func (s *DonationService) importDonor(store *repository.Store, email string, created *[]models.User) (models.User, error) {
+	for _, users := range [][]models.User{s.users, *created} {
+		for _, user := range users {
+			if strings.EqualFold(user.Email, email) {
+				return user, nil
+			}
+		}
+	}
+
+	user := models.User{Name: email, Email: email, CreatedAt: s.clock.Now()}
+	if err := store.Users.Create(&user); err != nil {
+		return models.User{}, fmt.Errorf("falha ao cadastrar o doador: %w", err)
+	}
+	*created = append(*created, user)
+	return user, nil
+}
+
+// ParseDonationImportCSV lê as doações a importar de um CSV cujo cabeçalho nomeia as colunas
+// donor_email, ngo_id, amount, date e, opcionalmente, external_ref, em qualquer ordem.
+// Valores numéricos ilegíveis invalidam o arquivo inteiro, indicando a linha.
+func ParseDonationImportCSV(r io.Reader) ([]models.DonationImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrDonationImportEmpty
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImportCSV, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff") // BOM de planilhas exportadas
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"donor_email", "ngo_id", "amount", "date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: coluna %q ausente no cabeçalho", ErrInvalidImportCSV, required)
+		}
+	}
+
+	var rows []models.DonationImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidImportCSV, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := models.DonationImportRow{
+			DonorEmail:  field("donor_email"),
+			Date:        field("date"),
+			ExternalRef: field("external_ref"),
+		}
+		if value := field("ngo_id"); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%w: linha %d: ngo_id deve ser um número inteiro", ErrInvalidImportCSV, line)
+			}
+			row.NGOID = uint(id)
+		}
+		if value := field("amount"); value != "" {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: linha %d: amount deve ser um número (use ponto como separador decimal)", ErrInvalidImportCSV, line)
+			}
+			row.Amount = amount
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ImportDonations importa doações históricas (ver DonationService.ImportDonations) e registra
+// a importação no log de auditoria quando alguma doação é criada
+func (s *AdminService) ImportDonations(rows []models.DonationImportRow, partial bool, adminID uint) (models.DonationImportResult, error) {
+	result, err := s.donationService.ImportDonations(rows, partial)
+	if result.Imported > 0 {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.logAuditAction(adminID, models.AuditActionDonationsImported, "donation", 0, "", "",
+			fmt.Sprintf("%d doações importadas, %d linhas inválidas", result.Imported, result.Invalid))
+	}
+	return result, err
+}
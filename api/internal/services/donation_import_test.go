@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportDonationsValidatesEveryRowBeforeCommitting(t *testing.T) {
+	donationSvc := NewDonationService()
+	donationSvc.SetClock(ClockFunc(func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }))
+
+	rows := []models.DonationImportRow{
+		{DonorEmail: "JOAO@example.com", NGOID: 1, Amount: 50, Date: "2023-11-20", ExternalRef: "kick-1"},
+		{DonorEmail: "nova@example.com", NGOID: 2, Amount: 80, Date: "2023-12-01T10:30:00-03:00", ExternalRef: "kick-2"},
+		{DonorEmail: "nova@example.com", NGOID: 2, Amount: 0, Date: "2023-12-02"},
+		{DonorEmail: "nova@example.com", NGOID: 2, Amount: 10, Date: "2025-01-01"},
+		{DonorEmail: "nova@example.com", NGOID: 99, Amount: 10, Date: "2023-12-02"},
+		{DonorEmail: "nova@example.com", NGOID: 1, Amount: 10, Date: "2023-12-03", ExternalRef: "kick-1"},
+	}
+
+	// Sem importação parcial, nada é gravado e cada linha informa a sua situação
+	result, err := donationSvc.ImportDonations(rows, false)
+	require.ErrorIs(t, err, ErrDonationImportInvalid)
+	assert.Empty(t, donationSvc.donations)
+	assert.Len(t, donationSvc.users, 2, "Nenhum doador deve ser cadastrado")
+	assert.Equal(t, 0, result.Imported)
+	assert.Equal(t, 4, result.Invalid)
+	statuses := make([]string, len(result.Rows))
+	for i, row := range result.Rows {
+		statuses[i] = row.Status
+	}
+	assert.Equal(t, []string{"skipped", "skipped", "invalid", "invalid", "invalid", "invalid"}, statuses)
+	assert.Contains(t, result.Rows[2].Error, "amount")
+	assert.Contains(t, result.Rows[3].Error, "futuro")
+	assert.Contains(t, result.Rows[4].Error, "ngo_id")
+	assert.Contains(t, result.Rows[5].Error, "external_ref")
+
+	// Com importação parcial, as linhas válidas entram como doações concluídas e importadas
+	result, err = donationSvc.ImportDonations(rows, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	require.Len(t, donationSvc.donations, 2)
+
+	first := donationSvc.donations[0]
+	assert.Equal(t, result.Rows[0].DonationID, first.ID)
+	assert.Equal(t, uint(1), first.DonorID, "O doador existente é encontrado pelo e-mail")
+	assert.Equal(t, "completed", first.Status)
+	assert.True(t, first.Imported)
+	assert.Equal(t, "kick-1", first.ExternalRef)
+	assert.Empty(t, first.TransactionHash)
+
+	created, err := donationSvc.GetUserByID(donationSvc.donations[1].DonorID)
+	require.NoError(t, err)
+	assert.Equal(t, "nova@example.com", created.Email)
+
+	// Repetir a importação não duplica as doações com referência externa
+	result, err = donationSvc.ImportDonations(rows[:2], false)
+	require.ErrorIs(t, err, ErrDonationImportInvalid)
+	assert.Equal(t, 2, result.Invalid)
+	assert.Len(t, donationSvc.donations, 2)
+}
+
+// failingUsers simula um banco que recusa o cadastro de usuários
+type failingUsers struct {
+	repository.Repository[models.User]
+}
+
+func (failingUsers) Create(*models.User) error {
+	return errors.New("conexão perdida")
+}
+
+func TestImportDonationsRollsBackOnSaveFailure(t *testing.T) {
+	store := repository.NewMemoryStore()
+	donationSvc, err := NewDonationServiceWithStore(store)
+	require.NoError(t, err)
+	require.NoError(t, donationSvc.Seed())
+	store.Users = failingUsers{store.Users}
+
+	// A primeira linha é gravada; a segunda falha ao cadastrar o doador novo
+	rows := []models.DonationImportRow{
+		{DonorEmail: "joao@example.com", NGOID: 1, Amount: 50, Date: "2023-11-20"},
+		{DonorEmail: "nova@example.com", NGOID: 2, Amount: 80, Date: "2023-12-01"},
+	}
+	result, err := donationSvc.ImportDonations(rows, false)
+	require.ErrorIs(t, err, ErrDonationImportFailed)
+	assert.Equal(t, 0, result.Imported)
+	assert.Equal(t, models.ImportRowSkipped, result.Rows[0].Status)
+	assert.Zero(t, result.Rows[0].DonationID)
+	assert.Equal(t, models.ImportRowInvalid, result.Rows[1].Status)
+
+	assert.Empty(t, donationSvc.donations)
+	persisted, err := store.Donations.FindAll()
+	require.NoError(t, err)
+	assert.Empty(t, persisted, "A doação já gravada deve ser desfeita")
+}
+
+func TestImportedDonationsDoNotBreakChainAudit(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 30, DonorID: 1, NGOID: 1})
+	_, err := adminSvc.ImportDonations([]models.DonationImportRow{
+		{DonorEmail: "joao@example.com", NGOID: 1, Amount: 500, Date: "2023-11-20"},
+	}, false, 1)
+	require.NoError(t, err)
+
+	result, err := adminSvc.AuditEntity(models.AuditRequest{EntityType: "ngo", EntityID: 1}, 1)
+	require.NoError(t, err)
+	for _, problem := range result.ValidationErrors {
+		assert.NotContains(t, problem, "Saldo da ONG")
+	}
+}
+
+func TestParseDonationImportCSV(t *testing.T) {
+	rows, err := ParseDonationImportCSV(strings.NewReader(
+		"\ufeffdate,amount,ngo_id,donor_email,external_ref\n" +
+			"2023-11-20,50.5,1,joao@example.com,kick-1\n" +
+			"2023-11-21,,2,maria@example.com,\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []models.DonationImportRow{
+		{DonorEmail: "joao@example.com", NGOID: 1, Amount: 50.5, Date: "2023-11-20", ExternalRef: "kick-1"},
+		{DonorEmail: "maria@example.com", NGOID: 2, Date: "2023-11-21"},
+	}, rows)
+
+	_, err = ParseDonationImportCSV(strings.NewReader("donor_email,ngo_id,date\n"))
+	assert.ErrorIs(t, err, ErrInvalidImportCSV)
+
+	_, err = ParseDonationImportCSV(strings.NewReader("donor_email,ngo_id,amount,date\njoao@example.com,1,\"50,00\",2023-11-20\n"))
+	assert.ErrorIs(t, err, ErrInvalidImportCSV)
+	assert.Contains(t, err.Error(), "linha 2")
+
+	_, err = ParseDonationImportCSV(strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrDonationImportEmpty)
+}
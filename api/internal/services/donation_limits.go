@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"trackable-donations/api/internal/models"
+)
+
+var (
+	ErrDonationBelowMinimum = errors.New("valor abaixo do mínimo permitido para doações")
+	ErrDonationAboveMaximum = errors.New("valor acima do máximo permitido para doações")
+)
+
+// Limites padrão do valor de uma doação, em reais
+const (
+	DefaultMinDonationAmount = 1.0
+	DefaultMaxDonationAmount = 1_000_000.0
+)
+
+// DonationLimits são os valores mínimo e máximo, em reais, aceitos em uma doação
+type DonationLimits struct {
+	Min float64
+	Max float64
+}
+
+// DefaultDonationLimits são os limites usados quando nenhum é configurado
+var DefaultDonationLimits = DonationLimits{Min: DefaultMinDonationAmount, Max: DefaultMaxDonationAmount}
+
+// SetDonationLimits define os limites de valor aplicados às novas doações; as ONGs com
+// limites próprios (ver models.NGO) os substituem
+func (s *DonationService) SetDonationLimits(limits DonationLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+}
+
+// forNGO retorna os limites da ONG: os definidos no cadastro dela prevalecem sobre os gerais
+func (l DonationLimits) forNGO(ngo models.NGO) DonationLimits {
+	if ngo.MinDonationAmount != nil {
+		l.Min = *ngo.MinDonationAmount
+	}
+	if ngo.MaxDonationAmount != nil {
+		l.Max = *ngo.MaxDonationAmount
+	}
+	return l
+}
+
+// check verifica se o valor, em reais, está dentro dos limites, informando o limite violado
+func (l DonationLimits) check(amount float64) error {
+	if amount < l.Min {
+		return fmt.Errorf("%w: o mínimo é R$ %.2f (recebido R$ %.2f)", ErrDonationBelowMinimum, l.Min, amount)
+	}
+	if amount > l.Max {
+		return fmt.Errorf("%w: o máximo é R$ %.2f (recebido R$ %.2f)", ErrDonationAboveMaximum, l.Max, amount)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDonationAmountLimits(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	_, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 0.01, DonorID: 1, NGOID: 1})
+	assert.ErrorIs(t, err, ErrDonationBelowMinimum)
+	assert.ErrorContains(t, err, "o mínimo é R$ 1.00")
+
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 999_999_999, DonorID: 1, NGOID: 1})
+	assert.ErrorIs(t, err, ErrDonationAboveMaximum)
+	assert.ErrorContains(t, err, "o máximo é R$ 1000000.00")
+
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 1, DonorID: 1, NGOID: 1})
+	assert.NoError(t, err, "Os limites são inclusivos")
+	assert.Len(t, donationSvc.snapshotDonations(), 1)
+
+	// Os limites valem para o valor convertido em reais
+	donationSvc.SetExchangeRateProvider(&mockExchangeRates{rates: map[string]float64{"USD": 5}})
+	donationSvc.SetDonationLimits(DonationLimits{Min: 10, Max: 100})
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 25, Currency: "USD", DonorID: 1, NGOID: 1})
+	assert.ErrorIs(t, err, ErrDonationAboveMaximum)
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 15, DonorID: 1, NGOID: 1})
+	assert.NoError(t, err)
+}
+
+func TestNGODonationLimitsOverrideDefaults(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	ngo, err := donationSvc.findNGO(2)
+	require.NoError(t, err)
+	minimum := 50.0
+	ngo.MinDonationAmount = &minimum
+	for i := range donationSvc.ngos {
+		if donationSvc.ngos[i].ID == ngo.ID {
+			donationSvc.replaceNGO(i, ngo)
+		}
+	}
+
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 20, DonorID: 1, NGOID: 2})
+	assert.ErrorIs(t, err, ErrDonationBelowMinimum)
+	assert.ErrorContains(t, err, "o mínimo é R$ 50.00")
+
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 20, DonorID: 1, NGOID: 1})
+	assert.NoError(t, err, "As demais ONGs seguem os limites gerais")
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 2_000_000, DonorID: 1, NGOID: 2})
+	assert.ErrorIs(t, err, ErrDonationAboveMaximum, "Sem máximo próprio, vale o geral")
+}
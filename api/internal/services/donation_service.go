@@ -1,183 +1,832 @@
 package services
 
 import (
+	"crypto/ecdsa"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"net/mail"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"trackable-donations/api/internal/metrics"
 	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/repository"
+	"trackable-donations/api/internal/utils"
+	"trackable-donations/blockchain-node/core"
 )
 
 // DonationService gerencia operações relacionadas a doações
 type DonationService struct {
-	// Em um sistema real, teríamos repositórios para acesso ao banco de dados
-	// Aqui usaremos dados em memória para demonstração
+	// mu protege os dados em memória abaixo, acessados concorrentemente pelos handlers e jobs
+	mu sync.RWMutex
+
+	// store persiste os dados (ver NewDonationServiceWithStore); as listas abaixo são a cópia
+	// em memória usada nas consultas, carregada na inicialização e atualizada a cada gravação
+	store          *repository.Store
 	donations      []models.Donation
 	ngos           []models.NGO
 	users          []models.User
 	resourceUsages []models.ResourceUsage
 	receipts       []models.DonationReceipt
+	campaigns      []models.Campaign
+	platformLedger models.PlatformLedger
+
+	// ngoIndex e userIndex indexam as listas acima pelo ID, para as buscas feitas em laço
+	// pelos demais serviços; toda inclusão ou alteração nas listas deve atualizá-los
+	ngoIndex  map[uint]models.NGO
+	userIndex map[uint]models.User
+
+	recurringDonations []models.RecurringDonation
+
+	// processing marca as doações com confirmação de pagamento ou estorno em andamento
+	processing map[uint]bool
+
+	// aggregatesVersion muda a cada alteração que afeta os agregados públicos (doação concluída
+	// ou estornada, ONG aprovada, alterada ou mesclada); os caches de agregados a comparam
+	// para saber se ficaram desatualizados
+	aggregatesVersion atomic.Uint64
+
+	// publicMetadataKeys são as chaves de metadados que podem aparecer nas visões públicas
+	publicMetadataKeys map[string]bool
+
+	// ipfs armazena comprovantes e documentos (ver SetIPFSClient); compartilhado com os demais serviços
+	ipfs IPFSClient
+
+	// notifier envia o comprovante ao doador após a confirmação do pagamento (ver SetNotifier)
+	notifier Notifier
+
+	// rates converte para reais as doações feitas em outras moedas (ver SetExchangeRateProvider)
+	rates ExchangeRateProvider
+
+	// limits são os valores mínimo e máximo das doações (ver SetDonationLimits)
+	limits DonationLimits
+
+	// clock fornece o horário das doações, usuários e estornos (ver SetClock)
+	clock Clock
+
+	// blockchain registra as doações confirmadas (ver SetBlockchain), protegida por chainMu.
+	// Os blocos e as chaves são persistidos no store e restaurados por loadChain.
+	chainMu    sync.Mutex
+	blockchain *core.Blockchain
+	// chainKeys guarda as chaves dos titulares (doadores e ONGs) na blockchain
+	chainKeys map[string]*ecdsa.PrivateKey
 }
 
-// NewDonationService cria uma nova instância do serviço
+// NewDonationService cria uma nova instância do serviço com armazenamento em memória,
+// já com as ONGs e usuários de demonstração (ver Seed)
 func NewDonationService() *DonationService {
-	// Inicializa com algumas ONGs para demonstração
+	s, err := NewDonationServiceWithStore(repository.NewMemoryStore())
+	if err == nil {
+		err = s.Seed()
+	}
+	if err != nil {
+		// O armazenamento em memória nunca falha
+		panic(err)
+	}
+	return s
+}
+
+// NewDonationServiceWithStore cria o serviço sobre o armazenamento informado, carregando
+// os dados já persistidos. Os dados de demonstração só são criados chamando Seed.
+func NewDonationServiceWithStore(store *repository.Store) (*DonationService, error) {
+	s := &DonationService{
+		store:      store,
+		ngoIndex:   map[uint]models.NGO{},
+		userIndex:  map[uint]models.User{},
+		processing: map[uint]bool{},
+
+		ipfs:       NewMemoryIPFSClient(),
+		notifier:   LogNotifier{},
+		rates:      FixedExchangeRates{},
+		limits:     DefaultDonationLimits,
+		clock:      RealClock{},
+		blockchain: core.NewBlockchain(),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.loadChain(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Seed cria as ONGs e usuários de demonstração quando ainda não há nenhuma ONG nem usuário;
+// sobre dados já existentes não faz nada
+func (s *DonationService) Seed() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.ngos) > 0 || len(s.users) > 0 {
+		return nil
+	}
+	return s.seedDemoData()
+}
+
+// load carrega os dados persistidos e recalcula o caixa de gorjetas da plataforma
+func (s *DonationService) load() error {
+	var err error
+	if s.donations, err = s.store.Donations.FindAll(); err != nil {
+		return fmt.Errorf("falha ao carregar doações: %w", err)
+	}
+	if s.ngos, err = s.store.NGOs.FindAll(); err != nil {
+		return fmt.Errorf("falha ao carregar ONGs: %w", err)
+	}
+	if s.users, err = s.store.Users.FindAll(); err != nil {
+		return fmt.Errorf("falha ao carregar usuários: %w", err)
+	}
+	for _, ngo := range s.ngos {
+		s.ngoIndex[ngo.ID] = ngo
+	}
+	for _, user := range s.users {
+		s.userIndex[user.ID] = user
+	}
+	if s.receipts, err = s.store.Receipts.FindAll(); err != nil {
+		return fmt.Errorf("falha ao carregar comprovantes: %w", err)
+	}
+	if s.resourceUsages, err = s.store.ResourceUsages.FindAll(); err != nil {
+		return fmt.Errorf("falha ao carregar usos de recursos: %w", err)
+	}
+	if s.campaigns, err = s.store.Campaigns.FindAll(); err != nil {
+		return fmt.Errorf("falha ao carregar campanhas: %w", err)
+	}
+	if s.recurringDonations, err = s.store.RecurringDonations.FindAll(); err != nil {
+		return fmt.Errorf("falha ao carregar doações recorrentes: %w", err)
+	}
+
+	for _, donation := range s.donations {
+		if donation.Status == "completed" && donation.Tip > 0 {
+			s.platformLedger.TotalTips += donation.Tip
+			s.platformLedger.TipsCount++
+		}
+	}
+	return nil
+}
+
+// seedDemoData cria as ONGs e usuários de demonstração; deve ser chamado com s.mu bloqueado
+func (s *DonationService) seedDemoData() error {
 	ngos := []models.NGO{
-		{ID: 1, Name: "Alimentando Esperança", Description: "Distribuição de alimentos para pessoas em situação de vulnerabilidade", Category: "Alimentação", LogoURL: "https://example.com/logo1.png"},
-		{ID: 2, Name: "Saúde para Todos", Description: "Fornecimento de medicamentos e atendimento médico gratuito", Category: "Saúde", LogoURL: "https://example.com/logo2.png"},
-		{ID: 3, Name: "Educação é Futuro", Description: "Apoio educacional para crianças de baixa renda", Category: "Educação", LogoURL: "https://example.com/logo3.png"},
+		{Name: "Alimentando Esperança", Description: "Distribuição de alimentos para pessoas em situação de vulnerabilidade", Category: "Alimentação", Email: "contato@alimentandoesperanca.org.br", Phone: "(11) 3333-1001", State: "SP", Region: "Sudeste", LogoURL: "https://example.com/logo1.png", Status: models.NGOActive},
+		{Name: "Saúde para Todos", Description: "Fornecimento de medicamentos e atendimento médico gratuito", Category: "Saúde", Email: "contato@saudeparatodos.org.br", Phone: "(21) 3333-2002", State: "RJ", Region: "Sudeste", LogoURL: "https://example.com/logo2.png", Status: models.NGOActive},
+		{Name: "Educação é Futuro", Description: "Apoio educacional para crianças de baixa renda", Category: "Educação", Email: "contato@educacaoefuturo.org.br", Phone: "(31) 3333-3003", State: "MG", Region: "Sudeste", LogoURL: "https://example.com/logo3.png", Status: models.NGOActive},
 	}
 
-	// Inicializa com alguns usuários para demonstração
 	users := []models.User{
-		{ID: 1, Name: "João Silva", Email: "joao@example.com", CreatedAt: time.Now()},
-		{ID: 2, Name: "Maria Oliveira", Email: "maria@example.com", CreatedAt: time.Now()},
+		{Name: "João Silva", Email: "joao@example.com", CreatedAt: s.clock.Now()},
+		{Name: "Maria Oliveira", Email: "maria@example.com", CreatedAt: s.clock.Now()},
 	}
 
-	return &DonationService{
-		donations:      []models.Donation{},
-		ngos:           ngos,
-		users:          users,
-		resourceUsages: []models.ResourceUsage{},
-		receipts:       []models.DonationReceipt{},
+	for i := range ngos {
+		if err := s.store.NGOs.Create(&ngos[i]); err != nil {
+			return fmt.Errorf("falha ao criar ONG de demonstração: %w", err)
+		}
+	}
+	for i := range users {
+		if err := s.store.Users.Create(&users[i]); err != nil {
+			return fmt.Errorf("falha ao criar usuário de demonstração: %w", err)
+		}
+	}
+	s.ngos = append(s.ngos, ngos...)
+	s.users = append(s.users, users...)
+	for _, ngo := range ngos {
+		s.ngoIndex[ngo.ID] = ngo
 	}
+	for _, user := range users {
+		s.userIndex[user.ID] = user
+	}
+	return nil
+}
+
+// SetIPFSClient define o cliente IPFS usado para armazenar comprovantes e documentos
+func (s *DonationService) SetIPFSClient(client IPFSClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ipfs = client
+}
+
+// SetNotifier define como os comprovantes são enviados aos doadores
+func (s *DonationService) SetNotifier(notifier Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifier = notifier
+}
+
+// SetExchangeRateProvider define as cotações usadas nas doações em moeda estrangeira
+func (s *DonationService) SetExchangeRateProvider(provider ExchangeRateProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates = provider
+}
+
+// SetClock define o relógio usado pelo serviço
+func (s *DonationService) SetClock(clock Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+}
+
+// now retorna o horário atual do relógio do serviço; não deve ser chamado com s.mu bloqueado
+func (s *DonationService) now() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clock.Now()
 }
 
-// GetAllNGOs retorna todas as ONGs disponíveis
+// exchangeFor valida a moeda e obtém a cotação; a consulta ao provedor é feita sem
+// bloquear o serviço, pois pode depender de um serviço externo
+func (s *DonationService) exchangeFor(currency string) (exchange, error) {
+	code, err := normalizeCurrency(currency)
+	if err != nil {
+		return exchange{}, err
+	}
+	if code == models.DefaultCurrency {
+		return brlExchange, nil
+	}
+
+	s.mu.RLock()
+	rates := s.rates
+	s.mu.RUnlock()
+
+	rate, err := rates.Rate(code)
+	if err != nil {
+		return exchange{}, err
+	}
+	return exchange{currency: code, rate: rate}, nil
+}
+
+// ipfsClient retorna o cliente IPFS configurado
+func (s *DonationService) ipfsClient() IPFSClient {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ipfs
+}
+
+// ipfsContentExists indica se a referência aponta para um conteúdo existente no IPFS; falhas
+// na consulta contam como conteúdo não encontrado
+func (s *DonationService) ipfsContentExists(reference string) bool {
+	if reference == "" {
+		return false
+	}
+
+	exists, err := s.ipfsClient().Exists(reference)
+	if err != nil {
+		log.Printf("Erro ao consultar a referência %s no IPFS: %v", reference, err)
+		return false
+	}
+	return exists
+}
+
+// GetAllNGOs retorna todas as ONGs, inclusive as suspensas, para as visões históricas
+// (registros mesclados em outra ONG são omitidos)
 func (s *DonationService) GetAllNGOs() []models.NGO {
-	return s.ngos
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ngos := []models.NGO{}
+	for _, ngo := range s.ngos {
+		if ngo.Status != models.NGOMerged {
+			ngos = append(ngos, ngo)
+		}
+	}
+	return ngos
+}
+
+// GetNGOsAcceptingDonations retorna as ONGs que podem receber novas doações
+// (sem as suspensas nem as mescladas em outra ONG)
+func (s *DonationService) GetNGOsAcceptingDonations() []models.NGO {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ngos := []models.NGO{}
+	for _, ngo := range s.ngos {
+		if ngo.Status != models.NGOMerged && ngo.Status != models.NGOSuspended {
+			ngos = append(ngos, ngo)
+		}
+	}
+	return ngos
 }
 
+// ErrNGONotFound indica que a ONG não existe
+var ErrNGONotFound = errors.New("ONG não encontrada")
+
+// ErrNGOSuspended indica que a ONG está suspensa e não recebe novas doações
+var ErrNGOSuspended = errors.New("ONG suspensa: não está recebendo novas doações")
+
 // GetNGOByID busca uma ONG pelo ID
 func (s *DonationService) GetNGOByID(id uint) (models.NGO, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.findNGO(id)
+}
+
+// findNGO busca uma ONG pelo ID, primeiro no índice; deve ser chamado com s.mu bloqueado
+func (s *DonationService) findNGO(id uint) (models.NGO, error) {
+	if ngo, ok := s.ngoIndex[id]; ok {
+		return ngo, nil
+	}
 	for _, ngo := range s.ngos {
 		if ngo.ID == id {
 			return ngo, nil
 		}
 	}
-	return models.NGO{}, errors.New("ONG não encontrada")
+	return models.NGO{}, ErrNGONotFound
+}
+
+// addNGO adiciona uma ONG aprovada (já persistida) à lista de ONGs que podem receber doações
+func (s *DonationService) addNGO(ngo models.NGO) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ngos = append(s.ngos, ngo)
+	s.ngoIndex[ngo.ID] = ngo
+	s.aggregatesVersion.Add(1)
+}
+
+// replaceNGO substitui a ONG na posição informada da lista, mantendo o índice em sincronia;
+// deve ser chamado com s.mu bloqueado para escrita
+func (s *DonationService) replaceNGO(index int, ngo models.NGO) {
+	s.ngos[index] = ngo
+	s.ngoIndex[ngo.ID] = ngo
+}
+
+var (
+	// ErrUserNotFound indica um usuário (doador) inexistente
+	ErrUserNotFound = errors.New("usuário não encontrado")
+	// ErrInvalidEmail indica um endereço de e-mail malformado
+	ErrInvalidEmail = errors.New("e-mail inválido")
+	// ErrEmailAlreadyRegistered indica que já existe um usuário com o e-mail informado. A
+	// mensagem não confirma a existência da conta, para dificultar a enumeração de e-mails.
+	ErrEmailAlreadyRegistered = errors.New("não foi possível concluir o cadastro com este e-mail")
+)
+
+// RegisterUser cadastra um novo doador. O e-mail deve ser um endereço simples
+// (sem nome de exibição) e único, sem diferenciar maiúsculas de minúsculas.
+// publicRecognition registra se o doador aceita aparecer no ranking público.
+func (s *DonationService) RegisterUser(name, email string, publicRecognition bool) (models.User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.User{}, errors.New("nome é obrigatório")
+	}
+	email = strings.TrimSpace(email)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return models.User{}, ErrInvalidEmail
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) {
+			return models.User{}, ErrEmailAlreadyRegistered
+		}
+	}
+
+	user := models.User{Name: name, Email: email, PublicRecognition: publicRecognition, CreatedAt: s.clock.Now()}
+	if err := s.store.Users.Create(&user); err != nil {
+		return models.User{}, fmt.Errorf("falha ao salvar o usuário: %w", err)
+	}
+	s.users = append(s.users, user)
+	s.userIndex[user.ID] = user
+	return user, nil
 }
 
 // GetUserByID busca um usuário pelo ID
 func (s *DonationService) GetUserByID(id uint) (models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.findUser(id)
+}
+
+// findUser busca um usuário pelo ID, primeiro no índice; deve ser chamado com s.mu bloqueado
+func (s *DonationService) findUser(id uint) (models.User, error) {
+	if user, ok := s.userIndex[id]; ok {
+		return user, nil
+	}
 	for _, user := range s.users {
 		if user.ID == id {
 			return user, nil
 		}
 	}
-	return models.User{}, errors.New("Usuário não encontrado")
+	return models.User{}, ErrUserNotFound
 }
 
-// ProcessDonation processa uma nova doação
+// ProcessDonation processa uma nova doação. Doações em outra moeda são convertidas para
+// reais pela cotação do momento; a moeda e o valor originais ficam registrados.
 func (s *DonationService) ProcessDonation(req models.DonationRequest) (models.DonationResponse, error) {
-	// Verificar se a ONG existe
-	_, err := s.GetNGOByID(req.NGOID)
+	exchange, err := s.exchangeFor(req.Currency)
+	if err != nil {
+		return models.DonationResponse{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.processDonation(req, exchange)
+}
+
+// processDonation cria a doação pendente com os valores convertidos pela cotação informada;
+// deve ser chamado com s.mu bloqueado para escrita
+func (s *DonationService) processDonation(req models.DonationRequest, exchange exchange) (models.DonationResponse, error) {
+	// Verificar se a ONG existe e pode receber doações
+	ngo, err := s.findNGO(req.NGOID)
 	if err != nil {
 		return models.DonationResponse{}, err
 	}
+	switch ngo.Status {
+	case models.NGOSuspended:
+		return models.DonationResponse{}, ErrNGOSuspended
+	case models.NGOMerged:
+		// As doações devem ir para a ONG canônica (ver NGO.MergedInto)
+		return models.DonationResponse{}, ErrNGOMerged
+	}
+
+	// A campanha, quando informada, deve ser da própria ONG
+	if req.CampaignID != 0 {
+		if err := s.checkCampaign(ngo.ID, req.CampaignID); err != nil {
+			return models.DonationResponse{}, err
+		}
+	}
 
 	// Verificar se o doador existe
-	_, err = s.GetUserByID(req.DonorID)
+	_, err = s.findUser(req.DonorID)
+	if err != nil {
+		return models.DonationResponse{}, err
+	}
+
+	// Os limites valem para o valor já convertido em reais
+	amount := exchange.toBRL(req.Amount)
+	if err := s.limits.forNGO(ngo).check(amount); err != nil {
+		return models.DonationResponse{}, err
+	}
+
+	metadata, err := utils.SanitizeMetadata(req.Metadata)
 	if err != nil {
 		return models.DonationResponse{}, err
 	}
 
-	// Criar nova doação
-	donationID := uint(len(s.donations) + 1) // Em um banco real, seria auto-incremento
+	// Criar nova doação (o ID vem do banco)
 	donation := models.Donation{
-		ID:        donationID,
-		Amount:    req.Amount,
-		DonorID:   req.DonorID,
-		NGOID:     req.NGOID,
-		CreatedAt: time.Now(),
-		Status:    "pending", // Inicialmente pendente
+		Amount:         amount,
+		Tip:            exchange.toBRL(req.Tip),
+		DonorID:        req.DonorID,
+		NGOID:          req.NGOID,
+		CreatedAt:      s.clock.Now(),
+		Status:         "pending", // Inicialmente pendente
+		Metadata:       metadata,
+		Currency:       exchange.currency,
+		OriginalAmount: req.Amount,
+		ExchangeRate:   exchange.rate,
+		DonorAnonymous: req.DonorAnonymous,
+		CampaignID:     req.CampaignID,
 	}
 
-	// Adicionar à lista (em um sistema real, seria salvo no banco)
+	if err := s.store.Donations.Create(&donation); err != nil {
+		return models.DonationResponse{}, fmt.Errorf("falha ao salvar a doação: %w", err)
+	}
 	s.donations = append(s.donations, donation)
-
-	// Simular url de pagamento
-	paymentURL := fmt.Sprintf("https://payment-gateway-mock.com/pay?donationId=%d&amount=%.2f", donation.ID, donation.Amount)
+	metrics.DonationsCreated.Inc()
 
 	return models.DonationResponse{
 		ID:         donation.ID,
 		Status:     donation.Status,
-		PaymentURL: paymentURL,
+		PaymentURL: paymentURL(donation),
+		Metadata:   donation.Metadata,
 	}, nil
 }
 
-// MockPaymentConfirmation simula a confirmação de pagamento pelo gateway
+// SetPublicMetadataKeys define quais chaves de metadados podem ser exibidas nas visões públicas
+func (s *DonationService) SetPublicMetadataKeys(keys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.publicMetadataKeys = make(map[string]bool, len(keys))
+	for _, key := range keys {
+		s.publicMetadataKeys[key] = true
+	}
+}
+
+// publicMetadata filtra os metadados de uma doação, mantendo apenas as chaves liberadas
+func (s *DonationService) publicMetadata(metadata map[string]string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var public map[string]string
+	for key, value := range metadata {
+		if s.publicMetadataKeys[key] {
+			if public == nil {
+				public = make(map[string]string)
+			}
+			public[key] = value
+		}
+	}
+	return public
+}
+
+// paymentURL simula a url de pagamento (o doador paga a doação mais a gorjeta, se houver)
+func paymentURL(donation models.Donation) string {
+	return fmt.Sprintf("https://payment-gateway-mock.com/pay?donationId=%d&amount=%.2f", donation.ID, donation.Amount+donation.Tip)
+}
+
+var (
+	// ErrDonationNotFound indica que a doação não existe
+	ErrDonationNotFound = errors.New("doação não encontrada")
+	// ErrDonationNotPending indica que a doação não aguarda mais pagamento (ex.: estornada)
+	ErrDonationNotPending = errors.New("doação não está aguardando pagamento")
+	// ErrPaymentConfirmationInProgress indica que outra confirmação da mesma doação está em andamento
+	ErrPaymentConfirmationInProgress = errors.New("confirmação de pagamento já em andamento")
+)
+
+// MockPaymentConfirmation simula a confirmação de pagamento pelo gateway. A confirmação
+// é idempotente: repeti-la para uma doação já concluída apenas retorna o resultado
+// anterior, sem registrar outra transação nem outro comprovante.
 func (s *DonationService) MockPaymentConfirmation(donationID uint) (models.DonationResponse, error) {
-	// Encontrar a doação
-	var donation models.Donation
-	var donorID uint
-	var ngoID uint
-	found := false
-
-	for i, d := range s.donations {
-		if d.ID == donationID {
-			donorID = d.DonorID
-			ngoID = d.NGOID
-			donation = d
+	// Encontrar a doação e reservá-la para esta confirmação
+	s.mu.Lock()
+	donation, found := s.findDonation(donationID)
+	switch {
+	case !found:
+		s.mu.Unlock()
+		return models.DonationResponse{}, ErrDonationNotFound
+	case donation.Status == "completed":
+		s.mu.Unlock()
+		return donationResponse(donation), nil
+	case donation.Status != "pending":
+		s.mu.Unlock()
+		return models.DonationResponse{}, ErrDonationNotPending
+	case s.processing[donationID]:
+		s.mu.Unlock()
+		return models.DonationResponse{}, ErrPaymentConfirmationInProgress
+	}
+	s.processing[donationID] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.processing, donationID)
+		s.mu.Unlock()
+	}()
+
+	// Registrar a doação na blockchain sem bloquear os dados em memória durante a mineração;
+	// o hash do bloco minerado é a referência da transação
+	transactionHash := s.recordDonationOnBlockchain(donation)
+	log.Printf("Doação %d registrada na blockchain: %s", donation.ID, transactionHash)
+
+	// Enviar o comprovante ao IPFS também fora do lock
+	s.mu.RLock()
+	receipt := s.buildReceipt(donation, donation.DonorID, donation.NGOID)
+	s.mu.RUnlock()
+	receipt.TransactionHash = transactionHash
+	s.uploadReceipt(&receipt)
+
+	s.mu.Lock()
+	for i := range s.donations {
+		if s.donations[i].ID == donationID {
 			// Atualizar o status
-			s.donations[i].Status = "completed"
-			// Gerar hash fictício para simulação de blockchain
-			s.donations[i].TransactionHash = generateMockTransactionHash()
-			donation = s.donations[i]
-			found = true
+			updated := s.donations[i]
+			updated.Status = "completed"
+			updated.TransactionHash = transactionHash
+			if err := s.store.Donations.Save(&updated); err != nil {
+				s.mu.Unlock()
+				return models.DonationResponse{}, fmt.Errorf("falha ao salvar a doação: %w", err)
+			}
+			s.donations[i] = updated
+			donation = updated
+			s.aggregatesVersion.Add(1)
 			break
 		}
 	}
 
-	if !found {
-		return models.DonationResponse{}, errors.New("doação não encontrada")
+	// A gorjeta vai para o caixa da plataforma, nunca para o saldo da ONG
+	if donation.Tip > 0 {
+		s.platformLedger.TotalTips += donation.Tip
+		s.platformLedger.TipsCount++
 	}
 
-	// Simular registro na blockchain (em um sistema real, registraríamos na blockchain)
-	log.Printf("Registrando doação na blockchain: %v", donation)
-
-	// Gerar comprovante de doação
-	s.generateDonationReceipt(donation, donorID, ngoID)
+	// Registrar o comprovante de doação
+	receipt = s.storeReceipt(receipt)
 
 	// Gerar uso dos recursos (mockado)
 	s.mockResourceUsage(donation)
+	notifier := s.notifier
+	s.mu.Unlock()
+
+	// Enviar o comprovante ao doador; uma falha no envio não desfaz a doação confirmada
+	if err := sendReceipt(notifier, receipt); err != nil {
+		log.Printf("Erro ao enviar comprovante da doação %d ao doador: %v", donation.ID, err)
+	}
+
+	return donationResponse(donation), nil
+}
+
+var (
+	// ErrDonationNotRefundable indica que apenas doações concluídas podem ser estornadas
+	ErrDonationNotRefundable = errors.New("apenas doações concluídas podem ser estornadas")
+	// ErrDonationHasExpenses indica que a doação já financia gastos e não pode ser estornada
+	ErrDonationHasExpenses = errors.New("a doação possui gastos registrados e não pode ser estornada")
+	// ErrRefundInProgress indica que outro estorno da mesma doação está em andamento
+	ErrRefundInProgress = errors.New("estorno já em andamento")
+)
 
+// refundDonation estorna uma doação concluída: registra a transação reversa na blockchain
+// e marca a doação como "refunded", tirando-a dos saldos e totais. hasExpenses indica se a
+// doação financia gastos; deve ser chamado com o lock do serviço de gastos (ver
+// ExpenseService.RefundDonation), para que nenhum gasto seja registrado durante o estorno.
+func (s *DonationService) refundDonation(id uint, reason string, hasExpenses bool) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("o motivo do estorno é obrigatório")
+	}
+
+	s.mu.Lock()
+	donation, found := s.findDonation(id)
+	switch {
+	case !found:
+		s.mu.Unlock()
+		return ErrDonationNotFound
+	case donation.Status != "completed":
+		s.mu.Unlock()
+		return ErrDonationNotRefundable
+	case s.processing[id]:
+		s.mu.Unlock()
+		return ErrRefundInProgress
+	}
+	if hasExpenses {
+		s.mu.Unlock()
+		return ErrDonationHasExpenses
+	}
+	s.processing[id] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.processing, id)
+		s.mu.Unlock()
+	}()
+
+	// Minerar a transação reversa fora do lock, como na confirmação
+	refundHash := s.recordRefundOnBlockchain(donation)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.donations {
+		if s.donations[i].ID != id {
+			continue
+		}
+		now := s.clock.Now()
+		updated := s.donations[i]
+		updated.Status = "refunded"
+		updated.RefundTransactionHash = refundHash
+		updated.RefundReason = reason
+		updated.RefundedAt = &now
+		if err := s.store.Donations.Save(&updated); err != nil {
+			return fmt.Errorf("falha ao salvar o estorno da doação: %w", err)
+		}
+		s.donations[i] = updated
+		s.aggregatesVersion.Add(1)
+
+		// A gorjeta também é devolvida ao doador
+		if updated.Tip > 0 {
+			s.platformLedger.TotalTips -= updated.Tip
+			s.platformLedger.TipsCount--
+		}
+		break
+	}
+
+	log.Printf("Doação %d estornada (transação reversa %s): %s", id, refundHash, reason)
+	return nil
+}
+
+// donationResponse monta a resposta da confirmação de pagamento
+func donationResponse(donation models.Donation) models.DonationResponse {
 	return models.DonationResponse{
 		ID:              donation.ID,
 		Status:          donation.Status,
 		TransactionHash: donation.TransactionHash,
-	}, nil
+		Metadata:        donation.Metadata,
+	}
+}
+
+// findDonation busca uma doação pelo ID; deve ser chamado com s.mu bloqueado
+func (s *DonationService) findDonation(id uint) (models.Donation, bool) {
+	for _, donation := range s.donations {
+		if donation.ID == id {
+			return donation, true
+		}
+	}
+	return models.Donation{}, false
 }
 
-// generateDonationReceipt gera um comprovante de doação
-func (s *DonationService) generateDonationReceipt(donation models.Donation, donorID, ngoID uint) models.DonationReceipt {
-	donor, _ := s.GetUserByID(donorID)
-	ngo, _ := s.GetNGOByID(ngoID)
+// snapshotDonations retorna uma cópia das doações, que pode ser percorrida sem manter o lock
+func (s *DonationService) snapshotDonations() []models.Donation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]models.Donation(nil), s.donations...)
+}
 
-	// Simular um hash IPFS para o comprovante
-	ipfsHash := fmt.Sprintf("Qm%s", generateMockHash(46))
+// snapshotNGOIndex retorna as ONGs, inclusive as mescladas, indexadas pelo ID, para as
+// consultas feitas a cada doação nos laços de agregação
+func (s *DonationService) snapshotNGOIndex() map[uint]models.NGO {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	receiptID := uint(len(s.receipts) + 1)
-	receipt := models.DonationReceipt{
-		ID:              receiptID,
-		DonationID:      donation.ID,
-		DonorName:       donor.Name,
-		DonorEmail:      donor.Email,
-		NGOName:         ngo.Name,
-		Amount:          donation.Amount,
-		Date:            donation.CreatedAt,
-		TransactionHash: donation.TransactionHash,
-		IPFSHash:        ipfsHash,
-		PdfURL:          fmt.Sprintf("https://ipfs.example.com/ipfs/%s", ipfsHash),
+	index := make(map[uint]models.NGO, len(s.ngos))
+	for _, ngo := range s.ngos {
+		index[ngo.ID] = ngo
+	}
+	return index
+}
+
+// snapshotUserIndex retorna os usuários indexados pelo ID
+func (s *DonationService) snapshotUserIndex() map[uint]models.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index := make(map[uint]models.User, len(s.users))
+	for _, user := range s.users {
+		index[user.ID] = user
 	}
+	return index
+}
 
+// snapshotReceipts retorna uma cópia dos comprovantes, que pode ser percorrida sem manter o lock
+func (s *DonationService) snapshotReceipts() []models.DonationReceipt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]models.DonationReceipt(nil), s.receipts...)
+}
+
+// uploadReceipt envia o comprovante ao IPFS e preenche o CID e o link. O e-mail do doador
+// não é publicado, pois o conteúdo no IPFS é público. Uma falha no envio não impede a
+// confirmação: o comprovante fica sem referência no IPFS e o erro é registrado no log.
+func (s *DonationService) uploadReceipt(receipt *models.DonationReceipt) {
+	public := *receipt
+	public.DonorEmail = ""
+
+	content, err := json.Marshal(public)
+	if err == nil {
+		receipt.IPFSHash, err = s.ipfsClient().Add(content)
+	}
+	if err != nil {
+		log.Printf("Erro ao enviar comprovante da doação %d ao IPFS: %v", receipt.DonationID, err)
+		return
+	}
+	receipt.PdfURL = fmt.Sprintf("https://ipfs.example.com/ipfs/%s", receipt.IPFSHash)
+}
+
+// storeReceipt registra o comprovante de doação; deve ser chamado com s.mu bloqueado para escrita.
+// Uma falha ao salvar é registrada no log sem desfazer a confirmação já gravada.
+func (s *DonationService) storeReceipt(receipt models.DonationReceipt) models.DonationReceipt {
+	if err := s.store.Receipts.Create(&receipt); err != nil {
+		log.Printf("Erro ao salvar comprovante da doação %d: %v", receipt.DonationID, err)
+	}
 	s.receipts = append(s.receipts, receipt)
 	return receipt
 }
 
-// mockResourceUsage simula o uso dos recursos da doação
+// buildReceipt monta os dados do comprovante que não dependem da blockchain nem do IPFS;
+// deve ser chamado com s.mu bloqueado
+func (s *DonationService) buildReceipt(donation models.Donation, donorID, ngoID uint) models.DonationReceipt {
+	donor, _ := s.findUser(donorID)
+	ngo, _ := s.findNGO(ngoID)
+
+	return models.DonationReceipt{
+		DonationID:   donation.ID,
+		DonorName:    donor.Name,
+		DonorEmail:   donor.Email,
+		NGOName:      ngo.Name,
+		Amount:       donation.Amount,
+		Tip:          donation.Tip,
+		TotalCharged: donation.Amount + donation.Tip,
+		Date:         donation.CreatedAt,
+	}
+}
+
+// PreviewReceipt monta a prévia do comprovante de uma doação pendente, sem persistir
+// nada nem gerar registros na blockchain ou no IPFS
+func (s *DonationService) PreviewReceipt(donationID uint) (models.DonationReceipt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, donation := range s.donations {
+		if donation.ID != donationID {
+			continue
+		}
+		if donation.Status != "pending" {
+			return models.DonationReceipt{}, errors.New("prévia disponível apenas para doações pendentes")
+		}
+
+		receipt := s.buildReceipt(donation, donation.DonorID, donation.NGOID)
+		receipt.Preview = true
+		return receipt, nil
+	}
+	return models.DonationReceipt{}, errors.New("doação não encontrada")
+}
+
+// mockResourceUsage simula o uso dos recursos da doação; deve ser chamado com s.mu bloqueado para escrita
 func (s *DonationService) mockResourceUsage(donation models.Donation) {
-	ngo, _ := s.GetNGOByID(donation.NGOID)
+	ngo, _ := s.findNGO(donation.NGOID)
 	amount := donation.Amount
 
 	// Simular diferentes tipos de uso de recursos baseados na categoria da ONG
@@ -227,24 +876,37 @@ func (s *DonationService) mockResourceUsage(donation models.Donation) {
 		ipfsHash := fmt.Sprintf("Qm%s", generateMockHash(46))
 
 		usage := models.ResourceUsage{
-			ID:          uint(len(s.resourceUsages) + i + 1),
 			DonationID:  donation.ID,
 			Description: description,
 			Amount:      usageAmounts[i],
 			Date:        usageDate,
 			ReceiptIPFS: ipfsHash,
 			NGOName:     ngo.Name,
-			CreatedAt:   time.Now(),
+			CreatedAt:   s.clock.Now(),
 		}
 
+		if err := s.store.ResourceUsages.Create(&usage); err != nil {
+			log.Printf("Erro ao salvar uso de recursos da doação %d: %v", donation.ID, err)
+			continue
+		}
 		s.resourceUsages = append(s.resourceUsages, usage)
 	}
 }
 
+// GetPlatformLedger retorna o total de gorjetas recebidas pela plataforma
+func (s *DonationService) GetPlatformLedger() models.PlatformLedger {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.platformLedger
+}
+
 // GetDonationsByDonorID retorna todas as doações de um doador
 func (s *DonationService) GetDonationsByDonorID(donorID uint) ([]models.Donation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	// Verificar se o doador existe
-	_, err := s.GetUserByID(donorID)
+	_, err := s.findUser(donorID)
 	if err != nil {
 		return nil, err
 	}
@@ -261,6 +923,9 @@ func (s *DonationService) GetDonationsByDonorID(donorID uint) ([]models.Donation
 
 // GetDonationReceipt retorna o comprovante de uma doação
 func (s *DonationService) GetDonationReceipt(donationID uint) (models.DonationReceipt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	for _, receipt := range s.receipts {
 		if receipt.DonationID == donationID {
 			return receipt, nil
@@ -271,16 +936,11 @@ func (s *DonationService) GetDonationReceipt(donationID uint) (models.DonationRe
 
 // GetResourceUsagesByDonationID retorna os usos dos recursos de uma doação
 func (s *DonationService) GetResourceUsagesByDonationID(donationID uint) ([]models.ResourceUsage, error) {
-	// Verificar se a doação existe
-	found := false
-	for _, donation := range s.donations {
-		if donation.ID == donationID {
-			found = true
-			break
-		}
-	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if !found {
+	// Verificar se a doação existe
+	if _, found := s.findDonation(donationID); !found {
 		return nil, errors.New("doação não encontrada")
 	}
 
@@ -318,9 +978,9 @@ func (s *DonationService) GetDonorDashboard(donorID uint) (models.DonorDashboard
 	}
 
 	// Calcular métricas fictícias de impacto
-	peopleHelped := int(totalDonated / 50)      // Estima 1 pessoa ajudada a cada R$ 50
-	mealsProvided := int(totalDonated / 10)     // Estima 1 refeição a cada R$ 10
-	medicinesProvided := int(totalDonated / 30) // Estima 1 medicamento a cada R$ 30
+	peopleHelped := int(totalDonated / moneyPerPersonHelped)
+	mealsProvided := int(totalDonated / moneyPerMeal)
+	medicinesProvided := int(totalDonated / moneyPerMedicine)
 
 	metrics := models.ImpactMetrics{
 		TotalDonated:      totalDonated,
@@ -333,6 +993,7 @@ func (s *DonationService) GetDonorDashboard(donorID uint) (models.DonorDashboard
 
 	// Contar todos os usos de recursos relacionados às doações do usuário
 	var usagesCount int
+	s.mu.RLock()
 	for _, usage := range s.resourceUsages {
 		for _, donation := range donations {
 			if usage.DonationID == donation.ID {
@@ -341,6 +1002,7 @@ func (s *DonationService) GetDonorDashboard(donorID uint) (models.DonorDashboard
 			}
 		}
 	}
+	s.mu.RUnlock()
 
 	return models.DonorDashboard{
 		DonorID:     donorID,
@@ -350,3 +1012,86 @@ func (s *DonationService) GetDonorDashboard(donorID uint) (models.DonorDashboard
 		UsagesCount: usagesCount,
 	}, nil
 }
+
+// GenerateDonorHistoryPDF gera um PDF com todo o histórico de doações de um doador,
+// agrupado por ano e por ONG. Doações estornadas aparecem marcadas e fora dos totais.
+func (s *DonationService) GenerateDonorHistoryPDF(donorID uint) ([]byte, error) {
+	donor, err := s.GetUserByID(donorID)
+	if err != nil {
+		return nil, err
+	}
+
+	donations, err := s.GetDonationsByDonorID(donorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Considerar apenas doações efetivadas (concluídas ou estornadas)
+	var history []models.Donation
+	for _, donation := range donations {
+		if donation.Status == "completed" || donation.Status == "refunded" {
+			history = append(history, donation)
+		}
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].CreatedAt.Before(history[j].CreatedAt)
+	})
+
+	// Agrupar por ano e, dentro do ano, por ONG
+	byYear := make(map[int]map[string][]models.Donation)
+	var years []int
+	for _, donation := range history {
+		year := donation.CreatedAt.Year()
+		if _, exists := byYear[year]; !exists {
+			byYear[year] = make(map[string][]models.Donation)
+			years = append(years, year)
+		}
+		ngo, _ := s.GetNGOByID(donation.NGOID)
+		byYear[year][ngo.Name] = append(byYear[year][ngo.Name], donation)
+	}
+
+	doc := utils.NewPDFDocument()
+	doc.Title("Histórico de Doações")
+	doc.Text(fmt.Sprintf("Doador: %s (%s)", donor.Name, donor.Email))
+	doc.Text(fmt.Sprintf("Emitido em: %s", s.clock.Now().Format("02/01/2006 15:04")))
+	doc.Space()
+
+	if len(history) == 0 {
+		doc.Text("Nenhuma doação registrada.")
+	}
+
+	var total float64
+	for _, year := range years {
+		doc.Heading(fmt.Sprintf("Ano %d", year))
+
+		ngoNames := make([]string, 0, len(byYear[year]))
+		for name := range byYear[year] {
+			ngoNames = append(ngoNames, name)
+		}
+		sort.Strings(ngoNames)
+
+		var yearTotal float64
+		for _, name := range ngoNames {
+			doc.Text(fmt.Sprintf("ONG: %s", name))
+			for _, donation := range byYear[year][name] {
+				line := fmt.Sprintf("    %s  #%d  %s  %s", donation.CreatedAt.Format("02/01/2006"),
+					donation.ID, utils.FormatBRL(donation.Amount), donation.TransactionHash)
+				if donation.Status == "refunded" {
+					line += "  [ESTORNADA]"
+				} else {
+					yearTotal += donation.Amount
+				}
+				doc.Text(line)
+			}
+		}
+
+		doc.Text(fmt.Sprintf("Total em %d: %s", year, utils.FormatBRL(yearTotal)))
+		doc.Space()
+		total += yearTotal
+	}
+
+	doc.Heading(fmt.Sprintf("Total doado: %s", utils.FormatBRL(total)))
+
+	return doc.Bytes()
+}
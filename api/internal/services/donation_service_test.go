@@ -0,0 +1,231 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTipExcludedFromNGOBalance(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	transparencySvc := NewTransparencyService(donationSvc, expenseSvc)
+
+	resp, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 100, Tip: 15, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	assert.Contains(t, resp.PaymentURL, "amount=115.00", "O doador deve pagar a doação mais a gorjeta")
+
+	_, err = donationSvc.MockPaymentConfirmation(resp.ID)
+	require.NoError(t, err)
+
+	summary, err := transparencySvc.GetNGOSummary(1)
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, summary.TotalReceived, "A gorjeta não deve contar no total recebido pela ONG")
+	assert.Equal(t, 100.0, summary.AvailableBalance, "A gorjeta não deve contar no saldo da ONG")
+
+	ledger := donationSvc.GetPlatformLedger()
+	assert.Equal(t, 15.0, ledger.TotalTips)
+	assert.Equal(t, 1, ledger.TipsCount)
+
+	receipt, err := donationSvc.GetDonationReceipt(resp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, receipt.Amount)
+	assert.Equal(t, 15.0, receipt.Tip)
+	assert.Equal(t, 115.0, receipt.TotalCharged)
+}
+
+func TestDonationWithoutTipLeavesLedgerEmpty(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	resp, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 50, DonorID: 2, NGOID: 2})
+	require.NoError(t, err)
+	_, err = donationSvc.MockPaymentConfirmation(resp.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.PlatformLedger{}, donationSvc.GetPlatformLedger())
+}
+
+func TestGenerateDonorHistoryPDFAcrossYears(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	for _, req := range []models.DonationRequest{
+		{Amount: 120, DonorID: 1, NGOID: 1},
+		{Amount: 80, DonorID: 1, NGOID: 2},
+	} {
+		resp, err := donationSvc.ProcessDonation(req)
+		require.NoError(t, err)
+		_, err = donationSvc.MockPaymentConfirmation(resp.ID)
+		require.NoError(t, err)
+	}
+	donationSvc.donations[0].CreatedAt = time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC)
+	donationSvc.donations[1].CreatedAt = time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	pdf, err := donationSvc.GenerateDonorHistoryPDF(1)
+	require.NoError(t, err)
+
+	content := string(pdf)
+	assert.True(t, strings.HasPrefix(content, "%PDF-"), "O documento deve ser um PDF")
+	assert.Contains(t, content, "Ano 2023")
+	assert.Contains(t, content, "Ano 2024")
+	assert.Contains(t, content, "10/05/2023")
+	assert.Contains(t, content, "03/02/2024")
+	assert.Contains(t, content, "R$ 120,00")
+	assert.Contains(t, content, "R$ 80,00")
+	assert.Contains(t, content, "Total doado: R$ 200,00")
+}
+
+func TestGenerateDonorHistoryPDFUnknownDonor(t *testing.T) {
+	_, err := NewDonationService().GenerateDonorHistoryPDF(999)
+	assert.Error(t, err)
+}
+
+func TestPreviewReceiptForPendingDonation(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	resp, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 75, Tip: 5, DonorID: 1, NGOID: 3})
+	require.NoError(t, err)
+
+	preview, err := donationSvc.PreviewReceipt(resp.ID)
+	require.NoError(t, err)
+	assert.True(t, preview.Preview)
+	assert.Equal(t, resp.ID, preview.DonationID)
+	assert.Equal(t, "João Silva", preview.DonorName)
+	assert.Equal(t, 80.0, preview.TotalCharged)
+	assert.Empty(t, preview.TransactionHash)
+	assert.Empty(t, preview.IPFSHash)
+
+	// A prévia não deve persistir comprovantes
+	assert.Empty(t, donationSvc.receipts)
+	_, err = donationSvc.GetDonationReceipt(resp.ID)
+	assert.Error(t, err)
+
+	_, err = donationSvc.MockPaymentConfirmation(resp.ID)
+	require.NoError(t, err)
+	_, err = donationSvc.PreviewReceipt(resp.ID)
+	assert.Error(t, err, "Doações confirmadas já possuem comprovante definitivo")
+}
+
+func TestConcurrentDonationsGetDistinctIDs(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	const total = 100
+	ids := make(chan uint, total)
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 10, DonorID: 1, NGOID: 1})
+			assert.NoError(t, err)
+			ids <- resp.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	distinct := make(map[uint]bool)
+	for id := range ids {
+		distinct[id] = true
+	}
+	assert.Len(t, distinct, total)
+
+	donations, err := donationSvc.GetDonationsByDonorID(1)
+	require.NoError(t, err)
+	assert.Len(t, donations, total)
+}
+
+func TestDonationServiceReloadsPersistedData(t *testing.T) {
+	store := repository.NewMemoryStore()
+	first, err := NewDonationServiceWithStore(store)
+	require.NoError(t, err)
+	require.NoError(t, first.Seed())
+	donationID := completeDonation(t, first, models.DonationRequest{Amount: 80, Tip: 5, DonorID: 1, NGOID: 2})
+
+	// Um novo serviço sobre o mesmo armazenamento simula o reinício da API
+	second, err := NewDonationServiceWithStore(store)
+	require.NoError(t, err)
+	require.NoError(t, second.Seed())
+	assert.Len(t, second.ngos, 3, "As ONGs de demonstração não devem ser recriadas")
+
+	receipt, err := second.GetDonationReceipt(donationID)
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, receipt.Amount)
+	assert.Equal(t, 5.0, second.platformLedger.TotalTips)
+
+	resp, err := second.ProcessDonation(models.DonationRequest{Amount: 10, DonorID: 2, NGOID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, donationID+1, resp.ID, "Os IDs continuam a sequência do armazenamento")
+}
+
+func TestDonationServiceSeedsOnlyOnRequest(t *testing.T) {
+	donationSvc, err := NewDonationServiceWithStore(repository.NewMemoryStore())
+	require.NoError(t, err)
+	assert.Empty(t, donationSvc.GetNGOsAcceptingDonations(), "Sem Seed, nenhuma ONG de demonstração é criada")
+
+	require.NoError(t, donationSvc.Seed())
+	assert.Len(t, donationSvc.GetNGOsAcceptingDonations(), 3)
+}
+
+func TestRegisterUserAllowsDonations(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	user, err := donationSvc.RegisterUser("Ana Souza", " ana@example.com ", false)
+	require.NoError(t, err)
+	assert.Equal(t, "ana@example.com", user.Email)
+
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 20, DonorID: user.ID, NGOID: 1})
+	assert.NoError(t, err, "O novo doador deve poder doar")
+
+	_, err = donationSvc.RegisterUser("Ana", "Ana@Example.com", false)
+	assert.ErrorIs(t, err, ErrEmailAlreadyRegistered)
+	for _, email := range []string{"", "ana", "Ana <ana2@example.com>", "ana@example.com\r\nBcc: x@example.com"} {
+		_, err = donationSvc.RegisterUser("Ana", email, false)
+		assert.ErrorIs(t, err, ErrInvalidEmail, email)
+	}
+}
+
+func TestNGOAndUserIndexesFollowUpdates(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	user, err := donationSvc.RegisterUser("Ana Souza", "ana@example.com", false)
+	require.NoError(t, err)
+	found, err := donationSvc.GetUserByID(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user, found)
+
+	_, err = adminSvc.SuspendNGO(1, 7, "registro cassado")
+	require.NoError(t, err)
+	ngo, err := donationSvc.GetNGOByID(1)
+	require.NoError(t, err)
+	assert.Equal(t, models.NGOSuspended, ngo.Status)
+
+	require.NoError(t, adminSvc.MergeNGOs(2, 3, 7))
+	ngo, err = donationSvc.GetNGOByID(3)
+	require.NoError(t, err)
+	assert.Equal(t, models.NGOMerged, ngo.Status)
+	assert.Equal(t, uint(2), ngo.MergedInto)
+
+	_, err = donationSvc.GetNGOByID(99)
+	assert.ErrorIs(t, err, ErrNGONotFound)
+}
+
+func BenchmarkGetNGOByID(b *testing.B) {
+	donationSvc := NewDonationService()
+	for i := 0; i < 5000; i++ {
+		donationSvc.addNGO(models.NGO{ID: uint(len(donationSvc.ngos) + 1), Name: "ONG", Status: models.NGOActive})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := donationSvc.GetNGOByID(uint(i%len(donationSvc.ngos) + 1)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Formatos aceitos por ExportDonorHistory
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// ErrUnsupportedExportFormat indica um formato de exportação diferente de csv e json
+var ErrUnsupportedExportFormat = errors.New("formato de exportação não suportado (use csv ou json)")
+
+// donorHistoryCSVHeader segue a ordem dos campos de DonorHistoryEntry
+var donorHistoryCSVHeader = []string{"id", "date", "ngo_id", "ngo_name", "amount", "status", "transaction_hash"}
+
+// DonorHistoryEntry é uma doação no histórico exportado pelo doador
+type DonorHistoryEntry struct {
+	ID              uint      `json:"id"`
+	Date            time.Time `json:"date"`
+	NGOID           uint      `json:"ngo_id"`
+	NGOName         string    `json:"ngo_name"`
+	Amount          float64   `json:"amount"`
+	Status          string    `json:"status"`
+	TransactionHash string    `json:"transaction_hash,omitempty"`
+}
+
+// DonorHistoryExport é o histórico completo do doador no formato JSON
+type DonorHistoryExport struct {
+	DonorID   uint                `json:"donor_id"`
+	DonorName string              `json:"donor_name"`
+	Donations []DonorHistoryEntry `json:"donations"`
+	// TotalDonated soma apenas as doações concluídas (pendentes e estornadas ficam de fora)
+	TotalDonated float64 `json:"total_donated"`
+}
+
+// ExportDonorHistory exporta todas as doações do doador, em ordem cronológica, em CSV ou JSON
+// (ex.: para a declaração de imposto de renda). O CSV termina com uma linha de total.
+func (s *DonationService) ExportDonorHistory(donorID uint, format string) ([]byte, error) {
+	if format != ExportFormatCSV && format != ExportFormatJSON {
+		return nil, ErrUnsupportedExportFormat
+	}
+
+	donor, err := s.GetUserByID(donorID)
+	if err != nil {
+		return nil, err
+	}
+	donations, err := s.GetDonationsByDonorID(donorID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(donations, func(i, j int) bool {
+		return donations[i].CreatedAt.Before(donations[j].CreatedAt)
+	})
+
+	export := DonorHistoryExport{
+		DonorID:   donor.ID,
+		DonorName: donor.Name,
+		Donations: make([]DonorHistoryEntry, 0, len(donations)),
+	}
+	for _, donation := range donations {
+		ngo, _ := s.GetNGOByID(donation.NGOID)
+		export.Donations = append(export.Donations, DonorHistoryEntry{
+			ID:              donation.ID,
+			Date:            donation.CreatedAt,
+			NGOID:           donation.NGOID,
+			NGOName:         ngo.Name,
+			Amount:          donation.Amount,
+			Status:          donation.Status,
+			TransactionHash: donation.TransactionHash,
+		})
+		if donation.Status == "completed" {
+			export.TotalDonated += donation.Amount
+		}
+	}
+
+	if format == ExportFormatJSON {
+		return json.MarshalIndent(export, "", "  ")
+	}
+
+	var out bytes.Buffer
+	entries := export.Donations
+	err = writeCSV(&out, donorHistoryCSVHeader, len(entries)+1, func(i int) []string {
+		if i == len(entries) {
+			return []string{"TOTAL", "", "", "", csvAmount(export.TotalDonated), "completed", ""}
+		}
+		e := entries[i]
+		return []string{
+			strconv.FormatUint(uint64(e.ID), 10),
+			csvDate(e.Date),
+			strconv.FormatUint(uint64(e.NGOID), 10),
+			csvText(e.NGOName),
+			csvAmount(e.Amount),
+			e.Status,
+			e.TransactionHash,
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gerar o CSV do histórico: %w", err)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportDonorHistoryCSVEndsWithTotal(t *testing.T) {
+	svc := NewDonationService()
+	completeDonation(t, svc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	completeDonation(t, svc, models.DonationRequest{Amount: 50.5, DonorID: 1, NGOID: 2})
+	_, err := svc.ProcessDonation(models.DonationRequest{Amount: 30, DonorID: 1, NGOID: 1}) // Pendente
+	require.NoError(t, err)
+
+	export, err := svc.ExportDonorHistory(1, ExportFormatCSV)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(strings.NewReader(string(export))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5, "Cabeçalho, três doações e o total")
+	assert.Equal(t, donorHistoryCSVHeader, records[0])
+
+	ngo, err := svc.GetNGOByID(2)
+	require.NoError(t, err)
+	assert.Equal(t, ngo.Name, records[2][3])
+	assert.Equal(t, "50.50", records[2][4])
+	assert.NotEmpty(t, records[2][6], "Doações concluídas trazem o hash da transação")
+	assert.Equal(t, "pending", records[3][5])
+
+	assert.Equal(t, []string{"TOTAL", "", "", "", "150.50", "completed", ""}, records[4],
+		"O total considera apenas as doações concluídas")
+}
+
+func TestExportDonorHistoryJSON(t *testing.T) {
+	svc := NewDonationService()
+	completeDonation(t, svc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+
+	export, err := svc.ExportDonorHistory(1, ExportFormatJSON)
+	require.NoError(t, err)
+
+	var history DonorHistoryExport
+	require.NoError(t, json.Unmarshal(export, &history))
+	assert.Equal(t, uint(1), history.DonorID)
+	require.Len(t, history.Donations, 1)
+	assert.Equal(t, "completed", history.Donations[0].Status)
+	assert.Equal(t, 100.0, history.TotalDonated)
+}
+
+func TestExportDonorHistoryErrors(t *testing.T) {
+	svc := NewDonationService()
+
+	_, err := svc.ExportDonorHistory(1, "xlsx")
+	assert.ErrorIs(t, err, ErrUnsupportedExportFormat)
+
+	_, err = svc.ExportDonorHistory(999, ExportFormatCSV)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"trackable-donations/api/internal/models"
+)
+
+var (
+	ErrUnsupportedCurrency     = errors.New("moeda não suportada")
+	ErrExchangeRateUnavailable = errors.New("cotação indisponível")
+)
+
+// ExchangeRateProvider informa as cotações usadas para converter as doações para reais
+type ExchangeRateProvider interface {
+	// Rate retorna quantos reais vale uma unidade da moeda informada
+	Rate(currency string) (float64, error)
+}
+
+// FixedExchangeRates é um ExchangeRateProvider com cotações fixas por moeda (ex.: USD: 5.10);
+// o real sempre vale 1
+type FixedExchangeRates map[string]float64
+
+func (r FixedExchangeRates) Rate(currency string) (float64, error) {
+	if currency == models.DefaultCurrency {
+		return 1, nil
+	}
+	rate, ok := r[currency]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("%w para %s", ErrExchangeRateUnavailable, currency)
+	}
+	return rate, nil
+}
+
+// exchange é a moeda de uma doação e a cotação aplicada para convertê-la em reais
+type exchange struct {
+	currency string
+	rate     float64
+}
+
+// brlExchange é a conversão das doações feitas em reais
+var brlExchange = exchange{currency: models.DefaultCurrency, rate: 1}
+
+// normalizeCurrency padroniza o código da moeda (vazio = BRL) e verifica se é suportado
+func normalizeCurrency(currency string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(currency))
+	if code == "" {
+		return models.DefaultCurrency, nil
+	}
+	if !slices.Contains(models.SupportedCurrencies, code) {
+		return "", fmt.Errorf("%w: %q (use uma de: %s)", ErrUnsupportedCurrency, currency, strings.Join(models.SupportedCurrencies, ", "))
+	}
+	return code, nil
+}
+
+// toBRL converte o valor para reais, arredondado em centavos; valores em reais ficam como vieram
+func (e exchange) toBRL(amount float64) float64 {
+	if e.currency == models.DefaultCurrency {
+		return amount
+	}
+	return math.Round(amount*e.rate*100) / 100
+}
+
+// donationCurrency retorna a moeda e o valor originais da doação; registros anteriores
+// ao suporte a outras moedas foram feitos em reais
+func donationCurrency(donation models.Donation) (string, float64) {
+	if donation.Currency == "" {
+		return models.DefaultCurrency, donation.Amount
+	}
+	return donation.Currency, donation.OriginalAmount
+}
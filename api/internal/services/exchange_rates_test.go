@@ -0,0 +1,121 @@
+package services
+
+import (
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockExchangeRates é um provedor de cotações para os testes que conta as consultas
+type mockExchangeRates struct {
+	rates map[string]float64
+	calls int
+}
+
+func (m *mockExchangeRates) Rate(currency string) (float64, error) {
+	m.calls++
+	rate, ok := m.rates[currency]
+	if !ok {
+		return 0, ErrExchangeRateUnavailable
+	}
+	return rate, nil
+}
+
+func TestDonationInForeignCurrencyIsNormalizedToBRL(t *testing.T) {
+	donationSvc := NewDonationService()
+	rates := &mockExchangeRates{rates: map[string]float64{"USD": 5.1234}}
+	donationSvc.SetExchangeRateProvider(rates)
+
+	id := completeDonation(t, donationSvc, models.DonationRequest{Amount: 10, Tip: 1, Currency: "usd", DonorID: 1, NGOID: 1})
+
+	donation := donationSvc.snapshotDonations()[0]
+	require.Equal(t, id, donation.ID)
+	assert.Equal(t, 51.23, donation.Amount, "O valor é convertido para reais e arredondado em centavos")
+	assert.Equal(t, 5.12, donation.Tip)
+	assert.Equal(t, "USD", donation.Currency)
+	assert.Equal(t, 10.0, donation.OriginalAmount)
+	assert.Equal(t, 5.1234, donation.ExchangeRate)
+	assert.Equal(t, 1, rates.calls)
+}
+
+func TestDonationCurrencyDefaultsToBRL(t *testing.T) {
+	donationSvc := NewDonationService()
+	rates := &mockExchangeRates{}
+	donationSvc.SetExchangeRateProvider(rates)
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 25.5, DonorID: 1, NGOID: 1})
+
+	donation := donationSvc.snapshotDonations()[0]
+	assert.Equal(t, 25.5, donation.Amount)
+	assert.Equal(t, models.DefaultCurrency, donation.Currency)
+	assert.Equal(t, 25.5, donation.OriginalAmount)
+	assert.Zero(t, rates.calls, "Doações em reais não consultam cotação")
+}
+
+func TestDonationRejectsUnsupportedOrUnquotedCurrency(t *testing.T) {
+	donationSvc := NewDonationService()
+	donationSvc.SetExchangeRateProvider(&mockExchangeRates{rates: map[string]float64{"USD": 5}})
+
+	_, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 10, Currency: "JPY", DonorID: 1, NGOID: 1})
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 10, Currency: "EUR", DonorID: 1, NGOID: 1})
+	assert.ErrorIs(t, err, ErrExchangeRateUnavailable)
+
+	assert.Empty(t, donationSvc.snapshotDonations())
+}
+
+func TestFixedExchangeRates(t *testing.T) {
+	rates := FixedExchangeRates{"USD": 5.1}
+
+	rate, err := rates.Rate("BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, rate)
+
+	rate, err = rates.Rate("USD")
+	require.NoError(t, err)
+	assert.Equal(t, 5.1, rate)
+
+	_, err = rates.Rate("EUR")
+	assert.ErrorIs(t, err, ErrExchangeRateUnavailable)
+}
+
+func TestTotalsSumBRLAcrossCurrencies(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	donationSvc.SetExchangeRateProvider(&mockExchangeRates{rates: map[string]float64{"USD": 5, "EUR": 6}})
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 10, Currency: "USD", DonorID: 1, NGOID: 1})
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 10, Currency: "EUR", DonorID: 2, NGOID: 2})
+
+	dashboard := NewDashboardService(donationSvc, expenseSvc).GetGlobalDashboard()
+	assert.Equal(t, 210.0, dashboard.TotalDonated)
+
+	transparencySvc := NewTransparencyService(donationSvc, expenseSvc)
+	assert.Equal(t, 210.0, transparencySvc.GetTransparencyDashboard().TotalDonations)
+	summary, err := transparencySvc.GetNGOSummary(1)
+	require.NoError(t, err)
+	assert.Equal(t, 150.0, summary.TotalReceived)
+}
+
+func TestExplorerShowsOriginalCurrency(t *testing.T) {
+	donationSvc := NewDonationService()
+	explorerSvc := NewExplorerService(donationSvc, NewExpenseService(donationSvc))
+	donationSvc.SetExchangeRateProvider(&mockExchangeRates{rates: map[string]float64{"EUR": 6}})
+
+	id := completeDonation(t, donationSvc, models.DonationRequest{Amount: 20, Currency: "EUR", DonorID: 1, NGOID: 1})
+
+	details, err := explorerSvc.GetDonationByID(id)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", details.Currency)
+	assert.Equal(t, 20.0, details.OriginalAmount)
+	assert.Equal(t, 120.0, details.Amount)
+
+	// Registros anteriores ao suporte a moedas aparecem em reais
+	currency, amount := donationCurrency(models.Donation{Amount: 30})
+	assert.Equal(t, models.DefaultCurrency, currency)
+	assert.Equal(t, 30.0, amount)
+}
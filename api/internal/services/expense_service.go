@@ -1,34 +1,163 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"time"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"trackable-donations/api/internal/models"
 )
 
 // ExpenseService gerencia operações relacionadas a gastos das ONGs
 type ExpenseService struct {
-	// Em um sistema real, teríamos repositórios para acesso ao banco de dados
+	// mu protege expenses; quem também precisa das doações bloqueia mu antes do lock do
+	// serviço de doações, nunca o contrário
+	mu sync.RWMutex
+	// Cópia em memória dos gastos persistidos no armazenamento do serviço de doações
 	expenses    []models.Expense
 	donationSvc *DonationService
+	// maxExpensesPerDonation limita quantos gastos uma doação pode ter (0 = ilimitado)
+	maxExpensesPerDonation int
+	// clock fornece o horário de criação e atualização dos gastos (ver SetClock)
+	clock Clock
 }
 
-// NewExpenseService cria uma nova instância do serviço de gastos
+// NewExpenseService cria uma nova instância do serviço de gastos, carregando os gastos
+// já persistidos no armazenamento do serviço de doações
 func NewExpenseService(donationSvc *DonationService) *ExpenseService {
+	expenses, err := donationSvc.store.Expenses.FindAll()
+	if err != nil {
+		log.Printf("Erro ao carregar gastos: %v", err)
+	}
 	return &ExpenseService{
-		expenses:    []models.Expense{},
+		expenses:    append([]models.Expense{}, expenses...),
 		donationSvc: donationSvc,
+		clock:       RealClock{},
+	}
+}
+
+// SetClock define o relógio usado pelo serviço
+func (s *ExpenseService) SetClock(clock Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+}
+
+// SetMaxExpensesPerDonation define o limite de gastos por doação (0 = ilimitado)
+func (s *ExpenseService) SetMaxExpensesPerDonation(limit int) {
+	if limit < 0 {
+		limit = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxExpensesPerDonation = limit
+}
+
+// ErrInvalidExpenseCategory indica uma categoria fora de models.ExpenseCategories
+var ErrInvalidExpenseCategory = errors.New("categoria de gasto inválida")
+
+// ErrNGOBalanceExceeded indica um gasto maior que o saldo da ONG somando todas as suas doações
+var ErrNGOBalanceExceeded = errors.New("valor excede o saldo disponível da ONG")
+
+// NGOAvailableBalance retorna quanto a ONG ainda pode gastar: o total das doações confirmadas
+// menos os gastos aprovados e pendentes (os pendentes já comprometem o saldo)
+func (s *ExpenseService) NGOAvailableBalance(ngoID uint) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ngoAvailableBalance(ngoID)
+}
+
+// ngoAvailableBalance calcula o saldo da ONG; deve ser chamado com s.mu bloqueado
+func (s *ExpenseService) ngoAvailableBalance(ngoID uint) float64 {
+	var received float64
+	for _, d := range s.donationSvc.snapshotDonations() {
+		if d.NGOID == ngoID && d.Status == "completed" {
+			received += d.Amount
+		}
 	}
+
+	var committed float64
+	for _, e := range s.expenses {
+		if e.NGOID == ngoID && e.Status != "rejeitado" {
+			committed += e.Amount
+		}
+	}
+	return received - committed
+}
+
+// ErrDonationNotCompleted indica uma doação que ainda não foi confirmada (ou foi estornada)
+var ErrDonationNotCompleted = errors.New("doação não está concluída")
+
+// GetDonationBalance retorna o saldo de uma doação concluída: o total, os gastos aprovados, os
+// pendentes e o que ainda pode ser gasto. Gastos rejeitados não contam.
+func (s *ExpenseService) GetDonationBalance(donationID uint) (models.DonationBalance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var donation models.Donation
+	found := false
+	for _, d := range s.donationSvc.snapshotDonations() {
+		if d.ID == donationID {
+			donation, found = d, true
+			break
+		}
+	}
+	if !found {
+		return models.DonationBalance{}, ErrDonationNotFound
+	}
+	if donation.Status != "completed" {
+		return models.DonationBalance{}, ErrDonationNotCompleted
+	}
+
+	balance := models.DonationBalance{DonationID: donation.ID, Total: donation.Amount}
+	for _, e := range s.expenses {
+		switch e.Status {
+		case "aprovado":
+			balance.Spent += e.DrawnFrom(donationID)
+		case "pendente":
+			balance.Pending += e.DrawnFrom(donationID)
+		}
+	}
+	balance.Spent = roundTwoDecimals(balance.Spent)
+	balance.Pending = roundTwoDecimals(balance.Pending)
+	balance.Available = roundTwoDecimals(balance.Total - balance.Spent - balance.Pending)
+	return balance, nil
+}
+
+// canonicalExpenseCategory busca a categoria sem diferenciar maiúsculas de minúsculas
+// e retorna a grafia oficial
+func canonicalExpenseCategory(category string) (string, bool) {
+	category = strings.TrimSpace(category)
+	for _, valid := range models.ExpenseCategories {
+		if strings.EqualFold(valid, category) {
+			return valid, true
+		}
+	}
+	return "", false
 }
 
 // RegisterExpense registra um novo gasto relacionado a uma doação
 func (s *ExpenseService) RegisterExpense(req models.ExpenseRequest) (models.ExpenseResponse, error) {
+	category, ok := canonicalExpenseCategory(req.Category)
+	if !ok {
+		return models.ExpenseResponse{}, fmt.Errorf("%w: %q (use uma de: %s)",
+			ErrInvalidExpenseCategory, req.Category, strings.Join(models.ExpenseCategories, ", "))
+	}
+	req.Category = category
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Verificar se a doação existe
 	found := false
 	var donation models.Donation
 
-	for _, d := range s.donationSvc.donations {
+	for _, d := range s.donationSvc.snapshotDonations() {
 		if d.ID == req.DonationID {
 			donation = d
 			found = true
@@ -52,34 +181,46 @@ func (s *ExpenseService) RegisterExpense(req models.ExpenseRequest) (models.Expe
 
 	// Verificar se o valor do gasto não excede o total disponível
 	totalExpenses := float64(0)
+	expensesCount := 0
 	for _, e := range s.expenses {
-		if e.DonationID == req.DonationID {
-			totalExpenses += e.Amount
+		// Gastos rejeitados não consomem o saldo nem contam para o limite da doação
+		if drawn := e.DrawnFrom(req.DonationID); drawn > 0 && e.Status != "rejeitado" {
+			totalExpenses += drawn
+			expensesCount++
 		}
 	}
 
+	// Evitar que os gastos de uma doação sejam fragmentados indefinidamente
+	if s.maxExpensesPerDonation > 0 && expensesCount >= s.maxExpensesPerDonation {
+		return models.ExpenseResponse{}, fmt.Errorf("limite de %d gastos por doação atingido", s.maxExpensesPerDonation)
+	}
+
 	remainingAmount := donation.Amount - totalExpenses
 
 	if req.Amount > remainingAmount {
 		return models.ExpenseResponse{}, fmt.Errorf("valor excede o saldo disponível da doação (%.2f)", remainingAmount)
 	}
 
-	// Criar novo gasto
-	expenseID := uint(len(s.expenses) + 1) // Em um banco real, seria auto-incremento
+	// Os gastos também não podem ultrapassar o que a ONG recebeu no total
+	if balance := s.ngoAvailableBalance(req.NGOID); req.Amount > balance {
+		return models.ExpenseResponse{}, fmt.Errorf("%w (%.2f)", ErrNGOBalanceExceeded, balance)
+	}
 
+	// Criar novo gasto (o ID vem do banco)
 	expense := models.Expense{
-		ID:          expenseID,
 		DonationID:  req.DonationID,
 		NGOID:       req.NGOID,
 		Amount:      req.Amount,
 		Description: req.Description,
 		Category:    req.Category,
 		Status:      "pendente", // Inicialmente pendente até upload de comprovante
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		CreatedAt:   s.clock.Now(),
+		UpdatedAt:   s.clock.Now(),
 	}
 
-	// Adicionar à lista (em um sistema real, seria salvo no banco)
+	if err := s.donationSvc.store.Expenses.Create(&expense); err != nil {
+		return models.ExpenseResponse{}, fmt.Errorf("falha ao salvar o gasto: %w", err)
+	}
 	s.expenses = append(s.expenses, expense)
 
 	return models.ExpenseResponse{
@@ -94,96 +235,383 @@ func (s *ExpenseService) RegisterExpense(req models.ExpenseRequest) (models.Expe
 	}, nil
 }
 
-// UploadReceipt faz upload do comprovante para o IPFS e atualiza o gasto
-func (s *ExpenseService) UploadReceipt(expenseID uint, fileContent []byte) (models.ExpenseResponse, error) {
-	// Encontrar o gasto
-	found := false
-	var index int
+// RegisterPoolExpense registra um gasto custeado pelo saldo geral da ONG. O valor é retirado
+// das doações concluídas da ONG com saldo, da mais antiga para a mais recente, e as parcelas
+// ficam registradas em Sources (ver GetFundingDonations). Doações que já atingiram o limite
+// de gastos por doação são puladas.
+func (s *ExpenseService) RegisterPoolExpense(req models.PoolExpenseRequest) (models.ExpenseResponse, error) {
+	category, ok := canonicalExpenseCategory(req.Category)
+	if !ok {
+		return models.ExpenseResponse{}, fmt.Errorf("%w: %q (use uma de: %s)",
+			ErrInvalidExpenseCategory, req.Category, strings.Join(models.ExpenseCategories, ", "))
+	}
 
-	for i, e := range s.expenses {
-		if e.ID == expenseID {
-			index = i
-			found = true
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if balance := s.ngoAvailableBalance(req.NGOID); req.Amount > balance {
+		return models.ExpenseResponse{}, fmt.Errorf("%w (%.2f)", ErrNGOBalanceExceeded, balance)
+	}
+
+	var donations []models.Donation
+	for _, d := range s.donationSvc.snapshotDonations() {
+		if d.NGOID == req.NGOID && d.Status == "completed" {
+			donations = append(donations, d)
+		}
+	}
+	sort.SliceStable(donations, func(i, j int) bool {
+		return donations[i].CreatedAt.Before(donations[j].CreatedAt)
+	})
+
+	var sources []models.ExpenseSource
+	remaining := roundTwoDecimals(req.Amount)
+	for _, d := range donations {
+		if remaining <= 0 {
 			break
 		}
+
+		drawn, count := 0.0, 0
+		for _, e := range s.expenses {
+			if amount := e.DrawnFrom(d.ID); amount > 0 && e.Status != "rejeitado" {
+				drawn += amount
+				count++
+			}
+		}
+		if s.maxExpensesPerDonation > 0 && count >= s.maxExpensesPerDonation {
+			continue
+		}
+
+		available := roundTwoDecimals(d.Amount - drawn)
+		if available <= 0 {
+			continue
+		}
+		amount := min(available, remaining)
+		sources = append(sources, models.ExpenseSource{DonationID: d.ID, Amount: amount})
+		remaining = roundTwoDecimals(remaining - amount)
+	}
+	if remaining > 0 {
+		return models.ExpenseResponse{}, fmt.Errorf("%w: faltam %.2f nas doações disponíveis", ErrNGOBalanceExceeded, remaining)
 	}
 
-	if !found {
-		return models.ExpenseResponse{}, errors.New("gasto não encontrado")
+	expense := models.Expense{
+		NGOID:       req.NGOID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Category:    category,
+		Status:      "pendente",
+		CreatedAt:   s.clock.Now(),
+		UpdatedAt:   s.clock.Now(),
+		Sources:     sources,
+	}
+	if err := s.donationSvc.store.Expenses.Create(&expense); err != nil {
+		return models.ExpenseResponse{}, fmt.Errorf("falha ao salvar o gasto: %w", err)
+	}
+	s.expenses = append(s.expenses, expense)
+
+	return models.ExpenseResponse{
+		ID:          expense.ID,
+		NGOID:       expense.NGOID,
+		Amount:      expense.Amount,
+		Description: expense.Description,
+		Category:    expense.Category,
+		Status:      expense.Status,
+		CreatedAt:   expense.CreatedAt,
+		Sources:     expense.Sources,
+	}, nil
+}
+
+// UploadReceipt faz upload do comprovante para o IPFS e atualiza o gasto. O gasto
+// continua pendente até ser aprovado ou rejeitado por um administrador. Falhas no envio ao
+// IPFS são tentadas novamente até o cancelamento de ctx.
+func (s *ExpenseService) UploadReceipt(ctx context.Context, expenseID uint, fileContent []byte) (models.ExpenseResponse, error) {
+	if _, err := s.pendingExpense(expenseID); err != nil {
+		return models.ExpenseResponse{}, err
 	}
 
-	// Em um sistema real, faríamos o upload para o IPFS
-	// Por ora, simularemos com um hash
-	ipfsHash := fmt.Sprintf("Qm%s", generateMockHash(46))
+	// O envio ao IPFS acontece sem o lock, para não bloquear as consultas aos gastos
+	ipfsHash, err := addToIPFS(ctx, s.donationSvc.ipfsClient(), fileContent)
+	if err != nil {
+		return models.ExpenseResponse{}, fmt.Errorf("falha no upload do comprovante para o IPFS: %w", err)
+	}
 
 	// Em um sistema real, registraríamos na blockchain
 	blockchainRef := generateMockTransactionHash()
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// O gasto pode ter sido revisado enquanto o comprovante era enviado
+	index, err := s.pendingExpenseIndex(expenseID)
+	if err != nil {
+		return models.ExpenseResponse{}, err
+	}
+
 	// Atualizar o gasto
-	s.expenses[index].ReceiptIPFS = ipfsHash
-	s.expenses[index].BlockchainRef = blockchainRef
-	s.expenses[index].Status = "aprovado"
-	s.expenses[index].UpdatedAt = time.Now()
+	updated := s.expenses[index]
+	updated.ReceiptIPFS = ipfsHash
+	updated.BlockchainRef = blockchainRef
+	updated.UpdatedAt = s.clock.Now()
+	if err := s.donationSvc.store.Expenses.Save(&updated); err != nil {
+		return models.ExpenseResponse{}, fmt.Errorf("falha ao salvar o gasto: %w", err)
+	}
+	s.expenses[index] = updated
 
 	// Retornar o gasto atualizado
 	return models.ExpenseResponse{
-		ID:            s.expenses[index].ID,
-		DonationID:    s.expenses[index].DonationID,
-		NGOID:         s.expenses[index].NGOID,
-		Amount:        s.expenses[index].Amount,
-		Description:   s.expenses[index].Description,
-		Category:      s.expenses[index].Category,
-		ReceiptIPFS:   s.expenses[index].ReceiptIPFS,
-		BlockchainRef: s.expenses[index].BlockchainRef,
-		Status:        s.expenses[index].Status,
-		CreatedAt:     s.expenses[index].CreatedAt,
+		ID:            updated.ID,
+		DonationID:    updated.DonationID,
+		NGOID:         updated.NGOID,
+		Amount:        updated.Amount,
+		Description:   updated.Description,
+		Category:      updated.Category,
+		ReceiptIPFS:   updated.ReceiptIPFS,
+		BlockchainRef: updated.BlockchainRef,
+		Status:        updated.Status,
+		CreatedAt:     updated.CreatedAt,
+		Sources:       updated.Sources,
 	}, nil
 }
 
-// GetExpensesByDonation obtém todos os gastos relacionados a uma doação
-func (s *ExpenseService) GetExpensesByDonation(donationID uint) ([]models.ExpenseResponse, error) {
+// pendingExpense retorna o gasto, desde que ainda esteja pendente
+func (s *ExpenseService) pendingExpense(expenseID uint) (models.Expense, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	index, err := s.pendingExpenseIndex(expenseID)
+	if err != nil {
+		return models.Expense{}, err
+	}
+	return s.expenses[index], nil
+}
+
+// pendingExpenseIndex busca o gasto pendente; deve ser chamado com s.mu bloqueado
+func (s *ExpenseService) pendingExpenseIndex(expenseID uint) (int, error) {
+	for i, e := range s.expenses {
+		if e.ID == expenseID {
+			if e.Status != "pendente" {
+				return 0, errors.New("comprovante só pode ser enviado para gastos pendentes")
+			}
+			return i, nil
+		}
+	}
+	return 0, errors.New("gasto não encontrado")
+}
+
+// reviewExpense conclui a revisão de um gasto pendente, aprovando-o ou rejeitando-o
+func (s *ExpenseService) reviewExpense(expenseID uint, status, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, e := range s.expenses {
+		if e.ID != expenseID {
+			continue
+		}
+		if e.Status != "pendente" {
+			return fmt.Errorf("apenas gastos pendentes podem ser revisados (status atual: %s)", e.Status)
+		}
+		if status == "aprovado" && e.ReceiptIPFS == "" {
+			return errors.New("o gasto só pode ser aprovado após o envio do comprovante")
+		}
+
+		e.Status = status
+		e.RejectionReason = reason
+		e.UpdatedAt = s.clock.Now()
+		if err := s.donationSvc.store.Expenses.Save(&e); err != nil {
+			return fmt.Errorf("falha ao salvar o gasto: %w", err)
+		}
+		s.expenses[i] = e
+		return nil
+	}
+	return errors.New("gasto não encontrado")
+}
+
+// ErrInvalidExpenseStatus indica um status fora de models.ExpenseStatuses
+var ErrInvalidExpenseStatus = errors.New("status de gasto inválido")
+
+// GetExpensesByDonation obtém os gastos relacionados a uma doação, opcionalmente filtrados
+// por categoria e status
+func (s *ExpenseService) GetExpensesByDonation(donationID uint, filter models.ExpenseFilter) ([]models.ExpenseResponse, error) {
+	return s.listExpenses(filter, func(e models.Expense) bool { return e.DrawnFrom(donationID) > 0 })
+}
+
+// GetExpensesByNGO obtém os gastos relacionados a uma ONG, opcionalmente filtrados por
+// categoria e status
+func (s *ExpenseService) GetExpensesByNGO(ngoID uint, filter models.ExpenseFilter) ([]models.ExpenseResponse, error) {
+	return s.listExpenses(filter, func(e models.Expense) bool { return e.NGOID == ngoID })
+}
+
+// listExpenses retorna, na ordem de registro, os gastos selecionados por match que atendem
+// ao filtro, depois de validar a categoria e o status informados
+func (s *ExpenseService) listExpenses(filter models.ExpenseFilter, match func(models.Expense) bool) ([]models.ExpenseResponse, error) {
+	if filter.Category != "" {
+		category, ok := canonicalExpenseCategory(filter.Category)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q (use uma de: %s)",
+				ErrInvalidExpenseCategory, filter.Category, strings.Join(models.ExpenseCategories, ", "))
+		}
+		filter.Category = category
+	}
+	if filter.Status != "" && !slices.Contains(models.ExpenseStatuses, filter.Status) {
+		return nil, fmt.Errorf("%w: %q (use um de: %s)",
+			ErrInvalidExpenseStatus, filter.Status, strings.Join(models.ExpenseStatuses, ", "))
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var expenseResponses []models.ExpenseResponse
 
 	for _, e := range s.expenses {
-		if e.DonationID == donationID {
-			expenseResponses = append(expenseResponses, models.ExpenseResponse{
-				ID:            e.ID,
-				DonationID:    e.DonationID,
-				NGOID:         e.NGOID,
-				Amount:        e.Amount,
-				Description:   e.Description,
-				Category:      e.Category,
-				ReceiptIPFS:   e.ReceiptIPFS,
-				BlockchainRef: e.BlockchainRef,
-				Status:        e.Status,
-				CreatedAt:     e.CreatedAt,
-			})
+		if !match(e) || (filter.Category != "" && e.Category != filter.Category) ||
+			(filter.Status != "" && e.Status != filter.Status) {
+			continue
 		}
+		expenseResponses = append(expenseResponses, models.ExpenseResponse{
+			ID:              e.ID,
+			DonationID:      e.DonationID,
+			NGOID:           e.NGOID,
+			Amount:          e.Amount,
+			Description:     e.Description,
+			Category:        e.Category,
+			ReceiptIPFS:     e.ReceiptIPFS,
+			BlockchainRef:   e.BlockchainRef,
+			Status:          e.Status,
+			RejectionReason: e.RejectionReason,
+			CreatedAt:       e.CreatedAt,
+			Sources:         e.Sources,
+		})
 	}
 
 	return expenseResponses, nil
 }
 
-// GetExpensesByNGO obtém todos os gastos relacionados a uma ONG
-func (s *ExpenseService) GetExpensesByNGO(ngoID uint) ([]models.ExpenseResponse, error) {
-	var expenseResponses []models.ExpenseResponse
+// Tamanho padrão e máximo das páginas da fila de gastos pendentes
+const (
+	defaultPendingExpensesPageSize = 20
+	maxPendingExpensesPageSize     = 100
+)
+
+// GetPendingExpenses retorna a fila de revisão: os gastos pendentes de todas as ONGs, do mais
+// antigo para o mais recente, com o nome da ONG e o comprovante enviado, paginados
+func (s *ExpenseService) GetPendingExpenses(page, pageSize int) (models.PendingExpenseListResult, error) {
+	result := models.PendingExpenseListResult{Expenses: []models.PendingExpense{}, Page: page, PageSize: pageSize}
+	if result.Page <= 0 {
+		result.Page = 1
+	}
+	if result.PageSize <= 0 {
+		result.PageSize = defaultPendingExpensesPageSize
+	}
+	if result.PageSize > maxPendingExpensesPageSize {
+		result.PageSize = maxPendingExpensesPageSize
+	}
+
+	pending, err := s.listExpenses(models.ExpenseFilter{Status: "pendente"}, func(models.Expense) bool { return true })
+	if err != nil {
+		return models.PendingExpenseListResult{}, err
+	}
+
+	// No mesmo instante, o ID desempata mantendo a ordem de registro
+	sort.SliceStable(pending, func(i, j int) bool {
+		if !pending[i].CreatedAt.Equal(pending[j].CreatedAt) {
+			return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+		}
+		return pending[i].ID < pending[j].ID
+	})
+
+	result.Total = len(pending)
+	start := (result.Page - 1) * result.PageSize
+	if start < len(pending) {
+		ngos := s.donationSvc.snapshotNGOIndex()
+		for _, expense := range pending[start:min(start+result.PageSize, len(pending))] {
+			result.Expenses = append(result.Expenses, models.PendingExpense{
+				ExpenseResponse: expense,
+				NGOName:         ngos[expense.NGOID].Name,
+			})
+		}
+	}
+	return result, nil
+}
+
+// GetFundingDonations retorna as doações que custearam um gasto e o valor retirado de cada uma:
+// a doação vinculada, com o valor integral, ou as fontes de um gasto do saldo geral da ONG,
+// na ordem em que foram consumidas
+func (s *ExpenseService) GetFundingDonations(expenseID uint) ([]models.DonationContribution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	for _, e := range s.expenses {
-		if e.NGOID == ngoID {
-			expenseResponses = append(expenseResponses, models.ExpenseResponse{
-				ID:            e.ID,
-				DonationID:    e.DonationID,
-				NGOID:         e.NGOID,
-				Amount:        e.Amount,
-				Description:   e.Description,
-				Category:      e.Category,
-				ReceiptIPFS:   e.ReceiptIPFS,
-				BlockchainRef: e.BlockchainRef,
-				Status:        e.Status,
-				CreatedAt:     e.CreatedAt,
+		if e.ID != expenseID {
+			continue
+		}
+
+		donations := make(map[uint]models.Donation)
+		for _, d := range s.donationSvc.snapshotDonations() {
+			donations[d.ID] = d
+		}
+
+		sources := e.Sources
+		if len(sources) == 0 {
+			sources = []models.ExpenseSource{{DonationID: e.DonationID, Amount: e.Amount}}
+		}
+		contributions := []models.DonationContribution{}
+		for _, source := range sources {
+			d, ok := donations[source.DonationID]
+			if !ok {
+				continue
+			}
+			contributions = append(contributions, models.DonationContribution{
+				DonationID:      d.ID,
+				DonationAmount:  d.Amount,
+				AmountDrawn:     source.Amount,
+				TransactionHash: d.TransactionHash,
+				DonatedAt:       d.CreatedAt,
 			})
 		}
+		return contributions, nil
 	}
 
-	return expenseResponses, nil
+	return nil, errors.New("gasto não encontrado")
+}
+
+// RefundDonation estorna uma doação concluída (ver DonationService.refundDonation). Doações
+// com gastos aprovados ou ainda pendentes de revisão (que podem vir a ser aprovados) não são
+// estornadas; o lock dos gastos é mantido até o fim, para que nenhum gasto seja registrado
+// sobre a doação entre a verificação e o estorno.
+func (s *ExpenseService) RefundDonation(donationID uint, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hasExpenses := false
+	for _, expense := range s.expenses {
+		if expense.DrawnFrom(donationID) > 0 && expense.Status != "rejeitado" {
+			hasExpenses = true
+			break
+		}
+	}
+	return s.donationSvc.refundDonation(donationID, reason, hasExpenses)
+}
+
+// snapshotExpenses retorna uma cópia dos gastos, que pode ser percorrida sem manter o lock
+func (s *ExpenseService) snapshotExpenses() []models.Expense {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]models.Expense(nil), s.expenses...)
+}
+
+// reassignNGO transfere os gastos de uma ONG para outra (mesclagem de cadastros) e
+// retorna quantos foram transferidos, com as falhas ao salvá-los
+func (s *ExpenseService) reassignNGO(fromID, toID uint) (int, []error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	moved := 0
+	var saveErrs []error
+	for i, expense := range s.expenses {
+		if expense.NGOID == fromID {
+			s.expenses[i].NGOID = toID
+			s.expenses[i].UpdatedAt = s.clock.Now()
+			saveErrs = append(saveErrs, s.donationSvc.store.Expenses.Save(&s.expenses[i]))
+			moved++
+		}
+	}
+	return moved, saveErrs
 }
@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterExpenseRespectsPerDonationCap(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	expenseSvc.SetMaxExpensesPerDonation(2)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	req := models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 10, Description: "Cestas básicas", Category: "Alimentação"}
+
+	_, err := expenseSvc.RegisterExpense(req)
+	require.NoError(t, err)
+	_, err = expenseSvc.RegisterExpense(req)
+	require.NoError(t, err, "Gastos abaixo do limite devem ser aceitos")
+
+	_, err = expenseSvc.RegisterExpense(req)
+	require.Error(t, err, "O gasto acima do limite deve ser rejeitado")
+	assert.Contains(t, err.Error(), "limite de 2 gastos")
+}
+
+func TestRegisterExpenseUnlimitedByDefault(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	for i := 0; i < 20; i++ {
+		_, err := expenseSvc.RegisterExpense(models.ExpenseRequest{
+			DonationID: donationID, NGOID: 1, Amount: 1, Description: "Item", Category: "Alimentação",
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestGetFundingDonations(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 35, Description: "Cestas básicas", Category: "Alimentação"})
+	require.NoError(t, err)
+
+	contributions, err := expenseSvc.GetFundingDonations(expense.ID)
+	require.NoError(t, err)
+	require.Len(t, contributions, 1)
+	assert.Equal(t, donationID, contributions[0].DonationID)
+	assert.Equal(t, 100.0, contributions[0].DonationAmount)
+	assert.Equal(t, 35.0, contributions[0].AmountDrawn)
+	assert.NotEmpty(t, contributions[0].TransactionHash)
+
+	_, err = expenseSvc.GetFundingDonations(999)
+	assert.Error(t, err)
+}
+
+func TestGetFundingDonationsForPoolExpense(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	donationSvc.SetClock(ClockFunc(func() time.Time { return now }))
+
+	older := completeDonation(t, donationSvc, models.DonationRequest{Amount: 50, DonorID: 1, NGOID: 1})
+	now = now.Add(time.Hour)
+	newer := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 2, NGOID: 1})
+	// Parte da doação mais antiga já está comprometida com outro gasto
+	_, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: older, NGOID: 1, Amount: 20, Description: "Gás", Category: "Alimentação"})
+	require.NoError(t, err)
+
+	expense, err := expenseSvc.RegisterPoolExpense(models.PoolExpenseRequest{NGOID: 1, Amount: 70, Description: "Aluguel do galpão", Category: "infraestrutura"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), expense.DonationID)
+	assert.Equal(t, "Infraestrutura", expense.Category)
+
+	contributions, err := expenseSvc.GetFundingDonations(expense.ID)
+	require.NoError(t, err)
+	require.Len(t, contributions, 2)
+	assert.Equal(t, older, contributions[0].DonationID)
+	assert.Equal(t, 30.0, contributions[0].AmountDrawn)
+	assert.Equal(t, newer, contributions[1].DonationID)
+	assert.Equal(t, 40.0, contributions[1].AmountDrawn)
+
+	// As parcelas consomem o saldo de cada doação
+	balance, err := expenseSvc.GetDonationBalance(newer)
+	require.NoError(t, err)
+	assert.Equal(t, 40.0, balance.Pending)
+	assert.Equal(t, 60.0, balance.Available)
+	byDonation, err := expenseSvc.GetExpensesByDonation(newer, models.ExpenseFilter{})
+	require.NoError(t, err)
+	require.Len(t, byDonation, 1)
+	assert.Equal(t, expense.ID, byDonation[0].ID)
+
+	_, err = expenseSvc.RegisterPoolExpense(models.PoolExpenseRequest{NGOID: 1, Amount: 61, Description: "Reforma", Category: "Infraestrutura"})
+	assert.ErrorIs(t, err, ErrNGOBalanceExceeded)
+}
+
+func TestExpenseReviewFlow(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+	transparencySvc := NewTransparencyService(donationSvc, expenseSvc)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	legit, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 30, Description: "Cestas básicas", Category: "Alimentação"})
+	require.NoError(t, err)
+	fraud, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 70, Description: "Sem nota", Category: "Outros"})
+	require.NoError(t, err)
+
+	uploaded, err := expenseSvc.UploadReceipt(context.Background(), legit.ID, []byte("nota fiscal"))
+	require.NoError(t, err)
+	assert.Equal(t, "pendente", uploaded.Status, "O envio do comprovante não aprova o gasto")
+	assert.Error(t, adminSvc.ApproveExpense(fraud.ID, 1), "Gastos sem comprovante não podem ser aprovados")
+
+	require.NoError(t, adminSvc.ApproveExpense(legit.ID, 1))
+	require.NoError(t, adminSvc.RejectExpense(fraud.ID, 1, "Nota fiscal inexistente"))
+	assert.Error(t, adminSvc.RejectExpense(legit.ID, 1, "tarde demais"), "Apenas gastos pendentes podem ser revisados")
+
+	expenses, err := expenseSvc.GetExpensesByDonation(donationID, models.ExpenseFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, "rejeitado", expenses[1].Status)
+	assert.Equal(t, "Nota fiscal inexistente", expenses[1].RejectionReason)
+
+	public := transparencySvc.GetPublicExpenses()
+	require.Len(t, public, 1)
+	assert.Equal(t, legit.ID, public[0].ID)
+
+	summary, err := transparencySvc.GetNGOSummary(1)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, summary.TotalSpent)
+
+	// O valor rejeitado volta a ficar disponível na doação
+	_, err = expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 70, Description: "Transporte", Category: "Transporte"})
+	assert.NoError(t, err)
+
+	logs := adminSvc.GetAuditLogsByEntityID("expense", fraud.ID)
+	require.Len(t, logs, 1)
+	assert.Equal(t, models.AuditActionExpenseRejected, logs[0].Action)
+}
+
+func TestRegisterExpenseValidatesCategory(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+
+	_, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 10, Description: "Arroz", Category: "Alimentaçao"})
+	require.ErrorIs(t, err, ErrInvalidExpenseCategory)
+	assert.Contains(t, err.Error(), "Alimentação, Saúde", "O erro lista as categorias válidas")
+
+	expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 10, Description: "Arroz", Category: " ALIMENTAÇÃO "})
+	require.NoError(t, err)
+	assert.Equal(t, "Alimentação", expense.Category, "A categoria é gravada com a grafia oficial")
+}
+
+func TestRegisterExpenseRejectsSpendingBeyondNGOBalance(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	transparencySvc := NewTransparencyService(donationSvc, expenseSvc)
+
+	firstID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	secondID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+
+	// Gasto legado que consumiu mais do que a própria doação
+	expenseSvc.expenses = append(expenseSvc.expenses, models.Expense{
+		ID: 99, DonationID: firstID, NGOID: 1, Amount: 150, Category: "Alimentação", Status: "aprovado",
+	})
+	assert.Equal(t, 50.0, expenseSvc.NGOAvailableBalance(1))
+
+	req := models.ExpenseRequest{DonationID: secondID, NGOID: 1, Amount: 60, Description: "Cestas básicas", Category: "Alimentação"}
+	_, err := expenseSvc.RegisterExpense(req)
+	require.ErrorIs(t, err, ErrNGOBalanceExceeded, "O saldo da doação não basta se a ONG já gastou o total recebido")
+	assert.Contains(t, err.Error(), "50.00")
+
+	req.Amount = 50
+	_, err = expenseSvc.RegisterExpense(req)
+	require.NoError(t, err)
+
+	summary, err := transparencySvc.GetNGOSummary(1)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, summary.AvailableBalance, "O saldo público considera apenas gastos aprovados")
+	assert.Equal(t, 0.0, summary.SpendableBalance, "Gastos pendentes também comprometem o saldo")
+}
+
+func TestConcurrentCreationsGetUniqueIDs(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+
+	const workers = 8
+	donationIDs := make(chan uint, workers)
+	expenseIDs := make(chan uint, workers*2)
+	errs := make(chan error, workers*4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(ngoID uint) {
+			defer wg.Done()
+			donation, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: ngoID})
+			if err != nil {
+				errs <- err
+				return
+			}
+			if _, err := donationSvc.MockPaymentConfirmation(donation.ID); err != nil {
+				errs <- err
+				return
+			}
+			donationIDs <- donation.ID
+
+			// Dois gastos por doação, registrados em paralelo com as demais doações
+			for j := 0; j < 2; j++ {
+				expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{
+					DonationID: donation.ID, NGOID: ngoID, Amount: 10, Description: "Item", Category: "Alimentação",
+				})
+				if err != nil {
+					errs <- err
+					return
+				}
+				expenseIDs <- expense.ID
+			}
+		}(uint(i%3 + 1))
+	}
+	wg.Wait()
+	close(donationIDs)
+	close(expenseIDs)
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	assertUnique := func(kind string, ids <-chan uint, expected int) {
+		seen := map[uint]bool{}
+		for id := range ids {
+			assert.False(t, seen[id], "%s com ID %d repetido", kind, id)
+			seen[id] = true
+		}
+		assert.Len(t, seen, expected, kind)
+	}
+	assertUnique("doação", donationIDs, workers)
+	assertUnique("gasto", expenseIDs, workers*2)
+
+	receiptIDs := map[uint]bool{}
+	for _, receipt := range donationSvc.snapshotReceipts() {
+		assert.False(t, receiptIDs[receipt.ID], "comprovante com ID %d repetido", receipt.ID)
+		receiptIDs[receipt.ID] = true
+	}
+	assert.Len(t, receiptIDs, workers)
+}
+
+func TestGetExpensesFiltersByCategoryAndStatus(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	register := func(amount float64, category string) uint {
+		expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: amount, Description: "Item", Category: category})
+		require.NoError(t, err)
+		return expense.ID
+	}
+	approvedFood := register(10, "Alimentação")
+	pendingFood := register(20, "Alimentação")
+	pendingTransport := register(30, "Transporte")
+	_, err := expenseSvc.UploadReceipt(context.Background(), approvedFood, []byte("nota fiscal"))
+	require.NoError(t, err)
+	require.NoError(t, adminSvc.ApproveExpense(approvedFood, 1))
+
+	ids := func(expenses []models.ExpenseResponse) []uint {
+		result := []uint{}
+		for _, e := range expenses {
+			result = append(result, e.ID)
+		}
+		return result
+	}
+
+	for name, tc := range map[string]struct {
+		filter   models.ExpenseFilter
+		expected []uint
+	}{
+		"sem filtros":        {models.ExpenseFilter{}, []uint{approvedFood, pendingFood, pendingTransport}},
+		"categoria":          {models.ExpenseFilter{Category: "alimentação"}, []uint{approvedFood, pendingFood}},
+		"status":             {models.ExpenseFilter{Status: "pendente"}, []uint{pendingFood, pendingTransport}},
+		"categoria e status": {models.ExpenseFilter{Category: "Alimentação", Status: "pendente"}, []uint{pendingFood}},
+		"sem resultados":     {models.ExpenseFilter{Category: "Saúde"}, []uint{}},
+	} {
+		byNGO, err := expenseSvc.GetExpensesByNGO(1, tc.filter)
+		require.NoError(t, err, name)
+		assert.Equal(t, tc.expected, ids(byNGO), name)
+
+		byDonation, err := expenseSvc.GetExpensesByDonation(donationID, tc.filter)
+		require.NoError(t, err, name)
+		assert.Equal(t, tc.expected, ids(byDonation), name)
+	}
+
+	_, err = expenseSvc.GetExpensesByNGO(1, models.ExpenseFilter{Category: "Lazer"})
+	assert.ErrorIs(t, err, ErrInvalidExpenseCategory)
+	_, err = expenseSvc.GetExpensesByDonation(donationID, models.ExpenseFilter{Status: "pago"})
+	assert.ErrorIs(t, err, ErrInvalidExpenseStatus)
+}
+
+func TestGetDonationBalance(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	register := func(amount float64) uint {
+		expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: amount, Description: "Item", Category: "Saúde"})
+		require.NoError(t, err)
+		return expense.ID
+	}
+	approved := register(25.5)
+	register(10.25)
+	rejected := register(40)
+	for _, id := range []uint{approved, rejected} {
+		_, err := expenseSvc.UploadReceipt(context.Background(), id, []byte("nota fiscal"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, adminSvc.ApproveExpense(approved, 1))
+	require.NoError(t, adminSvc.RejectExpense(rejected, 1, "Nota ilegível"))
+
+	balance, err := expenseSvc.GetDonationBalance(donationID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DonationBalance{DonationID: donationID, Total: 100, Spent: 25.5, Pending: 10.25, Available: 64.25}, balance)
+
+	_, err = expenseSvc.GetDonationBalance(9999)
+	assert.ErrorIs(t, err, ErrDonationNotFound)
+
+	pending, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 50, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	_, err = expenseSvc.GetDonationBalance(pending.ID)
+	assert.ErrorIs(t, err, ErrDonationNotCompleted)
+}
+
+func TestGetPendingExpensesQueue(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	now := start
+	expenseSvc.SetClock(ClockFunc(func() time.Time { return now }))
+
+	donations := map[uint]uint{
+		1: completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1}),
+		2: completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 2}),
+	}
+	register := func(ngoID uint, offset time.Duration) uint {
+		now = start.Add(offset)
+		expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donations[ngoID], NGOID: ngoID, Amount: 10, Description: "Item", Category: "Saúde"})
+		require.NoError(t, err)
+		return expense.ID
+	}
+	second := register(2, 2*time.Hour)
+	first := register(1, 0)
+	approved := register(1, time.Hour)
+	third := register(1, 3*time.Hour)
+	for _, id := range []uint{approved, third} {
+		_, err := expenseSvc.UploadReceipt(context.Background(), id, []byte("nota fiscal"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, adminSvc.ApproveExpense(approved, 1))
+
+	page, err := expenseSvc.GetPendingExpenses(1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Expenses, 2)
+	assert.Equal(t, first, page.Expenses[0].ID)
+	assert.Equal(t, second, page.Expenses[1].ID)
+	assert.Equal(t, donationSvc.ngoIndex[2].Name, page.Expenses[1].NGOName)
+	assert.Empty(t, page.Expenses[0].ReceiptIPFS)
+
+	page, err = expenseSvc.GetPendingExpenses(2, 2)
+	require.NoError(t, err)
+	require.Len(t, page.Expenses, 1)
+	assert.Equal(t, third, page.Expenses[0].ID)
+	assert.NotEmpty(t, page.Expenses[0].ReceiptIPFS, "O revisor precisa do comprovante enviado")
+
+	// Padrões e limites da paginação
+	page, err = expenseSvc.GetPendingExpenses(0, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Page)
+	assert.Equal(t, maxPendingExpensesPageSize, page.PageSize)
+	assert.Len(t, page.Expenses, 3)
+}
+
+func TestRefundAndExpenseRegistrationAreExclusive(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+
+	const donations = 8
+	ids := make([]uint, donations)
+	for i := range ids {
+		ids[i] = completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	}
+
+	var wg sync.WaitGroup
+	refunded := make([]bool, donations)
+	spent := make([]bool, donations)
+	for i, id := range ids {
+		wg.Add(2)
+		go func(i int, id uint) {
+			defer wg.Done()
+			refunded[i] = expenseSvc.RefundDonation(id, "pagamento contestado") == nil
+		}(i, id)
+		go func(i int, id uint) {
+			defer wg.Done()
+			_, err := expenseSvc.RegisterExpense(models.ExpenseRequest{
+				DonationID: id, NGOID: 1, Amount: 10, Description: "Item", Category: "Alimentação",
+			})
+			spent[i] = err == nil
+		}(i, id)
+	}
+	wg.Wait()
+
+	// Ou a doação foi estornada, ou passou a financiar o gasto; nunca os dois
+	for i := range ids {
+		assert.NotEqual(t, refunded[i], spent[i], "doação %d", ids[i])
+	}
+}
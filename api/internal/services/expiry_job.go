@@ -0,0 +1,96 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// PendingDonationExpiryJob expira periodicamente as doações que ficaram pendentes (sem
+// pagamento) por mais tempo que o limite configurado
+type PendingDonationExpiryJob struct {
+	donationService *DonationService
+	// ttl é a idade a partir da qual uma doação pendente é expirada
+	ttl      time.Duration
+	interval time.Duration
+	now      func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewPendingDonationExpiryJob cria o job que expira as doações pendentes abandonadas
+func NewPendingDonationExpiryJob(donationSvc *DonationService, ttl, interval time.Duration) *PendingDonationExpiryJob {
+	return &PendingDonationExpiryJob{
+		donationService: donationSvc,
+		ttl:             ttl,
+		interval:        interval,
+		now:             time.Now,
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+}
+
+// Start inicia a execução periódica do job em segundo plano
+func (j *PendingDonationExpiryJob) Start() {
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if expired := j.RunOnce(); expired > 0 {
+					log.Printf("%d doações pendentes expiradas", expired)
+				}
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop interrompe o job e aguarda o término da execução em andamento
+func (j *PendingDonationExpiryJob) Stop() {
+	j.stopOnce.Do(func() { close(j.stop) })
+	<-j.done
+}
+
+// Close interrompe o job, permitindo encerrá-lo junto com os demais componentes no desligamento
+func (j *PendingDonationExpiryJob) Close() error {
+	j.Stop()
+	return nil
+}
+
+// RunOnce expira as doações pendentes mais antigas que o limite e retorna quantas foram expiradas
+func (j *PendingDonationExpiryJob) RunOnce() int {
+	return j.donationService.expirePendingDonations(j.now().Add(-j.ttl))
+}
+
+// expirePendingDonations passa para "expired" as doações pendentes criadas antes de cutoff,
+// exceto as com confirmação de pagamento em andamento, e retorna quantas foram expiradas
+func (s *DonationService) expirePendingDonations(cutoff time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := 0
+	for i := range s.donations {
+		donation := &s.donations[i]
+		if donation.Status != "pending" || !donation.CreatedAt.Before(cutoff) || s.processing[donation.ID] {
+			continue
+		}
+
+		donation.Status = "expired"
+		if err := s.store.Donations.Save(donation); err != nil {
+			// Sem persistir, a doação continua pendente e será tentada na próxima execução
+			donation.Status = "pending"
+			log.Printf("Erro ao expirar a doação %d: %v", donation.ID, err)
+			continue
+		}
+		log.Printf("Doação %d expirada: pendente desde %s", donation.ID, donation.CreatedAt.Format(time.RFC3339))
+		expired++
+	}
+	return expired
+}
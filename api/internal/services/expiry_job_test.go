@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingDonationExpiry(t *testing.T) {
+	donationSvc := NewDonationService()
+	explorerSvc := NewExplorerService(donationSvc, NewExpenseService(donationSvc))
+	job := NewPendingDonationExpiryJob(donationSvc, 24*time.Hour, time.Minute)
+
+	stale, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 40, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	completed := completeDonation(t, donationSvc, models.DonationRequest{Amount: 60, DonorID: 2, NGOID: 1})
+	created := donationSvc.donations[0].CreatedAt
+
+	job.now = func() time.Time { return created.Add(23 * time.Hour) }
+	assert.Equal(t, 0, job.RunOnce(), "Doações pendentes dentro do prazo não expiram")
+
+	job.now = func() time.Time { return created.Add(25 * time.Hour) }
+	assert.Equal(t, 1, job.RunOnce())
+	assert.Equal(t, 0, job.RunOnce(), "Cada doação expira uma única vez")
+
+	donation, found := donationSvc.findDonation(stale.ID)
+	require.True(t, found)
+	assert.Equal(t, "expired", donation.Status)
+	donation, _ = donationSvc.findDonation(completed)
+	assert.Equal(t, "completed", donation.Status)
+
+	// Doações expiradas não podem mais ser pagas nem aparecem no explorador
+	_, err = donationSvc.MockPaymentConfirmation(stale.ID)
+	assert.ErrorIs(t, err, ErrDonationNotPending)
+	_, err = explorerSvc.GetDonationByID(stale.ID)
+	assert.Error(t, err)
+	_, err = explorerSvc.GetDonationByID(completed)
+	assert.NoError(t, err)
+
+	result, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{Status: models.ExplorerStatusAll})
+	require.NoError(t, err)
+	require.Len(t, result.Donations, 1)
+	assert.Equal(t, completed, result.Donations[0].ID)
+}
+
+func TestPendingDonationExpirySkipsConfirmationInProgress(t *testing.T) {
+	donationSvc := NewDonationService()
+	job := NewPendingDonationExpiryJob(donationSvc, time.Hour, time.Minute)
+
+	pending, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 40, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	donationSvc.processing[pending.ID] = true
+
+	job.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	assert.Equal(t, 0, job.RunOnce())
+}
+
+func TestPendingDonationExpiryJobStop(t *testing.T) {
+	job := NewPendingDonationExpiryJob(NewDonationService(), time.Hour, time.Millisecond)
+	job.Start()
+	time.Sleep(5 * time.Millisecond)
+	job.Stop()
+	job.Stop()
+}
@@ -1,7 +1,12 @@
 package services
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"trackable-donations/api/internal/models"
@@ -21,8 +26,78 @@ func NewExplorerService(donationSvc *DonationService, expenseSvc *ExpenseService
 	}
 }
 
+// ErrInvalidAmountRange indica uma faixa de valores com mínimo maior que o máximo
+var ErrInvalidAmountRange = errors.New("valor mínimo não pode ser maior que o valor máximo")
+
+// ErrInvalidSortBy indica uma ordenação desconhecida na busca do explorador
+var ErrInvalidSortBy = errors.New("ordenação inválida: use date_asc, date_desc, amount_asc ou amount_desc")
+
+// ErrInvalidExplorerStatus indica um status que a busca pública do explorador não aceita
+var ErrInvalidExplorerStatus = errors.New("status inválido: use completed, refunded ou all")
+
+// ErrInvalidCursor indica um cursor de paginação malformado ou usado com ordenação por valor
+var ErrInvalidCursor = errors.New("cursor inválido: use o next_cursor de uma busca ordenada por data")
+
+// explorerStatuses retorna, para cada status aceito na busca, os status de doação exibidos
+var explorerStatuses = map[string][]string{
+	models.ExplorerStatusCompleted: {"completed"},
+	models.ExplorerStatusRefunded:  {"refunded"},
+	models.ExplorerStatusAll:       {"completed", "refunded"},
+}
+
+// explorerSortLess retorna, para cada ordenação aceita, a comparação entre duas doações. Nas
+// ordenações por data os empates seguem o ID, a ordem de registro, para que o cursor
+// (data e ID da última doação vista) identifique uma posição única.
+var explorerSortLess = map[string]func(a, b models.Donation) bool{
+	models.ExplorerSortDateAsc: func(a, b models.Donation) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	},
+	models.ExplorerSortDateDesc: func(a, b models.Donation) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	},
+	models.ExplorerSortAmountAsc:  func(a, b models.Donation) bool { return a.Amount < b.Amount },
+	models.ExplorerSortAmountDesc: func(a, b models.Donation) bool { return a.Amount > b.Amount },
+}
+
 // SearchDonations busca doações com base nos critérios fornecidos
 func (s *ExplorerService) SearchDonations(query models.TransactionExplorerQuery) (models.TransactionExplorerResult, error) {
+	if query.MinAmount > 0 && query.MaxAmount > 0 && query.MinAmount > query.MaxAmount {
+		return models.TransactionExplorerResult{}, ErrInvalidAmountRange
+	}
+
+	if query.SortBy == "" {
+		query.SortBy = models.ExplorerSortDateDesc
+	}
+	less, ok := explorerSortLess[query.SortBy]
+	if !ok {
+		return models.TransactionExplorerResult{}, ErrInvalidSortBy
+	}
+
+	if query.Status == "" {
+		query.Status = models.ExplorerStatusCompleted
+	}
+	statuses, ok := explorerStatuses[query.Status]
+	if !ok {
+		return models.TransactionExplorerResult{}, ErrInvalidExplorerStatus
+	}
+
+	// A paginação por cursor só é possível nas ordenações por data
+	byDate := query.SortBy == models.ExplorerSortDateAsc || query.SortBy == models.ExplorerSortDateDesc
+	var after *models.Donation
+	if query.After != "" {
+		cursor, err := decodeExplorerCursor(query.After)
+		if err != nil || !byDate {
+			return models.TransactionExplorerResult{}, ErrInvalidCursor
+		}
+		after = &cursor
+	}
+
 	result := models.TransactionExplorerResult{
 		Donations: []models.DonationDetails{},
 		Page:      query.Page,
@@ -39,9 +114,9 @@ func (s *ExplorerService) SearchDonations(query models.TransactionExplorerQuery)
 
 	// Filtrar doações com base nos critérios
 	var filteredDonations []models.Donation
-	for _, donation := range s.donationService.donations {
-		// Filtrar apenas doações completadas
-		if donation.Status != "completed" {
+	for _, donation := range s.donationService.snapshotDonations() {
+		// Filtrar pelo status; pendentes nunca aparecem no explorador público
+		if !slices.Contains(statuses, donation.Status) {
 			continue
 		}
 
@@ -55,6 +130,11 @@ func (s *ExplorerService) SearchDonations(query models.TransactionExplorerQuery)
 			continue
 		}
 
+		// Filtrar por campanha
+		if query.CampaignID != 0 && donation.CampaignID != query.CampaignID {
+			continue
+		}
+
 		// Filtrar por período
 		if !query.StartDate.IsZero() && donation.CreatedAt.Before(query.StartDate) {
 			continue
@@ -63,14 +143,40 @@ func (s *ExplorerService) SearchDonations(query models.TransactionExplorerQuery)
 			continue
 		}
 
+		// Filtrar por faixa de valor (zero não limita)
+		if query.MinAmount > 0 && donation.Amount < query.MinAmount {
+			continue
+		}
+		if query.MaxAmount > 0 && donation.Amount > query.MaxAmount {
+			continue
+		}
+
+		// Filtrar por metadados (todos os pares devem coincidir). Só as chaves públicas
+		// participam da busca, para que ela não revele os valores das chaves privadas.
+		if len(query.Metadata) > 0 && !matchesMetadata(s.donationService.publicMetadata(donation.Metadata), query.Metadata) {
+			continue
+		}
+
 		filteredDonations = append(filteredDonations, donation)
 	}
 
+	// Ordenar todo o conjunto filtrado antes de paginar (empates mantêm a ordem de registro)
+	sort.SliceStable(filteredDonations, func(i, j int) bool {
+		return less(filteredDonations[i], filteredDonations[j])
+	})
+
 	// Calcular total
 	result.Total = len(filteredDonations)
 
-	// Aplicar paginação
+	// Aplicar paginação: com cursor, a página começa logo depois da última doação vista,
+	// mesmo que novas doações tenham entrado antes dela
 	startIndex := (result.Page - 1) * result.PageSize
+	if after != nil {
+		result.Page = 0
+		startIndex = sort.Search(len(filteredDonations), func(i int) bool {
+			return less(*after, filteredDonations[i])
+		})
+	}
 	endIndex := startIndex + result.PageSize
 	if startIndex >= len(filteredDonations) {
 		return result, nil
@@ -78,6 +184,9 @@ func (s *ExplorerService) SearchDonations(query models.TransactionExplorerQuery)
 	if endIndex > len(filteredDonations) {
 		endIndex = len(filteredDonations)
 	}
+	if byDate && endIndex < len(filteredDonations) {
+		result.NextCursor = encodeExplorerCursor(filteredDonations[endIndex-1])
+	}
 
 	// Processar doações selecionadas
 	for _, donation := range filteredDonations[startIndex:endIndex] {
@@ -91,10 +200,38 @@ func (s *ExplorerService) SearchDonations(query models.TransactionExplorerQuery)
 	return result, nil
 }
 
+// encodeExplorerCursor gera o cursor opaco que aponta para a posição da doação nas
+// ordenações por data: a data de criação e o ID
+func encodeExplorerCursor(donation models.Donation) string {
+	raw := fmt.Sprintf("%d:%d", donation.CreatedAt.UnixNano(), donation.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeExplorerCursor recupera a data e o ID da doação apontada pelo cursor
+func decodeExplorerCursor(cursor string) (models.Donation, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return models.Donation{}, err
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return models.Donation{}, ErrInvalidCursor
+	}
+	createdAt, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return models.Donation{}, err
+	}
+	donationID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return models.Donation{}, err
+	}
+	return models.Donation{ID: uint(donationID), CreatedAt: time.Unix(0, createdAt)}, nil
+}
+
 // GetDonationByHash obtém os detalhes de uma doação pelo hash de transação
 func (s *ExplorerService) GetDonationByHash(hash string) (models.DonationDetails, error) {
-	for _, donation := range s.donationService.donations {
-		if strings.EqualFold(donation.TransactionHash, hash) {
+	for _, donation := range s.donationService.snapshotDonations() {
+		if strings.EqualFold(donation.TransactionHash, hash) && publiclyVisible(donation) {
 			return s.getDonationDetails(donation)
 		}
 	}
@@ -103,14 +240,112 @@ func (s *ExplorerService) GetDonationByHash(hash string) (models.DonationDetails
 
 // GetDonationByID obtém os detalhes de uma doação pelo ID
 func (s *ExplorerService) GetDonationByID(id uint) (models.DonationDetails, error) {
-	for _, donation := range s.donationService.donations {
-		if donation.ID == id {
+	for _, donation := range s.donationService.snapshotDonations() {
+		if donation.ID == id && publiclyVisible(donation) {
 			return s.getDonationDetails(donation)
 		}
 	}
 	return models.DonationDetails{}, errors.New("doação não encontrada")
 }
 
+// GetDonationTrace monta o rastro completo de uma doação para que o doador acompanhe
+// o uso do dinheiro do pagamento até cada gasto
+func (s *ExplorerService) GetDonationTrace(id uint) (models.DonationTrace, error) {
+	details, err := s.GetDonationByID(id)
+	if err != nil {
+		return models.DonationTrace{}, err
+	}
+
+	trace := models.DonationTrace{
+		Donation:       details,
+		ResourceUsages: []models.ResourceUsage{},
+		Expenses:       []models.ExpenseResponse{},
+	}
+
+	if receipt, err := s.donationService.GetDonationReceipt(id); err == nil {
+		receipt.DonorEmail = "" // O explorador é público
+		receipt.DonorName = details.DonorName
+		trace.Receipt = &receipt
+	}
+
+	usages, err := s.donationService.GetResourceUsagesByDonationID(id)
+	if err != nil {
+		return models.DonationTrace{}, err
+	}
+	trace.ResourceUsages = append(trace.ResourceUsages, usages...)
+
+	expenses, err := s.expenseService.GetExpensesByDonation(id, models.ExpenseFilter{})
+	if err != nil {
+		return models.DonationTrace{}, err
+	}
+	sort.SliceStable(expenses, func(i, j int) bool {
+		return expenses[i].CreatedAt.Before(expenses[j].CreatedAt)
+	})
+	for _, expense := range expenses {
+		if expense.Status != "rejeitado" {
+			trace.TotalSpent += expense.DrawnFrom(id)
+		}
+	}
+	trace.Expenses = append(trace.Expenses, expenses...)
+	trace.RemainingBalance = details.Amount - trace.TotalSpent
+
+	return trace, nil
+}
+
+// VerifyDonation confere novamente, na blockchain e no IPFS, uma doação concluída: se a
+// transação está em um bloco íntegro e se o comprovante existe no IPFS. É a versão pública
+// da auditoria de doações (ver AdminService.AuditEntity); doações que não aparecem no
+// explorador (ver publiclyVisible) não são encontradas.
+func (s *ExplorerService) VerifyDonation(id uint) (models.DonationVerification, error) {
+	var donation models.Donation
+	found := false
+	for _, d := range s.donationService.snapshotDonations() {
+		if d.ID == id && publiclyVisible(d) {
+			donation, found = d, true
+			break
+		}
+	}
+	if !found {
+		return models.DonationVerification{}, ErrDonationNotFound
+	}
+
+	result := models.DonationVerification{
+		DonationID:      donation.ID,
+		TransactionHash: donation.TransactionHash,
+		VerifiedAt:      s.donationService.now(),
+		Errors:          []string{},
+	}
+	if donation.Status != "completed" {
+		result.Errors = append(result.Errors, "Doação não concluída: ainda não há registro na blockchain nem comprovante")
+		return result, nil
+	}
+
+	if donation.TransactionHash == "" {
+		result.Errors = append(result.Errors, "Doação sem transação registrada na blockchain")
+	} else if index, ok := s.donationService.blockchainTransactionBlock(donation.TransactionHash, donationTransactionID(donation.ID)); ok {
+		result.BlockchainValid = true
+		result.BlockIndex = &index
+	} else {
+		result.Errors = append(result.Errors, "Transação não encontrada na blockchain ou cadeia adulterada")
+	}
+
+	for _, receipt := range s.donationService.snapshotReceipts() {
+		if receipt.DonationID == donation.ID {
+			result.ReceiptIPFS = receipt.IPFSHash
+			break
+		}
+	}
+	if result.ReceiptIPFS == "" {
+		result.Errors = append(result.Errors, "Comprovante da doação não encontrado")
+	} else if s.donationService.ipfsContentExists(result.ReceiptIPFS) {
+		result.IPFSValid = true
+	} else {
+		result.Errors = append(result.Errors, "Comprovante não encontrado no IPFS")
+	}
+
+	return result, nil
+}
+
 // getDonationDetails obtém os detalhes de uma doação
 func (s *ExplorerService) getDonationDetails(donation models.Donation) (models.DonationDetails, error) {
 	// Obter nome do doador
@@ -127,7 +362,7 @@ func (s *ExplorerService) getDonationDetails(donation models.Donation) (models.D
 
 	// Verificar se tem recibo
 	hasReceipt := false
-	for _, receipt := range s.donationService.receipts {
+	for _, receipt := range s.donationService.snapshotReceipts() {
 		if receipt.DonationID == donation.ID {
 			hasReceipt = true
 			break
@@ -137,18 +372,21 @@ func (s *ExplorerService) getDonationDetails(donation models.Donation) (models.D
 	// Verificar se tem despesas e contar
 	hasExpenses := false
 	expensesCount := 0
-	for _, expense := range s.expenseService.expenses {
-		if expense.DonationID == donation.ID {
+	for _, expense := range s.expenseService.snapshotExpenses() {
+		if expense.DrawnFrom(donation.ID) > 0 {
 			hasExpenses = true
 			expensesCount++
 		}
 	}
 
-	// Criar detalhes da doação
+	// Criar detalhes da doação, com a moeda em que o doador pagou
+	currency, originalAmount := donationCurrency(donation)
 	details := models.DonationDetails{
 		ID:              donation.ID,
 		Amount:          donation.Amount,
-		DonorName:       donor.Name,
+		Currency:        currency,
+		OriginalAmount:  originalAmount,
+		DonorName:       publicDonorName(donation, donor),
 		NGOName:         ngo.Name,
 		NGOCategory:     ngo.Category,
 		Date:            donation.CreatedAt,
@@ -157,11 +395,23 @@ func (s *ExplorerService) getDonationDetails(donation models.Donation) (models.D
 		HasReceipt:      hasReceipt,
 		HasExpenses:     hasExpenses,
 		ExpensesCount:   expensesCount,
+		Metadata:        s.donationService.publicMetadata(donation.Metadata),
+		CampaignID:      donation.CampaignID,
 	}
 
 	return details, nil
 }
 
+// matchesMetadata verifica se os metadados contêm todos os pares chave/valor do filtro
+func matchesMetadata(metadata, filter map[string]string) bool {
+	for key, value := range filter {
+		if current, ok := metadata[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
+
 // GetDonationsByNGO obtém todas as doações para uma ONG específica
 func (s *ExplorerService) GetDonationsByNGO(ngoID uint, page, pageSize int) (models.TransactionExplorerResult, error) {
 	query := models.TransactionExplorerQuery{
@@ -191,21 +441,17 @@ func (s *ExplorerService) GetRecentDonations(limit int) ([]models.DonationDetail
 
 	// Filtrar apenas doações completadas
 	var completedDonations []models.Donation
-	for _, donation := range s.donationService.donations {
+	for _, donation := range s.donationService.snapshotDonations() {
 		if donation.Status == "completed" {
 			completedDonations = append(completedDonations, donation)
 		}
 	}
 
-	// Ordenar por data (mais recentes primeiro)
+	// Ordenar por data (mais recentes primeiro), mantendo a ordem de registro em empates
 	// Em um sistema real, usaríamos ORDER BY na consulta SQL
-	for i := 0; i < len(completedDonations)-1; i++ {
-		for j := i + 1; j < len(completedDonations); j++ {
-			if completedDonations[i].CreatedAt.Before(completedDonations[j].CreatedAt) {
-				completedDonations[i], completedDonations[j] = completedDonations[j], completedDonations[i]
-			}
-		}
-	}
+	sort.SliceStable(completedDonations, func(i, j int) bool {
+		return completedDonations[i].CreatedAt.After(completedDonations[j].CreatedAt)
+	})
 
 	// Limitar ao número solicitado
 	if len(completedDonations) > limit {
@@ -224,3 +470,23 @@ func (s *ExplorerService) GetRecentDonations(limit int) ([]models.DonationDetail
 
 	return details, nil
 }
+
+// publiclyVisible informa se a doação aparece no explorador: apenas as concluídas e as
+// estornadas, como na busca. Pendentes, expiradas ou em qualquer outro status ficam de fora.
+func publiclyVisible(donation models.Donation) bool {
+	for _, status := range explorerStatuses[models.ExplorerStatusAll] {
+		if donation.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// publicDonorName é o nome do doador exibido no explorador: só aparece quando o doador
+// optou por não ser anônimo; doações sem a opção são anônimas
+func publicDonorName(donation models.Donation, donor models.User) string {
+	if donation.DonorAnonymous != nil && !*donation.DonorAnonymous {
+		return donor.Name
+	}
+	return models.AnonymousDonorName
+}
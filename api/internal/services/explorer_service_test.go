@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDonationMetadataRoundTripAndSearch(t *testing.T) {
+	donationSvc := NewDonationService()
+	explorerSvc := NewExplorerService(donationSvc, NewExpenseService(donationSvc))
+	donationSvc.SetPublicMetadataKeys([]string{"campanha"})
+
+	resp, err := donationSvc.ProcessDonation(models.DonationRequest{
+		Amount: 60, DonorID: 1, NGOID: 1,
+		Metadata: map[string]string{"crm_id": " CRM-42 ", "campanha": "natal"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"crm_id": "CRM-42", "campanha": "natal"}, resp.Metadata)
+	_, err = donationSvc.MockPaymentConfirmation(resp.ID)
+	require.NoError(t, err)
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 40, DonorID: 2, NGOID: 1, Metadata: map[string]string{"crm_id": "CRM-7"}})
+
+	result, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{Metadata: map[string]string{"campanha": "natal"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Total)
+	assert.Equal(t, resp.ID, result.Donations[0].ID)
+	assert.Equal(t, map[string]string{"campanha": "natal"}, result.Donations[0].Metadata, "Apenas chaves liberadas aparecem na visão pública")
+
+	// Chaves privadas não participam da busca, que não pode revelar os seus valores
+	result, err = explorerSvc.SearchDonations(models.TransactionExplorerQuery{Metadata: map[string]string{"crm_id": "CRM-42"}})
+	require.NoError(t, err)
+	assert.Zero(t, result.Total)
+	assert.Empty(t, result.Donations)
+
+	_, err = donationSvc.ProcessDonation(models.DonationRequest{Amount: 10, DonorID: 1, NGOID: 1, Metadata: map[string]string{"chave inválida": "x"}})
+	assert.Error(t, err)
+}
+
+// seedCompletedDonations registra diretamente doações concluídas, sem passar pela mineração
+func seedCompletedDonations(svc *DonationService, count int, start time.Time) {
+	for i := 0; i < count; i++ {
+		donation := models.Donation{
+			Amount:    10,
+			DonorID:   1,
+			NGOID:     uint(i%3 + 1),
+			CreatedAt: start.Add(time.Duration((i*7919)%count) * time.Minute),
+			Status:    "completed",
+		}
+		if err := svc.store.Donations.Create(&donation); err != nil {
+			panic(err)
+		}
+		svc.donations = append(svc.donations, donation)
+	}
+}
+
+func TestGetRecentDonationsOrdersByDateKeepingTies(t *testing.T) {
+	donationSvc := NewDonationService()
+	explorerSvc := NewExplorerService(donationSvc, NewExpenseService(donationSvc))
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, date := range []time.Time{start, start.Add(time.Hour), start, start.Add(2 * time.Hour)} {
+		donationSvc.donations = append(donationSvc.donations, models.Donation{
+			ID: uint(i + 1), Amount: 10, DonorID: 1, NGOID: 1, CreatedAt: date, Status: "completed",
+		})
+	}
+
+	recent, err := explorerSvc.GetRecentDonations(3)
+	require.NoError(t, err)
+	require.Len(t, recent, 3)
+	assert.Equal(t, []uint{4, 2, 1}, []uint{recent[0].ID, recent[1].ID, recent[2].ID},
+		"Empates mantêm a ordem de registro")
+}
+
+func BenchmarkGetRecentDonations(b *testing.B) {
+	donationSvc := NewDonationService()
+	explorerSvc := NewExplorerService(donationSvc, NewExpenseService(donationSvc))
+	seedCompletedDonations(donationSvc, 10000, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := explorerSvc.GetRecentDonations(10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSearchDonationsByAmountRange(t *testing.T) {
+	donationSvc := NewDonationService()
+	explorerSvc := NewExplorerService(donationSvc, NewExpenseService(donationSvc))
+
+	for _, amount := range []float64{50, 500, 15000} {
+		completeDonation(t, donationSvc, models.DonationRequest{Amount: amount, DonorID: 1, NGOID: 1})
+	}
+
+	result, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{MinAmount: 10000})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Total)
+	assert.Equal(t, 15000.0, result.Donations[0].Amount)
+
+	result, err = explorerSvc.SearchDonations(models.TransactionExplorerQuery{MinAmount: 50, MaxAmount: 500})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total, "Os limites são inclusivos")
+
+	result, err = explorerSvc.SearchDonations(models.TransactionExplorerQuery{MaxAmount: 100})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+
+	_, err = explorerSvc.SearchDonations(models.TransactionExplorerQuery{MinAmount: 500, MaxAmount: 50})
+	assert.ErrorIs(t, err, ErrInvalidAmountRange)
+}
+
+func TestSearchDonationsFiltersByStatus(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	explorerSvc := NewExplorerService(donationSvc, expenseSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+
+	completed := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	refunded := completeDonation(t, donationSvc, models.DonationRequest{Amount: 40, DonorID: 2, NGOID: 1})
+	require.NoError(t, adminSvc.RefundDonation(refunded, 3, "pagamento contestado"))
+	_, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 25, DonorID: 1, NGOID: 1})
+	require.NoError(t, err, "Doação pendente, que nunca deve aparecer")
+
+	ids := func(status string) []uint {
+		result, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{Status: status, SortBy: models.ExplorerSortAmountDesc})
+		require.NoError(t, err)
+		var ids []uint
+		for _, donation := range result.Donations {
+			ids = append(ids, donation.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []uint{completed}, ids(""), "O padrão continua exibindo apenas as confirmadas")
+	assert.Equal(t, []uint{completed}, ids(models.ExplorerStatusCompleted))
+	assert.Equal(t, []uint{refunded}, ids(models.ExplorerStatusRefunded))
+	assert.Equal(t, []uint{completed, refunded}, ids(models.ExplorerStatusAll))
+
+	for _, status := range []string{"pending", "cancelled"} {
+		_, err = explorerSvc.SearchDonations(models.TransactionExplorerQuery{Status: status})
+		assert.ErrorIs(t, err, ErrInvalidExplorerStatus, status)
+	}
+}
+
+func TestSearchDonationsSortsBeforePaginating(t *testing.T) {
+	donationSvc := NewDonationService()
+	explorerSvc := NewExplorerService(donationSvc, NewExpenseService(donationSvc))
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, amount := range []float64{300, 100, 500, 200, 400} {
+		donationSvc.donations = append(donationSvc.donations, models.Donation{
+			ID: uint(i + 1), Amount: amount, DonorID: 1, NGOID: 1,
+			CreatedAt: start.Add(time.Duration(i) * time.Hour), Status: "completed",
+		})
+	}
+
+	ids := func(result models.TransactionExplorerResult) []uint {
+		var out []uint
+		for _, donation := range result.Donations {
+			out = append(out, donation.ID)
+		}
+		return out
+	}
+
+	for sortBy, expected := range map[string][]uint{
+		"":                            {5, 4},
+		models.ExplorerSortDateDesc:   {5, 4},
+		models.ExplorerSortDateAsc:    {1, 2},
+		models.ExplorerSortAmountAsc:  {2, 4},
+		models.ExplorerSortAmountDesc: {3, 5},
+	} {
+		result, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{SortBy: sortBy, PageSize: 2})
+		require.NoError(t, err, sortBy)
+		assert.Equal(t, 5, result.Total, sortBy)
+		assert.Equal(t, expected, ids(result), sortBy)
+	}
+
+	result, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{SortBy: models.ExplorerSortAmountDesc, Page: 3, PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2}, ids(result), "A última página deve conter o menor valor")
+
+	_, err = explorerSvc.SearchDonations(models.TransactionExplorerQuery{SortBy: "amount"})
+	assert.ErrorIs(t, err, ErrInvalidSortBy)
+}
+
+func TestSearchDonationsCursorPagination(t *testing.T) {
+	donationSvc := NewDonationService()
+	explorerSvc := NewExplorerService(donationSvc, NewExpenseService(donationSvc))
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	addDonation := func(id uint, createdAt time.Time) {
+		donationSvc.donations = append(donationSvc.donations, models.Donation{
+			ID: id, Amount: 10, DonorID: 1, NGOID: 1, CreatedAt: createdAt, Status: "completed",
+		})
+	}
+	// As doações 2 e 3 empatam na data; o ID desempata
+	addDonation(1, start)
+	addDonation(2, start.Add(time.Hour))
+	addDonation(3, start.Add(time.Hour))
+	addDonation(4, start.Add(2*time.Hour))
+	addDonation(5, start.Add(3*time.Hour))
+
+	ids := func(result models.TransactionExplorerResult) []uint {
+		var out []uint
+		for _, donation := range result.Donations {
+			out = append(out, donation.ID)
+		}
+		return out
+	}
+
+	first, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{5, 4}, ids(first))
+	require.NotEmpty(t, first.NextCursor)
+
+	// Uma doação nova entre as buscas deslocaria a paginação por offset, mas não o cursor
+	addDonation(6, start.Add(4*time.Hour))
+	offset, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{Page: 2, PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{4, 2}, ids(offset), "O offset repete a doação 4")
+
+	second, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{PageSize: 2, After: first.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2, 3}, ids(second))
+	assert.Zero(t, second.Page)
+	assert.Equal(t, 6, second.Total)
+
+	third, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{PageSize: 2, After: second.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1}, ids(third))
+	assert.Empty(t, third.NextCursor, "A última página não tem próximo cursor")
+
+	ascending, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{SortBy: models.ExplorerSortDateAsc, PageSize: 3})
+	require.NoError(t, err)
+	ascending, err = explorerSvc.SearchDonations(models.TransactionExplorerQuery{SortBy: models.ExplorerSortDateAsc, PageSize: 3, After: ascending.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{4, 5, 6}, ids(ascending))
+
+	_, err = explorerSvc.SearchDonations(models.TransactionExplorerQuery{After: "não-é-um-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = explorerSvc.SearchDonations(models.TransactionExplorerQuery{SortBy: models.ExplorerSortAmountAsc, After: first.NextCursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor, "O cursor só vale nas ordenações por data")
+
+	amount, err := explorerSvc.SearchDonations(models.TransactionExplorerQuery{SortBy: models.ExplorerSortAmountAsc, PageSize: 2})
+	require.NoError(t, err)
+	assert.Empty(t, amount.NextCursor)
+}
+
+func TestGetDonationTrace(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	explorerSvc := NewExplorerService(donationSvc, expenseSvc)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	kept, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 30, Description: "Cestas básicas", Category: "Alimentação"})
+	require.NoError(t, err)
+	_, err = expenseSvc.UploadReceipt(context.Background(), kept.ID, []byte("nota fiscal"))
+	require.NoError(t, err)
+	rejected, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 50, Description: "Duplicado", Category: "Alimentação"})
+	require.NoError(t, err)
+	require.NoError(t, expenseSvc.reviewExpense(rejected.ID, "rejeitado", "Gasto duplicado"))
+
+	trace, err := explorerSvc.GetDonationTrace(donationID)
+	require.NoError(t, err)
+	assert.Equal(t, donationID, trace.Donation.ID)
+	require.NotNil(t, trace.Receipt)
+	assert.NotEmpty(t, trace.Receipt.TransactionHash)
+	assert.Empty(t, trace.Receipt.DonorEmail, "O explorador público não expõe o e-mail do doador")
+	assert.NotEmpty(t, trace.ResourceUsages)
+
+	require.Len(t, trace.Expenses, 2)
+	assert.NotEmpty(t, trace.Expenses[0].ReceiptIPFS)
+	assert.NotEmpty(t, trace.Expenses[0].BlockchainRef)
+	assert.Equal(t, "rejeitado", trace.Expenses[1].Status)
+	assert.Equal(t, 30.0, trace.TotalSpent, "Gastos rejeitados não consomem o saldo")
+	assert.Equal(t, 70.0, trace.RemainingBalance)
+
+	_, err = explorerSvc.GetDonationTrace(999)
+	assert.Error(t, err)
+}
+
+func TestExplorerMasksAnonymousDonors(t *testing.T) {
+	donationSvc := NewDonationService()
+	explorerSvc := NewExplorerService(donationSvc, NewExpenseService(donationSvc))
+
+	public := false
+	anonymousID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 50, DonorID: 1, NGOID: 1})
+	publicID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 80, DonorID: 1, NGOID: 1, DonorAnonymous: &public})
+
+	details, err := explorerSvc.GetDonationByID(anonymousID)
+	require.NoError(t, err)
+	assert.Equal(t, models.AnonymousDonorName, details.DonorName, "Sem a opção, a doação é anônima no explorador")
+
+	trace, err := explorerSvc.GetDonationTrace(anonymousID)
+	require.NoError(t, err)
+	assert.Equal(t, models.AnonymousDonorName, trace.Donation.DonorName)
+	require.NotNil(t, trace.Receipt)
+	assert.Equal(t, models.AnonymousDonorName, trace.Receipt.DonorName)
+
+	details, err = explorerSvc.GetDonationByID(publicID)
+	require.NoError(t, err)
+	assert.Equal(t, "João Silva", details.DonorName)
+
+	// O dashboard do próprio doador continua com o nome verdadeiro
+	dashboard, err := donationSvc.GetDonorDashboard(1)
+	require.NoError(t, err)
+	assert.Equal(t, "João Silva", dashboard.DonorName)
+}
+
+func TestVerifyDonationChecksChainAndIPFS(t *testing.T) {
+	donationSvc := NewDonationService()
+	explorerSvc := NewExplorerService(donationSvc, NewExpenseService(donationSvc))
+	ipfs := NewMemoryIPFSClient()
+	donationSvc.SetIPFSClient(ipfs)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 80, DonorID: 1, NGOID: 2})
+	result, err := explorerSvc.VerifyDonation(donationID)
+	require.NoError(t, err)
+	assert.True(t, result.BlockchainValid)
+	assert.True(t, result.IPFSValid)
+	require.NotNil(t, result.BlockIndex)
+	assert.Equal(t, donationSvc.blockchain.LastBlock().Index, *result.BlockIndex)
+	assert.Empty(t, result.Errors)
+
+	// Um bloco adulterado não passa na verificação, e um comprovante ausente do IPFS também é apontado
+	donationSvc.blockchain.Chain[len(donationSvc.blockchain.Chain)-1].Transactions[0].Amount = 8000
+	donationSvc.SetIPFSClient(NewMemoryIPFSClient())
+	result, err = explorerSvc.VerifyDonation(donationID)
+	require.NoError(t, err)
+	assert.False(t, result.BlockchainValid)
+	assert.False(t, result.IPFSValid)
+	assert.Nil(t, result.BlockIndex)
+	assert.Len(t, result.Errors, 2)
+
+	_, err = explorerSvc.VerifyDonation(9999)
+	assert.ErrorIs(t, err, ErrDonationNotFound)
+}
+
+func TestExplorerDonationRoutesHideUnfinishedDonations(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	explorerSvc := NewExplorerService(donationSvc, expenseSvc)
+
+	refunded := completeDonation(t, donationSvc, models.DonationRequest{Amount: 40, DonorID: 2, NGOID: 1})
+	require.NoError(t, expenseSvc.RefundDonation(refunded, "pagamento contestado"))
+
+	// Uma doação pendente, uma expirada e uma em um status fora do explorador, todas com hash
+	hidden := map[string]uint{}
+	for _, status := range []string{"pending", "expired", "cancelled"} {
+		resp, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 25, DonorID: 1, NGOID: 1})
+		require.NoError(t, err)
+		for i := range donationSvc.donations {
+			if donationSvc.donations[i].ID == resp.ID {
+				donationSvc.donations[i].Status = status
+				donationSvc.donations[i].TransactionHash = "0x" + status
+			}
+		}
+		hidden[status] = resp.ID
+	}
+
+	for status, id := range hidden {
+		_, err := explorerSvc.GetDonationByHash("0x" + status)
+		assert.Error(t, err, status)
+		_, err = explorerSvc.GetDonationByID(id)
+		assert.Error(t, err, status)
+		_, err = explorerSvc.GetDonationTrace(id)
+		assert.Error(t, err, status)
+		_, err = explorerSvc.VerifyDonation(id)
+		assert.ErrorIs(t, err, ErrDonationNotFound, status)
+	}
+
+	// As estornadas continuam públicas, como na busca
+	_, err := explorerSvc.GetDonationByID(refunded)
+	assert.NoError(t, err)
+	verification, err := explorerSvc.VerifyDonation(refunded)
+	require.NoError(t, err)
+	assert.NotEmpty(t, verification.Errors, "Uma doação estornada não é mais uma doação concluída")
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Situação de uma dependência na verificação de saúde
+const (
+	DependencyUp   = "up"
+	DependencyDown = "down"
+)
+
+// HealthProbe verifica se uma dependência externa (banco, IPFS, nó da blockchain) está acessível
+type HealthProbe struct {
+	Name string
+	// Critical indica que a API não consegue atender sem a dependência
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+// DependencyStatus é o resultado da verificação de uma dependência
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthReport reúne o resultado da verificação de todas as dependências
+type HealthReport struct {
+	Dependencies map[string]DependencyStatus
+	// Degraded indica que alguma dependência está fora do ar
+	Degraded bool
+	// CriticalDown indica que alguma dependência crítica está fora do ar
+	CriticalDown bool
+}
+
+// HealthChecker verifica as dependências em paralelo, cada uma limitada pelo timeout
+type HealthChecker struct {
+	probes  []HealthProbe
+	timeout time.Duration
+}
+
+// NewHealthChecker cria o verificador com as dependências informadas
+func NewHealthChecker(timeout time.Duration, probes ...HealthProbe) *HealthChecker {
+	return &HealthChecker{probes: probes, timeout: timeout}
+}
+
+// Check verifica todas as dependências e aguarda no máximo o timeout configurado
+func (h *HealthChecker) Check(ctx context.Context) HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	statuses := make([]DependencyStatus, len(h.probes))
+	var wg sync.WaitGroup
+	for i, probe := range h.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = runProbe(ctx, probe)
+		}()
+	}
+	wg.Wait()
+
+	report := HealthReport{Dependencies: make(map[string]DependencyStatus, len(h.probes))}
+	for i, probe := range h.probes {
+		status := statuses[i]
+		report.Dependencies[probe.Name] = status
+		if status.Status == DependencyDown {
+			report.Degraded = true
+			report.CriticalDown = report.CriticalDown || probe.Critical
+		}
+	}
+	return report
+}
+
+// runProbe executa a verificação, considerando fora do ar a dependência que não responde
+// até o fim do prazo do contexto
+func runProbe(ctx context.Context, probe HealthProbe) DependencyStatus {
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() { result <- probe.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("sem resposta em tempo hábil: %w", ctx.Err())
+	}
+
+	status := DependencyStatus{
+		Status:    DependencyUp,
+		Critical:  probe.Critical,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = DependencyDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// HTTPHealthCheck retorna uma verificação que considera a dependência no ar quando a URL
+// responde 200 a um GET (ex.: o /health do nó da blockchain)
+func HTTPHealthCheck(url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheckerReportsEachDependency(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer node.Close()
+
+	checker := NewHealthChecker(time.Second,
+		HealthProbe{Name: "database", Critical: true, Check: func(context.Context) error { return nil }},
+		HealthProbe{Name: "blockchain_node", Check: HTTPHealthCheck(node.URL + "/health")},
+	)
+
+	report := checker.Check(context.Background())
+	require.Len(t, report.Dependencies, 2)
+	assert.Equal(t, DependencyUp, report.Dependencies["database"].Status)
+	assert.True(t, report.Dependencies["database"].Critical)
+	assert.Equal(t, DependencyDown, report.Dependencies["blockchain_node"].Status)
+	assert.Contains(t, report.Dependencies["blockchain_node"].Error, "503")
+	assert.True(t, report.Degraded)
+	assert.False(t, report.CriticalDown, "Apenas dependências críticas devem derrubar a prontidão")
+}
+
+func TestHealthCheckerTimesOutUnresponsiveDependency(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	checker := NewHealthChecker(50*time.Millisecond,
+		HealthProbe{Name: "database", Critical: true, Check: func(context.Context) error {
+			<-block // Ignora o contexto, como um driver travado
+			return nil
+		}},
+		HealthProbe{Name: "ipfs", Check: func(context.Context) error { return errors.New("conexão recusada") }},
+	)
+
+	start := time.Now()
+	report := checker.Check(context.Background())
+	assert.Less(t, time.Since(start), time.Second, "A verificação deve respeitar o timeout")
+	assert.Equal(t, DependencyDown, report.Dependencies["database"].Status)
+	assert.Contains(t, report.Dependencies["database"].Error, "sem resposta")
+	assert.Equal(t, "conexão recusada", report.Dependencies["ipfs"].Error)
+	assert.True(t, report.CriticalDown)
+}
@@ -0,0 +1,77 @@
+package services
+
+import "trackable-donations/api/internal/models"
+
+// Bases possíveis de uma projeção de impacto
+const (
+	ProjectionBasisRecurring  = "recurring"
+	ProjectionBasisHistorical = "historical"
+	ProjectionBasisNone       = "none"
+)
+
+// Fatores de impacto simulados usados pelo dashboard do doador e pela projeção. Na
+// projeção, refeições só contam para ONGs de alimentação e medicamentos só para ONGs de saúde
+const (
+	moneyPerPersonHelped = 50.0 // 1 pessoa ajudada a cada R$ 50
+	moneyPerMeal         = 10.0 // 1 refeição a cada R$ 10
+	moneyPerMedicine     = 30.0 // 1 medicamento a cada R$ 30
+)
+
+// daysPerYear é o horizonte da projeção, em dias
+const daysPerYear = 365.0
+
+// ProjectDonorImpact projeta o total doado e o impacto dos próximos 12 meses. Usa as
+// doações recorrentes ativas do doador; sem elas, usa a média mensal dos últimos 12 meses.
+func (s *DonationService) ProjectDonorImpact(donorID uint) (models.ProjectedImpact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.findUser(donorID); err != nil {
+		return models.ProjectedImpact{}, err
+	}
+
+	projection := models.ProjectedImpact{DonorID: donorID, Basis: ProjectionBasisNone}
+	annualByNGO := make(map[uint]float64)
+
+	for _, recurring := range s.recurringDonations {
+		if recurring.DonorID != donorID || recurring.Status != models.RecurringActive {
+			continue
+		}
+		// Em ponto flutuante: intervalos que não dividem 365 (ou maiores que um ano)
+		// ainda contam a fração de cobranças esperada no período
+		cyclesPerYear := daysPerYear / float64(recurring.IntervalDays)
+		annualByNGO[recurring.NGOID] += recurring.Amount * cyclesPerYear
+		projection.Basis = ProjectionBasisRecurring
+	}
+
+	if projection.Basis == ProjectionBasisNone {
+		since := s.clock.Now().AddDate(-1, 0, 0)
+		for _, donation := range s.donations {
+			if donation.DonorID == donorID && donation.Status == "completed" && donation.CreatedAt.After(since) {
+				annualByNGO[donation.NGOID] += donation.Amount
+				projection.Basis = ProjectionBasisHistorical
+			}
+		}
+	}
+
+	var mealsAmount, medicinesAmount float64
+	for ngoID, amount := range annualByNGO {
+		projection.ProjectedAnnualTotal += amount
+
+		ngo, _ := s.findNGO(ngoID)
+		switch ngo.Category {
+		case "Alimentação":
+			mealsAmount += amount
+		case "Saúde":
+			medicinesAmount += amount
+		}
+	}
+
+	projection.ProjectedAnnualTotal = roundTwoDecimals(projection.ProjectedAnnualTotal)
+	projection.MonthlyAverage = roundTwoDecimals(projection.ProjectedAnnualTotal / 12)
+	projection.PeopleHelped = int(projection.ProjectedAnnualTotal / moneyPerPersonHelped)
+	projection.MealsProvided = int(mealsAmount / moneyPerMeal)
+	projection.MedicinesProvided = int(medicinesAmount / moneyPerMedicine)
+
+	return projection, nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDonorImpactFromMonthlySubscription(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	_, err := donationSvc.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 50, DonorID: 1, NGOID: 1, IntervalDays: 30})
+	require.NoError(t, err)
+
+	// Assinaturas pausadas não entram na projeção
+	paused, err := donationSvc.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 500, DonorID: 1, NGOID: 2, IntervalDays: 30})
+	require.NoError(t, err)
+	_, err = donationSvc.PauseRecurringDonation(paused.ID)
+	require.NoError(t, err)
+
+	projection, err := donationSvc.ProjectDonorImpact(1)
+	require.NoError(t, err)
+	assert.Equal(t, ProjectionBasisRecurring, projection.Basis)
+	assert.Equal(t, 608.33, projection.ProjectedAnnualTotal, "365/30 cobranças de R$ 50 no ano")
+	assert.Equal(t, 50.69, projection.MonthlyAverage)
+	assert.Equal(t, 60, projection.MealsProvided, "ONG de alimentação: 1 refeição a cada R$ 10")
+	assert.Equal(t, 0, projection.MedicinesProvided)
+	assert.Equal(t, 12, projection.PeopleHelped)
+}
+
+func TestProjectDonorImpactCountsPartialCycles(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	// Intervalos maiores que um ano não podem zerar a projeção
+	_, err := donationSvc.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 730, DonorID: 1, NGOID: 1, IntervalDays: 730})
+	require.NoError(t, err)
+	projection, err := donationSvc.ProjectDonorImpact(1)
+	require.NoError(t, err)
+	assert.Equal(t, 365.0, projection.ProjectedAnnualTotal, "meia cobrança no ano")
+
+	// 365/7 cobranças semanais, e não 52
+	_, err = donationSvc.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 7, DonorID: 2, NGOID: 1, IntervalDays: 7})
+	require.NoError(t, err)
+	projection, err = donationSvc.ProjectDonorImpact(2)
+	require.NoError(t, err)
+	assert.Equal(t, 365.0, projection.ProjectedAnnualTotal)
+}
+
+func TestProjectDonorImpactWithoutSubscriptions(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	projection, err := donationSvc.ProjectDonorImpact(2)
+	require.NoError(t, err)
+	assert.Equal(t, ProjectionBasisNone, projection.Basis)
+	assert.Zero(t, projection.ProjectedAnnualTotal)
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 120, DonorID: 2, NGOID: 2})
+	projection, err = donationSvc.ProjectDonorImpact(2)
+	require.NoError(t, err)
+	assert.Equal(t, ProjectionBasisHistorical, projection.Basis)
+	assert.Equal(t, 120.0, projection.ProjectedAnnualTotal)
+	assert.Equal(t, 4, projection.MedicinesProvided)
+
+	_, err = donationSvc.ProjectDonorImpact(999)
+	assert.Error(t, err)
+}
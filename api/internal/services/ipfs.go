@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IPFSClient armazena e consulta conteúdos no IPFS (comprovantes e documentos)
+type IPFSClient interface {
+	// Add envia o conteúdo e retorna o CID gerado
+	Add(content []byte) (string, error)
+	// Exists indica se o CID está disponível
+	Exists(cid string) (bool, error)
+	// Cat retorna o conteúdo armazenado sob o CID
+	Cat(cid string) ([]byte, error)
+}
+
+// ErrIPFSContentNotFound indica um CID cujo conteúdo não está disponível
+var ErrIPFSContentNotFound = errors.New("conteúdo não encontrado no IPFS")
+
+// MemoryIPFSClient mantém os conteúdos em memória (útil em desenvolvimento e testes)
+type MemoryIPFSClient struct {
+	mu       sync.RWMutex
+	contents map[string][]byte
+}
+
+// NewMemoryIPFSClient cria um cliente IPFS em memória vazio
+func NewMemoryIPFSClient() *MemoryIPFSClient {
+	return &MemoryIPFSClient{contents: map[string][]byte{}}
+}
+
+// Add armazena o conteúdo; assim como no IPFS, o mesmo conteúdo gera sempre o mesmo CID
+func (c *MemoryIPFSClient) Add(content []byte) (string, error) {
+	sum := sha256.Sum256(content)
+	cid := "Qm" + hex.EncodeToString(sum[:])[:44]
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.contents[cid] = append([]byte(nil), content...)
+	return cid, nil
+}
+
+// Exists indica se o CID foi armazenado por este cliente
+func (c *MemoryIPFSClient) Exists(cid string) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.contents[cid]
+	return ok, nil
+}
+
+// Cat retorna uma cópia do conteúdo armazenado sob o CID
+func (c *MemoryIPFSClient) Cat(cid string) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	content, ok := c.contents[cid]
+	if !ok {
+		return nil, ErrIPFSContentNotFound
+	}
+	return append([]byte(nil), content...), nil
+}
+
+// HTTPIPFSClient usa a API HTTP de um nó IPFS (ex.: Kubo em http://localhost:5001)
+type HTTPIPFSClient struct {
+	apiURL string
+	client *http.Client
+}
+
+// NewHTTPIPFSClient cria um cliente para a API HTTP do nó IPFS informado
+func NewHTTPIPFSClient(apiURL string) *HTTPIPFSClient {
+	return &HTTPIPFSClient{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Add envia o conteúdo ao nó, que o fixa (pin) para que não seja descartado
+func (c *HTTPIPFSClient) Add(content []byte) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "file")
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(content); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	resp, err := c.client.Post(c.apiURL+"/api/v0/add?pin=true", writer.FormDataContentType(), &body)
+	if err != nil {
+		return "", fmt.Errorf("falha ao enviar conteúdo ao IPFS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", ipfsAPIError(resp)
+	}
+
+	var added struct {
+		Hash string `json:"Hash"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&added); err != nil {
+		return "", fmt.Errorf("resposta inválida do IPFS: %w", err)
+	}
+	if added.Hash == "" {
+		return "", errors.New("resposta do IPFS sem CID")
+	}
+	return added.Hash, nil
+}
+
+// Exists verifica se o CID está fixado no nó, o que vale para tudo enviado por Add
+func (c *HTTPIPFSClient) Exists(cid string) (bool, error) {
+	if cid == "" {
+		return false, nil
+	}
+
+	resp, err := c.client.Post(c.apiURL+"/api/v0/pin/ls?arg="+url.QueryEscape(cid), "", nil)
+	if err != nil {
+		return false, fmt.Errorf("falha ao consultar o IPFS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusInternalServerError:
+		// A API responde 500 quando o CID é inválido ou não está fixado no nó
+		return false, nil
+	default:
+		return false, ipfsAPIError(resp)
+	}
+}
+
+// Cat baixa o conteúdo do CID pelo nó
+func (c *HTTPIPFSClient) Cat(cid string) ([]byte, error) {
+	if cid == "" {
+		return nil, ErrIPFSContentNotFound
+	}
+
+	resp, err := c.client.Post(c.apiURL+"/api/v0/cat?arg="+url.QueryEscape(cid), "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar o IPFS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ipfsAPIError(resp)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler conteúdo do IPFS: %w", err)
+	}
+	return content, nil
+}
+
+// Ping verifica se a API do nó responde, consultando a versão do IPFS
+func (c *HTTPIPFSClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"/api/v0/version", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("falha ao consultar o IPFS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ipfsAPIError(resp)
+	}
+	return nil
+}
+
+// ipfsAPIError converte uma resposta de erro da API do IPFS em error
+func ipfsAPIError(resp *http.Response) error {
+	var apiErr struct {
+		Message string `json:"Message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Message != "" {
+		return fmt.Errorf("erro da API do IPFS (%d): %s", resp.StatusCode, apiErr.Message)
+	}
+	return fmt.Errorf("erro da API do IPFS: status %d", resp.StatusCode)
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPIPFSClientAddAndExists(t *testing.T) {
+	pinned := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/add":
+			file, _, err := r.FormFile("file")
+			require.NoError(t, err)
+			content, _ := io.ReadAll(file)
+			assert.Equal(t, "nota fiscal", string(content))
+			pinned["QmNota"] = true
+			json.NewEncoder(w).Encode(map[string]string{"Hash": "QmNota"})
+		case "/api/v0/pin/ls":
+			if !pinned[r.URL.Query().Get("arg")] {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"Message": "not pinned", "Type": "error"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"Keys": map[string]any{}})
+		case "/api/v0/cat":
+			assert.Equal(t, "QmNota", r.URL.Query().Get("arg"))
+			w.Write([]byte("nota fiscal"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPIPFSClient(server.URL + "/")
+
+	exists, err := client.Exists("QmNota")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	cid, err := client.Add([]byte("nota fiscal"))
+	require.NoError(t, err)
+	assert.Equal(t, "QmNota", cid)
+
+	exists, err = client.Exists(cid)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	content, err := client.Cat(cid)
+	require.NoError(t, err)
+	assert.Equal(t, "nota fiscal", string(content))
+}
+
+func TestHTTPIPFSClientReportsUnavailableNode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewHTTPIPFSClient(server.URL)
+	_, err := client.Add([]byte("nota fiscal"))
+	assert.Error(t, err)
+	_, err = client.Exists("QmNota")
+	assert.Error(t, err)
+	_, err = client.Cat("QmNota")
+	assert.Error(t, err)
+}
+
+func TestAuditChecksIPFSContentExists(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+	ipfs := NewMemoryIPFSClient()
+	donationSvc.SetIPFSClient(ipfs)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	receipt := donationSvc.receipts[0]
+	exists, err := ipfs.Exists(receipt.IPFSHash)
+	require.NoError(t, err)
+	assert.True(t, exists, "O comprovante deve ser enviado ao IPFS na confirmação")
+
+	expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 40, Description: "Alimentos", Category: "Alimentação"})
+	require.NoError(t, err)
+	uploaded, err := expenseSvc.UploadReceipt(context.Background(), expense.ID, []byte("nota fiscal"))
+	require.NoError(t, err)
+
+	result, err := adminSvc.AuditEntity(models.AuditRequest{EntityType: "expense", EntityID: expense.ID}, 1)
+	require.NoError(t, err)
+	assert.True(t, result.IPFSValid)
+	assert.Equal(t, uploaded.ReceiptIPFS, result.IPFSRef)
+
+	// Uma referência com formato válido, mas sem conteúdo no IPFS, é rejeitada
+	expenseSvc.expenses[0].ReceiptIPFS = "Qm" + receipt.IPFSHash[2:45] + "x"
+	result, err = adminSvc.AuditEntity(models.AuditRequest{EntityType: "expense", EntityID: expense.ID}, 1)
+	require.NoError(t, err)
+	assert.False(t, result.IPFSValid)
+}
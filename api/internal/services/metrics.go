@@ -0,0 +1,23 @@
+package services
+
+import "trackable-donations/api/internal/metrics"
+
+// MetricsInventory retorna as quantidades de ONGs, doações (também por status) e gastos em
+// memória, expostas em /metrics
+func (s *ExpenseService) MetricsInventory() metrics.Inventory {
+	s.donationSvc.mu.RLock()
+	inventory := metrics.Inventory{
+		NGOs:              len(s.donationSvc.ngos),
+		Donations:         len(s.donationSvc.donations),
+		DonationsByStatus: make(map[string]int),
+	}
+	for _, donation := range s.donationSvc.donations {
+		inventory.DonationsByStatus[donation.Status]++
+	}
+	s.donationSvc.mu.RUnlock()
+
+	s.mu.RLock()
+	inventory.Expenses = len(s.expenses)
+	s.mu.RUnlock()
+	return inventory
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/utils"
+)
+
+// NGOAPIKeyHeader é o header com a chave de API da ONG exigida no envio de gastos e comprovantes
+const NGOAPIKeyHeader = "X-NGO-Key"
+
+var (
+	// ErrNGOAPIKeyMissing indica uma requisição sem a chave de API da ONG
+	ErrNGOAPIKeyMissing = errors.New("chave de API da ONG não informada no header " + NGOAPIKeyHeader)
+	// ErrNGOAPIKeyInvalid indica uma chave que não pertence à ONG do gasto
+	ErrNGOAPIKeyInvalid = errors.New("chave de API não pertence à ONG do gasto")
+)
+
+// newNGOAPIKey gera uma nova chave de API de ONG, retornando a chave e o hash que é armazenado
+func newNGOAPIKey() (string, string) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		// Sem fonte de aleatoriedade do sistema não há como gerar uma chave segura
+		panic(err)
+	}
+	key := "ngo_" + hex.EncodeToString(b)
+	return key, utils.HashSensitiveData(key, false)
+}
+
+// AuthenticateNGOKey verifica se a chave de API pertence à ONG informada. ONGs inexistentes ou
+// sem chave emitida são tratadas como chave inválida, sem revelar qual dos casos ocorreu.
+func (s *DonationService) AuthenticateNGOKey(ngoID uint, key string) error {
+	if key == "" {
+		return ErrNGOAPIKeyMissing
+	}
+
+	s.mu.RLock()
+	ngo, err := s.findNGO(ngoID)
+	s.mu.RUnlock()
+	if err != nil || ngo.APIKeyHash == "" {
+		return ErrNGOAPIKeyInvalid
+	}
+
+	hash := utils.HashSensitiveData(key, false)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(ngo.APIKeyHash)) != 1 {
+		return ErrNGOAPIKeyInvalid
+	}
+	return nil
+}
+
+// AuthenticateNGOKey verifica se a chave de API pertence à ONG que registra o gasto
+func (s *ExpenseService) AuthenticateNGOKey(ngoID uint, key string) error {
+	return s.donationSvc.AuthenticateNGOKey(ngoID, key)
+}
+
+// AuthenticateExpenseNGOKey verifica se a chave de API pertence à ONG dona do gasto
+func (s *ExpenseService) AuthenticateExpenseNGOKey(expenseID uint, key string) error {
+	if key == "" {
+		return ErrNGOAPIKeyMissing
+	}
+
+	s.mu.RLock()
+	ngoID, found := uint(0), false
+	for _, expense := range s.expenses {
+		if expense.ID == expenseID {
+			ngoID, found = expense.NGOID, true
+			break
+		}
+	}
+	s.mu.RUnlock()
+	if !found {
+		return errors.New("gasto não encontrado")
+	}
+
+	return s.donationSvc.AuthenticateNGOKey(ngoID, key)
+}
+
+// IssueNGOAPIKey emite uma nova chave de API para a ONG, invalidando a anterior. A chave é
+// retornada apenas aqui; somente o hash fica armazenado.
+func (s *AdminService) IssueNGOAPIKey(ngoID uint, adminID uint) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.donationService.mu.Lock()
+	index := -1
+	for i, ngo := range s.donationService.ngos {
+		if ngo.ID == ngoID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		s.donationService.mu.Unlock()
+		return "", ErrNGONotFound
+	}
+
+	ngo := s.donationService.ngos[index]
+	if ngo.Status == models.NGOMerged {
+		s.donationService.mu.Unlock()
+		return "", ErrNGOMerged
+	}
+
+	key, hash := newNGOAPIKey()
+	rotated := ngo.APIKeyHash != ""
+	ngo.APIKeyHash = hash
+	ngo.UpdatedAt = s.clock.Now()
+	if err := s.donationService.store.NGOs.Save(&ngo); err != nil {
+		s.donationService.mu.Unlock()
+		return "", fmt.Errorf("falha ao salvar a ONG: %w", err)
+	}
+	s.donationService.replaceNGO(index, ngo)
+	s.donationService.mu.Unlock()
+
+	// Manter a cópia do serviço de administração em sincronia
+	for i := range s.ngos {
+		if s.ngos[i].ID == ngoID {
+			s.ngos[i] = ngo
+		}
+	}
+
+	comments := "Chave de API emitida"
+	if rotated {
+		comments = "Chave de API substituída; a anterior deixou de valer"
+	}
+	s.logAuditAction(adminID, models.AuditActionNGOAPIKeyIssued, "ngo", ngoID, "", "", comments)
+	return key, nil
+}
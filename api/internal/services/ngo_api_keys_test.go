@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovedNGOReceivesHashedAPIKey(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	registration := registerWithCallback(t, adminSvc, "11.222.333/0001-81", "")
+	_, err := adminSvc.ValidateCNPJOnline(registration.ID)
+	require.NoError(t, err)
+	_, err = adminSvc.UploadNGODocuments(context.Background(), registration.ID, []byte("estatuto"))
+	require.NoError(t, err)
+	ngo, key, err := adminSvc.ApproveNGO(registration.ID, 1, "")
+	require.NoError(t, err)
+
+	require.True(t, strings.HasPrefix(key, "ngo_"))
+	assert.NotEmpty(t, ngo.APIKeyHash)
+	assert.NotContains(t, ngo.APIKeyHash, strings.TrimPrefix(key, "ngo_"))
+
+	assert.NoError(t, donationSvc.AuthenticateNGOKey(ngo.ID, key))
+	assert.ErrorIs(t, donationSvc.AuthenticateNGOKey(ngo.ID, ""), ErrNGOAPIKeyMissing)
+	assert.ErrorIs(t, donationSvc.AuthenticateNGOKey(ngo.ID, key+"x"), ErrNGOAPIKeyInvalid)
+	// ONGs sem chave emitida e inexistentes não aceitam nenhuma chave
+	assert.ErrorIs(t, donationSvc.AuthenticateNGOKey(1, key), ErrNGOAPIKeyInvalid)
+	assert.ErrorIs(t, donationSvc.AuthenticateNGOKey(9999, key), ErrNGOAPIKeyInvalid)
+}
+
+func TestIssueNGOAPIKeyRotatesKey(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+
+	first, err := adminSvc.IssueNGOAPIKey(1, 7)
+	require.NoError(t, err)
+	second, err := adminSvc.IssueNGOAPIKey(1, 7)
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+
+	assert.ErrorIs(t, expenseSvc.AuthenticateNGOKey(1, first), ErrNGOAPIKeyInvalid)
+	assert.NoError(t, expenseSvc.AuthenticateNGOKey(1, second))
+
+	_, err = adminSvc.IssueNGOAPIKey(9999, 7)
+	assert.ErrorIs(t, err, ErrNGONotFound)
+
+	logs := adminSvc.GetAuditLogs()
+	require.NotEmpty(t, logs)
+	last := logs[len(logs)-1]
+	assert.Equal(t, models.AuditActionNGOAPIKeyIssued, last.Action)
+	assert.Equal(t, uint(1), last.EntityID)
+	assert.NotContains(t, last.Comments, second)
+}
+
+func TestExpenseNGOKeyMustMatchExpenseOwner(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+
+	ownKey, err := adminSvc.IssueNGOAPIKey(1, 7)
+	require.NoError(t, err)
+	otherKey, err := adminSvc.IssueNGOAPIKey(2, 7)
+	require.NoError(t, err)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 10, Description: "Cestas", Category: "Alimentação"})
+	require.NoError(t, err)
+
+	assert.NoError(t, expenseSvc.AuthenticateExpenseNGOKey(expense.ID, ownKey))
+	assert.ErrorIs(t, expenseSvc.AuthenticateExpenseNGOKey(expense.ID, otherKey), ErrNGOAPIKeyInvalid)
+	assert.ErrorIs(t, expenseSvc.AuthenticateExpenseNGOKey(expense.ID, ""), ErrNGOAPIKeyMissing)
+	assert.Error(t, expenseSvc.AuthenticateExpenseNGOKey(9999, ownKey))
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/retry"
+)
+
+// Headers das requisições com os eventos de status enviados às ONGs
+const (
+	// NGOWebhookSignatureHeader traz "sha256=" seguido do HMAC-SHA256 (hex) do corpo, calculado
+	// com o segredo informado na criação do registro
+	NGOWebhookSignatureHeader = "X-Webhook-Signature"
+	// NGOWebhookEventHeader traz o tipo do evento (ex.: ngo_registration.approved)
+	NGOWebhookEventHeader = "X-Webhook-Event"
+)
+
+// DefaultWebhookTimeout é o tempo máximo de cada tentativa de entrega de um evento
+const DefaultWebhookTimeout = 10 * time.Second
+
+// webhookDeliveryDeadline é o prazo para a entrega de um evento, somando todas as tentativas
+const webhookDeliveryDeadline = time.Minute
+
+// ErrInvalidCallbackURL indica uma URL de callback que não é http(s) absoluta
+var ErrInvalidCallbackURL = errors.New("callback_url deve ser uma URL http ou https")
+
+// WebhookSender entrega os eventos de status às URLs de callback das ONGs
+type WebhookSender interface {
+	// SendNGOEvent envia o corpo JSON do evento com a assinatura; um erro indica que a ONG
+	// não confirmou o recebimento
+	SendNGOEvent(callbackURL, eventType string, body []byte, signature string) error
+}
+
+// HTTPWebhookSender entrega os eventos por POST, considerando entregues as respostas 2xx
+type HTTPWebhookSender struct {
+	client *http.Client
+}
+
+// NewHTTPWebhookSender cria o entregador de eventos com o tempo máximo por tentativa
+func NewHTTPWebhookSender(timeout time.Duration) *HTTPWebhookSender {
+	return &HTTPWebhookSender{client: &http.Client{Timeout: timeout}}
+}
+
+// SendNGOEvent envia o evento à URL de callback
+func (s *HTTPWebhookSender) SendNGOEvent(callbackURL, eventType string, body []byte, signature string) error {
+	req, err := http.NewRequest(http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(NGOWebhookEventHeader, eventType)
+	req.Header.Set(NGOWebhookSignatureHeader, signature)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("callback respondeu com status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SetWebhookSender define como os eventos de status são entregues às ONGs
+func (s *AdminService) SetWebhookSender(sender WebhookSender) {
+	s.webhookMu.Lock()
+	defer s.webhookMu.Unlock()
+	s.webhookSender = sender
+}
+
+// validateCallbackURL aceita apenas URLs http(s) absolutas
+func validateCallbackURL(callbackURL string) error {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidCallbackURL
+	}
+	return nil
+}
+
+// newWebhookSecret gera o segredo aleatório que assina os eventos de um registro
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		// Sem fonte de aleatoriedade do sistema não há como gerar um segredo seguro
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// notifyStatusChange envia em segundo plano o evento da mudança de status à URL de callback
+// do registro, quando houver, tentando novamente em caso de falha até webhookDeliveryDeadline
+// ou até o Close do serviço. Cada entrega fica registrada (ver GetWebhookDeliveries). Deve ser
+// chamado com s.mu bloqueado.
+func (s *AdminService) notifyStatusChange(registration models.NGORegistration, eventType string, ngoID uint, comments string) {
+	if registration.CallbackURL == "" {
+		return
+	}
+
+	event := models.NGOStatusEvent{
+		ID:             "evt_" + generateMockHash(24),
+		Type:           eventType,
+		RegistrationID: registration.ID,
+		NGOID:          ngoID,
+		Status:         registration.Status,
+		Comments:       comments,
+		OccurredAt:     s.clock.Now(),
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Erro ao gerar o evento %s do registro %d: %v", eventType, registration.ID, err)
+		return
+	}
+	signature := "sha256=" + hex.EncodeToString(SignWebhookPayload([]byte(registration.WebhookSecret), body))
+
+	s.webhookMu.Lock()
+	sender := s.webhookSender
+	s.webhookMu.Unlock()
+
+	s.webhooks.Add(1)
+	go func() {
+		defer s.webhooks.Done()
+		ctx, cancel := context.WithTimeout(s.webhookCtx, webhookDeliveryDeadline)
+		defer cancel()
+
+		delivery := models.WebhookDelivery{
+			EventID:        event.ID,
+			EventType:      eventType,
+			RegistrationID: registration.ID,
+			URL:            registration.CallbackURL,
+			CreatedAt:      event.OccurredAt,
+		}
+		err := retry.DoContext(ctx, func() error {
+			delivery.Attempts++
+			return sender.SendNGOEvent(registration.CallbackURL, eventType, body, signature)
+		}, externalCallAttempts, externalCallBaseDelay)
+		delivery.Delivered = err == nil
+		if err != nil {
+			delivery.Error = err.Error()
+			log.Printf("Erro ao entregar o evento %s do registro %d: %v", eventType, registration.ID, err)
+		}
+		s.recordWebhookDelivery(delivery)
+	}()
+}
+
+// recordWebhookDelivery registra o resultado da entrega de um evento
+func (s *AdminService) recordWebhookDelivery(delivery models.WebhookDelivery) {
+	s.webhookMu.Lock()
+	defer s.webhookMu.Unlock()
+
+	if err := s.donationService.store.WebhookDeliveries.Create(&delivery); err != nil {
+		log.Printf("Erro ao salvar a entrega do evento %s: %v", delivery.EventID, err)
+	}
+	s.webhookDeliveries = append(s.webhookDeliveries, delivery)
+}
+
+// GetWebhookDeliveries retorna as entregas de eventos de um registro de ONG, da mais antiga
+// para a mais recente
+func (s *AdminService) GetWebhookDeliveries(registrationID uint) ([]models.WebhookDelivery, error) {
+	if _, err := s.GetNGORegistrationByID(registrationID); err != nil {
+		return nil, err
+	}
+
+	s.webhookMu.Lock()
+	defer s.webhookMu.Unlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for _, delivery := range s.webhookDeliveries {
+		if delivery.RegistrationID == registrationID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+// Close interrompe as novas tentativas das entregas de eventos em andamento e aguarda o seu
+// término, permitindo encerrar o serviço junto com os demais componentes no desligamento.
+// As entregas interrompidas ficam registradas como não entregues.
+func (s *AdminService) Close() error {
+	s.cancelWebhooks()
+	s.webhooks.Wait()
+	return nil
+}
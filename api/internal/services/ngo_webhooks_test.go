@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturingWebhookSender guarda os eventos entregues, falhando nas primeiras tentativas
+type capturingWebhookSender struct {
+	mu         sync.Mutex
+	failures   int
+	attempts   int
+	events     []models.NGOStatusEvent
+	signatures []string
+}
+
+func (s *capturingWebhookSender) SendNGOEvent(callbackURL, eventType string, body []byte, signature string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.attempts <= s.failures {
+		return errors.New("callback indisponível")
+	}
+
+	var event models.NGOStatusEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return err
+	}
+	s.events = append(s.events, event)
+	s.signatures = append(s.signatures, signature)
+	return nil
+}
+
+func registerWithCallback(t *testing.T, adminSvc *AdminService, cnpj, callbackURL string) models.NGORegistration {
+	t.Helper()
+	registration, err := adminSvc.RegisterNGO(models.NGORegistrationRequest{
+		Name: "Sertão Vivo", Description: "Cisternas", Category: "Infraestrutura", CNPJ: cnpj,
+		Email: "contato@sertaovivo.org", Phone: "7133334444", Address: "Rua C", State: "BA", ResponsibleID: 1,
+		CallbackURL: callbackURL,
+	})
+	require.NoError(t, err)
+	return registration
+}
+
+func TestNGOStatusChangesAreDeliveredSigned(t *testing.T) {
+	shortRetryDelay(t)
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+	sender := &capturingWebhookSender{failures: 2}
+	adminSvc.SetWebhookSender(sender)
+
+	registration := registerWithCallback(t, adminSvc, "11.222.333/0001-81", "https://ong.example.org/eventos")
+	require.Len(t, registration.WebhookSecret, 64)
+	_, err := adminSvc.ValidateCNPJOnline(registration.ID)
+	require.NoError(t, err)
+	_, err = adminSvc.UploadNGODocuments(context.Background(), registration.ID, []byte("estatuto"))
+	require.NoError(t, err)
+	ngo, _, err := adminSvc.ApproveNGO(registration.ID, 1, "Documentação completa")
+	require.NoError(t, err)
+	// Aguardar as entregas em segundo plano
+	adminSvc.webhooks.Wait()
+
+	require.Len(t, sender.events, 1)
+	event := sender.events[0]
+	assert.Equal(t, models.NGOEventRegistrationApproved, event.Type)
+	assert.Equal(t, registration.ID, event.RegistrationID)
+	assert.Equal(t, ngo.ID, event.NGOID)
+	assert.Equal(t, models.NGOStatusApproved, event.Status)
+	assert.Equal(t, "Documentação completa", event.Comments)
+
+	// A assinatura é o HMAC do corpo com o segredo do registro
+	body, err := json.Marshal(event)
+	require.NoError(t, err)
+	expected := "sha256=" + hex.EncodeToString(SignWebhookPayload([]byte(registration.WebhookSecret), body))
+	assert.Equal(t, expected, sender.signatures[0])
+
+	deliveries, err := adminSvc.GetWebhookDeliveries(registration.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.True(t, deliveries[0].Delivered)
+	assert.Equal(t, 3, deliveries[0].Attempts)
+	assert.Equal(t, event.ID, deliveries[0].EventID)
+}
+
+func TestFailedNGOEventDeliveryIsRecorded(t *testing.T) {
+	shortRetryDelay(t)
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(NGOWebhookEventHeader))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	registration := registerWithCallback(t, adminSvc, "11.222.333/0001-81", server.URL)
+	_, err := adminSvc.RejectNGO(registration.ID, 1, "CNPJ irregular")
+	require.NoError(t, err)
+	// Aguardar as entregas em segundo plano
+	adminSvc.webhooks.Wait()
+
+	assert.Equal(t, []string{models.NGOEventRegistrationRejected, models.NGOEventRegistrationRejected, models.NGOEventRegistrationRejected}, received)
+	deliveries, err := adminSvc.GetWebhookDeliveries(registration.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.False(t, deliveries[0].Delivered)
+	assert.Equal(t, externalCallAttempts, deliveries[0].Attempts)
+	assert.Contains(t, deliveries[0].Error, "500")
+
+	// Sem URL de callback não há segredo nem eventos
+	plain := registerWithCallback(t, adminSvc, "11.444.777/0001-61", "")
+	assert.Empty(t, plain.WebhookSecret)
+
+	_, err = adminSvc.RegisterNGO(models.NGORegistrationRequest{CNPJ: "22.333.444/0001-81", State: "SP", Category: "Saúde", CallbackURL: "ftp://ong.example.org"})
+	assert.ErrorIs(t, err, ErrInvalidCallbackURL)
+}
+
+func TestReopenRejectedNGORegistrationSendsEvent(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+	sender := &capturingWebhookSender{}
+	adminSvc.SetWebhookSender(sender)
+
+	registration := registerWithCallback(t, adminSvc, "11.222.333/0001-81", "https://ong.example.org/eventos")
+	_, err := adminSvc.ReopenNGORegistration(registration.ID, 1, "Nova documentação")
+	assert.ErrorIs(t, err, ErrNGORegistrationNotRejected, "Apenas registros rejeitados podem ser reabertos")
+	_, err = adminSvc.ReopenNGORegistration(9999, 1, "Nova documentação")
+	assert.ErrorIs(t, err, ErrNGORegistrationNotFound)
+
+	_, err = adminSvc.RejectNGO(registration.ID, 1, "Estatuto ilegível")
+	require.NoError(t, err)
+	reopened, err := adminSvc.ReopenNGORegistration(registration.ID, 1, "Nova documentação")
+	require.NoError(t, err)
+	assert.Equal(t, models.NGOStatusPending, reopened.Status)
+	assert.Equal(t, "Nova documentação", reopened.AdminComments)
+	// Aguardar as entregas em segundo plano
+	adminSvc.webhooks.Wait()
+
+	// As entregas são concorrentes, então o evento é localizado pelo tipo
+	require.Len(t, sender.events, 2)
+	var event models.NGOStatusEvent
+	for _, sent := range sender.events {
+		if sent.Type == models.NGOEventRegistrationReopened {
+			event = sent
+		}
+	}
+	assert.Equal(t, registration.ID, event.RegistrationID)
+	assert.Equal(t, models.NGOStatusPending, event.Status)
+	assert.Equal(t, "Nova documentação", event.Comments)
+
+	logs := adminSvc.GetAuditLogsByEntityID("ngo_registration", registration.ID)
+	require.NotEmpty(t, logs)
+	last := logs[len(logs)-1]
+	assert.Equal(t, models.AuditActionNGOReopened, last.Action)
+	assert.Equal(t, string(models.NGOStatusRejected), last.PreviousState)
+	assert.Equal(t, string(models.NGOStatusPending), last.NewState)
+}
+
+func TestCloseStopsRetryingNGOEvents(t *testing.T) {
+	// Sem o cancelamento, a próxima tentativa só viria daqui a uma hora
+	previous := externalCallBaseDelay
+	externalCallBaseDelay = time.Hour
+	t.Cleanup(func() { externalCallBaseDelay = previous })
+
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+	sender := &capturingWebhookSender{failures: externalCallAttempts}
+	adminSvc.SetWebhookSender(sender)
+
+	registration := registerWithCallback(t, adminSvc, "11.222.333/0001-81", "https://ong.example.org/eventos")
+	_, err := adminSvc.RejectNGO(registration.ID, 1, "CNPJ irregular")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		sender.mu.Lock()
+		defer sender.mu.Unlock()
+		return sender.attempts == 1
+	}, time.Second, time.Millisecond)
+	closed := make(chan error)
+	go func() { closed <- adminSvc.Close() }()
+	select {
+	case err := <-closed:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close deve interromper as novas tentativas de entrega")
+	}
+
+	deliveries, err := adminSvc.GetWebhookDeliveries(registration.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.False(t, deliveries[0].Delivered)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Contains(t, deliveries[0].Error, context.Canceled.Error())
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"trackable-donations/api/internal/models"
+)
+
+// Notifier envia notificações aos doadores (e-mail, SMS, etc.)
+type Notifier interface {
+	SendPaymentReminder(reminder models.PaymentReminder) error
+	SendReceipt(receipt models.DonationReceipt) error
+}
+
+// LogNotifier apenas registra as notificações no log (útil em desenvolvimento)
+type LogNotifier struct{}
+
+// SendPaymentReminder registra o lembrete de pagamento no log
+func (LogNotifier) SendPaymentReminder(reminder models.PaymentReminder) error {
+	log.Printf("Lembrete de pagamento para %s (doação %d): %s",
+		reminder.DonorEmail, reminder.DonationID, reminder.PaymentURL)
+	return nil
+}
+
+// SendReceipt registra o envio do comprovante no log
+func (LogNotifier) SendReceipt(receipt models.DonationReceipt) error {
+	log.Printf("Comprovante da doação %d para %s: %s", receipt.DonationID, receipt.DonorEmail, receipt.PdfURL)
+	return nil
+}
+
+// SMTPConfig reúne os dados de acesso ao servidor SMTP
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // Vazio = sem autenticação
+	Password string
+	From     string // Ex.: "Levitate <nao-responda@levitate.org>"
+}
+
+// SMTPNotifier envia as notificações por e-mail através de um servidor SMTP
+type SMTPNotifier struct {
+	cfg SMTPConfig
+	// sendMail envia a mensagem (substituível nos testes)
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPNotifier cria um notificador que envia e-mails pelo servidor informado
+func NewSMTPNotifier(cfg SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg, sendMail: smtp.SendMail}
+}
+
+// SendPaymentReminder envia por e-mail o lembrete de pagamento
+func (n *SMTPNotifier) SendPaymentReminder(reminder models.PaymentReminder) error {
+	body := fmt.Sprintf("Olá!\n\nSua doação #%d ainda aguarda pagamento. Para concluí-la, acesse:\n%s\n",
+		reminder.DonationID, reminder.PaymentURL)
+	return n.send(reminder.DonorEmail, "Sua doação aguarda pagamento", body)
+}
+
+// SendReceipt envia por e-mail o comprovante da doação confirmada
+func (n *SMTPNotifier) SendReceipt(receipt models.DonationReceipt) error {
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "Olá, %s!\n\nRecebemos sua doação para %s. Obrigado!\n\n", receipt.DonorName, receipt.NGOName)
+	fmt.Fprintf(&body, "Doação: #%d\nData: %s\nValor: R$ %.2f\n", receipt.DonationID, receipt.Date.Format("02/01/2006"), receipt.Amount)
+	if receipt.Tip > 0 {
+		fmt.Fprintf(&body, "Contribuição para a plataforma: R$ %.2f\nTotal cobrado: R$ %.2f\n", receipt.Tip, receipt.TotalCharged)
+	}
+	fmt.Fprintf(&body, "Transação na blockchain: %s\n", receipt.TransactionHash)
+	if receipt.PdfURL != "" {
+		fmt.Fprintf(&body, "Comprovante: %s\n", receipt.PdfURL)
+	}
+	return n.send(receipt.DonorEmail, "Comprovante da sua doação", body.String())
+}
+
+// send monta a mensagem em texto simples (UTF-8) e a envia ao destinatário
+func (n *SMTPNotifier) send(to, subject, body string) error {
+	// ParseAddress também rejeita quebras de linha, evitando injeção de cabeçalhos
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("e-mail do destinatário inválido: %w", err)
+	}
+	if n.cfg.Host == "" {
+		return errors.New("servidor SMTP não configurado")
+	}
+	sender, err := mail.ParseAddress(n.cfg.From)
+	if err != nil {
+		return fmt.Errorf("remetente SMTP inválido: %w", err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", sender.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", recipient.Address)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(body)
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	return n.sendMail(addr, auth, sender.Address, []string{recipient.Address}, msg.Bytes())
+}
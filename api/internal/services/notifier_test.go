@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"net/smtp"
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptSentAfterPaymentConfirmation(t *testing.T) {
+	donationSvc := NewDonationService()
+	notifier := &capturingNotifier{}
+	donationSvc.SetNotifier(notifier)
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+
+	require.Len(t, notifier.receipts, 1)
+	assert.Equal(t, donationID, notifier.receipts[0].DonationID)
+	assert.Equal(t, "joao@example.com", notifier.receipts[0].DonorEmail)
+	assert.NotEmpty(t, notifier.receipts[0].TransactionHash)
+	assert.Equal(t, donationSvc.receipts[0], notifier.receipts[0])
+}
+
+func TestReceiptDeliveryFailureKeepsDonationConfirmed(t *testing.T) {
+	donationSvc := NewDonationService()
+	donationSvc.SetNotifier(&capturingNotifier{err: errors.New("servidor indisponível")})
+
+	created, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+
+	confirmed, err := donationSvc.MockPaymentConfirmation(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", confirmed.Status)
+	assert.Equal(t, "completed", donationSvc.donations[0].Status)
+	assert.Len(t, donationSvc.receipts, 1)
+}
+
+func TestSMTPNotifierSendsReceipt(t *testing.T) {
+	notifier := NewSMTPNotifier(SMTPConfig{
+		Host: "smtp.example.com", Port: 587, Username: "usuario", Password: "senha",
+		From: "Levitate <nao-responda@levitate.org>",
+	})
+
+	var addr, from string
+	var to []string
+	var msg []byte
+	notifier.sendMail = func(a string, auth smtp.Auth, f string, t []string, m []byte) error {
+		addr, from, to, msg = a, f, t, m
+		return nil
+	}
+
+	err := notifier.SendReceipt(models.DonationReceipt{
+		DonationID: 7, DonorName: "João Silva", DonorEmail: "joao@example.com", NGOName: "Saúde para Todos",
+		Amount: 50, TransactionHash: "0xabc", PdfURL: "https://ipfs.example.com/ipfs/QmNota",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.Equal(t, "nao-responda@levitate.org", from)
+	assert.Equal(t, []string{"joao@example.com"}, to)
+	assert.Contains(t, string(msg), "To: joao@example.com\r\n")
+	assert.Contains(t, string(msg), "Doação: #7")
+	assert.Contains(t, string(msg), "Valor: R$ 50.00")
+	assert.Contains(t, string(msg), "https://ipfs.example.com/ipfs/QmNota")
+}
+
+func TestSMTPNotifierRejectsHeaderInjection(t *testing.T) {
+	notifier := NewSMTPNotifier(SMTPConfig{Host: "smtp.example.com", Port: 587, From: "nao-responda@levitate.org"})
+	notifier.sendMail = func(string, smtp.Auth, string, []string, []byte) error {
+		t.Fatal("nenhum e-mail deveria ser enviado")
+		return nil
+	}
+
+	err := notifier.SendReceipt(models.DonationReceipt{DonorEmail: "joao@example.com\r\nBcc: todos@example.com"})
+	assert.Error(t, err)
+}
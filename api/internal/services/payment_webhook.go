@@ -0,0 +1,87 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"trackable-donations/api/internal/models"
+)
+
+// PaymentStatusPaid é o status do gateway que confirma o pagamento da doação
+const PaymentStatusPaid = "paid"
+
+var (
+	// ErrInvalidWebhookSignature indica um webhook sem assinatura ou com assinatura inválida
+	ErrInvalidWebhookSignature = errors.New("assinatura do webhook inválida")
+	// ErrInvalidWebhookPayload indica um corpo de webhook malformado
+	ErrInvalidWebhookPayload = errors.New("payload do webhook inválido")
+)
+
+// PaymentWebhookHandler recebe os webhooks do gateway de pagamento, autenticados por
+// HMAC-SHA256 do corpo da requisição com o segredo compartilhado
+type PaymentWebhookHandler struct {
+	secret          []byte
+	donationService *DonationService
+}
+
+// NewPaymentWebhookHandler cria o receptor de webhooks. Sem segredo, todos os webhooks
+// são rejeitados, pois qualquer um poderia calcular a assinatura.
+func NewPaymentWebhookHandler(donationSvc *DonationService, secret string) *PaymentWebhookHandler {
+	return &PaymentWebhookHandler{secret: []byte(secret), donationService: donationSvc}
+}
+
+// Handle valida a assinatura (hex, com ou sem o prefixo "sha256=") e, se o status for
+// "paid", confirma o pagamento. Retorna nil na resposta quando o status é apenas registrado.
+func (h *PaymentWebhookHandler) Handle(body []byte, signature string) (*models.DonationResponse, error) {
+	if !h.validSignature(body, signature) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var payload models.PaymentWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.DonationID == 0 || payload.Status == "" {
+		return nil, ErrInvalidWebhookPayload
+	}
+
+	if payload.Status != PaymentStatusPaid {
+		h.donationService.mu.RLock()
+		_, found := h.donationService.findDonation(payload.DonationID)
+		h.donationService.mu.RUnlock()
+		if !found {
+			return nil, fmt.Errorf("doação %d: %w", payload.DonationID, ErrDonationNotFound)
+		}
+		log.Printf("Webhook de pagamento: doação %d com status %q (ref. %s)", payload.DonationID, payload.Status, payload.GatewayRef)
+		return nil, nil
+	}
+
+	// A confirmação é idempotente, então webhooks repetidos não geram outro comprovante
+	response, err := h.donationService.MockPaymentConfirmation(payload.DonationID)
+	if err != nil {
+		return nil, fmt.Errorf("doação %d: %w", payload.DonationID, err)
+	}
+	log.Printf("Webhook de pagamento: doação %d paga (ref. %s)", payload.DonationID, payload.GatewayRef)
+	return &response, nil
+}
+
+// validSignature compara a assinatura recebida com o HMAC-SHA256 do corpo em tempo constante
+func (h *PaymentWebhookHandler) validSignature(body []byte, signature string) bool {
+	if len(h.secret) == 0 {
+		return false
+	}
+	received, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), "sha256="))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(received, SignWebhookPayload(h.secret, body))
+}
+
+// SignWebhookPayload calcula o HMAC-SHA256 do corpo do webhook
+func SignWebhookPayload(secret, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"testing"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedWebhook(secret, body string) string {
+	return "sha256=" + hex.EncodeToString(SignWebhookPayload([]byte(secret), []byte(body)))
+}
+
+func TestPaymentWebhookConfirmsOnceForDuplicates(t *testing.T) {
+	donationSvc := NewDonationService()
+	handler := NewPaymentWebhookHandler(donationSvc, "segredo")
+
+	created, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 30, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	body := fmt.Sprintf(`{"donation_id": %d, "status": "paid", "gateway_ref": "pay_1"}`, created.ID)
+
+	// Webhooks duplicados, inclusive simultâneos, não podem gerar dois comprovantes
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := handler.Handle([]byte(body), signedWebhook("segredo", body))
+			if err != nil {
+				assert.ErrorIs(t, err, ErrPaymentConfirmationInProgress)
+			}
+		}()
+	}
+	wg.Wait()
+
+	response, err := handler.Handle([]byte(body), signedWebhook("segredo", body))
+	require.NoError(t, err)
+	require.NotNil(t, response)
+	assert.Equal(t, "completed", response.Status)
+
+	receipts := 0
+	for _, receipt := range donationSvc.snapshotReceipts() {
+		if receipt.DonationID == created.ID {
+			receipts++
+		}
+	}
+	assert.Equal(t, 1, receipts)
+}
+
+func TestPaymentWebhookRejectsBadSignaturesAndUnknownDonations(t *testing.T) {
+	donationSvc := NewDonationService()
+	handler := NewPaymentWebhookHandler(donationSvc, "segredo")
+	body := `{"donation_id": 999, "status": "paid"}`
+
+	_, err := handler.Handle([]byte(body), signedWebhook("outro-segredo", body))
+	assert.ErrorIs(t, err, ErrInvalidWebhookSignature)
+	_, err = handler.Handle([]byte(body), "")
+	assert.ErrorIs(t, err, ErrInvalidWebhookSignature)
+	_, err = NewPaymentWebhookHandler(donationSvc, "").Handle([]byte(body), signedWebhook("", body))
+	assert.ErrorIs(t, err, ErrInvalidWebhookSignature, "Sem segredo configurado nenhum webhook é aceito")
+
+	_, err = handler.Handle([]byte(body), signedWebhook("segredo", body))
+	assert.ErrorIs(t, err, ErrDonationNotFound)
+
+	pendingBody := `{"donation_id": 999, "status": "failed"}`
+	_, err = handler.Handle([]byte(pendingBody), signedWebhook("segredo", pendingBody))
+	assert.ErrorIs(t, err, ErrDonationNotFound)
+
+	_, err = handler.Handle([]byte(`{"status": "paid"}`), signedWebhook("segredo", `{"status": "paid"}`))
+	assert.ErrorIs(t, err, ErrInvalidWebhookPayload)
+}
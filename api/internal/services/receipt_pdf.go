@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/utils"
+)
+
+// ReceiptPDF gera o comprovante da doação em PDF (A4)
+func ReceiptPDF(receipt models.DonationReceipt) ([]byte, error) {
+	doc := utils.NewPDFDocument()
+	doc.Title("Comprovante de Doação")
+	doc.Space()
+
+	doc.Text(fmt.Sprintf("Doação: #%d", receipt.DonationID))
+	doc.Text(fmt.Sprintf("Doador: %s", receipt.DonorName))
+	doc.Text(fmt.Sprintf("ONG: %s", receipt.NGOName))
+	doc.Text(fmt.Sprintf("Data: %s", receipt.Date.Format("02/01/2006 15:04")))
+	doc.Text(fmt.Sprintf("Valor doado: %s", utils.FormatBRL(receipt.Amount)))
+	if receipt.Tip > 0 {
+		doc.Text(fmt.Sprintf("Gorjeta: %s", utils.FormatBRL(receipt.Tip)))
+		doc.Text(fmt.Sprintf("Total cobrado: %s", utils.FormatBRL(receipt.TotalCharged)))
+	}
+	doc.Space()
+
+	// Cada hash em uma linha própria, para facilitar a conferência
+	for _, hash := range []struct{ label, value string }{
+		{"Transação na blockchain", receipt.TransactionHash},
+		{"Comprovante no IPFS", receipt.IPFSHash},
+	} {
+		value := hash.value
+		if value == "" {
+			value = "-"
+		}
+		doc.Heading(hash.label)
+		doc.Text(value)
+	}
+
+	pdf, err := doc.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gerar o PDF do comprovante: %w", err)
+	}
+	return pdf, nil
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptPDF(t *testing.T) {
+	pdf, err := ReceiptPDF(models.DonationReceipt{
+		DonationID:      1,
+		DonorName:       "João Silva",
+		NGOName:         "Educação é Futuro",
+		Amount:          1234.56,
+		TotalCharged:    1234.56,
+		Date:            time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC),
+		TransactionHash: "0x" + generateMockHash(64),
+		IPFSHash:        "Qm" + generateMockHash(44),
+	})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+	assert.True(t, bytes.Contains(pdf, []byte("/MediaBox [0 0 595.28 841.89]")), "O comprovante deve ser A4")
+	assert.True(t, bytes.Contains(pdf, []byte("Valor doado: R$ 1.234,56")), "Os valores seguem o padrão brasileiro")
+}
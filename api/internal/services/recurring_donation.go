@@ -0,0 +1,212 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"trackable-donations/api/internal/models"
+)
+
+// defaultRecurringIntervalDays é o intervalo padrão entre cobranças recorrentes
+const defaultRecurringIntervalDays = 30
+
+// CreateRecurringDonation cria uma assinatura de doação recorrente. A primeira
+// cobrança é gerada imediatamente e as seguintes a cada intervalo.
+func (s *DonationService) CreateRecurringDonation(req models.RecurringDonationRequest) (models.RecurringDonation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ngo, err := s.findNGO(req.NGOID)
+	if err != nil {
+		return models.RecurringDonation{}, err
+	}
+	switch ngo.Status {
+	case models.NGOSuspended:
+		return models.RecurringDonation{}, ErrNGOSuspended
+	case models.NGOMerged:
+		// As assinaturas devem ser feitas para a ONG canônica (ver NGO.MergedInto)
+		return models.RecurringDonation{}, ErrNGOMerged
+	}
+	if _, err := s.findUser(req.DonorID); err != nil {
+		return models.RecurringDonation{}, err
+	}
+
+	intervalDays := req.IntervalDays
+	if intervalDays <= 0 {
+		intervalDays = defaultRecurringIntervalDays
+	}
+
+	now := s.clock.Now()
+	// O ID vem do banco
+	recurring := models.RecurringDonation{
+		Amount:       req.Amount,
+		DonorID:      req.DonorID,
+		NGOID:        req.NGOID,
+		IntervalDays: intervalDays,
+		Status:       models.RecurringActive,
+		NextRunAt:    now,
+		CreatedAt:    now,
+	}
+	if err := s.store.RecurringDonations.Create(&recurring); err != nil {
+		return models.RecurringDonation{}, fmt.Errorf("falha ao salvar a doação recorrente: %w", err)
+	}
+	s.recurringDonations = append(s.recurringDonations, recurring)
+
+	return recurring, nil
+}
+
+// GetRecurringDonationByID busca uma doação recorrente pelo ID
+func (s *DonationService) GetRecurringDonationByID(id uint) (models.RecurringDonation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, recurring := range s.recurringDonations {
+		if recurring.ID == id {
+			return recurring, nil
+		}
+	}
+	return models.RecurringDonation{}, errors.New("doação recorrente não encontrada")
+}
+
+// PauseRecurringDonation suspende temporariamente as cobranças sem perder o agendamento
+func (s *DonationService) PauseRecurringDonation(id uint) (models.RecurringDonation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, recurring := range s.recurringDonations {
+		if recurring.ID != id {
+			continue
+		}
+		if recurring.Status == models.RecurringPaused {
+			return models.RecurringDonation{}, errors.New("doação recorrente já está pausada")
+		}
+		recurring.Status = models.RecurringPaused
+		if err := s.store.RecurringDonations.Save(&recurring); err != nil {
+			return models.RecurringDonation{}, fmt.Errorf("falha ao salvar a doação recorrente: %w", err)
+		}
+		s.recurringDonations[i] = recurring
+		return recurring, nil
+	}
+	return models.RecurringDonation{}, errors.New("doação recorrente não encontrada")
+}
+
+// ResumeRecurringDonation reativa uma doação recorrente pausada. Os ciclos perdidos
+// durante a pausa não são cobrados: a próxima cobrança é a próxima data do agendamento.
+func (s *DonationService) ResumeRecurringDonation(id uint) (models.RecurringDonation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, recurring := range s.recurringDonations {
+		if recurring.ID != id {
+			continue
+		}
+		if recurring.Status != models.RecurringPaused {
+			return models.RecurringDonation{}, errors.New("doação recorrente não está pausada")
+		}
+		recurring.Status = models.RecurringActive
+		recurring.NextRunAt = nextScheduledRun(recurring, s.clock.Now())
+		if err := s.store.RecurringDonations.Save(&recurring); err != nil {
+			return models.RecurringDonation{}, fmt.Errorf("falha ao salvar a doação recorrente: %w", err)
+		}
+		s.recurringDonations[i] = recurring
+		return recurring, nil
+	}
+	return models.RecurringDonation{}, errors.New("doação recorrente não encontrada")
+}
+
+// ProcessDueRecurringDonations gera as doações pendentes das assinaturas ativas
+// vencidas até o instante informado. Assinaturas pausadas são ignoradas.
+func (s *DonationService) ProcessDueRecurringDonations(now time.Time) []models.DonationResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	responses := []models.DonationResponse{}
+	for i, recurring := range s.recurringDonations {
+		if recurring.Status != models.RecurringActive || recurring.NextRunAt.After(now) {
+			continue
+		}
+
+		response, err := s.processDonation(models.DonationRequest{
+			Amount:  recurring.Amount,
+			DonorID: recurring.DonorID,
+			NGOID:   recurring.NGOID,
+		}, brlExchange)
+		if err != nil {
+			log.Printf("Erro ao processar doação recorrente %d: %v", recurring.ID, err)
+			continue
+		}
+
+		// Uma única cobrança por execução, mesmo que o job tenha ficado parado por vários ciclos
+		s.recurringDonations[i].NextRunAt = nextScheduledRun(recurring, now)
+		if err := s.store.RecurringDonations.Save(&s.recurringDonations[i]); err != nil {
+			log.Printf("Erro ao salvar a doação recorrente %d: %v", recurring.ID, err)
+		}
+		responses = append(responses, response)
+	}
+
+	return responses
+}
+
+// nextScheduledRun retorna a primeira data do agendamento posterior ao instante informado
+func nextScheduledRun(recurring models.RecurringDonation, now time.Time) time.Time {
+	next := recurring.NextRunAt
+	for !next.After(now) {
+		next = next.AddDate(0, 0, recurring.IntervalDays)
+	}
+	return next
+}
+
+// RecurringDonationJob processa periodicamente as doações recorrentes vencidas
+type RecurringDonationJob struct {
+	donationService *DonationService
+	interval        time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewRecurringDonationJob cria o job de doações recorrentes
+func NewRecurringDonationJob(donationSvc *DonationService, interval time.Duration) *RecurringDonationJob {
+	return &RecurringDonationJob{
+		donationService: donationSvc,
+		interval:        interval,
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+}
+
+// Start inicia a execução periódica do job em segundo plano. As doações vencem pelo
+// relógio do serviço de doações (ver DonationService.SetClock).
+func (j *RecurringDonationJob) Start() {
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if processed := j.donationService.ProcessDueRecurringDonations(j.donationService.now()); len(processed) > 0 {
+					log.Printf("%d doações recorrentes geradas", len(processed))
+				}
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop interrompe o job e aguarda o término da execução em andamento
+func (j *RecurringDonationJob) Stop() {
+	j.stopOnce.Do(func() { close(j.stop) })
+	<-j.done
+}
+
+// Close interrompe o job, permitindo encerrá-lo junto com os demais componentes no desligamento
+func (j *RecurringDonationJob) Close() error {
+	j.Stop()
+	return nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPausedRecurringDonationIsSkipped(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	recurring, err := donationSvc.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 30, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, defaultRecurringIntervalDays, recurring.IntervalDays)
+
+	_, err = donationSvc.PauseRecurringDonation(recurring.ID)
+	require.NoError(t, err)
+
+	processed := donationSvc.ProcessDueRecurringDonations(time.Now().Add(time.Minute))
+	assert.Empty(t, processed, "Assinaturas pausadas não devem gerar doações")
+	assert.Empty(t, donationSvc.donations)
+}
+
+func TestResumedRecurringDonationFiresOnNextDueDate(t *testing.T) {
+	donationSvc := NewDonationService()
+
+	recurring, err := donationSvc.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 30, DonorID: 1, NGOID: 2, IntervalDays: 30})
+	require.NoError(t, err)
+
+	// Simular uma assinatura pausada há 45 dias, com um ciclo perdido durante a pausa
+	scheduledAt := time.Now().AddDate(0, 0, -45)
+	donationSvc.recurringDonations[0].NextRunAt = scheduledAt
+	_, err = donationSvc.PauseRecurringDonation(recurring.ID)
+	require.NoError(t, err)
+
+	resumed, err := donationSvc.ResumeRecurringDonation(recurring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RecurringActive, resumed.Status)
+	assert.Equal(t, scheduledAt.AddDate(0, 0, 60), resumed.NextRunAt, "Deve retomar na próxima data do agendamento")
+
+	assert.Empty(t, donationSvc.ProcessDueRecurringDonations(time.Now()), "Ciclos perdidos não devem ser cobrados")
+
+	processed := donationSvc.ProcessDueRecurringDonations(resumed.NextRunAt)
+	require.Len(t, processed, 1)
+	assert.Equal(t, "pending", processed[0].Status)
+
+	updated, err := donationSvc.GetRecurringDonationByID(recurring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, resumed.NextRunAt.AddDate(0, 0, 30), updated.NextRunAt)
+}
+
+func TestRecurringDonationsSurviveRestart(t *testing.T) {
+	store := repository.NewMemoryStore()
+	first, err := NewDonationServiceWithStore(store)
+	require.NoError(t, err)
+	require.NoError(t, first.Seed())
+
+	recurring, err := first.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 30, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	_, err = first.PauseRecurringDonation(recurring.ID)
+	require.NoError(t, err)
+
+	// Um novo serviço sobre o mesmo armazenamento simula o reinício da API
+	second, err := NewDonationServiceWithStore(store)
+	require.NoError(t, err)
+	reloaded, err := second.GetRecurringDonationByID(recurring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RecurringPaused, reloaded.Status)
+
+	// Os IDs continuam a sequência do banco
+	next, err := second.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 15, DonorID: 2, NGOID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, recurring.ID+1, next.ID)
+}
+
+func TestMergeNGOsMovesRecurringDonations(t *testing.T) {
+	donationSvc := NewDonationService()
+	adminSvc := NewAdminService(donationSvc, NewExpenseService(donationSvc))
+
+	recurring, err := donationSvc.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 30, DonorID: 1, NGOID: 2})
+	require.NoError(t, err)
+	require.NoError(t, adminSvc.MergeNGOs(1, 2, 7))
+
+	_, err = donationSvc.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 30, DonorID: 1, NGOID: 2})
+	assert.ErrorIs(t, err, ErrNGOMerged)
+
+	// As cobranças seguintes vão para a ONG canônica
+	processed := donationSvc.ProcessDueRecurringDonations(recurring.NextRunAt.Add(time.Minute))
+	require.Len(t, processed, 1)
+	moved, err := donationSvc.GetRecurringDonationByID(recurring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), moved.NGOID)
+	donations, err := donationSvc.GetDonationsByDonorID(1)
+	require.NoError(t, err)
+	require.NotEmpty(t, donations)
+	assert.Equal(t, uint(1), donations[len(donations)-1].NGOID)
+}
+
+func TestRecurringDonationJobUsesServiceClock(t *testing.T) {
+	donationSvc := NewDonationService()
+	recurring, err := donationSvc.CreateRecurringDonation(models.RecurringDonationRequest{Amount: 30, DonorID: 1, NGOID: 1, IntervalDays: 30})
+	require.NoError(t, err)
+	require.Len(t, donationSvc.ProcessDueRecurringDonations(recurring.NextRunAt), 1)
+
+	// O segundo ciclo só vence pelo relógio do serviço, 30 dias à frente
+	donationSvc.SetClock(ClockFunc(func() time.Time { return recurring.NextRunAt.AddDate(0, 0, 31) }))
+	job := NewRecurringDonationJob(donationSvc, time.Millisecond)
+	job.Start()
+	require.Eventually(t, func() bool {
+		donations, err := donationSvc.GetDonationsByDonorID(1)
+		return err == nil && len(donations) == 2
+	}, time.Second, time.Millisecond)
+	job.Stop()
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+	"trackable-donations/api/internal/models"
+)
+
+// PaymentReminderJob envia periodicamente um lembrete aos doadores com doações pendentes
+type PaymentReminderJob struct {
+	donationService *DonationService
+	notifier        Notifier
+	// remindAfter é a idade mínima de uma doação pendente para receber lembrete
+	remindAfter time.Duration
+	// staleAfter é a idade a partir da qual a doação é considerada abandonada e não recebe mais lembretes
+	staleAfter time.Duration
+	interval   time.Duration
+	now        func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewPaymentReminderJob cria o job de lembretes de pagamento
+func NewPaymentReminderJob(donationSvc *DonationService, notifier Notifier, remindAfter, staleAfter, interval time.Duration) *PaymentReminderJob {
+	return &PaymentReminderJob{
+		donationService: donationSvc,
+		notifier:        notifier,
+		remindAfter:     remindAfter,
+		staleAfter:      staleAfter,
+		interval:        interval,
+		now:             time.Now,
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+}
+
+// Start inicia a execução periódica do job em segundo plano
+func (j *PaymentReminderJob) Start() {
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if sent := j.RunOnce(); sent > 0 {
+					log.Printf("%d lembretes de pagamento enviados", sent)
+				}
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop interrompe o job e aguarda o término da execução em andamento
+func (j *PaymentReminderJob) Stop() {
+	j.stopOnce.Do(func() { close(j.stop) })
+	<-j.done
+}
+
+// Close interrompe o job, permitindo encerrá-lo junto com os demais componentes no desligamento
+func (j *PaymentReminderJob) Close() error {
+	j.Stop()
+	return nil
+}
+
+// RunOnce envia os lembretes devidos e retorna quantos foram enviados
+func (j *PaymentReminderJob) RunOnce() int {
+	now := j.now()
+	sent := 0
+
+	for _, donation := range j.donationService.snapshotDonations() {
+		if donation.Status != "pending" || donation.ReminderSentAt != nil {
+			continue
+		}
+
+		age := now.Sub(donation.CreatedAt)
+		if age < j.remindAfter || (j.staleAfter > 0 && age >= j.staleAfter) {
+			continue
+		}
+
+		donor, err := j.donationService.GetUserByID(donation.DonorID)
+		if err != nil {
+			continue
+		}
+		ngo, _ := j.donationService.GetNGOByID(donation.NGOID)
+
+		reminder := models.PaymentReminder{
+			DonationID: donation.ID,
+			DonorName:  donor.Name,
+			DonorEmail: donor.Email,
+			NGOName:    ngo.Name,
+			Amount:     donation.Amount + donation.Tip,
+			PaymentURL: paymentURL(donation),
+		}
+
+		// Em caso de falha o lembrete será tentado novamente na próxima execução
+		if err := sendPaymentReminder(j.notifier, reminder); err != nil {
+			log.Printf("Erro ao enviar lembrete da doação %d: %v", donation.ID, err)
+			continue
+		}
+
+		j.donationService.markReminderSent(donation.ID, now)
+		sent++
+	}
+
+	return sent
+}
+
+// markReminderSent registra o envio do lembrete de pagamento de uma doação
+func (s *DonationService) markReminderSent(donationID uint, sentAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.donations {
+		if s.donations[i].ID == donationID {
+			s.donations[i].ReminderSentAt = &sentAt
+			if err := s.store.Donations.Save(&s.donations[i]); err != nil {
+				log.Printf("Erro ao salvar lembrete da doação %d: %v", donationID, err)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturingNotifier guarda as notificações enviadas para inspeção nos testes
+type capturingNotifier struct {
+	reminders []models.PaymentReminder
+	receipts  []models.DonationReceipt
+	err       error // Devolvido por todos os envios, para simular falhas
+}
+
+func (n *capturingNotifier) SendPaymentReminder(reminder models.PaymentReminder) error {
+	n.reminders = append(n.reminders, reminder)
+	return n.err
+}
+
+func (n *capturingNotifier) SendReceipt(receipt models.DonationReceipt) error {
+	n.receipts = append(n.receipts, receipt)
+	return n.err
+}
+
+func TestPaymentReminderSentOnceForPendingDonation(t *testing.T) {
+	donationSvc := NewDonationService()
+	notifier := &capturingNotifier{}
+	job := NewPaymentReminderJob(donationSvc, notifier, time.Hour, 24*time.Hour, time.Minute)
+
+	pending, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 40, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	completed := completeDonation(t, donationSvc, models.DonationRequest{Amount: 60, DonorID: 2, NGOID: 1})
+
+	created := donationSvc.donations[0].CreatedAt
+	job.now = func() time.Time { return created.Add(2 * time.Hour) }
+
+	assert.Equal(t, 1, job.RunOnce())
+	require.Len(t, notifier.reminders, 1)
+	assert.Equal(t, pending.ID, notifier.reminders[0].DonationID)
+	assert.Equal(t, "joao@example.com", notifier.reminders[0].DonorEmail)
+	assert.Equal(t, pending.PaymentURL, notifier.reminders[0].PaymentURL)
+	assert.NotEqual(t, completed, notifier.reminders[0].DonationID)
+
+	assert.Equal(t, 0, job.RunOnce(), "O lembrete deve ser enviado apenas uma vez")
+	assert.NotNil(t, donationSvc.donations[0].ReminderSentAt)
+}
+
+func TestPaymentReminderSkipsTooRecentAndStaleDonations(t *testing.T) {
+	donationSvc := NewDonationService()
+	notifier := &capturingNotifier{}
+	job := NewPaymentReminderJob(donationSvc, notifier, time.Hour, 24*time.Hour, time.Minute)
+
+	_, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 40, DonorID: 1, NGOID: 1})
+	require.NoError(t, err)
+	created := donationSvc.donations[0].CreatedAt
+
+	job.now = func() time.Time { return created.Add(30 * time.Minute) }
+	assert.Equal(t, 0, job.RunOnce(), "Doações recentes ainda não recebem lembrete")
+
+	job.now = func() time.Time { return created.Add(25 * time.Hour) }
+	assert.Equal(t, 0, job.RunOnce(), "Doações abandonadas não recebem lembrete")
+	assert.Empty(t, notifier.reminders)
+}
+
+func TestPaymentReminderJobStop(t *testing.T) {
+	job := NewPaymentReminderJob(NewDonationService(), &capturingNotifier{}, time.Hour, 24*time.Hour, time.Millisecond)
+	job.Start()
+	time.Sleep(5 * time.Millisecond)
+	job.Stop()
+	job.Stop()
+}
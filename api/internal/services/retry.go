@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"time"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/retry"
+)
+
+// Novas tentativas das chamadas a serviços externos (IPFS e envio de notificações), que
+// costumam falhar de forma passageira
+var (
+	externalCallAttempts  = 3
+	externalCallBaseDelay = 200 * time.Millisecond
+)
+
+// addToIPFS envia o conteúdo ao IPFS, tentando novamente em caso de falha enquanto ctx
+// (normalmente o da requisição) não for cancelado
+func addToIPFS(ctx context.Context, client IPFSClient, content []byte) (string, error) {
+	var cid string
+	err := retry.DoContext(ctx, func() error {
+		var err error
+		cid, err = client.Add(content)
+		return err
+	}, externalCallAttempts, externalCallBaseDelay)
+	return cid, err
+}
+
+// sendReceipt envia o comprovante ao doador, tentando novamente em caso de falha
+func sendReceipt(notifier Notifier, receipt models.DonationReceipt) error {
+	return retry.Do(func() error {
+		return notifier.SendReceipt(receipt)
+	}, externalCallAttempts, externalCallBaseDelay)
+}
+
+// sendPaymentReminder envia o lembrete de pagamento, tentando novamente em caso de falha
+func sendPaymentReminder(notifier Notifier, reminder models.PaymentReminder) error {
+	return retry.Do(func() error {
+		return notifier.SendPaymentReminder(reminder)
+	}, externalCallAttempts, externalCallBaseDelay)
+}
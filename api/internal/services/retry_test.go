@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyIPFSClient falha nos primeiros envios antes de repassá-los ao cliente em memória
+type flakyIPFSClient struct {
+	*MemoryIPFSClient
+	failures int
+	calls    int
+}
+
+func (c *flakyIPFSClient) Add(content []byte) (string, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return "", errors.New("gateway indisponível")
+	}
+	return c.MemoryIPFSClient.Add(content)
+}
+
+// shortRetryDelay reduz a espera entre tentativas durante o teste
+func shortRetryDelay(t *testing.T) {
+	previous := externalCallBaseDelay
+	externalCallBaseDelay = time.Millisecond
+	t.Cleanup(func() { externalCallBaseDelay = previous })
+}
+
+func TestUploadsRetryTransientIPFSFailures(t *testing.T) {
+	shortRetryDelay(t)
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+
+	ipfs := &flakyIPFSClient{MemoryIPFSClient: NewMemoryIPFSClient(), failures: 2}
+	donationSvc.SetIPFSClient(ipfs)
+
+	expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 40, Description: "Alimentos", Category: "Alimentação"})
+	require.NoError(t, err)
+	uploaded, err := expenseSvc.UploadReceipt(context.Background(), expense.ID, []byte("nota fiscal"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, ipfs.calls)
+	assert.NotEmpty(t, uploaded.ReceiptIPFS)
+
+	// Com a requisição cancelada, o upload desiste sem novas tentativas
+	ipfs.calls, ipfs.failures = 0, 10
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = expenseSvc.UploadReceipt(ctx, expense.ID, []byte("nota fiscal"))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, ipfs.calls)
+}
+
+func TestReceiptDeliveryIsRetried(t *testing.T) {
+	shortRetryDelay(t)
+	notifier := &capturingNotifier{err: errors.New("servidor indisponível")}
+
+	assert.Error(t, sendReceipt(notifier, models.DonationReceipt{DonationID: 1}))
+	assert.Len(t, notifier.receipts, externalCallAttempts)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cabeçalhos das exportações CSV, na mesma ordem dos campos de TransparencyDonation e TransparencyExpense
+var (
+	donationCSVHeader = []string{"id", "amount", "ngo_name", "ngo_category", "date", "status", "transaction_hash", "metadata"}
+	expenseCSVHeader  = []string{"id", "donation_id", "ngo_name", "amount", "description", "category", "date", "receipt_ipfs", "blockchain_ref", "status"}
+)
+
+// WriteDonationsCSV escreve as doações públicas em CSV (RFC 4180), com linha de cabeçalho
+func WriteDonationsCSV(w io.Writer, donations []TransparencyDonation) error {
+	return writeCSV(w, donationCSVHeader, len(donations), func(i int) []string {
+		d := donations[i]
+		return []string{
+			strconv.FormatUint(uint64(d.ID), 10),
+			csvAmount(d.Amount),
+			csvText(d.NGOName),
+			csvText(d.NGOCategory),
+			csvDate(d.Date),
+			d.Status,
+			d.TransactionHash,
+			csvMetadata(d.Metadata),
+		}
+	})
+}
+
+// WriteExpensesCSV escreve as despesas públicas em CSV (RFC 4180), com linha de cabeçalho
+func WriteExpensesCSV(w io.Writer, expenses []TransparencyExpense) error {
+	return writeCSV(w, expenseCSVHeader, len(expenses), func(i int) []string {
+		e := expenses[i]
+		return []string{
+			strconv.FormatUint(uint64(e.ID), 10),
+			strconv.FormatUint(uint64(e.DonationID), 10),
+			csvText(e.NGOName),
+			csvAmount(e.Amount),
+			csvText(e.Description),
+			csvText(e.Category),
+			csvDate(e.Date),
+			e.ReceiptIPFS,
+			e.BlockchainRef,
+			e.Status,
+		}
+	})
+}
+
+// csvFlushRows é a quantidade de linhas escritas entre um envio e outro do CSV ao cliente
+const csvFlushRows = 500
+
+// flusher é implementado pelas respostas HTTP que podem enviar ao cliente o que já foi escrito
+// (como o ResponseWriter do gin)
+type flusher interface {
+	Flush()
+}
+
+// writeCSV escreve o cabeçalho e as linhas direto em w, sem montar o arquivo em memória. A
+// cada csvFlushRows linhas o buffer do CSV é esvaziado e, quando w é uma resposta HTTP, o
+// conteúdo é enviado ao cliente, para que exportações grandes cheguem aos poucos.
+func writeCSV(w io.Writer, header []string, rows int, row func(int) []string) error {
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true // Quebra de linha exigida pela RFC 4180
+
+	flush := func() error {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		if f, ok := w.(flusher); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < rows; i++ {
+		if err := writer.Write(row(i)); err != nil {
+			return err
+		}
+		if (i+1)%csvFlushRows == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// csvAmount formata valores como decimal simples com ponto (ex.: 1234.50)
+func csvAmount(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// csvDate formata datas em ISO-8601 (UTC)
+func csvDate(date time.Time) string {
+	return date.UTC().Format(time.RFC3339)
+}
+
+// csvMetadata serializa os metadados públicos como JSON (vazio quando não há metadados)
+func csvMetadata(metadata map[string]string) string {
+	if len(metadata) == 0 {
+		return ""
+	}
+	encoded, _ := json.Marshal(metadata) // As chaves do mapa saem ordenadas
+	return csvText(string(encoded))
+}
+
+// csvText neutraliza textos livres que planilhas interpretariam como fórmula
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
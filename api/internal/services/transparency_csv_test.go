@@ -0,0 +1,80 @@
+package services
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDonationsCSV(t *testing.T) {
+	var out strings.Builder
+	err := WriteDonationsCSV(&out, []TransparencyDonation{{
+		ID:              7,
+		Amount:          1234.5,
+		NGOName:         "Saúde, Vida e \"Esperança\"",
+		NGOCategory:     "Saúde",
+		Date:            time.Date(2024, 3, 5, 14, 30, 0, 0, time.FixedZone("BRT", -3*60*60)),
+		Status:          "completed",
+		TransactionHash: "0xabc",
+		Metadata:        map[string]string{"campanha": "natal"},
+	}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(out.String(), "\r\n"), "Linhas terminam em CRLF")
+
+	records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, donationCSVHeader, records[0])
+	assert.Equal(t, []string{"7", "1234.50", "Saúde, Vida e \"Esperança\"", "Saúde", "2024-03-05T17:30:00Z", "completed", "0xabc", `{"campanha":"natal"}`}, records[1])
+}
+
+func TestWriteExpensesCSVNeutralizesFormulas(t *testing.T) {
+	var out strings.Builder
+	err := WriteExpensesCSV(&out, []TransparencyExpense{{
+		ID: 3, DonationID: 7, NGOName: "ONG", Amount: 50, Description: "=HYPERLINK(\"http://x\")",
+		Category: "Saúde", Date: time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC), Status: "aprovado",
+	}})
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, expenseCSVHeader, records[0])
+	assert.Equal(t, "50.00", records[1][3])
+	assert.Equal(t, "'=HYPERLINK(\"http://x\")", records[1][4])
+}
+
+// flushCountingWriter simula uma resposta HTTP, contando os envios ao cliente
+type flushCountingWriter struct {
+	strings.Builder
+	flushes int
+}
+
+func (w *flushCountingWriter) Flush() { w.flushes++ }
+
+func TestWriteDonationsCSVStreamsLargeExports(t *testing.T) {
+	const rows = 50000
+	donations := make([]TransparencyDonation, rows)
+	for i := range donations {
+		donations[i] = TransparencyDonation{
+			ID: uint(i + 1), Amount: float64(i%1000) + 0.5, NGOName: "ONG", NGOCategory: "Saúde",
+			Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Status: "completed", TransactionHash: "0xabc",
+		}
+	}
+
+	var out flushCountingWriter
+	require.NoError(t, WriteDonationsCSV(&out, donations))
+
+	records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, rows+1)
+	assert.Equal(t, donationCSVHeader, records[0])
+	assert.Equal(t, "50000", records[rows][0])
+
+	// A resposta é enviada a cada lote de linhas, e não só ao final
+	assert.Equal(t, rows/csvFlushRows+1, out.flushes)
+}
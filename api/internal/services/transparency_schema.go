@@ -0,0 +1,89 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// jsonSchemaDraft é a versão do JSON Schema usada no dicionário de dados
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema descreve um tipo no formato JSON Schema (apenas o subconjunto usado pelos dados públicos)
+type JSONSchema struct {
+	Type                 string                `json:"type"`
+	Format               string                `json:"format,omitempty"`
+	Properties           map[string]JSONSchema `json:"properties,omitempty"`
+	Required             []string              `json:"required,omitempty"`
+	Items                *JSONSchema           `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema           `json:"additionalProperties,omitempty"`
+}
+
+// TransparencySchema é o dicionário de dados dos tipos publicados nas rotas de transparência
+type TransparencySchema struct {
+	Schema      string                `json:"$schema"`
+	Definitions map[string]JSONSchema `json:"$defs"`
+}
+
+// GetTransparencySchema gera, por reflexão, o JSON Schema dos tipos públicos de transparência.
+// Campos com omitempty são opcionais; os demais são obrigatórios.
+func GetTransparencySchema() TransparencySchema {
+	return TransparencySchema{
+		Schema: jsonSchemaDraft,
+		Definitions: map[string]JSONSchema{
+			"TransparencyDonation":   schemaFor(reflect.TypeOf(TransparencyDonation{})),
+			"TransparencyExpense":    schemaFor(reflect.TypeOf(TransparencyExpense{})),
+			"TransparencyNGOSummary": schemaFor(reflect.TypeOf(TransparencyNGOSummary{})),
+		},
+	}
+}
+
+// schemaFor traduz um tipo Go para o JSON Schema correspondente à sua serialização
+func schemaFor(t reflect.Type) JSONSchema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return JSONSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		items := schemaFor(t.Elem())
+		return JSONSchema{Type: "array", Items: &items}
+	case reflect.Map:
+		values := schemaFor(t.Elem())
+		return JSONSchema{Type: "object", AdditionalProperties: &values}
+	case reflect.Struct:
+		schema := JSONSchema{Type: "object", Properties: make(map[string]JSONSchema)}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			schema.Properties[name] = schemaFor(field.Type)
+			if !strings.Contains(options, "omitempty") {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		return schema
+	default:
+		return JSONSchema{Type: "string"}
+	}
+}
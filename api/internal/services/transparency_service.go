@@ -1,26 +1,39 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"sort"
+	"sync"
 	"time"
 )
 
+// scoreCacheTTL é o tempo durante o qual o índice de transparência calculado é reaproveitado
+const scoreCacheTTL = 5 * time.Minute
+
 // TransparencyService gerencia operações relacionadas à transparência pública
 type TransparencyService struct {
 	donationService *DonationService
 	expenseService  *ExpenseService
+
+	scoreMu       sync.Mutex
+	scoreCache    *TransparencyScore
+	scoreCachedAt time.Time
+	now           func() time.Time
 }
 
 // TransparencyDonation representa uma doação para exibição pública
 type TransparencyDonation struct {
-	ID              uint      `json:"id"`
-	Amount          float64   `json:"amount"`
-	NGOName         string    `json:"ngo_name"`
-	NGOCategory     string    `json:"ngo_category"`
-	Date            time.Time `json:"date"`
-	Status          string    `json:"status"`
-	TransactionHash string    `json:"transaction_hash,omitempty"`
+	ID              uint              `json:"id"`
+	Amount          float64           `json:"amount"`
+	NGOName         string            `json:"ngo_name"`
+	NGOCategory     string            `json:"ngo_category"`
+	Date            time.Time         `json:"date"`
+	Status          string            `json:"status"`
+	TransactionHash string            `json:"transaction_hash,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"` // Apenas chaves liberadas para exibição pública
+	CampaignID      uint              `json:"campaign_id,omitempty"`
 }
 
 // TransparencyExpense representa uma despesa para exibição pública
@@ -47,8 +60,29 @@ type TransparencyNGOSummary struct {
 	DonationsCount   int     `json:"donations_count"`
 	ExpensesCount    int     `json:"expenses_count"`
 	AvailableBalance float64 `json:"available_balance"`
+	// SpendableBalance desconta também os gastos pendentes de aprovação; é o limite
+	// para novos gastos da ONG
+	SpendableBalance float64 `json:"spendable_balance"`
+	// SpendingRatio é a fração do total recebido já aplicada em gastos aprovados (0 sem doações)
+	SpendingRatio float64 `json:"spending_ratio"`
+	// AdministrativeRatio é a fração do total gasto na categoria "Administrativo" (0 sem gastos)
+	AdministrativeRatio float64 `json:"administrative_ratio"`
 }
 
+// administrativeExpenseCategory é a categoria dos gastos administrativos da ONG (ver models.ExpenseCategories)
+const administrativeExpenseCategory = "Administrativo"
+
+// TransparencyNGOContact representa o contato público de uma ONG para dúvidas de doadores
+type TransparencyNGOContact struct {
+	NGOID uint   `json:"ngo_id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// ErrNGOContactHidden indica que a ONG optou por não divulgar seu contato
+var ErrNGOContactHidden = errors.New("ONG optou por não divulgar contato público")
+
 // TransparencyDashboard representa o resumo geral de transparência
 type TransparencyDashboard struct {
 	TotalDonations  float64                  `json:"total_donations"`
@@ -61,21 +95,37 @@ type TransparencyDashboard struct {
 	NGOsSummary     []TransparencyNGOSummary `json:"ngos_summary"`
 }
 
+// TransparencyScore representa o índice de transparência da plataforma (0 a 100)
+// com a decomposição de cada componente, todos expressos em percentual
+type TransparencyScore struct {
+	Score                    float64   `json:"score"`
+	DonationVerificationRate float64   `json:"donation_verification_rate"`
+	ReceiptCoverageRate      float64   `json:"receipt_coverage_rate"`
+	SpendRatio               float64   `json:"spend_ratio"`
+	VerifiedDonations        int       `json:"verified_donations"`
+	TotalDonations           int       `json:"total_donations"`
+	ExpensesWithReceipt      int       `json:"expenses_with_receipt"`
+	TotalExpenses            int       `json:"total_expenses"`
+	CalculatedAt             time.Time `json:"calculated_at"`
+}
+
 // NewTransparencyService cria uma nova instância do serviço de transparência
 func NewTransparencyService(donationSvc *DonationService, expenseSvc *ExpenseService) *TransparencyService {
 	return &TransparencyService{
 		donationService: donationSvc,
 		expenseService:  expenseSvc,
+		now:             time.Now,
 	}
 }
 
-// GetPublicDonations retorna todas as doações públicas
-func (s *TransparencyService) GetPublicDonations() []TransparencyDonation {
+// GetPublicDonations retorna todas as doações públicas; campaignID diferente de zero restringe
+// às doações atribuídas à campanha
+func (s *TransparencyService) GetPublicDonations(campaignID uint) []TransparencyDonation {
 	var publicDonations []TransparencyDonation
 
 	// Filtrar apenas doações que foram completadas
-	for _, donation := range s.donationService.donations {
-		if donation.Status == "completed" {
+	for _, donation := range s.donationService.snapshotDonations() {
+		if donation.Status == "completed" && (campaignID == 0 || donation.CampaignID == campaignID) {
 			ngo, _ := s.donationService.GetNGOByID(donation.NGOID)
 
 			publicDonation := TransparencyDonation{
@@ -86,6 +136,8 @@ func (s *TransparencyService) GetPublicDonations() []TransparencyDonation {
 				Date:            donation.CreatedAt,
 				Status:          donation.Status,
 				TransactionHash: donation.TransactionHash,
+				Metadata:        s.donationService.publicMetadata(donation.Metadata),
+				CampaignID:      donation.CampaignID,
 			}
 
 			publicDonations = append(publicDonations, publicDonation)
@@ -105,7 +157,7 @@ func (s *TransparencyService) GetPublicExpenses() []TransparencyExpense {
 	var publicExpenses []TransparencyExpense
 
 	// Filtrar apenas despesas aprovadas
-	for _, expense := range s.expenseService.expenses {
+	for _, expense := range s.expenseService.snapshotExpenses() {
 		if expense.Status == "aprovado" {
 			ngo, _ := s.donationService.GetNGOByID(expense.NGOID)
 
@@ -134,8 +186,9 @@ func (s *TransparencyService) GetPublicExpenses() []TransparencyExpense {
 	return publicExpenses
 }
 
-// GetDonationsByNGO retorna todas as doações recebidas por uma ONG específica
-func (s *TransparencyService) GetDonationsByNGO(ngoID uint) ([]TransparencyDonation, error) {
+// GetDonationsByNGO retorna todas as doações recebidas por uma ONG específica; campaignID
+// diferente de zero restringe às doações atribuídas à campanha
+func (s *TransparencyService) GetDonationsByNGO(ngoID, campaignID uint) ([]TransparencyDonation, error) {
 	// Verificar se a ONG existe
 	ngo, err := s.donationService.GetNGOByID(ngoID)
 	if err != nil {
@@ -145,8 +198,9 @@ func (s *TransparencyService) GetDonationsByNGO(ngoID uint) ([]TransparencyDonat
 	var ngoDonations []TransparencyDonation
 
 	// Filtrar doações da ONG
-	for _, donation := range s.donationService.donations {
-		if donation.NGOID == ngoID && donation.Status == "completed" {
+	for _, donation := range s.donationService.snapshotDonations() {
+		if donation.NGOID == ngoID && donation.Status == "completed" &&
+			(campaignID == 0 || donation.CampaignID == campaignID) {
 			publicDonation := TransparencyDonation{
 				ID:              donation.ID,
 				Amount:          donation.Amount,
@@ -155,6 +209,8 @@ func (s *TransparencyService) GetDonationsByNGO(ngoID uint) ([]TransparencyDonat
 				Date:            donation.CreatedAt,
 				Status:          donation.Status,
 				TransactionHash: donation.TransactionHash,
+				Metadata:        s.donationService.publicMetadata(donation.Metadata),
+				CampaignID:      donation.CampaignID,
 			}
 
 			ngoDonations = append(ngoDonations, publicDonation)
@@ -180,7 +236,7 @@ func (s *TransparencyService) GetExpensesByNGO(ngoID uint) ([]TransparencyExpens
 	var ngoExpenses []TransparencyExpense
 
 	// Filtrar despesas da ONG
-	for _, expense := range s.expenseService.expenses {
+	for _, expense := range s.expenseService.snapshotExpenses() {
 		if expense.NGOID == ngoID && expense.Status == "aprovado" {
 			publicExpense := TransparencyExpense{
 				ID:            expense.ID,
@@ -219,27 +275,38 @@ func (s *TransparencyService) GetNGOSummary(ngoID uint) (TransparencyNGOSummary,
 	var donationsCount int
 
 	// Calcular total recebido
-	for _, donation := range s.donationService.donations {
+	for _, donation := range s.donationService.snapshotDonations() {
 		if donation.NGOID == ngoID && donation.Status == "completed" {
 			totalReceived += donation.Amount
 			donationsCount++
 		}
 	}
 
-	var totalSpent float64
+	var totalSpent, administrativeSpent float64
 	var expensesCount int
 
 	// Calcular total gasto
-	for _, expense := range s.expenseService.expenses {
+	for _, expense := range s.expenseService.snapshotExpenses() {
 		if expense.NGOID == ngoID && expense.Status == "aprovado" {
 			totalSpent += expense.Amount
 			expensesCount++
+			if expense.Category == administrativeExpenseCategory {
+				administrativeSpent += expense.Amount
+			}
 		}
 	}
 
 	// Calcular saldo disponível
 	availableBalance := totalReceived - totalSpent
 
+	var spendingRatio, administrativeRatio float64
+	if totalReceived > 0 {
+		spendingRatio = roundTwoDecimals(totalSpent / totalReceived)
+	}
+	if totalSpent > 0 {
+		administrativeRatio = roundTwoDecimals(administrativeSpent / totalSpent)
+	}
+
 	return TransparencyNGOSummary{
 		ID:               ngo.ID,
 		Name:             ngo.Name,
@@ -249,6 +316,29 @@ func (s *TransparencyService) GetNGOSummary(ngoID uint) (TransparencyNGOSummary,
 		DonationsCount:   donationsCount,
 		ExpensesCount:    expensesCount,
 		AvailableBalance: availableBalance,
+		SpendableBalance: s.expenseService.NGOAvailableBalance(ngoID),
+
+		SpendingRatio:       spendingRatio,
+		AdministrativeRatio: administrativeRatio,
+	}, nil
+}
+
+// GetNGOContact retorna o contato institucional da ONG (nunca os dados pessoais do responsável)
+func (s *TransparencyService) GetNGOContact(ngoID uint) (TransparencyNGOContact, error) {
+	ngo, err := s.donationService.GetNGOByID(ngoID)
+	if err != nil {
+		return TransparencyNGOContact{}, err
+	}
+
+	if ngo.HideContact {
+		return TransparencyNGOContact{}, ErrNGOContactHidden
+	}
+
+	return TransparencyNGOContact{
+		NGOID: ngo.ID,
+		Name:  ngo.Name,
+		Email: ngo.Email,
+		Phone: ngo.Phone,
 	}, nil
 }
 
@@ -256,7 +346,7 @@ func (s *TransparencyService) GetNGOSummary(ngoID uint) (TransparencyNGOSummary,
 func (s *TransparencyService) GetAllNGOsSummary() []TransparencyNGOSummary {
 	var summaries []TransparencyNGOSummary
 
-	for _, ngo := range s.donationService.ngos {
+	for _, ngo := range s.donationService.GetAllNGOs() {
 		summary, err := s.GetNGOSummary(ngo.ID)
 		if err == nil {
 			summaries = append(summaries, summary)
@@ -277,7 +367,7 @@ func (s *TransparencyService) GetTransparencyDashboard() TransparencyDashboard {
 	var donationsCount int
 
 	// Contar doações completadas
-	for _, donation := range s.donationService.donations {
+	for _, donation := range s.donationService.snapshotDonations() {
 		if donation.Status == "completed" {
 			totalDonations += donation.Amount
 			donationsCount++
@@ -288,7 +378,7 @@ func (s *TransparencyService) GetTransparencyDashboard() TransparencyDashboard {
 	var expensesCount int
 
 	// Contar despesas aprovadas
-	for _, expense := range s.expenseService.expenses {
+	for _, expense := range s.expenseService.snapshotExpenses() {
 		if expense.Status == "aprovado" {
 			totalExpenses += expense.Amount
 			expensesCount++
@@ -296,7 +386,7 @@ func (s *TransparencyService) GetTransparencyDashboard() TransparencyDashboard {
 	}
 
 	// Obter doações recentes (limitado a 5)
-	recentDonations := s.GetPublicDonations()
+	recentDonations := s.GetPublicDonations(0)
 	if len(recentDonations) > 5 {
 		recentDonations = recentDonations[:5]
 	}
@@ -315,9 +405,72 @@ func (s *TransparencyService) GetTransparencyDashboard() TransparencyDashboard {
 		TotalExpenses:   totalExpenses,
 		DonationsCount:  donationsCount,
 		ExpensesCount:   expensesCount,
-		NGOsCount:       len(s.donationService.ngos),
+		NGOsCount:       len(s.donationService.GetAllNGOs()),
 		RecentDonations: recentDonations,
 		RecentExpenses:  recentExpenses,
 		NGOsSummary:     ngosSummary,
 	}
 }
+
+// GetPlatformTransparencyScore calcula o índice de transparência da plataforma como a média de três
+// componentes: doações com registro na blockchain, despesas com comprovante anexado e proporção
+// do total doado que já foi aplicado. O resultado é mantido em cache por alguns minutos.
+func (s *TransparencyService) GetPlatformTransparencyScore() TransparencyScore {
+	s.scoreMu.Lock()
+	defer s.scoreMu.Unlock()
+
+	now := s.now()
+	if s.scoreCache != nil && now.Sub(s.scoreCachedAt) < scoreCacheTTL {
+		return *s.scoreCache
+	}
+
+	score := TransparencyScore{CalculatedAt: now}
+
+	var totalDonated float64
+	for _, donation := range s.donationService.snapshotDonations() {
+		if donation.Status != "completed" {
+			continue
+		}
+		score.TotalDonations++
+		totalDonated += donation.Amount
+		if donation.TransactionHash != "" {
+			score.VerifiedDonations++
+		}
+	}
+
+	var totalSpent float64
+	for _, expense := range s.expenseService.snapshotExpenses() {
+		if expense.Status == "rejeitado" {
+			continue
+		}
+		score.TotalExpenses++
+		if expense.ReceiptIPFS != "" {
+			score.ExpensesWithReceipt++
+		}
+		if expense.Status == "aprovado" {
+			totalSpent += expense.Amount
+		}
+	}
+
+	// Componentes sem base de cálculo (nenhuma doação ou despesa) valem zero
+	if score.TotalDonations > 0 {
+		score.DonationVerificationRate = roundTwoDecimals(float64(score.VerifiedDonations) / float64(score.TotalDonations) * 100)
+	}
+	if score.TotalExpenses > 0 {
+		score.ReceiptCoverageRate = roundTwoDecimals(float64(score.ExpensesWithReceipt) / float64(score.TotalExpenses) * 100)
+	}
+	if totalDonated > 0 {
+		score.SpendRatio = roundTwoDecimals(math.Min(totalSpent/totalDonated, 1) * 100)
+	}
+
+	score.Score = roundTwoDecimals((score.DonationVerificationRate + score.ReceiptCoverageRate + score.SpendRatio) / 3)
+
+	s.scoreCache = &score
+	s.scoreCachedAt = now
+	return score
+}
+
+// roundTwoDecimals arredonda um valor para duas casas decimais
+func roundTwoDecimals(value float64) float64 {
+	return math.Round(value*100) / 100
+}
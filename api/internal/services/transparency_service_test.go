@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlatformTransparencyScore(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	transparencySvc := NewTransparencyService(donationSvc, expenseSvc)
+
+	first := completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 300, DonorID: 2, NGOID: 2})
+	_, err := donationSvc.ProcessDonation(models.DonationRequest{Amount: 500, DonorID: 1, NGOID: 3})
+	require.NoError(t, err)
+
+	// Uma das doações concluídas ficou sem registro na blockchain
+	donationSvc.donations[1].TransactionHash = ""
+
+	withReceipt, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: first, NGOID: 1, Amount: 40, Description: "Alimentos", Category: "Alimentação"})
+	require.NoError(t, err)
+	_, err = expenseSvc.UploadReceipt(context.Background(), withReceipt.ID, []byte("nota fiscal"))
+	require.NoError(t, err)
+	require.NoError(t, NewAdminService(donationSvc, expenseSvc).ApproveExpense(withReceipt.ID, 1))
+	_, err = expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: first, NGOID: 1, Amount: 20, Description: "Transporte", Category: "Transporte"})
+	require.NoError(t, err)
+
+	score := transparencySvc.GetPlatformTransparencyScore()
+
+	assert.Equal(t, 2, score.TotalDonations, "Doações pendentes não entram no cálculo")
+	assert.Equal(t, 1, score.VerifiedDonations)
+	assert.Equal(t, 50.0, score.DonationVerificationRate)
+	assert.Equal(t, 2, score.TotalExpenses)
+	assert.Equal(t, 1, score.ExpensesWithReceipt)
+	assert.Equal(t, 50.0, score.ReceiptCoverageRate)
+	assert.Equal(t, 10.0, score.SpendRatio, "R$ 40 aplicados de R$ 400 doados")
+	assert.Equal(t, 36.67, score.Score)
+}
+
+func TestNGOSummarySpendingRatios(t *testing.T) {
+	donationSvc := NewDonationService()
+	expenseSvc := NewExpenseService(donationSvc)
+	adminSvc := NewAdminService(donationSvc, expenseSvc)
+	transparencySvc := NewTransparencyService(donationSvc, expenseSvc)
+
+	summary, err := transparencySvc.GetNGOSummary(1)
+	require.NoError(t, err)
+	assert.Zero(t, summary.SpendingRatio, "Sem doações recebidas a razão é zero")
+	assert.Zero(t, summary.AdministrativeRatio, "Sem gastos a razão é zero")
+
+	donationID := completeDonation(t, donationSvc, models.DonationRequest{Amount: 900, DonorID: 1, NGOID: 1})
+	approve := func(amount float64, category string) {
+		expense, err := expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: amount, Description: category, Category: category})
+		require.NoError(t, err)
+		_, err = expenseSvc.UploadReceipt(context.Background(), expense.ID, []byte("nota fiscal"))
+		require.NoError(t, err)
+		require.NoError(t, adminSvc.ApproveExpense(expense.ID, 1))
+	}
+	approve(200, "Alimentação")
+	approve(50, "Administrativo")
+	approve(50, "Transporte")
+	// Gastos pendentes não contam
+	_, err = expenseSvc.RegisterExpense(models.ExpenseRequest{DonationID: donationID, NGOID: 1, Amount: 100, Description: "Aluguel", Category: "Administrativo"})
+	require.NoError(t, err)
+
+	summary, err = transparencySvc.GetNGOSummary(1)
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, summary.TotalSpent)
+	assert.Equal(t, 0.33, summary.SpendingRatio, "R$ 300 aplicados de R$ 900 recebidos")
+	assert.Equal(t, 0.17, summary.AdministrativeRatio, "R$ 50 administrativos de R$ 300 gastos")
+}
+
+func TestPlatformTransparencyScoreIsCached(t *testing.T) {
+	donationSvc := NewDonationService()
+	transparencySvc := NewTransparencyService(donationSvc, NewExpenseService(donationSvc))
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	transparencySvc.now = func() time.Time { return now }
+
+	empty := transparencySvc.GetPlatformTransparencyScore()
+	assert.Equal(t, 0.0, empty.Score)
+
+	completeDonation(t, donationSvc, models.DonationRequest{Amount: 100, DonorID: 1, NGOID: 1})
+	assert.Equal(t, 0, transparencySvc.GetPlatformTransparencyScore().TotalDonations, "O valor em cache deve ser reaproveitado")
+
+	now = now.Add(scoreCacheTTL)
+	assert.Equal(t, 1, transparencySvc.GetPlatformTransparencyScore().TotalDonations, "O cache expirado deve ser recalculado")
+}
+
+func TestGetNGOContactExposesPublicContact(t *testing.T) {
+	donationSvc := NewDonationService()
+	transparencySvc := NewTransparencyService(donationSvc, NewExpenseService(donationSvc))
+
+	contact, err := transparencySvc.GetNGOContact(1)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), contact.NGOID)
+	assert.Equal(t, "contato@alimentandoesperanca.org.br", contact.Email)
+	assert.NotEmpty(t, contact.Phone)
+}
+
+func TestGetNGOContactRespectsOptOut(t *testing.T) {
+	donationSvc := NewDonationService()
+	transparencySvc := NewTransparencyService(donationSvc, NewExpenseService(donationSvc))
+	ngo := donationSvc.ngos[1]
+	ngo.HideContact = true
+	donationSvc.replaceNGO(1, ngo)
+
+	contact, err := transparencySvc.GetNGOContact(2)
+	assert.ErrorIs(t, err, ErrNGOContactHidden)
+	assert.Equal(t, TransparencyNGOContact{}, contact)
+
+	_, err = transparencySvc.GetNGOContact(999)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNGOContactHidden)
+}
+
+func TestTransparencySchemaCoversExportedFields(t *testing.T) {
+	schema := GetTransparencySchema()
+
+	for name, value := range map[string]any{
+		"TransparencyDonation":   TransparencyDonation{},
+		"TransparencyExpense":    TransparencyExpense{},
+		"TransparencyNGOSummary": TransparencyNGOSummary{},
+	} {
+		definition, ok := schema.Definitions[name]
+		require.True(t, ok, name)
+
+		typ := reflect.TypeOf(value)
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			assert.Contains(t, definition.Properties, jsonName, "%s.%s", name, field.Name)
+		}
+	}
+
+	donation := schema.Definitions["TransparencyDonation"]
+	assert.Equal(t, JSONSchema{Type: "string", Format: "date-time"}, donation.Properties["date"])
+	assert.Contains(t, donation.Required, "amount")
+	assert.NotContains(t, donation.Required, "transaction_hash", "Campos com omitempty são opcionais")
+}
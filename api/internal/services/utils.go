@@ -1,32 +1,33 @@
 package services
 
 import (
-	"math/rand"
-	"time"
+	"crypto/rand"
+	"encoding/hex"
+	mathrand "math/rand/v2"
+	"strings"
 )
 
-// Função auxiliar para gerar um hash de transação fictício
+// Função auxiliar para gerar um hash de transação fictício. Os 32 bytes vêm do crypto/rand
+// para que dois hashes gerados em sequência nunca coincidam.
 func generateMockTransactionHash() string {
-	const charset = "abcdef0123456789"
-	rand.Seed(time.Now().UnixNano())
-
-	hash := "0x"
-	for i := 0; i < 64; i++ {
-		hash += string(charset[rand.Intn(len(charset))])
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		// Sem fonte de aleatoriedade do sistema não há como gerar referências únicas
+		panic(err)
 	}
-
-	return hash
+	return "0x" + hex.EncodeToString(b)
 }
 
-// Função auxiliar para gerar um hash fictício genérico
+// Função auxiliar para gerar um hash fictício genérico. O gerador do math/rand/v2 é
+// semeado uma única vez pelo runtime, então chamadas seguidas não repetem a sequência.
 func generateMockHash(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	rand.Seed(time.Now().UnixNano())
 
-	hash := ""
+	var hash strings.Builder
+	hash.Grow(length)
 	for i := 0; i < length; i++ {
-		hash += string(charset[rand.Intn(len(charset))])
+		hash.WriteByte(charset[mathrand.IntN(len(charset))])
 	}
 
-	return hash
+	return hash.String()
 }
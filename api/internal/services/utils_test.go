@@ -0,0 +1,29 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockHashesDoNotCollide(t *testing.T) {
+	const count = 10000
+	txHashFormat := regexp.MustCompile(`^0x[0-9a-f]{64}$`)
+
+	txHashes := make(map[string]bool, count)
+	hashes := make(map[string]bool, count)
+	for i := 0; i < count; i++ {
+		txHash := generateMockTransactionHash()
+		require.Regexp(t, txHashFormat, txHash)
+		txHashes[txHash] = true
+
+		hash := generateMockHash(46)
+		require.Len(t, hash, 46)
+		hashes[hash] = true
+	}
+
+	assert.Len(t, txHashes, count, "Hashes de transação gerados em sequência não podem se repetir")
+	assert.Len(t, hashes, count, "Hashes gerados em sequência não podem se repetir")
+}
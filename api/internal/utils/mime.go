@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// MIMETypeDOCX é o tipo MIME de documentos do Word (Office Open XML)
+const MIMETypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// DetectMIMEType identifica o tipo do arquivo pelo conteúdo (magic bytes), ignorando
+// nome e extensão. Arquivos DOCX são reconhecidos dentro do contêiner ZIP.
+func DetectMIMEType(data []byte) string {
+	mimeType := http.DetectContentType(data)
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+
+	if mimeType == "application/zip" && isDOCX(data) {
+		return MIMETypeDOCX
+	}
+	return mimeType
+}
+
+func isDOCX(data []byte) bool {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	for _, file := range reader.File {
+		if file.Name == "word/document.xml" {
+			return true
+		}
+	}
+	return false
+}
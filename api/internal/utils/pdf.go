@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Dimensões de uma página A4 em pontos
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 50.0
+)
+
+type pdfLine struct {
+	font string
+	size float64
+	y    float64
+	text string
+}
+
+// PDFDocument é um gerador mínimo de PDF (A4, fontes Helvetica) escrito em Go puro,
+// suficiente para comprovantes e relatórios textuais sem depender de binários externos
+type PDFDocument struct {
+	pages [][]pdfLine
+	y     float64
+}
+
+// NewPDFDocument cria um documento vazio com a primeira página
+func NewPDFDocument() *PDFDocument {
+	d := &PDFDocument{}
+	d.newPage()
+	return d
+}
+
+func (d *PDFDocument) newPage() {
+	d.pages = append(d.pages, []pdfLine{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+func (d *PDFDocument) addLine(font string, size float64, text string) {
+	lineHeight := size * 1.5
+	if d.y-lineHeight < pdfMargin {
+		d.newPage()
+	}
+	d.y -= lineHeight
+	page := len(d.pages) - 1
+	d.pages[page] = append(d.pages[page], pdfLine{font: font, size: size, y: d.y, text: text})
+}
+
+// Title adiciona um título em destaque
+func (d *PDFDocument) Title(text string) {
+	d.addLine("F2", 16, text)
+}
+
+// Heading adiciona um subtítulo em negrito
+func (d *PDFDocument) Heading(text string) {
+	d.addLine("F2", 12, text)
+}
+
+// Text adiciona uma linha de texto comum
+func (d *PDFDocument) Text(text string) {
+	d.addLine("F1", 10, text)
+}
+
+// Space adiciona uma linha em branco
+func (d *PDFDocument) Space() {
+	d.y -= 10
+}
+
+// Bytes serializa o documento no formato PDF 1.4
+func (d *PDFDocument) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	writeObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objetos fixos: catálogo (1), árvore de páginas (2) e fontes (3 e 4).
+	// Cada página usa dois objetos: a página em si e o seu conteúdo.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range d.pages {
+		var content bytes.Buffer
+		for _, line := range lines {
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+				line.font, line.size, pdfMargin, line.y, pdfEscape(line.text))
+		}
+
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+i*2))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	return buf.Bytes(), nil
+}
+
+// pdfEscape converte o texto para WinAnsi (Latin-1) e escapa os caracteres especiais do PDF
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// FormatBRL formata um valor no padrão monetário brasileiro (ex.: R$ 1.234,56)
+func FormatBRL(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	cents := int64(math.Round(amount * 100))
+	integer := fmt.Sprintf("%d", cents/100)
+
+	// Inserir separador de milhar a cada três dígitos
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return fmt.Sprintf("%sR$ %s,%02d", sign, grouped.String(), cents%100)
+}
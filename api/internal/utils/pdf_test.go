@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatBRL(t *testing.T) {
+	cases := map[float64]string{
+		0:          "R$ 0,00",
+		1234.56:    "R$ 1.234,56",
+		999.999:    "R$ 1.000,00",
+		1000000:    "R$ 1.000.000,00",
+		-50.5:      "-R$ 50,50",
+		123456.789: "R$ 123.456,79",
+		5.5:        "R$ 5,50",
+		0.005:      "R$ 0,01",
+		1234567.8:  "R$ 1.234.567,80",
+	}
+	for amount, expected := range cases {
+		assert.Equal(t, expected, FormatBRL(amount))
+	}
+}
+
+func TestPDFDocumentPagination(t *testing.T) {
+	doc := NewPDFDocument()
+	for i := 0; i < 200; i++ {
+		doc.Text("Linha (com parênteses)")
+	}
+
+	pdf, err := doc.Bytes()
+	require.NoError(t, err)
+
+	content := string(pdf)
+	assert.True(t, strings.HasSuffix(content, "%%EOF\n"))
+	assert.Contains(t, content, `Linha \(com par`)
+	assert.Greater(t, strings.Count(content, "/Type /Page "), 1, "O conteúdo longo deve ocupar várias páginas")
+}
@@ -1,19 +1,42 @@
 package utils
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
+	"unicode"
 )
 
 var (
-	cpfRegex  = regexp.MustCompile(`^\d{3}\.\d{3}\.\d{3}-\d{2}$`)
-	cnpjRegex = regexp.MustCompile(`^\d{2}\.\d{3}\.\d{3}/\d{4}-\d{2}$`)
+	cpfRegex         = regexp.MustCompile(`^\d{3}\.\d{3}\.\d{3}-\d{2}$`)
+	cnpjRegex        = regexp.MustCompile(`^\d{2}\.\d{3}\.\d{3}/\d{4}-\d{2}$`)
+	metadataKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 )
 
+// Limites dos metadados livres associados a uma doação
+const (
+	MaxMetadataKeys        = 10
+	MaxMetadataKeyLength   = 40
+	MaxMetadataValueLength = 200
+)
+
+// hashSalt é o salt configurado na inicialização (ver SetHashSalt)
+var hashSalt string
+
+// SetHashSalt define o salt usado por HashSensitiveData, substituindo a leitura de HASH_SALT
+func SetHashSalt(salt string) {
+	hashSalt = salt
+}
+
 // HashSensitiveData aplica SHA-256 com salt em dados sensíveis como CPF/CNPJ
 func HashSensitiveData(data string, prefixaConsulta bool) string {
 	// Se o dado estiver vazio, retorne vazio
@@ -24,8 +47,11 @@ func HashSensitiveData(data string, prefixaConsulta bool) string {
 	// Remover caracteres não numéricos para uniformização
 	cleanData := strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(data, ".", ""), "-", ""), "/", "")
 
-	// Obter salt da variável de ambiente ou usar valor padrão
-	salt := os.Getenv("HASH_SALT")
+	// Obter salt configurado, da variável de ambiente ou usar valor padrão
+	salt := hashSalt
+	if salt == "" {
+		salt = os.Getenv("HASH_SALT")
+	}
 	if salt == "" {
 		salt = "levitate-default-salt" // Em produção, usar um valor mais seguro
 		log.Println("AVISO: HASH_SALT não está definido, usando salt padrão. NÃO use em produção!")
@@ -58,10 +84,102 @@ func HashSensitiveData(data string, prefixaConsulta bool) string {
 	return hashString
 }
 
-// ValidateCPF verifica se o formato do CPF está correto antes de anonimizar
+// encryptionKey é o segredo configurado na inicialização (ver SetEncryptionKey)
+var (
+	encryptionKey         string
+	encryptionWarningOnce sync.Once
+)
+
+// SetEncryptionKey define o segredo usado por EncryptSensitiveData e DecryptSensitiveData,
+// substituindo a leitura de DATA_ENCRYPTION_KEY
+func SetEncryptionKey(key string) {
+	encryptionKey = key
+}
+
+// dataCipher retorna o AES-256-GCM com a chave derivada (SHA-256) do segredo configurado
+func dataCipher() (cipher.AEAD, error) {
+	key := encryptionKey
+	if key == "" {
+		key = os.Getenv("DATA_ENCRYPTION_KEY")
+	}
+	if key == "" {
+		key = "levitate-default-encryption-key" // Em produção, DATA_ENCRYPTION_KEY é obrigatório
+		encryptionWarningOnce.Do(func() {
+			log.Println("AVISO: DATA_ENCRYPTION_KEY não está definido, usando chave padrão. NÃO use em produção!")
+		})
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptSensitiveData cifra dados que precisam ser recuperados depois, como chaves privadas,
+// com AES-256-GCM. O resultado, em hexadecimal, leva o nonce aleatório à frente.
+func EncryptSensitiveData(plaintext []byte) (string, error) {
+	aead, err := dataCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// ErrDecryptionFailed indica um dado cifrado corrompido ou cifrado com outra chave
+var ErrDecryptionFailed = errors.New("falha ao decifrar: dado corrompido ou chave de criptografia diferente")
+
+// DecryptSensitiveData decifra um valor produzido por EncryptSensitiveData
+func DecryptSensitiveData(ciphertext string) ([]byte, error) {
+	aead, err := dataCipher()
+	if err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(ciphertext)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, ErrDecryptionFailed
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+// ValidateCPF verifica o formato do CPF (com ou sem pontuação) e seus dígitos verificadores
 func ValidateCPF(cpf string) bool {
-	return cpfRegex.MatchString(cpf) ||
-		(len(cpf) == 11 && regexp.MustCompile(`^\d{11}$`).MatchString(cpf))
+	if !cpfRegex.MatchString(cpf) && !(len(cpf) == 11 && regexp.MustCompile(`^\d{11}$`).MatchString(cpf)) {
+		return false
+	}
+
+	digits := strings.NewReplacer(".", "", "-", "").Replace(cpf)
+
+	// Sequências de dígitos repetidos passam no cálculo, mas não são CPFs válidos
+	if strings.Count(digits, digits[:1]) == len(digits) {
+		return false
+	}
+
+	// Cada dígito verificador é calculado sobre os dígitos anteriores, com pesos decrescentes
+	for position := 9; position <= 10; position++ {
+		sum := 0
+		for i := 0; i < position; i++ {
+			sum += int(digits[i]-'0') * (position + 1 - i)
+		}
+
+		verificationDigit := 0
+		if remainder := sum % 11; remainder >= 2 {
+			verificationDigit = 11 - remainder
+		}
+		if int(digits[position]-'0') != verificationDigit {
+			return false
+		}
+	}
+	return true
 }
 
 // ValidateCNPJ verifica se o formato do CNPJ está correto antes de anonimizar
@@ -69,3 +187,35 @@ func ValidateCNPJ(cnpj string) bool {
 	return cnpjRegex.MatchString(cnpj) ||
 		(len(cnpj) == 14 && regexp.MustCompile(`^\d{14}$`).MatchString(cnpj))
 }
+
+// SanitizeMetadata valida os metadados livres de uma doação: limita a quantidade de chaves,
+// restringe as chaves a letras, dígitos, "_" e "-" e remove caracteres de controle dos valores
+func SanitizeMetadata(metadata map[string]string) (map[string]string, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	if len(metadata) > MaxMetadataKeys {
+		return nil, fmt.Errorf("metadados excedem o limite de %d chaves", MaxMetadataKeys)
+	}
+
+	sanitized := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		key = strings.TrimSpace(key)
+		if len(key) > MaxMetadataKeyLength || !metadataKeyRegex.MatchString(key) {
+			return nil, fmt.Errorf("chave de metadado inválida: %q", key)
+		}
+
+		value = strings.TrimSpace(strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, value))
+		if len(value) > MaxMetadataValueLength {
+			return nil, fmt.Errorf("valor do metadado %q excede %d caracteres", key, MaxMetadataValueLength)
+		}
+
+		sanitized[key] = value
+	}
+	return sanitized, nil
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeMetadata(t *testing.T) {
+	sanitized, err := SanitizeMetadata(map[string]string{" crm_id ": "  ABC-123\n\t", "origem": "parceiro"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"crm_id": "ABC-123", "origem": "parceiro"}, sanitized)
+
+	_, err = SanitizeMetadata(map[string]string{"chave inválida": "x"})
+	assert.Error(t, err)
+
+	_, err = SanitizeMetadata(map[string]string{"nota": strings.Repeat("a", MaxMetadataValueLength+1)})
+	assert.Error(t, err)
+
+	tooMany := map[string]string{}
+	for i := 0; i <= MaxMetadataKeys; i++ {
+		tooMany[fmt.Sprintf("k%d", i)] = "v"
+	}
+	_, err = SanitizeMetadata(tooMany)
+	assert.Error(t, err)
+}
+
+func TestValidateCPF(t *testing.T) {
+	tests := []struct {
+		cpf   string
+		valid bool
+	}{
+		{"529.982.247-25", true},
+		{"52998224725", true},
+		{"111.444.777-35", true},
+		{"529.982.247-24", false}, // segundo dígito verificador incorreto
+		{"529.982.247-15", false}, // primeiro dígito verificador incorreto
+		{"111.111.111-11", false}, // todos os dígitos iguais
+		{"00000000000", false},
+		{"12345678900", false},
+		{"5299822472", false},
+		{"529.982.24725", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, ValidateCPF(tt.cpf), tt.cpf)
+	}
+}
+
+func TestEncryptSensitiveDataRoundTrip(t *testing.T) {
+	SetEncryptionKey("segredo-de-teste-com-32-caracteres!")
+	t.Cleanup(func() { SetEncryptionKey("") })
+
+	ciphertext, err := EncryptSensitiveData([]byte("chave privada"))
+	require.NoError(t, err)
+	assert.NotContains(t, ciphertext, fmt.Sprintf("%x", "chave privada"), "O texto claro não deve aparecer no valor cifrado")
+
+	again, err := EncryptSensitiveData([]byte("chave privada"))
+	require.NoError(t, err)
+	assert.NotEqual(t, ciphertext, again, "Cada cifragem usa um nonce novo")
+
+	plaintext, err := DecryptSensitiveData(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "chave privada", string(plaintext))
+
+	// Com outra chave, ou com o valor adulterado, a decifragem falha
+	SetEncryptionKey("outro-segredo-com-mais-de-32-caracteres")
+	_, err = DecryptSensitiveData(ciphertext)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = DecryptSensitiveData("nao-hexadecimal")
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
@@ -1,63 +1,176 @@
 package routes
 
 import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"trackable-donations/api/internal/auth"
+	"trackable-donations/api/internal/config"
 	"trackable-donations/api/internal/controllers"
 	"trackable-donations/api/internal/middleware"
+	"trackable-donations/api/internal/repository"
 	"trackable-donations/api/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AdminMiddleware middleware para verificar se o usuário é um administrador
-func AdminMiddleware() gin.HandlerFunc {
+// APIV1Prefix é o prefixo da versão atual da API
+const APIV1Prefix = "/api/v1"
+
+// DeprecatedRouteMiddleware sinaliza que a rota é um alias legado e indica o caminho versionado equivalente
+func DeprecatedRouteMiddleware(successorPrefix string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Em um sistema real, verificaria o token JWT para confirmar se é um administrador
-		// Aqui, apenas verificamos se existe um header específico
-		adminID := c.GetHeader("X-Admin-ID")
-		if adminID == "" {
-			c.JSON(401, gin.H{"error": "Acesso não autorizado"})
-			c.Abort()
-			return
-		}
+		c.Header("Deprecation", "true")
+		c.Header("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", successorPrefix, c.Request.URL.Path))
 		c.Next()
 	}
 }
 
-// SetupRoutes configura todas as rotas da API
-func SetupRoutes(router *gin.Engine, publicRateLimiter, adminRateLimiter *middleware.RateLimiter) {
+// SetupRoutes configura todas as rotas da API sobre os dados do armazenamento informado
+// e retorna os jobs em segundo plano iniciados, que devem ser fechados no desligamento do servidor.
+// verifyRateLimiter limita, além do limite público, a reverificação de doações no explorador.
+func SetupRoutes(router *gin.Engine, cfg config.Config, store *repository.Store, publicRateLimiter, adminRateLimiter, verifyRateLimiter middleware.Limiter) ([]io.Closer, error) {
 	// Configurar serviços
-	donationService := services.NewDonationService()
-	controllers.SetupExpenseService(donationService)
+	donationService, err := services.NewDonationServiceWithStore(store)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SeedDemoData {
+		if err := donationService.Seed(); err != nil {
+			return nil, err
+		}
+	}
+	donationService.SetPublicMetadataKeys(cfg.PublicMetadataKeys)
+	donationService.SetExchangeRateProvider(services.FixedExchangeRates(cfg.ExchangeRates))
+	donationService.SetDonationLimits(services.DonationLimits{Min: cfg.MinDonationAmount, Max: cfg.MaxDonationAmount})
+
+	// O banco é a única dependência crítica: sem IPFS ou o nó da blockchain, a API
+	// continua atendendo consultas e o /health apenas sinaliza "degraded"
+	probes := []services.HealthProbe{{Name: "database", Critical: true, Check: store.Ping}}
+	if cfg.IPFSAPIURL != "" {
+		ipfsClient := services.NewHTTPIPFSClient(cfg.IPFSAPIURL)
+		donationService.SetIPFSClient(ipfsClient)
+		probes = append(probes, services.HealthProbe{Name: "ipfs", Check: ipfsClient.Ping})
+	}
+	if cfg.BlockchainNodeURL != "" {
+		probes = append(probes, services.HealthProbe{
+			Name:  "blockchain_node",
+			Check: services.HTTPHealthCheck(strings.TrimRight(cfg.BlockchainNodeURL, "/") + "/health"),
+		})
+	}
+	controllers.SetupHealthChecker(services.NewHealthChecker(cfg.HealthCheckTimeout, probes...))
+
+	// Enviar as notificações por e-mail quando houver um servidor SMTP configurado
+	var notifier services.Notifier = services.LogNotifier{}
+	if cfg.SMTPHost != "" {
+		notifier = services.NewSMTPNotifier(services.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	}
+	donationService.SetNotifier(notifier)
+
+	controllers.SetupDonationService(donationService)
+	controllers.SetupExpenseService(donationService, cfg.MaxExpensesPerDonation)
 	controllers.SetupTransparencyService(donationService, controllers.ExpenseService)
 	controllers.SetupAdminService(donationService, controllers.ExpenseService)
-	controllers.SetupPublicServices(donationService, controllers.ExpenseService)
+	controllers.SetupPublicServices(donationService, controllers.ExpenseService, cfg.DashboardCacheTTL)
+	controllers.SetAllowedUploadTypes(cfg.AllowedUploadTypes)
+	controllers.SetMaxUploadSize(cfg.MaxUploadSize)
+
+	if cfg.PaymentWebhookSecret == "" {
+		log.Println("PAYMENT_WEBHOOK_SECRET não definido: webhooks de pagamento serão rejeitados")
+	}
+	controllers.SetupPaymentWebhook(donationService, cfg.PaymentWebhookSecret)
+
+	// Autenticação dos administradores por token JWT
+	if cfg.JWTSecret == "" {
+		log.Println("JWT_SECRET não definido: usando um segredo aleatório (tokens de admin expiram ao reiniciar)")
+	}
+	authManager := auth.NewManager(cfg.JWTSecret, cfg.AdminTokenTTL, cfg.AdminUsers)
+	controllers.SetupAuth(authManager)
 
-	// Rota de verificação de saúde sem rate limiting
+	// Lembrar doadores de pagamentos pendentes (uma única vez por doação)
+	reminderJob := services.NewPaymentReminderJob(donationService, notifier,
+		cfg.PaymentReminderAfter, cfg.PendingDonationTTL, cfg.PaymentReminderInterval)
+	reminderJob.Start()
+
+	// Expirar as doações pendentes abandonadas
+	expiryJob := services.NewPendingDonationExpiryJob(donationService, cfg.PendingDonationTTL, cfg.PendingExpiryInterval)
+	expiryJob.Start()
+
+	// Gerar as cobranças das doações recorrentes vencidas
+	recurringJob := services.NewRecurringDonationJob(donationService, cfg.RecurringDonationInterval)
+	recurringJob.Start()
+
+	// Rotas de verificação de saúde sem rate limiting; /health/live e /health/ready
+	// separam a verificação de vida da de prontidão para o Kubernetes
 	router.GET("/health", controllers.HealthCheck)
+	router.GET("/health/live", controllers.LivenessCheck)
+	router.GET("/health/ready", controllers.HealthCheck)
+
+	// Página de teste do Swagger (fora do versionamento, como a própria documentação)
+	router.GET("/swagger-test", publicRateLimiter.RateLimit(), controllers.SwaggerUITest)
+
+	// Rotas versionadas
+	registerAPIRoutes(router.Group(APIV1Prefix), authManager, !cfg.IsProduction(), publicRateLimiter, adminRateLimiter, verifyRateLimiter)
+
+	// Aliases legados na raiz, mantidos durante a transição para /api/v1
+	legacyRoutes := router.Group("/")
+	legacyRoutes.Use(DeprecatedRouteMiddleware(APIV1Prefix))
+	registerAPIRoutes(legacyRoutes, authManager, !cfg.IsProduction(), publicRateLimiter, adminRateLimiter, verifyRateLimiter)
+
+	return []io.Closer{reminderJob, expiryJob, recurringJob, controllers.AdminService}, nil
+}
 
+// registerAPIRoutes registra as rotas públicas e administrativas da API no grupo informado.
+// A confirmação simulada de pagamentos só é registrada com mockPayments (fora de produção).
+func registerAPIRoutes(group *gin.RouterGroup, authManager *auth.Manager, mockPayments bool, publicRateLimiter, adminRateLimiter, verifyRateLimiter middleware.Limiter) {
 	// Rotas públicas com rate limiting
-	publicRoutes := router.Group("/")
+	publicRoutes := group.Group("/")
 	publicRoutes.Use(publicRateLimiter.RateLimit())
 	{
 		// Rotas para ONGs
 		publicRoutes.GET("/ngos", controllers.ListNGOs)
 		publicRoutes.GET("/ngos/:id", controllers.GetNGOByID)
+		publicRoutes.GET("/ngos/:id/campaigns", controllers.ListCampaigns)
+		publicRoutes.POST("/ngos/:id/campaigns", controllers.CreateCampaign)
+		publicRoutes.GET("/campaigns/:id/donations", controllers.GetCampaignDonations)
 
 		// Rotas para doações
 		publicRoutes.POST("/donations", controllers.CreateDonation)
-		publicRoutes.POST("/donations/:id/confirm-payment", controllers.ConfirmPayment)
+		publicRoutes.POST("/webhooks/payment", controllers.ReceivePaymentWebhook)
+		publicRoutes.POST("/donations/recurring", controllers.CreateRecurringDonation)
+		publicRoutes.POST("/donations/recurring/:id/pause", controllers.PauseRecurringDonation)
+		publicRoutes.POST("/donations/recurring/:id/resume", controllers.ResumeRecurringDonation)
 
 		// Rotas para rastreamento de doações
 		publicRoutes.GET("/donations/:id/receipt", controllers.GetDonationReceipt)
+		publicRoutes.GET("/donations/:id/receipt/preview", controllers.PreviewDonationReceipt)
+		publicRoutes.GET("/donations/:id/receipt/pdf", controllers.GetDonationReceiptPDF)
 		publicRoutes.GET("/donations/:id/usages", controllers.GetResourceUsagesByDonation)
+		publicRoutes.GET("/donations/:id/balance", controllers.GetDonationBalance)
+
+		// Rotas para usuários (doadores)
+		// O cadastro revela se o e-mail já existe, então segue também o limite mais restrito do admin
+		publicRoutes.POST("/users", adminRateLimiter.RateLimit(), controllers.RegisterUser)
 
 		// Rotas para doadores
 		publicRoutes.GET("/donors/:id/donations", controllers.GetDonationsByDonor)
 		publicRoutes.GET("/donors/:id/dashboard", controllers.GetDonorDashboard)
+		publicRoutes.GET("/donors/:id/donations/export", controllers.ExportDonorHistory)
+		publicRoutes.GET("/donors/:id/history/pdf", controllers.GetDonorHistoryPDF)
+		publicRoutes.GET("/donors/:id/impact-projection", controllers.GetDonorImpactProjection)
 
 		// Rotas para despesas
 		publicRoutes.POST("/expenses", controllers.RegisterExpense)
+		publicRoutes.POST("/expenses/pool", controllers.RegisterPoolExpense)
 		publicRoutes.POST("/expenses/:id/receipt", controllers.UploadReceipt)
+		publicRoutes.GET("/expenses/:id/funding", controllers.GetExpenseFunding)
 		publicRoutes.GET("/expenses/donation/:donationId", controllers.GetExpensesByDonation)
 		publicRoutes.GET("/expenses/ngo/:ngoId", controllers.GetExpensesByNGO)
 
@@ -65,8 +178,13 @@ func SetupRoutes(router *gin.Engine, publicRateLimiter, adminRateLimiter *middle
 		publicRoutes.GET("/transparency", controllers.GetPublicDashboard)
 		publicRoutes.GET("/transparency/donations", controllers.GetPublicDonations)
 		publicRoutes.GET("/transparency/expenses", controllers.GetPublicExpenses)
+		publicRoutes.GET("/transparency/donations.csv", controllers.ExportPublicDonationsCSV)
+		publicRoutes.GET("/transparency/expenses.csv", controllers.ExportPublicExpensesCSV)
+		publicRoutes.GET("/transparency/score", controllers.GetPlatformTransparencyScore)
+		publicRoutes.GET("/transparency/schema", controllers.GetTransparencySchema)
 		publicRoutes.GET("/transparency/ngos", controllers.GetPublicNGOsSummary)
 		publicRoutes.GET("/transparency/ngos/:id", controllers.GetPublicNGOSummary)
+		publicRoutes.GET("/transparency/ngos/:id/contact", controllers.GetPublicNGOContact)
 		publicRoutes.GET("/transparency/ngos/:id/donations", controllers.GetPublicNGODonations)
 		publicRoutes.GET("/transparency/ngos/:id/expenses", controllers.GetPublicNGOExpenses)
 
@@ -74,6 +192,8 @@ func SetupRoutes(router *gin.Engine, publicRateLimiter, adminRateLimiter *middle
 		publicRoutes.GET("/explorer/search", controllers.SearchDonations)
 		publicRoutes.GET("/explorer/donations/hash/:hash", controllers.GetDonationByHash)
 		publicRoutes.GET("/explorer/donations/:id", controllers.GetDonationByID)
+		publicRoutes.GET("/explorer/donations/:id/trace", controllers.GetDonationTrace)
+		publicRoutes.GET("/explorer/donations/:id/verify", verifyRateLimiter.RateLimit(), controllers.VerifyDonation)
 		publicRoutes.GET("/explorer/donations/ngo/:ngo_id", controllers.GetDonationsByNGO)
 		publicRoutes.GET("/explorer/donations/recent", controllers.GetRecentDonations)
 
@@ -81,25 +201,60 @@ func SetupRoutes(router *gin.Engine, publicRateLimiter, adminRateLimiter *middle
 		publicRoutes.GET("/dashboard/global", controllers.GetGlobalDashboard)
 		publicRoutes.GET("/dashboard/by-date-range", controllers.GetDashboardByDateRange)
 		publicRoutes.GET("/dashboard/by-category/:category", controllers.GetDashboardByCategory)
+		publicRoutes.GET("/dashboard/retention", controllers.GetDonorRetention)
+		publicRoutes.GET("/dashboard/categories", controllers.GetActiveCategories)
+		publicRoutes.GET("/dashboard/category-timeseries", controllers.GetCategoryTimeSeries)
+		publicRoutes.GET("/dashboard/top-donors", controllers.GetTopDonors)
+		publicRoutes.GET("/dashboard/ngos/:id/campaigns", controllers.GetNGOCampaignDashboard)
+		publicRoutes.GET("/categories", controllers.GetCategories)
+	}
+
+	// Login dos administradores (público, com o rate limiting mais restrito das rotas de admin)
+	group.POST("/admin/login", adminRateLimiter.RateLimit(), controllers.AdminLogin)
+
+	// Estorno de doações: fica junto das rotas de doações, mas exige um administrador
+	group.POST("/donations/:id/refund", adminRateLimiter.RateLimit(), middleware.AdminAuth(authManager), controllers.RefundDonation)
 
-		// Rotas para teste do Swagger
-		publicRoutes.GET("/swagger-test", controllers.SwaggerUITest)
+	// Os dados cadastrais do doador incluem o e-mail: apenas administradores os consultam
+	group.GET("/users/:id", adminRateLimiter.RateLimit(), middleware.AdminAuth(authManager), controllers.GetUserByID)
+
+	// Confirmação simulada de pagamento, sem prova do gateway: apenas para administradores e
+	// fora de produção. Em produção, os pagamentos são confirmados pelo webhook assinado.
+	if mockPayments {
+		group.POST("/donations/:id/confirm-payment", adminRateLimiter.RateLimit(), middleware.AdminAuth(authManager), controllers.ConfirmPayment)
 	}
 
-	// Rotas para administração (protegidas por middleware e com rate limiting mais restrito)
-	adminRoutes := router.Group("/admin")
-	adminRoutes.Use(AdminMiddleware())
+	// Rotas para administração (protegidas por token JWT e com rate limiting mais restrito)
+	adminRoutes := group.Group("/admin")
 	adminRoutes.Use(adminRateLimiter.RateLimit())
+	adminRoutes.Use(middleware.AdminAuth(authManager))
 	{
 		// Cadastro e gestão de ONGs
 		adminRoutes.POST("/ngos/register", controllers.RegisterNGO)
 		adminRoutes.POST("/ngos/registration/:id/validate-cnpj", controllers.ValidateCNPJ)
 		adminRoutes.POST("/ngos/registration/:id/upload-documents", controllers.UploadNGODocuments)
+		adminRoutes.GET("/ngos/registration/:id/documents", controllers.GetNGODocuments)
 		adminRoutes.POST("/ngos/registration/:id/approve", controllers.ApproveNGO)
 		adminRoutes.POST("/ngos/registration/:id/reject", controllers.RejectNGO)
+		adminRoutes.POST("/ngos/registration/:id/reopen", controllers.ReopenNGORegistration)
 		adminRoutes.GET("/ngos/registrations", controllers.GetNGORegistrations)
+		adminRoutes.GET("/ngos/registrations/pending", controllers.GetPendingNGORegistrations)
 		adminRoutes.GET("/ngos/registrations/:id", controllers.GetNGORegistrationByID)
+		adminRoutes.GET("/ngos/registrations/:id/webhook-deliveries", controllers.GetWebhookDeliveries)
 		adminRoutes.GET("/ngos/registrations/by-cnpj", controllers.GetNGORegistrationsByCNPJ)
+		adminRoutes.POST("/ngos/merge", controllers.MergeNGOs)
+		adminRoutes.PUT("/ngos/:id", controllers.UpdateNGO)
+		adminRoutes.POST("/ngos/:id/suspend", controllers.SuspendNGO)
+		adminRoutes.POST("/ngos/:id/api-key", controllers.IssueNGOAPIKey)
+
+		// Visão completa das doações (todos os status)
+		adminRoutes.GET("/donations", controllers.ListDonations)
+		adminRoutes.POST("/donations/import", controllers.ImportDonations)
+
+		// Revisão de despesas
+		adminRoutes.GET("/expenses/pending", controllers.GetPendingExpenses)
+		adminRoutes.POST("/expenses/:id/approve", controllers.ApproveExpense)
+		adminRoutes.POST("/expenses/:id/reject", controllers.RejectExpense)
 
 		// Auditoria
 		adminRoutes.POST("/audit", controllers.AuditEntity)
@@ -0,0 +1,174 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"trackable-donations/api/internal/auth"
+	"trackable-donations/api/internal/config"
+	"trackable-donations/api/internal/middleware"
+	"trackable-donations/api/internal/models"
+	"trackable-donations/api/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func setupTestRouter() *gin.Engine {
+	return setupTestRouterWithConfig(func(*config.Config) {})
+}
+
+// setupTestRouterWithConfig monta o roteador permitindo ajustar a configuração de teste
+func setupTestRouterWithConfig(configure func(*config.Config)) *gin.Engine {
+	return setupTestRouterWithVerifyLimiter(configure, middleware.NewRateLimiter(1000, time.Minute))
+}
+
+// setupTestRouterWithVerifyLimiter monta o roteador com um limitador próprio para a reverificação de doações
+func setupTestRouterWithVerifyLimiter(configure func(*config.Config), verifyRateLimiter middleware.Limiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	rateLimiter := middleware.NewRateLimiter(1000, time.Minute)
+	cfg := config.Config{
+		PaymentReminderAfter:      time.Hour,
+		PendingDonationTTL:        24 * time.Hour,
+		PendingExpiryInterval:     time.Hour,
+		PaymentReminderInterval:   time.Hour,
+		RecurringDonationInterval: time.Hour,
+		JWTSecret:                 "segredo-de-teste-com-32-caracteres!",
+		AdminTokenTTL:             time.Hour,
+		SeedDemoData:              true,
+	}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha-forte"), bcrypt.MinCost)
+	cfg.AdminUsers = []auth.Admin{{ID: 5, Username: "admin", PasswordHash: string(hash)}}
+	configure(&cfg)
+	if _, err := SetupRoutes(router, cfg, repository.NewMemoryStore(), rateLimiter, rateLimiter, verifyRateLimiter); err != nil {
+		panic(err)
+	}
+	return router
+}
+
+func TestVersionedAndLegacyRoutesResolve(t *testing.T) {
+	router := setupTestRouter()
+
+	versioned := httptest.NewRecorder()
+	router.ServeHTTP(versioned, httptest.NewRequest(http.MethodGet, "/api/v1/ngos", nil))
+	assert.Equal(t, http.StatusOK, versioned.Code)
+	assert.Empty(t, versioned.Header().Get("Deprecation"))
+
+	legacy := httptest.NewRecorder()
+	router.ServeHTTP(legacy, httptest.NewRequest(http.MethodGet, "/ngos", nil))
+	assert.Equal(t, http.StatusOK, legacy.Code)
+	assert.Equal(t, "true", legacy.Header().Get("Deprecation"))
+	assert.Contains(t, legacy.Header().Get("Link"), "</api/v1/ngos>")
+	assert.Equal(t, versioned.Body.String(), legacy.Body.String())
+
+	admin := httptest.NewRecorder()
+	router.ServeHTTP(admin, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit/logs", nil))
+	assert.Equal(t, http.StatusUnauthorized, admin.Code, "Rotas administrativas versionadas continuam protegidas")
+}
+
+func TestVerifyDonationHasItsOwnRateLimit(t *testing.T) {
+	router := setupTestRouterWithVerifyLimiter(func(*config.Config) {}, middleware.NewRateLimiter(1, time.Minute))
+
+	statuses := make([]int, 0, 2)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIV1Prefix+"/explorer/donations/9999/verify", nil))
+		statuses = append(statuses, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusNotFound, http.StatusTooManyRequests}, statuses)
+
+	// As demais rotas públicas seguem apenas o limite público
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIV1Prefix+"/explorer/donations/9999", nil))
+	assert.NotEqual(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestSeedDemoDataControlsInitialNGOs(t *testing.T) {
+	listNGOs := func(router *gin.Engine) []models.NGO {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/ngos", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data []models.NGO `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Data
+	}
+
+	assert.Len(t, listNGOs(setupTestRouter()), 3)
+
+	unseeded := setupTestRouterWithConfig(func(cfg *config.Config) { cfg.SeedDemoData = false })
+	assert.Empty(t, listNGOs(unseeded), "Sem SEED_DEMO_DATA, só ONGs aprovadas aparecem")
+}
+
+func TestAdminRoutesRequireLoginToken(t *testing.T) {
+	router := setupTestRouter()
+
+	login := func(password string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		body := `{"username":"admin","password":"` + password + `"}`
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/login", strings.NewReader(body)))
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, login("errada").Code)
+
+	w := login("senha-forte")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response models.AdminLoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Bearer", response.TokenType)
+
+	// O antigo header X-Admin-ID não dá mais acesso
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit/logs", nil)
+	req.Header.Set("X-Admin-ID", "1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit/logs", nil)
+	req.Header.Set("Authorization", "Bearer "+response.Token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestConfirmPaymentRequiresAdminOutsideProduction(t *testing.T) {
+	router := setupTestRouter()
+
+	// Sem token não é possível confirmar uma doação pendente
+	for _, path := range []string{"/api/v1/donations/1/confirm-payment", "/donations/1/confirm-payment"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/donations/1/confirm-payment", nil)
+	req.Header.Set("Authorization", "Bearer token-invalido")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Em produção a rota nem é registrada: os pagamentos são confirmados pelo webhook assinado
+	production := setupTestRouterWithConfig(func(cfg *config.Config) { cfg.Env = "production" })
+	w = httptest.NewRecorder()
+	production.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/donations/1/confirm-payment", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetUserByIDRequiresAdmin(t *testing.T) {
+	router := setupTestRouter()
+
+	for _, path := range []string{"/api/v1/users/1", "/users/1"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+		assert.NotContains(t, w.Body.String(), "@example.com")
+	}
+}
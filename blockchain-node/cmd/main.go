@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+	"trackable-donations/blockchain-node/core"
+	"trackable-donations/blockchain-node/network"
+)
+
+func main() {
+	blockchain := core.NewBlockchain()
+
+	// Intervalo alvo entre blocos (ex.: "10s", "1m")
+	if target := os.Getenv("BLOCK_TIME_TARGET"); target != "" {
+		duration, err := time.ParseDuration(target)
+		if err != nil || duration <= 0 {
+			log.Fatalf("BLOCK_TIME_TARGET inválido: %q", target)
+		}
+		blockchain.BlockTimeTarget = duration
+	}
+
+	// Quantidade de blocos entre halvings da recompensa (zero desativa o halving)
+	if interval := os.Getenv("BLOCK_HALVING_INTERVAL"); interval != "" {
+		blocks, err := strconv.Atoi(interval)
+		if err != nil || blocks < 0 {
+			log.Fatalf("BLOCK_HALVING_INTERVAL inválido: %q", interval)
+		}
+		blockchain.HalvingInterval = blocks
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8545"
+	}
+
+	server := network.NewServer(blockchain)
+
+	// Endereço que recebe as recompensas dos blocos minerados por este nó
+	if address := os.Getenv("NODE_ADDRESS"); address != "" {
+		server.SetMinerAddress(address)
+	}
+
+	log.Printf("Nó da blockchain iniciando na porta %s...", port)
+	if err := server.Router().Run(":" + port); err != nil {
+		log.Fatalf("Falha ao iniciar o nó: %v", err)
+	}
+}
@@ -1,5 +1,12 @@
 package core
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
 // Definição da struct Transaction
 type Transaction struct {
 	ID        string  `json:"id"`
@@ -7,12 +14,63 @@ type Transaction struct {
 	Sender    string  `json:"sender"`
 	Receiver  string  `json:"receiver"`
 	Timestamp string  `json:"timestamp"`
+	// Nonce é um valor aleatório assinado junto com a transação (ver Sign), que a torna
+	// única: uma transação reenviada com o mesmo remetente e nonce é recusada
+	Nonce string `json:"nonce,omitempty"`
+	// Signature é a assinatura ECDSA do remetente (ver Sign); a coinbase não é assinada
+	Signature string `json:"signature,omitempty"`
 }
 
 type Block struct {
 	Index        int           `json:"index"`
 	Timestamp    string        `json:"timestamp"`
 	Transactions []Transaction `json:"transactions"`
-	Proof        int           `json:"proof"`
-	PreviousHash string        `json:"previous_hash"`
+	// MerkleRoot resume as transações do bloco (ver MerkleRoot) e entra no hash do bloco
+	MerkleRoot   string `json:"merkle_root"`
+	Proof        int    `json:"proof"`
+	PreviousHash string `json:"previous_hash"`
+}
+
+// Time retorna o horário de criação do bloco
+func (b Block) Time() (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, b.Timestamp)
+}
+
+// Hash retorna o SHA-256 (em hexadecimal) do bloco serializado em JSON. A ordem dos
+// campos segue a da struct, então o resultado é estável e muda se qualquer campo mudar.
+func (b Block) Hash() string {
+	// Block só contém tipos serializáveis, então a serialização não falha
+	data, _ := json.Marshal(b)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// MerkleRoot calcula a raiz da árvore de Merkle das transações: cada folha é o SHA-256 da
+// transação em JSON, e cada nível combina os hashes dois a dois (SHA-256 da concatenação),
+// duplicando o último quando a quantidade é ímpar. Sem transações, é o SHA-256 de um
+// conteúdo vazio. Alterar, incluir, remover ou reordenar transações muda a raiz.
+func MerkleRoot(txs []Transaction) string {
+	if len(txs) == 0 {
+		sum := sha256.Sum256(nil)
+		return hex.EncodeToString(sum[:])
+	}
+
+	level := make([][32]byte, len(txs))
+	for i, tx := range txs {
+		// Transaction só contém tipos serializáveis, então a serialização não falha
+		data, _ := json.Marshal(tx)
+		level[i] = sha256.Sum256(data)
+	}
+
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		next := make([][32]byte, 0, len(level)/2)
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, sha256.Sum256(append(level[i][:], level[i+1][:]...)))
+		}
+		level = next
+	}
+	return hex.EncodeToString(level[0][:])
 }
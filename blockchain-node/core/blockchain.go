@@ -1,25 +1,73 @@
 package core
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultBlockTimeTarget é o intervalo alvo entre blocos usado quando nenhum é configurado
+const DefaultBlockTimeTarget = 10 * time.Second
+
+// DefaultDifficulty é a quantidade de zeros iniciais exigida no hash da prova de trabalho
+const DefaultDifficulty = 4
+
+// Recompensa simbólica por bloco minerado e intervalo (em blocos) entre halvings
+const (
+	DefaultBlockReward     = 50.0
+	DefaultHalvingInterval = 210
+)
+
+// CoinbaseSender identifica o remetente da transação de recompensa de um bloco minerado
+const CoinbaseSender = "0"
+
+// GenesisPreviousHash é o hash anterior do bloco gênesis, que não tem antecessor
+const GenesisPreviousHash = "1"
+
 type Blockchain struct {
 	Chain               []Block       `json:"chain"`
 	CurrentTransactions []Transaction `json:"current_transactions"`
+	// BlockTimeTarget é o tempo médio desejado entre blocos, usado para calibrar a dificuldade
+	BlockTimeTarget time.Duration `json:"-"`
+	// BlockReward é a recompensa inicial por bloco, reduzida pela metade a cada HalvingInterval blocos
+	BlockReward float64 `json:"-"`
+	// HalvingInterval é a quantidade de blocos entre halvings (zero desativa o halving)
+	HalvingInterval int `json:"-"`
+	// Difficulty é a quantidade de zeros iniciais exigida pela prova de trabalho
+	Difficulty int `json:"-"`
+
+	now func() time.Time
 }
 
 func NewBlockchain() *Blockchain {
 	blockchain := &Blockchain{
 		Chain:               []Block{},
 		CurrentTransactions: []Transaction{},
+		BlockTimeTarget:     DefaultBlockTimeTarget,
+		BlockReward:         DefaultBlockReward,
+		HalvingInterval:     DefaultHalvingInterval,
+		Difficulty:          DefaultDifficulty,
+		now:                 time.Now,
 	}
 	// Cria o bloco gênesis
-	blockchain.NewBlock(100, "1")
+	blockchain.NewBlock(100)
 	return blockchain
 }
 
-func (bc *Blockchain) NewBlock(proof int, previousHash string) Block {
+// NewBlock fecha um novo bloco com as transações pendentes, encadeado ao hash do último bloco
+func (bc *Blockchain) NewBlock(proof int) Block {
+	previousHash := GenesisPreviousHash
+	if len(bc.Chain) > 0 {
+		previousHash = bc.LastBlock().Hash()
+	}
+
 	block := Block{
 		Index:        len(bc.Chain) + 1,
-		Timestamp:    "data/hora atual",
+		Timestamp:    bc.now().UTC().Format(time.RFC3339Nano),
 		Transactions: bc.CurrentTransactions,
+		MerkleRoot:   MerkleRoot(bc.CurrentTransactions),
 		Proof:        proof,
 		PreviousHash: previousHash,
 	}
@@ -28,4 +76,243 @@ func (bc *Blockchain) NewBlock(proof int, previousHash string) Block {
 	return block
 }
 
+// MineBlock fecha um novo bloco com as transações pendentes, incluindo como
+// primeira transação a recompensa (coinbase) do minerador
+func (bc *Blockchain) MineBlock(minerAddress string, proof int) Block {
+	coinbase := Transaction{
+		ID:        fmt.Sprintf("coinbase-%d", len(bc.Chain)+1),
+		Amount:    bc.CurrentReward(),
+		Sender:    CoinbaseSender,
+		Receiver:  minerAddress,
+		Timestamp: bc.now().UTC().Format(time.RFC3339Nano),
+	}
+	bc.CurrentTransactions = append([]Transaction{coinbase}, bc.CurrentTransactions...)
+	return bc.NewBlock(proof)
+}
+
+// NewTransaction adiciona uma transação pendente, assinada pelo remetente (ver Sign), e
+// retorna o índice do bloco que a conterá (o próximo a ser minerado). Retorna -1 se a
+// transação for inválida, se a assinatura não conferir ou se ela já estiver pendente ou
+// na cadeia (mesmo remetente e nonce), o que impede reenviar uma transação assinada.
+func (bc *Blockchain) NewTransaction(tx Transaction) int {
+	if tx.Sender == "" || tx.Sender == CoinbaseSender || tx.Receiver == "" || tx.Amount <= 0 {
+		return -1
+	}
+	if !VerifyTransaction(tx) {
+		return -1
+	}
+	if bc.hasTransaction(tx.Sender, tx.Nonce) {
+		return -1
+	}
+
+	index := bc.LastBlock().Index + 1
+	tx.ID = fmt.Sprintf("tx-%d-%d", index, len(bc.CurrentTransactions)+1)
+	bc.CurrentTransactions = append(bc.CurrentTransactions, tx)
+	return index
+}
+
+// hasTransaction informa se já há uma transação do remetente com o nonce informado,
+// pendente ou confirmada
+func (bc *Blockchain) hasTransaction(sender, nonce string) bool {
+	for _, tx := range bc.CurrentTransactions {
+		if tx.Sender == sender && tx.Nonce == nonce {
+			return true
+		}
+	}
+	for _, block := range bc.Chain {
+		for _, tx := range block.Transactions {
+			if tx.Sender == sender && tx.Nonce == nonce {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Balance retorna o saldo do endereço considerando apenas as transações confirmadas
+// (já mineradas em blocos): os valores recebidos menos os enviados. Transações
+// pendentes não contam.
+func (bc *Blockchain) Balance(address string) float64 {
+	var balance float64
+	for _, block := range bc.Chain {
+		for _, transaction := range block.Transactions {
+			if transaction.Receiver == address {
+				balance += transaction.Amount
+			}
+			if transaction.Sender == address {
+				balance -= transaction.Amount
+			}
+		}
+	}
+	return balance
+}
+
+// CurrentReward retorna a recompensa do próximo bloco a ser minerado,
+// reduzida pela metade a cada HalvingInterval blocos após o gênesis
+func (bc *Blockchain) CurrentReward() float64 {
+	// A altura do próximo bloco é o número de blocos já existentes (o gênesis tem altura zero)
+	return bc.rewardAt(len(bc.Chain))
+}
+
+// rewardAt retorna a recompensa do bloco na altura informada (o índice do bloco menos um)
+func (bc *Blockchain) rewardAt(height int) float64 {
+	if bc.HalvingInterval <= 0 {
+		return bc.BlockReward
+	}
+
+	halvings := height / bc.HalvingInterval
+	if halvings >= 64 {
+		return 0
+	}
+	return bc.BlockReward / float64(uint64(1)<<halvings)
+}
+
+// ProofOfWork procura, a partir de zero, a primeira prova válida para o último bloco da cadeia
+func (bc *Blockchain) ProofOfWork(lastProof int) int {
+	lastHash := bc.LastBlock().Hash()
+	proof := 0
+	for !ValidProof(lastProof, proof, lastHash, bc.Difficulty) {
+		proof++
+	}
+	return proof
+}
+
+// ValidProof verifica se o SHA-256 da concatenação de lastProof, proof e lastHash
+// começa com a quantidade de zeros exigida pela dificuldade
+func ValidProof(lastProof, proof int, lastHash string, difficulty int) bool {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d%d%s", lastProof, proof, lastHash)))
+	return strings.HasPrefix(hex.EncodeToString(sum[:]), strings.Repeat("0", difficulty))
+}
+
+// LastBlock retorna o último bloco da cadeia
+func (bc *Blockchain) LastBlock() Block {
+	return bc.Chain[len(bc.Chain)-1]
+}
+
+// AverageBlockTime calcula o tempo médio entre os últimos lastN blocos.
+// Se lastN for menor ou igual a zero, considera a cadeia inteira.
+// Retorna zero quando não há blocos suficientes para calcular um intervalo.
+func (bc *Blockchain) AverageBlockTime(lastN int) time.Duration {
+	if lastN <= 0 || lastN > len(bc.Chain) {
+		lastN = len(bc.Chain)
+	}
+	if lastN < 2 {
+		return 0
+	}
+
+	blocks := bc.Chain[len(bc.Chain)-lastN:]
+	first, err := blocks[0].Time()
+	if err != nil {
+		return 0
+	}
+	last, err := blocks[len(blocks)-1].Time()
+	if err != nil {
+		return 0
+	}
+
+	// A média dos intervalos consecutivos é o intervalo total dividido pelo número de intervalos
+	return last.Sub(first) / time.Duration(lastN-1)
+}
+
+// ValidChain verifica a integridade estrutural da cadeia: índices sequenciais
+// e carimbos de tempo válidos em ordem cronológica
+func (bc *Blockchain) ValidChain() bool {
+	var previous time.Time
+	for i, block := range bc.Chain {
+		if block.Index != i+1 {
+			return false
+		}
+
+		blockTime, err := block.Time()
+		if err != nil || blockTime.Before(previous) {
+			return false
+		}
+		previous = blockTime
+	}
+	return len(bc.Chain) > 0
+}
+
 // Outras funções de validação e consenso
+
+// IsValid verifica a integridade criptográfica da cadeia: todos os blocos devem passar
+// em ValidBlock. Qualquer alteração em um bloco anterior quebra o encadeamento. Uma
+// transação assinada repetida (mesmo remetente e nonce) também invalida a cadeia.
+func (bc *Blockchain) IsValid() bool {
+	seen := make(map[string]bool)
+	for i, block := range bc.Chain {
+		if !bc.ValidBlock(i) {
+			return false
+		}
+		for _, tx := range block.Transactions {
+			if tx.Sender == CoinbaseSender {
+				continue
+			}
+			key := tx.Sender + "|" + tx.Nonce
+			if seen[key] {
+				return false
+			}
+			seen[key] = true
+		}
+	}
+	return len(bc.Chain) > 0
+}
+
+// ValidBlock verifica apenas o bloco na posição informada da cadeia: a raiz de Merkle deve
+// conferir com as suas transações, todas as transações, exceto a coinbase, devem estar
+// assinadas pelo remetente, e há no máximo uma coinbase, obrigatoriamente a primeira
+// transação e com a recompensa da altura do bloco. A partir do segundo bloco, ele deve
+// apontar para o hash recalculado do anterior e ter uma prova de trabalho válida.
+func (bc *Blockchain) ValidBlock(position int) bool {
+	if position < 0 || position >= len(bc.Chain) {
+		return false
+	}
+	block := bc.Chain[position]
+
+	if block.MerkleRoot != MerkleRoot(block.Transactions) {
+		return false
+	}
+	for i, tx := range block.Transactions {
+		if tx.Sender != CoinbaseSender {
+			if !VerifyTransaction(tx) {
+				return false
+			}
+			continue
+		}
+		if i != 0 || tx.Amount != bc.rewardAt(block.Index-1) {
+			return false
+		}
+	}
+
+	if position == 0 {
+		return true
+	}
+	previous := bc.Chain[position-1]
+	previousHash := previous.Hash()
+	return block.PreviousHash == previousHash && ValidProof(previous.Proof, block.Proof, previousHash, bc.Difficulty)
+}
+
+// Candidate retorna uma blockchain com a cadeia informada e as regras de consenso deste nó
+// (dificuldade e recompensas), para validar com IsValid uma cadeia recebida de outro nó
+func (bc *Blockchain) Candidate(chain []Block) *Blockchain {
+	return &Blockchain{
+		Chain:           chain,
+		Difficulty:      bc.Difficulty,
+		BlockReward:     bc.BlockReward,
+		HalvingInterval: bc.HalvingInterval,
+	}
+}
+
+// ReplaceChain adota a cadeia candidata se ela for mais longa que a local e válida
+// (IsValid, com a dificuldade e as recompensas deste nó). Retorna se a cadeia foi substituída.
+func (bc *Blockchain) ReplaceChain(chain []Block) bool {
+	if len(chain) <= len(bc.Chain) {
+		return false
+	}
+
+	if !bc.Candidate(chain).IsValid() {
+		return false
+	}
+
+	bc.Chain = append([]Block(nil), chain...)
+	return true
+}
@@ -0,0 +1,309 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedClock devolve horários sequenciais pré-definidos a cada chamada
+func fixedClock(times ...time.Time) func() time.Time {
+	i := 0
+	return func() time.Time {
+		t := times[i]
+		if i < len(times)-1 {
+			i++
+		}
+		return t
+	}
+}
+
+func TestAverageBlockTime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bc := NewBlockchain()
+	bc.now = fixedClock(
+		start.Add(10*time.Second),
+		start.Add(30*time.Second),
+		start.Add(60*time.Second),
+	)
+	bc.Chain[0].Timestamp = start.Format(time.RFC3339Nano)
+
+	for i := 0; i < 3; i++ {
+		bc.NewBlock(100)
+	}
+
+	// Intervalos: 10s, 20s, 30s
+	assert.Equal(t, 20*time.Second, bc.AverageBlockTime(0))
+	assert.Equal(t, 25*time.Second, bc.AverageBlockTime(3), "Deve considerar apenas os últimos blocos")
+	assert.Equal(t, 20*time.Second, bc.AverageBlockTime(100), "Janela maior que a cadeia usa a cadeia inteira")
+}
+
+func TestAverageBlockTimeWithSingleBlock(t *testing.T) {
+	bc := NewBlockchain()
+	assert.Equal(t, time.Duration(0), bc.AverageBlockTime(10))
+}
+
+func TestCurrentRewardHalvesAtBoundary(t *testing.T) {
+	bc := NewBlockchain()
+	bc.HalvingInterval = 3
+
+	// Blocos de altura 1 e 2 recebem a recompensa integral
+	for height := 1; height < 3; height++ {
+		block := bc.MineBlock("miner", 0)
+		assert.Equal(t, CoinbaseSender, block.Transactions[0].Sender)
+		assert.Equal(t, DefaultBlockReward, block.Transactions[0].Amount)
+	}
+
+	// A partir da altura 3 a recompensa cai pela metade
+	assert.Equal(t, DefaultBlockReward/2, bc.CurrentReward())
+	block := bc.MineBlock("miner", 0)
+	assert.Equal(t, DefaultBlockReward/2, block.Transactions[0].Amount)
+	assert.Equal(t, "miner", block.Transactions[0].Receiver)
+
+	for len(bc.Chain) < 6 {
+		bc.MineBlock("miner", 0)
+	}
+	assert.Equal(t, DefaultBlockReward/4, bc.CurrentReward())
+}
+
+func TestMineBlockKeepsPendingTransactions(t *testing.T) {
+	bc := NewBlockchain()
+	bc.CurrentTransactions = append(bc.CurrentTransactions, Transaction{ID: "tx-1", Amount: 10, Sender: "a", Receiver: "b"})
+
+	block := bc.MineBlock("miner", 0)
+	assert.Len(t, block.Transactions, 2)
+	assert.Equal(t, "tx-1", block.Transactions[1].ID)
+	assert.Empty(t, bc.CurrentTransactions)
+}
+
+func TestBlockHashIsStableAndSensitiveToChanges(t *testing.T) {
+	bc := NewBlockchain()
+	genesis := bc.LastBlock()
+	assert.Equal(t, GenesisPreviousHash, genesis.PreviousHash)
+
+	hash := genesis.Hash()
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, genesis.Hash(), "O hash deve ser determinístico")
+
+	changed := genesis
+	changed.Proof++
+	assert.NotEqual(t, hash, changed.Hash())
+
+	block := bc.NewBlock(200)
+	assert.Equal(t, hash, block.PreviousHash, "O novo bloco deve apontar para o hash do anterior")
+}
+
+func TestNewBlockOnEmptyChainIsGenesis(t *testing.T) {
+	bc := &Blockchain{now: time.Now}
+	block := bc.NewBlock(100)
+	assert.Equal(t, 1, block.Index)
+	assert.Equal(t, GenesisPreviousHash, block.PreviousHash)
+}
+
+func TestValidProof(t *testing.T) {
+	// Dificuldade zero aceita qualquer prova
+	assert.True(t, ValidProof(100, 0, "abc", 0))
+
+	bc := NewBlockchain()
+	bc.Difficulty = 2
+	lastHash := bc.LastBlock().Hash()
+	proof := bc.ProofOfWork(100)
+	assert.True(t, ValidProof(100, proof, lastHash, 2))
+	for candidate := 0; candidate < proof; candidate++ {
+		assert.False(t, ValidProof(100, candidate, lastHash, 2), "ProofOfWork deve retornar a primeira prova válida")
+	}
+}
+
+func TestProofOfWorkAtDefaultDifficulty(t *testing.T) {
+	bc := NewBlockchain()
+	lastBlock := bc.LastBlock()
+
+	start := time.Now()
+	proof := bc.ProofOfWork(lastBlock.Proof)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.True(t, ValidProof(lastBlock.Proof, proof, lastBlock.Hash(), DefaultDifficulty))
+}
+
+// mineChain minera blocos com prova de trabalho válida até a cadeia ter o tamanho informado
+func mineChain(bc *Blockchain, length int) {
+	for len(bc.Chain) < length {
+		bc.MineBlock("miner", bc.ProofOfWork(bc.LastBlock().Proof))
+	}
+}
+
+func TestIsValidDetectsTampering(t *testing.T) {
+	bc := NewBlockchain()
+	bc.Difficulty = 2
+	mineChain(bc, 4)
+	assert.True(t, bc.IsValid())
+
+	bc.Chain[2].Transactions[0].Amount = 1000
+	assert.False(t, bc.IsValid(), "Alterar uma transação do bloco 3 deve invalidar a cadeia")
+}
+
+func TestValidBlockChecksOnlyTheGivenBlock(t *testing.T) {
+	bc := NewBlockchain()
+	bc.Difficulty = 2
+	mineChain(bc, 4)
+	for i := range bc.Chain {
+		assert.True(t, bc.ValidBlock(i))
+	}
+	assert.False(t, bc.ValidBlock(-1))
+	assert.False(t, bc.ValidBlock(len(bc.Chain)))
+
+	// A alteração do bloco 2 invalida ele e o encadeamento do bloco 3, mas não o bloco 4
+	bc.Chain[1].Transactions[0].Amount = 1000
+	assert.False(t, bc.ValidBlock(1))
+	assert.False(t, bc.ValidBlock(2))
+	assert.True(t, bc.ValidBlock(3))
+	assert.False(t, bc.IsValid())
+}
+
+func TestIsValidRejectsInvalidProof(t *testing.T) {
+	bc := NewBlockchain()
+	bc.Difficulty = 2
+	mineChain(bc, 2)
+
+	// Encadeado corretamente, mas sem prova de trabalho
+	for proof := 0; ; proof++ {
+		if !ValidProof(bc.LastBlock().Proof, proof, bc.LastBlock().Hash(), bc.Difficulty) {
+			bc.NewBlock(proof)
+			break
+		}
+	}
+	assert.False(t, bc.IsValid())
+}
+
+func TestNewTransactionReturnsNextBlockIndex(t *testing.T) {
+	bc := NewBlockchain()
+	donor := newWallet(t)
+
+	assert.Equal(t, 2, bc.NewTransaction(donor.transfer(t, "ngo", 25)))
+	assert.Equal(t, 2, bc.NewTransaction(donor.transfer(t, "ngo", 10)))
+	require.Len(t, bc.CurrentTransactions, 2)
+	assert.NotEqual(t, bc.CurrentTransactions[0].ID, bc.CurrentTransactions[1].ID)
+	assert.NotEmpty(t, bc.CurrentTransactions[0].Timestamp)
+
+	block := bc.NewBlock(100)
+	assert.Equal(t, 2, block.Index)
+	assert.Len(t, block.Transactions, 2)
+	assert.Equal(t, 3, bc.NewTransaction(donor.transfer(t, "ngo", 5)))
+}
+
+func TestNewTransactionRejectsInvalidInput(t *testing.T) {
+	bc := NewBlockchain()
+	donor := newWallet(t)
+
+	noReceiver := donor.transfer(t, "", 10)
+	assert.Equal(t, -1, bc.NewTransaction(noReceiver))
+	assert.Equal(t, -1, bc.NewTransaction(donor.transfer(t, "ngo", 0)))
+	assert.Equal(t, -1, bc.NewTransaction(donor.transfer(t, "ngo", -5)))
+	assert.Equal(t, -1, bc.NewTransaction(Transaction{Receiver: "ngo", Amount: 10}), "Sem remetente")
+	assert.Equal(t, -1, bc.NewTransaction(Transaction{Sender: CoinbaseSender, Receiver: "ngo", Amount: 10}), "Recompensas só entram pela mineração")
+	assert.Empty(t, bc.CurrentTransactions)
+}
+
+func TestReplaceChainAdoptsLongerValidChain(t *testing.T) {
+	local := NewBlockchain()
+	local.Difficulty = 2
+	mineChain(local, 2)
+
+	longer := NewBlockchain()
+	longer.Difficulty = 2
+	mineChain(longer, 4)
+
+	assert.False(t, longer.ReplaceChain(local.Chain), "Uma cadeia mais curta nunca substitui a local")
+
+	tampered := append([]Block(nil), longer.Chain...)
+	tampered = append(tampered, Block{Index: 5, PreviousHash: "falso"})
+	assert.False(t, local.ReplaceChain(tampered), "Uma cadeia inválida é ignorada")
+	assert.Len(t, local.Chain, 2)
+
+	require.True(t, local.ReplaceChain(longer.Chain))
+	assert.Equal(t, longer.Chain, local.Chain)
+	assert.True(t, local.IsValid())
+}
+
+func TestReplaceChainRejectsForgedCoinbase(t *testing.T) {
+	local := NewBlockchain()
+	local.Difficulty = 2
+	mineChain(local, 2)
+
+	// A falsificação é minerada normalmente, com a raiz de Merkle e a prova de trabalho corretas
+	forge := func(tx Transaction) []Block {
+		forged := NewBlockchain()
+		forged.Difficulty = 2
+		mineChain(forged, 2)
+		forged.CurrentTransactions = append(forged.CurrentTransactions, tx)
+		forged.MineBlock("miner", forged.ProofOfWork(forged.LastBlock().Proof))
+		mineChain(forged, 4)
+		return forged.Chain
+	}
+
+	midBlock := forge(Transaction{ID: "coinbase-extra", Amount: 1000, Sender: CoinbaseSender, Receiver: "attacker"})
+	require.Len(t, midBlock[2].Transactions, 2)
+	assert.False(t, local.ReplaceChain(midBlock), "Uma segunda coinbase no meio do bloco é rejeitada")
+
+	inflated := NewBlockchain()
+	inflated.Difficulty = 2
+	inflated.BlockReward = 1000
+	mineChain(inflated, 4)
+	assert.False(t, local.ReplaceChain(inflated.Chain), "A coinbase deve ter a recompensa da altura do bloco")
+	assert.Len(t, local.Chain, 2)
+}
+
+func TestBalanceCountsOnlyConfirmedTransactions(t *testing.T) {
+	bc := NewBlockchain()
+	bc.Difficulty = 2
+	donor, ngo := newWallet(t), newWallet(t)
+
+	bc.NewTransaction(donor.transfer(t, ngo.address, 100))
+	bc.MineBlock("miner", bc.ProofOfWork(bc.LastBlock().Proof))
+	bc.NewTransaction(ngo.transfer(t, "fornecedor", 30))
+	bc.MineBlock("miner", bc.ProofOfWork(bc.LastBlock().Proof))
+
+	assert.Equal(t, 70.0, bc.Balance(ngo.address))
+	assert.Equal(t, -100.0, bc.Balance(donor.address))
+	assert.Equal(t, 30.0, bc.Balance("fornecedor"))
+	assert.Equal(t, 2*DefaultBlockReward, bc.Balance("miner"), "As recompensas chegam pela coinbase")
+
+	bc.NewTransaction(donor.transfer(t, ngo.address, 50))
+	assert.Equal(t, 70.0, bc.Balance(ngo.address), "Transações pendentes não contam")
+	assert.Zero(t, bc.Balance("desconhecido"))
+}
+
+func TestMerkleRootDetectsAlteredTransaction(t *testing.T) {
+	donor := newWallet(t)
+	txs := []Transaction{
+		donor.transfer(t, "ngo-1", 10),
+		donor.transfer(t, "ngo-2", 20),
+		donor.transfer(t, "ngo-3", 30),
+	}
+	root := MerkleRoot(txs)
+	assert.Len(t, root, 64)
+	assert.Equal(t, root, MerkleRoot(append([]Transaction(nil), txs...)), "A raiz é determinística")
+
+	altered := append([]Transaction(nil), txs...)
+	altered[2].Amount = 300
+	assert.NotEqual(t, root, MerkleRoot(altered))
+
+	reordered := []Transaction{txs[1], txs[0], txs[2]}
+	assert.NotEqual(t, root, MerkleRoot(reordered), "A ordem das transações também é comprometida")
+
+	// Com quantidade ímpar, a última folha é duplicada
+	assert.Equal(t, MerkleRoot([]Transaction{txs[0], txs[1], txs[2], txs[2]}), root)
+}
+
+func TestIsValidChecksMerkleRootOfLastBlock(t *testing.T) {
+	bc := NewBlockchain()
+	bc.Difficulty = 2
+	mineChain(bc, 3)
+	require.Equal(t, MerkleRoot(bc.LastBlock().Transactions), bc.LastBlock().MerkleRoot)
+	require.True(t, bc.IsValid())
+
+	// Nenhum bloco aponta para o último, então só a raiz de Merkle revela a alteração
+	bc.Chain[2].Transactions[0].Amount = 1000
+	assert.False(t, bc.IsValid())
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Os endereços da cadeia são as chaves públicas ECDSA (P-256) dos titulares, em hexadecimal
+// no formato comprimido; assim a assinatura de uma transação é verificada com o próprio Sender.
+
+// nonceSize é o tamanho, em bytes, do nonce aleatório de cada transação
+const nonceSize = 16
+
+// GenerateKeyPair cria um par de chaves ECDSA e retorna a chave privada e o endereço
+// (chave pública) correspondente
+func GenerateKeyPair() (*ecdsa.PrivateKey, string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("falha ao gerar chaves: %w", err)
+	}
+	return key, Address(&key.PublicKey), nil
+}
+
+// Address retorna o endereço da chave pública
+func Address(publicKey *ecdsa.PublicKey) string {
+	return hex.EncodeToString(elliptic.MarshalCompressed(publicKey.Curve, publicKey.X, publicKey.Y))
+}
+
+// Sign assina a transação com a chave do remetente. Sem carimbo de tempo, usa o
+// horário atual, e sem nonce, gera um aleatório; ambos também são assinados.
+func Sign(tx *Transaction, key *ecdsa.PrivateKey) error {
+	if tx.Sender != Address(&key.PublicKey) {
+		return errors.New("a chave não pertence ao remetente da transação")
+	}
+	if tx.Timestamp == "" {
+		tx.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	if tx.Nonce == "" {
+		nonce := make([]byte, nonceSize)
+		if _, err := rand.Read(nonce); err != nil {
+			return fmt.Errorf("falha ao gerar o nonce da transação: %w", err)
+		}
+		tx.Nonce = hex.EncodeToString(nonce)
+	}
+
+	digest := tx.signingDigest()
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		return fmt.Errorf("falha ao assinar a transação: %w", err)
+	}
+	tx.Signature = hex.EncodeToString(signature)
+	return nil
+}
+
+// VerifyTransaction confere a assinatura da transação com a chave pública do remetente.
+// Transações sem nonce são recusadas, pois não há como distinguir um reenvio.
+func VerifyTransaction(tx Transaction) bool {
+	if tx.Nonce == "" {
+		return false
+	}
+	publicKey, err := parseAddress(tx.Sender)
+	if err != nil {
+		return false
+	}
+	signature, err := hex.DecodeString(tx.Signature)
+	if err != nil || len(signature) == 0 {
+		return false
+	}
+
+	digest := tx.signingDigest()
+	return ecdsa.VerifyASN1(publicKey, digest[:], signature)
+}
+
+// parseAddress converte um endereço de volta para a chave pública
+func parseAddress(address string) (*ecdsa.PublicKey, error) {
+	data, err := hex.DecodeString(address)
+	if err != nil {
+		return nil, err
+	}
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), data)
+	if x == nil {
+		return nil, errors.New("endereço não é uma chave pública P-256")
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
+
+// signingDigest é o SHA-256 dos campos assinados: remetente, destinatário, valor,
+// carimbo de tempo e nonce. O ID é atribuído pela cadeia depois da assinatura e fica de
+// fora; é o nonce que impede reaproveitar a assinatura (ver Blockchain.NewTransaction).
+func (tx Transaction) signingDigest() [32]byte {
+	amount := strconv.FormatFloat(tx.Amount, 'f', -1, 64)
+	return sha256.Sum256([]byte(tx.Sender + "|" + tx.Receiver + "|" + amount + "|" + tx.Timestamp + "|" + tx.Nonce))
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wallet reúne a chave e o endereço de um titular nos testes
+type wallet struct {
+	key     *ecdsa.PrivateKey
+	address string
+}
+
+func newWallet(t *testing.T) wallet {
+	t.Helper()
+	key, address, err := GenerateKeyPair()
+	require.NoError(t, err)
+	return wallet{key: key, address: address}
+}
+
+// transfer cria uma transação do titular para o destinatário, assinada com a sua chave
+func (w wallet) transfer(t *testing.T, receiver string, amount float64) Transaction {
+	t.Helper()
+	tx := Transaction{Sender: w.address, Receiver: receiver, Amount: amount}
+	require.NoError(t, Sign(&tx, w.key))
+	return tx
+}
+
+func TestSignedTransactionIsAccepted(t *testing.T) {
+	donor := newWallet(t)
+	tx := donor.transfer(t, "ngo", 25)
+
+	assert.NotEmpty(t, tx.Signature)
+	assert.True(t, VerifyTransaction(tx))
+
+	bc := NewBlockchain()
+	assert.Equal(t, 2, bc.NewTransaction(tx))
+}
+
+func TestTamperedTransactionIsRejected(t *testing.T) {
+	donor, other := newWallet(t), newWallet(t)
+	bc := NewBlockchain()
+
+	tampered := donor.transfer(t, "ngo", 25)
+	tampered.Amount = 2500
+	assert.False(t, VerifyTransaction(tampered))
+	assert.Equal(t, -1, bc.NewTransaction(tampered))
+
+	unsigned := Transaction{Sender: donor.address, Receiver: "ngo", Amount: 25}
+	assert.Equal(t, -1, bc.NewTransaction(unsigned))
+
+	// Assinada por outra chave que não a do remetente
+	forged := other.transfer(t, "ngo", 25)
+	forged.Sender = donor.address
+	assert.Equal(t, -1, bc.NewTransaction(forged))
+
+	assert.Error(t, Sign(&Transaction{Sender: donor.address, Receiver: "ngo", Amount: 1}, other.key),
+		"Não se assina em nome de outro remetente")
+	assert.Empty(t, bc.CurrentTransactions)
+}
+
+func TestIsValidVerifiesSignatures(t *testing.T) {
+	donor := newWallet(t)
+	bc := NewBlockchain()
+	bc.Difficulty = 2
+
+	require.Equal(t, 2, bc.NewTransaction(donor.transfer(t, "ngo", 40)))
+	bc.MineBlock("miner", bc.ProofOfWork(bc.LastBlock().Proof))
+	require.True(t, bc.IsValid(), "A coinbase não precisa de assinatura")
+
+	// Mesmo com o bloco minerado de novo, uma transação alterada não confere com a assinatura
+	transactions := append([]Transaction(nil), bc.Chain[1].Transactions...)
+	transactions[1].Amount = 4000
+	rebuilt := &Blockchain{Chain: []Block{bc.Chain[0]}, Difficulty: bc.Difficulty, now: time.Now}
+	rebuilt.CurrentTransactions = transactions
+	rebuilt.NewBlock(rebuilt.ProofOfWork(rebuilt.LastBlock().Proof))
+	assert.False(t, rebuilt.IsValid())
+}
+
+func TestReplayedTransactionIsRejected(t *testing.T) {
+	donor := newWallet(t)
+	bc := NewBlockchain()
+	bc.Difficulty = 2
+
+	tx := donor.transfer(t, "ngo", 40)
+	assert.NotEmpty(t, tx.Nonce)
+	require.Equal(t, 2, bc.NewTransaction(tx))
+	assert.Equal(t, -1, bc.NewTransaction(tx), "A mesma transação já está pendente")
+
+	bc.MineBlock("miner", bc.ProofOfWork(bc.LastBlock().Proof))
+	assert.Equal(t, -1, bc.NewTransaction(tx), "A mesma transação já está na cadeia")
+
+	// O nonce é assinado: trocá-lo invalida a assinatura
+	renonced := tx
+	renonced.Nonce = "outro"
+	assert.False(t, VerifyTransaction(renonced))
+	assert.Equal(t, -1, bc.NewTransaction(renonced))
+
+	// Outra transação com os mesmos campos recebe outro nonce e é aceita
+	assert.Equal(t, 3, bc.NewTransaction(donor.transfer(t, "ngo", 40)))
+	assert.Len(t, bc.CurrentTransactions, 1)
+}
+
+func TestIsValidRejectsReplayedTransaction(t *testing.T) {
+	donor := newWallet(t)
+	bc := NewBlockchain()
+	bc.Difficulty = 2
+
+	tx := donor.transfer(t, "ngo", 40)
+	require.Equal(t, 2, bc.NewTransaction(tx))
+	bc.MineBlock("miner", bc.ProofOfWork(bc.LastBlock().Proof))
+	require.True(t, bc.IsValid())
+
+	// Um bloco minerado com a transação repetida, fora de NewTransaction
+	bc.CurrentTransactions = []Transaction{tx}
+	bc.NewBlock(bc.ProofOfWork(bc.LastBlock().Proof))
+	assert.False(t, bc.IsValid())
+}
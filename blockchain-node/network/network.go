@@ -1,3 +1,54 @@
 package network
 
 // Implementação da comunicação P2P
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"trackable-donations/blockchain-node/core"
+)
+
+// peerTimeout é o tempo máximo de espera pela cadeia de um par
+const peerTimeout = 10 * time.Second
+
+// ChainResponse é a cadeia completa do nó, como trocada entre os pares
+type ChainResponse struct {
+	Chain  []core.Block `json:"chain"`
+	Length int          `json:"length"`
+}
+
+// normalizePeerURL valida o endereço de um par (ex.: http://node2:8545) e remove a barra final
+func normalizePeerURL(rawURL string) (string, error) {
+	peer := strings.TrimRight(strings.TrimSpace(rawURL), "/")
+	u, err := url.Parse(peer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("endereço de nó inválido: %q", rawURL)
+	}
+	return peer, nil
+}
+
+// fetchChain obtém a cadeia completa de um par pelo GET /chain
+func fetchChain(client *http.Client, peer string) ([]core.Block, error) {
+	resp, err := client.Get(peer + "/chain")
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar o nó %s: %w", peer, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nó %s respondeu com status %d", peer, resp.StatusCode)
+	}
+
+	var chain ChainResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chain); err != nil {
+		return nil, fmt.Errorf("resposta inválida do nó %s: %w", peer, err)
+	}
+	if len(chain.Chain) != chain.Length {
+		return nil, fmt.Errorf("tamanho informado pelo nó %s não confere com a cadeia", peer)
+	}
+	return chain.Chain, nil
+}
@@ -0,0 +1,252 @@
+package network
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+	"trackable-donations/blockchain-node/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// averageWindow é a quantidade de blocos usada no cálculo do tempo médio de bloco
+const averageWindow = 10
+
+// Server expõe a blockchain do nó via HTTP
+type Server struct {
+	// mu protege a blockchain e os pares, acessados por requisições concorrentes
+	mu         sync.RWMutex
+	blockchain *core.Blockchain
+	peers      map[string]struct{}
+	// minerAddress recebe a recompensa dos blocos minerados por este nó
+	minerAddress string
+	client       *http.Client
+	now          func() time.Time
+}
+
+// NodeHealth representa o estado de saúde do nó
+type NodeHealth struct {
+	Status              string  `json:"status"`
+	ChainLength         int     `json:"chain_length"`
+	ChainValid          bool    `json:"chain_valid"`
+	Peers               int     `json:"peers"`
+	LastBlockAgeSeconds float64 `json:"last_block_age_seconds"`
+}
+
+// ChainStatus representa o estado atual da cadeia do nó
+type ChainStatus struct {
+	Length                  int     `json:"length"`
+	LastBlockIndex          int     `json:"last_block_index"`
+	PendingTransactions     int     `json:"pending_transactions"`
+	AverageBlockTimeSeconds float64 `json:"average_block_time_seconds"`
+	TargetBlockTimeSeconds  float64 `json:"target_block_time_seconds"`
+	CurrentReward           float64 `json:"current_reward"`
+}
+
+// RegisterNodesRequest lista os endereços dos pares a registrar (ex.: http://node2:8545)
+type RegisterNodesRequest struct {
+	Nodes []string `json:"nodes" binding:"required,min=1"`
+}
+
+// RegisterNodesResponse lista todos os pares conhecidos após o registro
+type RegisterNodesResponse struct {
+	Message string   `json:"message"`
+	Nodes   []string `json:"nodes"`
+}
+
+// BalanceResponse é o saldo confirmado de um endereço
+type BalanceResponse struct {
+	Address string  `json:"address"`
+	Balance float64 `json:"balance"`
+}
+
+// ResolveResponse informa o resultado do consenso e a cadeia vigente do nó
+type ResolveResponse struct {
+	Replaced bool         `json:"replaced"`
+	Length   int          `json:"length"`
+	Chain    []core.Block `json:"chain"`
+}
+
+// DefaultMinerAddress é o endereço que recebe as recompensas quando nenhum é configurado
+const DefaultMinerAddress = "node"
+
+// NewServer cria um novo servidor HTTP para o nó
+func NewServer(blockchain *core.Blockchain) *Server {
+	return &Server{
+		blockchain:   blockchain,
+		peers:        make(map[string]struct{}),
+		minerAddress: DefaultMinerAddress,
+		client:       &http.Client{Timeout: peerTimeout},
+		now:          time.Now,
+	}
+}
+
+// SetMinerAddress define o endereço que recebe as recompensas dos blocos minerados
+func (s *Server) SetMinerAddress(address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minerAddress = address
+}
+
+// Router configura as rotas do nó
+func (s *Server) Router() *gin.Engine {
+	router := gin.Default()
+	router.GET("/health", s.Health)
+	router.GET("/chain", s.Chain)
+	router.GET("/chain/status", s.ChainStatus)
+	router.POST("/mine", s.Mine)
+	router.GET("/balance/:address", s.Balance)
+	router.POST("/nodes/register", s.RegisterNodes)
+	router.POST("/nodes/resolve", s.ResolveConflicts)
+	return router
+}
+
+// Chain retorna a cadeia completa do nó e o seu tamanho
+func (s *Server) Chain(c *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c.JSON(http.StatusOK, ChainResponse{Chain: s.blockchain.Chain, Length: len(s.blockchain.Chain)})
+}
+
+// ChainStatus retorna o tamanho da cadeia e o tempo médio de bloco comparado ao alvo configurado
+func (s *Server) ChainStatus(c *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bc := s.blockchain
+	c.JSON(http.StatusOK, ChainStatus{
+		Length:                  len(bc.Chain),
+		LastBlockIndex:          bc.LastBlock().Index,
+		PendingTransactions:     len(bc.CurrentTransactions),
+		AverageBlockTimeSeconds: bc.AverageBlockTime(averageWindow).Seconds(),
+		TargetBlockTimeSeconds:  bc.BlockTimeTarget.Seconds(),
+		CurrentReward:           bc.CurrentReward(),
+	})
+}
+
+// Mine executa a prova de trabalho sobre o último bloco e fecha um novo bloco com as
+// transações pendentes e a recompensa do minerador. O nó fica bloqueado durante a
+// mineração, para que dois blocos não sejam minerados sobre o mesmo antecessor.
+func (s *Server) Mine(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bc := s.blockchain
+	proof := bc.ProofOfWork(bc.LastBlock().Proof)
+	block := bc.MineBlock(s.minerAddress, proof)
+	c.JSON(http.StatusCreated, block)
+}
+
+// Balance retorna o saldo do endereço nas transações já mineradas
+func (s *Server) Balance(c *gin.Context) {
+	address := c.Param("address")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c.JSON(http.StatusOK, BalanceResponse{Address: address, Balance: s.blockchain.Balance(address)})
+}
+
+// RegisterNodes adiciona pares ao nó; endereços já conhecidos são ignorados
+func (s *Server) RegisterNodes(c *gin.Context) {
+	var req RegisterNodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe a lista de nós em \"nodes\""})
+		return
+	}
+
+	peers := make([]string, 0, len(req.Nodes))
+	for _, node := range req.Nodes {
+		peer, err := normalizePeerURL(node)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		peers = append(peers, peer)
+	}
+
+	s.mu.Lock()
+	for _, peer := range peers {
+		s.peers[peer] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	c.JSON(http.StatusCreated, RegisterNodesResponse{Message: "Nós registrados", Nodes: s.peerList()})
+}
+
+// ResolveConflicts aplica o consenso da cadeia mais longa: consulta todos os pares e
+// adota a maior cadeia válida, se for mais longa que a local. Pares indisponíveis
+// ou com cadeias inválidas são ignorados.
+func (s *Server) ResolveConflicts(c *gin.Context) {
+	s.mu.RLock()
+	rules := s.blockchain.Candidate(nil)
+	s.mu.RUnlock()
+
+	// As cadeias são buscadas sem bloquear o nó, que continua atendendo requisições
+	var longest []core.Block
+	for _, peer := range s.peerList() {
+		chain, err := fetchChain(s.client, peer)
+		if err != nil {
+			log.Printf("Consenso: %v", err)
+			continue
+		}
+		if len(chain) > len(longest) {
+			if rules.Candidate(chain).IsValid() {
+				longest = chain
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replaced := longest != nil && s.blockchain.ReplaceChain(longest)
+	c.JSON(http.StatusOK, ResolveResponse{
+		Replaced: replaced,
+		Length:   len(s.blockchain.Chain),
+		Chain:    s.blockchain.Chain,
+	})
+}
+
+// peerList retorna os pares registrados em ordem alfabética
+func (s *Server) peerList() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	peers := make([]string, 0, len(s.peers))
+	for peer := range s.peers {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+// Health informa se o nó está apto a receber tráfego. Uma cadeia corrompida
+// responde 503 para que o orquestrador retire o nó de rotação.
+func (s *Server) Health(c *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bc := s.blockchain
+	health := NodeHealth{
+		Status:      "online",
+		ChainLength: len(bc.Chain),
+		ChainValid:  bc.ValidChain(),
+		Peers:       len(s.peers),
+	}
+
+	if len(bc.Chain) > 0 {
+		if lastBlockTime, err := bc.LastBlock().Time(); err == nil {
+			health.LastBlockAgeSeconds = s.now().Sub(lastBlockTime).Seconds()
+		}
+	}
+
+	if !health.ChainValid {
+		health.Status = "corrupted"
+		c.JSON(http.StatusServiceUnavailable, health)
+		return
+	}
+
+	c.JSON(http.StatusOK, health)
+}
@@ -0,0 +1,176 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"trackable-donations/blockchain-node/core"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func doRequest(t *testing.T, server *Server, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	server.Router().ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestHealthValidChain(t *testing.T) {
+	bc := core.NewBlockchain()
+	bc.NewBlock(200)
+	server := NewServer(bc)
+
+	w := doRequest(t, server, http.MethodGet, "/health")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var health NodeHealth
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.True(t, health.ChainValid)
+	assert.Equal(t, 2, health.ChainLength)
+	assert.Equal(t, 0, health.Peers)
+	assert.GreaterOrEqual(t, health.LastBlockAgeSeconds, 0.0)
+}
+
+func TestHealthCorruptedChain(t *testing.T) {
+	bc := core.NewBlockchain()
+	bc.NewBlock(200)
+	bc.Chain[1].Index = 7
+	server := NewServer(bc)
+
+	w := doRequest(t, server, http.MethodGet, "/health")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var health NodeHealth
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.False(t, health.ChainValid)
+	assert.Equal(t, "corrupted", health.Status)
+}
+
+// minedChain cria uma cadeia com prova de trabalho válida no tamanho informado
+func minedChain(length int) *core.Blockchain {
+	bc := core.NewBlockchain()
+	bc.Difficulty = 2
+	for len(bc.Chain) < length {
+		bc.MineBlock("miner", bc.ProofOfWork(bc.LastBlock().Proof))
+	}
+	return bc
+}
+
+// signedTransfer cria uma transação assinada por um novo remetente
+func signedTransfer(t *testing.T, receiver string, amount float64) core.Transaction {
+	t.Helper()
+	key, address, err := core.GenerateKeyPair()
+	require.NoError(t, err)
+	tx := core.Transaction{Sender: address, Receiver: receiver, Amount: amount}
+	require.NoError(t, core.Sign(&tx, key))
+	return tx
+}
+
+func doJSON(t *testing.T, server *Server, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	server.Router().ServeHTTP(w, req)
+	return w
+}
+
+func TestChainReturnsFullChain(t *testing.T) {
+	server := NewServer(minedChain(3))
+
+	w := doRequest(t, server, http.MethodGet, "/chain")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var chain ChainResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chain))
+	assert.Equal(t, 3, chain.Length)
+	assert.Len(t, chain.Chain, 3)
+}
+
+func TestRegisterNodesValidatesURLs(t *testing.T) {
+	server := NewServer(core.NewBlockchain())
+
+	w := doJSON(t, server, http.MethodPost, "/nodes/register", `{"nodes":["node2:8545"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSON(t, server, http.MethodPost, "/nodes/register", `{"nodes":["http://node2:8545/","http://node2:8545"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp RegisterNodesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"http://node2:8545"}, resp.Nodes, "Endereços repetidos são registrados uma vez")
+}
+
+func TestResolveAdoptsLongestValidPeerChain(t *testing.T) {
+	longer := minedChain(4)
+	peer := httptest.NewServer(NewServer(longer).Router())
+	defer peer.Close()
+
+	tampered := minedChain(6)
+	tampered.Chain[3].Proof++
+	invalidPeer := httptest.NewServer(NewServer(tampered).Router())
+	defer invalidPeer.Close()
+
+	local := minedChain(2)
+	server := NewServer(local)
+	body := `{"nodes":["` + peer.URL + `","` + invalidPeer.URL + `","http://127.0.0.1:1"]}`
+	require.Equal(t, http.StatusCreated, doJSON(t, server, http.MethodPost, "/nodes/register", body).Code)
+
+	w := doRequest(t, server, http.MethodPost, "/nodes/resolve")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp ResolveResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Replaced, "A cadeia válida mais longa substitui a local; a adulterada e o nó fora do ar são ignorados")
+	assert.Equal(t, 4, resp.Length)
+	assert.Equal(t, longer.Chain, local.Chain)
+
+	w = doRequest(t, server, http.MethodPost, "/nodes/resolve")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Replaced, "Com cadeias do mesmo tamanho a local é mantida")
+}
+
+func TestMineAppendsBlockWithCoinbase(t *testing.T) {
+	bc := core.NewBlockchain()
+	bc.Difficulty = 2
+	require.Equal(t, 2, bc.NewTransaction(signedTransfer(t, "ong", 25)))
+	server := NewServer(bc)
+	server.SetMinerAddress("no-1")
+
+	w := doRequest(t, server, http.MethodPost, "/mine")
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var block core.Block
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &block))
+	assert.Equal(t, 2, block.Index)
+	assert.Equal(t, bc.Chain[0].Hash(), block.PreviousHash)
+	require.Len(t, block.Transactions, 2)
+	assert.Equal(t, core.CoinbaseSender, block.Transactions[0].Sender)
+	assert.Equal(t, "no-1", block.Transactions[0].Receiver)
+	assert.Equal(t, "ong", block.Transactions[1].Receiver)
+
+	assert.Empty(t, bc.CurrentTransactions, "As transações pendentes entram no bloco")
+	assert.True(t, bc.IsValid())
+}
+
+func TestBalanceEndpoint(t *testing.T) {
+	bc := core.NewBlockchain()
+	bc.Difficulty = 2
+	bc.NewTransaction(signedTransfer(t, "ong-1", 40))
+	bc.MineBlock("miner", bc.ProofOfWork(bc.LastBlock().Proof))
+	bc.NewTransaction(signedTransfer(t, "ong-1", 10))
+
+	w := doRequest(t, NewServer(bc), http.MethodGet, "/balance/ong-1")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var balance BalanceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &balance))
+	assert.Equal(t, BalanceResponse{Address: "ong-1", Balance: 40}, balance)
+}